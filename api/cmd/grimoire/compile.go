@@ -0,0 +1,49 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"grimoire-api/internal/snapshot"
+)
+
+// runCompile implements `grimoire compile [flags]`
+func runCompile(args []string) error {
+	flags := flag.NewFlagSet("compile", flag.ExitOnError)
+	dataDir := flags.String("data-dir", defaultDataDir(), "data directory to compile")
+	overlayDir := flags.String("overlay-dir", os.Getenv("OVERLAY_DIR"), "directory of local overlays to apply")
+	output := flags.String("o", "snapshot.bin", "compiled snapshot file to write")
+	strict := flags.Bool("strict", false, "fail if any catalogue file fails to load, instead of compiling the rest")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: grimoire compile [flags]")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Parses the data and overlays and writes them, with every unit transformed, to a binary snapshot")
+		fmt.Fprintln(os.Stderr, "the server loads at startup (COMPILED_SNAPSHOT) instead of parsing the XML.")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	start := time.Now()
+	log.SetOutput(io.Discard)
+	snap, err := snapshot.Compile(snapshot.Config{DataDir: *dataDir, OverlayDir: *overlayDir, Quiet: true, Strict: *strict}, *output)
+	if err != nil {
+		return err
+	}
+
+	for _, loadErr := range snap.LoadReport.Errors {
+		fmt.Fprintf(os.Stderr, "skipped %s: %s\n", loadErr.File, loadErr.Error)
+	}
+
+	info, err := os.Stat(*output)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Compiled revision %s to %s (%.1f MB) in %s: %d catalogues, %d libraries, %d units\n",
+		snap.Revision, *output, float64(info.Size())/(1<<20), time.Since(start).Round(time.Millisecond),
+		len(snap.Parser.GetAllCatalogues()), len(snap.Parser.GetAllLibraries()), len(snap.Resolver.ResolveRootUnits()))
+	return nil
+}
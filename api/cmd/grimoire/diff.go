@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"grimoire-api/internal/diff"
+)
+
+// runDiff implements `grimoire diff [flags] <from> <to>`
+func runDiff(args []string) error {
+	flags := flag.NewFlagSet("diff", flag.ExitOnError)
+	dataDir := flags.String("data-dir", defaultDataDir(), "data git repository used to resolve revisions")
+	format := flags.String("format", "text", "output format: text or json")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: grimoire diff [flags] <from> <to>")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "from and to are data directories or git revisions of the data repository.")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 2 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	if *format != "text" && *format != "json" {
+		return fmt.Errorf("unknown format %q", *format)
+	}
+
+	// Parser progress logging would drown the report
+	log.SetOutput(io.Discard)
+
+	fromParser, fromSource, err := diff.LoadSource(context.Background(), *dataDir, flags.Arg(0), true)
+	if err != nil {
+		return err
+	}
+	toParser, toSource, err := diff.LoadSource(context.Background(), *dataDir, flags.Arg(1), true)
+	if err != nil {
+		return err
+	}
+
+	result, err := diff.Compare(context.Background(), fromParser, toParser)
+	if err != nil {
+		return err
+	}
+	fromSource.GameSystemRevision = result.From.GameSystemRevision
+	toSource.GameSystemRevision = result.To.GameSystemRevision
+	result.From = fromSource
+	result.To = toSource
+
+	if *format == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(result)
+	}
+	return diff.WriteText(os.Stdout, result)
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"grimoire-api/internal/export"
+	"grimoire-api/internal/snapshot"
+)
+
+// runExport implements `grimoire export [flags]`
+func runExport(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	dataDir := flags.String("data-dir", defaultDataDir(), "data directory to export")
+	overlayDir := flags.String("overlay-dir", os.Getenv("OVERLAY_DIR"), "directory of local overlays to apply")
+	database := flags.String("database", "", "database written by grimoire import to export instead of the data directory")
+	format := flags.String("format", export.FormatCSV, "export format: "+strings.Join(export.Formats, ", "))
+	output := flags.String("o", "", "file to write, or - for standard output (default grimoire-<revision>.zip or .db)")
+	strict := flags.Bool("strict", false, "fail if any catalogue file fails to load, instead of exporting the rest")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: grimoire export [flags]")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Writes every unit, model profile, weapon, ability and cost as normalized tables: a zip of CSV")
+		fmt.Fprintln(os.Stderr, "or NDJSON files, or a SQLite database, each with a manifest of the data revisions.")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if !export.ValidFormat(*format) {
+		return fmt.Errorf("unknown format %q", *format)
+	}
+
+	start := time.Now()
+	log.SetOutput(io.Discard)
+	snap, err := snapshot.Load(snapshot.Config{DataDir: *dataDir, OverlayDir: *overlayDir, Quiet: true, Strict: *strict, Database: *database})
+	if err != nil {
+		return err
+	}
+	for _, loadErr := range snap.LoadReport.Errors {
+		fmt.Fprintf(os.Stderr, "skipped %s: %s\n", loadErr.File, loadErr.Error)
+	}
+
+	ctx := context.Background()
+	dataset, err := export.Build(ctx, snap.Revision, snap.Repositories)
+	if err != nil {
+		return err
+	}
+
+	if *output == "-" {
+		_, err := dataset.Write(ctx, os.Stdout, *format)
+		return err
+	}
+	if *output == "" {
+		*output = export.FileName(*format, snap.Revision)
+	}
+	file, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+	manifest, err := dataset.Write(ctx, file, *format)
+	if err != nil {
+		file.Close()
+		os.Remove(*output)
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	var counts []string
+	for _, table := range manifest.Tables {
+		counts = append(counts, fmt.Sprintf("%d %s", table.RowCount, strings.ReplaceAll(table.Name, "_", " ")))
+	}
+	fmt.Printf("Exported revision %s to %s in %s: %s\n", snap.Revision, *output, time.Since(start).Round(time.Millisecond), strings.Join(counts, ", "))
+	return nil
+}
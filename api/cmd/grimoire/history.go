@@ -0,0 +1,58 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"grimoire-api/internal/history"
+)
+
+// runHistory implements `grimoire history [flags] [unit-id]`
+func runHistory(args []string) error {
+	flags := flag.NewFlagSet("history", flag.ExitOnError)
+	dataDir := flags.String("data-dir", defaultDataDir(), "data git repository to index")
+	indexPath := flags.String("index", defaultHistoryIndex(), "points history index file")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: grimoire history [flags] [unit-id]")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Updates the points history index, then prints the history of unit-id as JSON if given.")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() > 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	idx, err := history.Load(*indexPath)
+	if err != nil {
+		return err
+	}
+	indexed, err := history.Update(*dataDir, idx, *indexPath)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Indexed %d new commits; %d units up to %.12s\n", indexed, len(idx.Units), idx.Head)
+
+	if flags.NArg() == 0 {
+		return nil
+	}
+	unit, found := idx.Lookup(flags.Arg(0))
+	if !found {
+		return fmt.Errorf("no points history for unit: %s", flags.Arg(0))
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(unit)
+}
+
+// defaultHistoryIndex returns HISTORY_INDEX or the index file used by the server
+func defaultHistoryIndex() string {
+	if path := os.Getenv("HISTORY_INDEX"); path != "" {
+		return path
+	}
+	return "points-history.json.gz"
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"grimoire-api/internal/snapshot"
+)
+
+// runImport implements `grimoire import [flags]`
+func runImport(args []string) error {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	dataDir := flags.String("data-dir", defaultDataDir(), "data directory to import")
+	overlayDir := flags.String("overlay-dir", os.Getenv("OVERLAY_DIR"), "directory of local overlays to apply")
+	output := flags.String("o", "grimoire.db", "SQLite database to write")
+	strict := flags.Bool("strict", false, "fail if any catalogue file fails to load, instead of importing the rest")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: grimoire import [flags]")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Parses the data and overlays and writes the units, catalogues, factions, rules and game system")
+		fmt.Fprintln(os.Stderr, "to a SQLite database the server can serve (DATABASE) instead of the XML. An existing database")
+		fmt.Fprintln(os.Stderr, "is replaced once the import succeeds.")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	start := time.Now()
+	log.SetOutput(io.Discard)
+	info, err := snapshot.Import(context.Background(), snapshot.Config{DataDir: *dataDir, OverlayDir: *overlayDir, Quiet: true, Strict: *strict}, *output)
+	if err != nil {
+		return err
+	}
+
+	for _, loadErr := range info.LoadReport.Errors {
+		fmt.Fprintf(os.Stderr, "skipped %s: %s\n", loadErr.File, loadErr.Error)
+	}
+
+	stat, err := os.Stat(*output)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Imported revision %s to %s (%.1f MB) in %s: %d catalogues, %d libraries, %d units\n",
+		info.Revision, *output, float64(stat.Size())/(1<<20), time.Since(start).Round(time.Millisecond),
+		info.Catalogues, info.Libraries, info.Units)
+	return nil
+}
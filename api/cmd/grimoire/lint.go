@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"grimoire-api/internal/lint"
+	"grimoire-api/internal/models"
+	"grimoire-api/internal/parser"
+)
+
+// runLint implements `grimoire lint [flags]`
+func runLint(args []string) error {
+	flags := flag.NewFlagSet("lint", flag.ExitOnError)
+	dataDir := flags.String("data-dir", defaultDataDir(), "data directory to check")
+	format := flags.String("format", "text", "output format: text or json")
+	failOn := flags.String("fail-on", models.SeverityError, "exit with status 1 on issues of this severity or worse: error, warning or none")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: grimoire lint [flags]")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Checks every catalogue for unresolved links, units without profiles or points, duplicate IDs, cyclic links and files that fail to parse.")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if *format != "text" && *format != "json" {
+		return fmt.Errorf("unknown format %q", *format)
+	}
+	if *failOn != models.SeverityError && *failOn != models.SeverityWarning && *failOn != "none" {
+		return fmt.Errorf("unknown severity %q", *failOn)
+	}
+
+	// Catalogues that fail to parse are reported as issues and the rest are still checked
+	log.SetOutput(io.Discard)
+	p := parser.NewParser(*dataDir)
+	if err := p.LoadGameSystem(); err != nil {
+		return err
+	}
+	var loadErrs *parser.LoadErrors
+	if err := p.LoadAllCatalogues(); err != nil && !errors.As(err, &loadErrs) {
+		return err
+	}
+	report := lint.Run(p)
+
+	if *format == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			return err
+		}
+	} else {
+		for _, issue := range report.Issues {
+			location := issue.File
+			if issue.Line > 0 {
+				location = fmt.Sprintf("%s:%d", issue.File, issue.Line)
+			}
+			fmt.Printf("%s: %s: [%s] %s\n", location, issue.Severity, issue.Rule, issue.Message)
+		}
+		fmt.Printf("%d files, %d units checked: %d errors, %d warnings\n",
+			report.Summary.Files, report.Summary.Units, report.Summary.Errors, report.Summary.Warnings)
+	}
+
+	failures := 0
+	switch *failOn {
+	case models.SeverityError:
+		failures = report.Summary.Errors
+	case models.SeverityWarning:
+		failures = report.Summary.Errors + report.Summary.Warnings
+	}
+	if failures > 0 {
+		return fmt.Errorf("%d issues at or above %s severity", failures, *failOn)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// command is a grimoire subcommand
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) error
+}
+
+var commands = []command{
+	{"compile", "Compile the data into a binary snapshot the server loads at startup", runCompile},
+	{"diff", "Compare two data directories or git revisions of the data repository", runDiff},
+	{"export", "Export every unit as normalized CSV, NDJSON or SQLite tables for analysis", runExport},
+	{"history", "Index unit points history from the data repository's git log", runHistory},
+	{"import", "Import the data into a SQLite database the server can serve instead of the XML", runImport},
+	{"lint", "Check the data for unresolved links, missing profiles and points, duplicate IDs and cycles", runLint},
+	{"site", "Write a static JSON mirror of the API for a static host or CDN", runSite},
+}
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] == "-h" || os.Args[1] == "--help" || os.Args[1] == "help" {
+		usage()
+		os.Exit(2)
+	}
+
+	for _, cmd := range commands {
+		if cmd.name == os.Args[1] {
+			if err := cmd.run(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "grimoire %s: %v\n", cmd.name, err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "grimoire: unknown command %q\n\n", os.Args[1])
+	usage()
+	os.Exit(2)
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: grimoire <command> [flags] [arguments]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Run 'grimoire <command> -h' for command flags.")
+}
+
+// defaultDataDir returns DATA_DIR or the default data directory used by the server
+func defaultDataDir() string {
+	if dataDir := os.Getenv("DATA_DIR"); dataDir != "" {
+		return dataDir
+	}
+	return "../wh40k-10e"
+}
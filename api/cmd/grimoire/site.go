@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"grimoire-api/internal/site"
+	"grimoire-api/internal/snapshot"
+)
+
+// runSite implements `grimoire site [flags]`
+func runSite(args []string) error {
+	flags := flag.NewFlagSet("site", flag.ExitOnError)
+	dataDir := flags.String("data-dir", defaultDataDir(), "data directory to mirror")
+	overlayDir := flags.String("overlay-dir", os.Getenv("OVERLAY_DIR"), "directory of local overlays to apply")
+	database := flags.String("database", "", "database written by grimoire import to mirror instead of the data directory")
+	output := flags.String("o", "site", "directory to write the site to, replacing a site generated there before")
+	pageSize := flags.Int("page-size", site.DefaultPageSize, "units on each page of the unit list")
+	strict := flags.Bool("strict", false, "fail if any catalogue file fails to load, instead of mirroring the rest")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: grimoire site [flags]")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Writes the JSON body of every read route of the API to a directory a static host or CDN can")
+		fmt.Fprintln(os.Stderr, "serve, with an index.json manifest of content hashes and precomputed search shards.")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	start := time.Now()
+	log.SetOutput(io.Discard)
+	gin.SetMode(gin.ReleaseMode)
+	snapshots := snapshot.NewStore(snapshot.Config{DataDir: *dataDir, OverlayDir: *overlayDir, Quiet: true, Strict: *strict, Database: *database})
+	snap, _, err := snapshots.Reload()
+	if err != nil {
+		return err
+	}
+	for _, loadErr := range snap.LoadReport.Errors {
+		fmt.Fprintf(os.Stderr, "skipped %s: %s\n", loadErr.File, loadErr.Error)
+	}
+
+	manifest, err := site.Generate(context.Background(), snapshots, *output, *pageSize)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Generated revision %s to %s in %s: %d files, %d unit pages, %d search shards\n",
+		manifest.Revision, *output, time.Since(start).Round(time.Millisecond), len(manifest.Files), manifest.UnitPages, len(manifest.Search))
+	return nil
+}
@@ -2,15 +2,23 @@ package main
 
 import (
 	"log"
+	"net"
 	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
 
-	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 
 	"grimoire-api/internal/cache"
-	"grimoire-api/internal/handlers"
-	"grimoire-api/internal/parser"
+	"grimoire-api/internal/export"
+	"grimoire-api/internal/graphql"
+	"grimoire-api/internal/grpc"
+	"grimoire-api/internal/homebrew"
+	"grimoire-api/internal/server"
 	"grimoire-api/internal/service"
+	"grimoire-api/internal/snapshot"
 )
 
 func main() {
@@ -20,97 +28,139 @@ func main() {
 		dataDir = "../wh40k-10e"
 	}
 
-	log.Printf("Initializing parser with data directory: %s", dataDir)
-
-	// Initialize parser
-	p := parser.NewParser(dataDir)
-
-	// Load game system
-	if err := p.LoadGameSystem(); err != nil {
-		log.Fatalf("Failed to load game system: %v", err)
+	// Points history index, built from the data repository's git log
+	historyIndex := os.Getenv("HISTORY_INDEX")
+	if historyIndex == "" {
+		historyIndex = "points-history.json.gz"
 	}
 
-	// Load all catalogues
-	if err := p.LoadAllCatalogues(); err != nil {
-		log.Fatalf("Failed to load catalogues: %v", err)
+	// Homebrew catalogues uploaded through the API are saved here and loaded into every snapshot
+	homebrewDir := os.Getenv("HOMEBREW_DIR")
+	if homebrewDir == "" {
+		homebrewDir = "homebrew"
 	}
+	homebrewStore := homebrew.NewStore(homebrewDir)
 
-	log.Printf("Loaded %d catalogues and %d libraries", len(p.GetAllCatalogues()), len(p.GetAllLibraries()))
+	// strict refuses to load data with a bad catalogue file; lenient serves everything that parsed
+	loadMode := os.Getenv("LOAD_MODE")
+	if loadMode == "" {
+		loadMode = "lenient"
+	}
+	if loadMode != "strict" && loadMode != "lenient" {
+		log.Fatalf("LOAD_MODE must be strict or lenient, not %q", loadMode)
+	}
 
-	// Initialize components
-	cache := cache.NewCache()
-	resolver := parser.NewLinkResolver(p)
-	transformer := parser.NewTransformer(resolver)
+	// Transformed responses are cached for every retained snapshot, up to this many units in total
+	cacheSize := cache.DefaultCapacity
+	if value := os.Getenv("CACHE_SIZE"); value != "" {
+		size, err := strconv.Atoi(value)
+		if err != nil || size <= 0 {
+			log.Fatalf("CACHE_SIZE must be a positive number, not %q", value)
+		}
+		cacheSize = size
+	}
 
-	// Initialize services
-	unitService := service.NewUnitService(p, resolver, transformer, cache)
-	catalogueService := service.NewCatalogueService(p, resolver, transformer, cache)
+	// Requests answer 504 once past their deadline: REQUEST_TIMEOUT for single lookups and the
+	// longer SLOW_REQUEST_TIMEOUT for routes that list, search or diff whole datasets
+	requestTimeout := durationEnv("REQUEST_TIMEOUT", server.DefaultRequestTimeout)
+	slowRequestTimeout := durationEnv("SLOW_REQUEST_TIMEOUT", server.DefaultSlowRequestTimeout)
+
+	// GraphQL queries nested or estimated to resolve beyond these limits are refused before they run
+	graphQLLimits := graphql.DefaultLimits
+	graphQLLimits.MaxDepth = positiveEnv("GRAPHQL_MAX_DEPTH", graphQLLimits.MaxDepth)
+	graphQLLimits.MaxComplexity = positiveEnv("GRAPHQL_MAX_COMPLEXITY", graphQLLimits.MaxComplexity)
+
+	// Snapshots kept for requests pinned to a revision, including the current one
+	retained := positiveEnv("SNAPSHOT_RETAINED", snapshot.DefaultRetained)
+
+	log.Printf("Loading data from %s", dataDir)
+
+	// Load the first snapshot of the data. Reloads publish new snapshots without touching this one.
+	snapshots := snapshot.NewStore(snapshot.Config{
+		DataDir:    dataDir,
+		OverlayDir: os.Getenv("OVERLAY_DIR"),
+		Homebrew:   homebrewStore,
+		Strict:     loadMode == "strict",
+		Cache:      cache.New(cacheSize),
+		// Transform every unit after each load; /ready reports warming until the first load is done
+		WarmUp:   os.Getenv("WARM_UP") == "true",
+		Retained: retained,
+		// Written by `grimoire compile`; used while it matches the data, otherwise the XML is parsed
+		CompiledFile: os.Getenv("COMPILED_SNAPSHOT"),
+		// Written by `grimoire import`; served instead of the XML, without the XML-only routes
+		Database: os.Getenv("DATABASE"),
+	})
+	current, _, err := snapshots.Reload()
+	if err != nil {
+		log.Fatalf("Failed to load data: %v", err)
+	}
+	info := current.Info()
+	source := "parsed"
+	if info.Compiled {
+		source = "compiled"
+	} else if info.Database {
+		source = "database"
+	}
+	log.Printf("Loaded %s snapshot %s: %d catalogues and %d libraries", source, current.Revision, info.Catalogues, info.Libraries)
+	if failed := len(current.LoadReport.Errors); failed > 0 {
+		log.Printf("%d of %d catalogue files failed to load; see /health", failed, current.LoadReport.Files)
+	}
 
-	// Initialize handlers
-	unitHandler := handlers.NewUnitHandler(unitService)
-	catalogueHandler := handlers.NewCatalogueHandler(catalogueService)
-	factionHandler := handlers.NewFactionHandler(unitService, catalogueService)
-	searchHandler := handlers.NewSearchHandler(unitService)
-	gameSystemHandler := handlers.NewGameSystemHandler(p)
+	// Reload on SIGHUP, for example after pulling new data
+	hangups := make(chan os.Signal, 1)
+	signal.Notify(hangups, syscall.SIGHUP)
+	go func() {
+		for range hangups {
+			snap, changed, err := snapshots.Reload()
+			if err != nil {
+				log.Printf("Failed to reload data: %v", err)
+				continue
+			}
+			if changed {
+				log.Printf("Loaded snapshot %s", snap.Revision)
+			}
+		}
+	}()
+
+	historyService := service.NewHistoryService(dataDir, historyIndex)
+
+	// Serve the saved history right away. Indexing new data commits loads every one of them, so it
+	// runs in the background only with INDEX_HISTORY=true; otherwise build it with `grimoire history`.
+	if err := historyService.Load(); err != nil {
+		log.Printf("Failed to load points history index: %v", err)
+	}
+	if os.Getenv("INDEX_HISTORY") == "true" {
+		go func() {
+			indexed, err := historyService.Refresh()
+			if err != nil {
+				log.Printf("Failed to update points history index: %v", err)
+				return
+			}
+			log.Printf("Points history index up to date (%d new commits indexed)", indexed)
+		}()
+	}
 
-	// Setup Gin router
 	if os.Getenv("GIN_MODE") == "release" {
 		gin.SetMode(gin.ReleaseMode)
 	}
-
-	router := gin.Default()
-
-	// CORS middleware
-	config := cors.DefaultConfig()
-	config.AllowAllOrigins = true
-	config.AllowMethods = []string{"GET", "OPTIONS"}
-	router.Use(cors.New(config))
-
-	// Health check
-	router.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{
-			"status": "healthy",
-		})
-	})
-
-	// API routes
-	v1 := router.Group("/api/v1")
-	{
-		// Game system
-		v1.GET("/game-system", gameSystemHandler.GetGameSystem)
-
-		// Catalogues
-		v1.GET("/catalogues", catalogueHandler.ListCatalogues)
-		v1.GET("/catalogues/:id", catalogueHandler.GetCatalogue)
-		v1.GET("/catalogues/:id/units", catalogueHandler.GetCatalogueUnits)
-
-		// Units
-		v1.GET("/units", unitHandler.ListUnits)
-		v1.GET("/units/:id", unitHandler.GetUnit)
-		v1.GET("/units/:id/weapons", unitHandler.GetUnitWeapons)
-
-		// Factions
-		v1.GET("/factions", factionHandler.ListFactions)
-		v1.GET("/factions/:name/units", factionHandler.GetFactionUnits)
-
-		// Search
-		v1.GET("/search", searchHandler.Search)
-	}
-
-	// Root endpoint
-	router.GET("/", func(c *gin.Context) {
-		c.JSON(200, gin.H{
-			"name":    "Warhammer 40K 10th Edition API",
-			"version": "1.0.0",
-			"endpoints": gin.H{
-				"game-system": "/api/v1/game-system",
-				"catalogues":  "/api/v1/catalogues",
-				"units":       "/api/v1/units",
-				"factions":    "/api/v1/factions",
-				"search":      "/api/v1/search",
-			},
-		})
+	// Exports are written once per snapshot and format, up to EXPORT_WRITES at once, and kept in a
+	// temporary directory while their snapshot is retained
+	exports := export.NewFiles("", positiveEnv("EXPORT_WRITES", export.DefaultWrites))
+
+	router, err := server.NewRouter(server.Config{
+		Snapshots:          snapshots,
+		History:            historyService,
+		Homebrew:           homebrewStore,
+		Exports:            exports,
+		HomebrewToken:      os.Getenv("HOMEBREW_TOKEN"),
+		AdminToken:         os.Getenv("ADMIN_TOKEN"),
+		RequestTimeout:     requestTimeout,
+		SlowRequestTimeout: slowRequestTimeout,
+		GraphQLLimits:      graphQLLimits,
 	})
+	if err != nil {
+		log.Fatalf("Failed to set up routes: %v", err)
+	}
 
 	// Get port from environment or use default
 	port := os.Getenv("PORT")
@@ -118,10 +168,49 @@ func main() {
 		port = "8080"
 	}
 
+	// gRPC server for typed clients, on its own port; off unless GRPC_PORT is set
+	if grpcPort := os.Getenv("GRPC_PORT"); grpcPort != "" {
+		listener, err := net.Listen("tcp", ":"+grpcPort)
+		if err != nil {
+			log.Fatalf("Failed to listen for gRPC: %v", err)
+		}
+		grpcServer := grpc.NewServer(snapshots)
+		go func() {
+			log.Printf("Starting gRPC server on port %s", grpcPort)
+			if err := grpcServer.Serve(listener); err != nil {
+				log.Fatalf("Failed to start gRPC server: %v", err)
+			}
+		}()
+	}
+
 	log.Printf("Starting server on port %s", port)
 	if err := router.Run(":" + port); err != nil {
 		log.Fatalf("Failed to start server: %v", err)
 	}
 }
 
+// durationEnv reads a duration such as "30s" from the environment variable name, or returns fallback
+func durationEnv(name string, fallback time.Duration) time.Duration {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		log.Fatalf("%s must be a positive duration such as 30s, not %q", name, value)
+	}
+	return d
+}
 
+// positiveEnv reads a positive number from the environment variable name, or returns fallback
+func positiveEnv(name string, fallback int) int {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		log.Fatalf("%s must be a positive number, not %q", name, value)
+	}
+	return n
+}
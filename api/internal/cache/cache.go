@@ -1,76 +1,339 @@
+// Package cache holds transformed responses in a size-bounded LRU cache shared by every snapshot
+// of the data. Entries are keyed by snapshot revision, so a pinned snapshot never sees values
+// computed from another one.
 package cache
 
 import (
+	"container/list"
+	"context"
+	"errors"
+	"sort"
+	"strings"
 	"sync"
 
+	"golang.org/x/sync/singleflight"
+
 	"grimoire-api/internal/models"
 )
 
-// Cache provides in-memory caching for parsed data
+// DefaultCapacity is the default total cost of the cached values. A value costs one per unit it
+// holds, so the default keeps about this many transformed units.
+const DefaultCapacity = 20000
+
+// Kind is the kind of a cached value
+type Kind string
+
+const (
+	KindUnit      Kind = "unit"
+	KindCatalogue Kind = "catalogue"
+	KindUnitList  Kind = "unitList"
+	KindSearch    Kind = "search"
+	KindDiff      Kind = "diff"
+)
+
+// Key identifies a cached value
+type Key struct {
+	Revision string // Snapshot the value was computed from
+	Kind     Kind
+	ID       string
+	Query    string // Canonical query parameters, for lists and searches
+}
+
+func (k Key) String() string {
+	return strings.Join([]string{k.Revision, string(k.Kind), k.ID, k.Query}, "\x00")
+}
+
+type entry struct {
+	key   Key
+	value interface{}
+	cost  int
+}
+
+type kindStats struct {
+	hits, misses uint64
+}
+
+// store is the LRU shared by every Cache made from the same New
+type store struct {
+	mu        sync.Mutex
+	capacity  int
+	cost      int
+	lru       *list.List // Front is most recently used
+	items     map[Key]*list.Element
+	kinds     map[Kind]*kindStats
+	evictions uint64
+
+	loads singleflight.Group
+}
+
+// Cache provides in-memory caching for transformed data. Each Cache reads and writes the entries
+// of one revision; ForRevision gives views of other revisions sharing the same bounded store.
 type Cache struct {
-	units       map[string]*models.UnitResponse
-	catalogues  map[string]*models.CatalogueResponse
-	mu          sync.RWMutex
+	store    *store
+	revision string
+}
+
+// New creates a cache holding values up to a total cost of capacity
+func New(capacity int) *Cache {
+	if capacity <= 0 {
+		capacity = DefaultCapacity
+	}
+	return &Cache{store: &store{
+		capacity: capacity,
+		lru:      list.New(),
+		items:    make(map[Key]*list.Element),
+		kinds:    make(map[Kind]*kindStats),
+	}}
 }
 
-// NewCache creates a new cache instance
+// NewCache creates a new cache instance with the default capacity
 func NewCache() *Cache {
-	return &Cache{
-		units:      make(map[string]*models.UnitResponse),
-		catalogues: make(map[string]*models.CatalogueResponse),
+	return New(DefaultCapacity)
+}
+
+// ForRevision returns a view of the cache for the snapshot with the given revision
+func (c *Cache) ForRevision(revision string) *Cache {
+	return &Cache{store: c.store, revision: revision}
+}
+
+// Revision returns the revision the cache reads and writes
+func (c *Cache) Revision() string {
+	return c.revision
+}
+
+func (c *Cache) key(kind Kind, id, query string) Key {
+	return Key{Revision: c.revision, Kind: kind, ID: id, Query: query}
+}
+
+// Get returns a cached value, counting a hit or a miss
+func (c *Cache) Get(kind Kind, id, query string) (interface{}, bool) {
+	s := c.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stats := s.kindStats(kind)
+	if el, ok := s.items[c.key(kind, id, query)]; ok {
+		s.lru.MoveToFront(el)
+		stats.hits++
+		return el.Value.(*entry).value, true
+	}
+	stats.misses++
+	return nil, false
+}
+
+// Set caches a value, evicting the least recently used values to keep within the capacity.
+// A value costing more than the whole capacity isn't cached.
+func (c *Cache) Set(kind Kind, id, query string, value interface{}, cost int) {
+	if cost < 1 {
+		cost = 1
+	}
+	s := c.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := c.key(kind, id, query)
+	if el, ok := s.items[key]; ok {
+		s.remove(el)
+	}
+	if cost > s.capacity {
+		return
+	}
+	s.items[key] = s.lru.PushFront(&entry{key: key, value: value, cost: cost})
+	s.cost += cost
+	for s.cost > s.capacity {
+		s.remove(s.lru.Back())
+		s.evictions++
 	}
 }
 
+// Load returns a cached value, or computes and caches it with load. Concurrent misses on the same
+// key share one call to load, which gets the context of the caller that started it. Errors are
+// returned to every waiting caller and not cached, except that when the caller running load gives
+// up, the others try again rather than fail with its context's error.
+func (c *Cache) Load(ctx context.Context, kind Kind, id, query string, load func(ctx context.Context) (value interface{}, cost int, err error)) (interface{}, error) {
+	if value, ok := c.Get(kind, id, query); ok {
+		return value, nil
+	}
+	key := c.key(kind, id, query)
+	for {
+		results := c.store.loads.DoChan(key.String(), func() (interface{}, error) {
+			// Another caller may have finished loading it between the miss and now
+			if value, ok := c.store.peek(key); ok {
+				return value, nil
+			}
+			value, cost, err := load(ctx)
+			if err != nil {
+				return nil, err
+			}
+			c.Set(kind, id, query, value, cost)
+			return value, nil
+		})
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case result := <-results:
+			if isContextError(result.Err) && ctx.Err() == nil {
+				continue
+			}
+			return result.Val, result.Err
+		}
+	}
+}
+
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
 // SetUnit caches a unit response
 func (c *Cache) SetUnit(id string, unit *models.UnitResponse) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	c.units[id] = unit
+	c.Set(KindUnit, id, "", unit, 1)
 }
 
 // GetUnit retrieves a cached unit response
 func (c *Cache) GetUnit(id string) (*models.UnitResponse, bool) {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-	unit, exists := c.units[id]
-	return unit, exists
+	value, exists := c.Get(KindUnit, id, "")
+	if !exists {
+		return nil, false
+	}
+	return value.(*models.UnitResponse), true
+}
+
+// Unit returns a cached unit response, or transforms it once with load
+func (c *Cache) Unit(ctx context.Context, id string, load func(ctx context.Context) (*models.UnitResponse, error)) (*models.UnitResponse, error) {
+	value, err := c.Load(ctx, KindUnit, id, "", func(ctx context.Context) (interface{}, int, error) {
+		unit, err := load(ctx)
+		return unit, 1, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.(*models.UnitResponse), nil
 }
 
 // SetCatalogue caches a catalogue response
 func (c *Cache) SetCatalogue(id string, catalogue *models.CatalogueResponse) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	c.catalogues[id] = catalogue
+	c.Set(KindCatalogue, id, "", catalogue, len(catalogue.Units)+1)
 }
 
 // GetCatalogue retrieves a cached catalogue response
 func (c *Cache) GetCatalogue(id string) (*models.CatalogueResponse, bool) {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-	catalogue, exists := c.catalogues[id]
-	return catalogue, exists
+	value, exists := c.Get(KindCatalogue, id, "")
+	if !exists {
+		return nil, false
+	}
+	return value.(*models.CatalogueResponse), true
+}
+
+// Catalogue returns a cached catalogue response, or transforms it once with load
+func (c *Cache) Catalogue(ctx context.Context, id string, load func(ctx context.Context) (*models.CatalogueResponse, error)) (*models.CatalogueResponse, error) {
+	value, err := c.Load(ctx, KindCatalogue, id, "", func(ctx context.Context) (interface{}, int, error) {
+		catalogue, err := load(ctx)
+		if err != nil {
+			return nil, 0, err
+		}
+		return catalogue, len(catalogue.Units) + 1, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.(*models.CatalogueResponse), nil
 }
 
-// Clear clears all cached data
+// Clear clears all cached data, of every revision
 func (c *Cache) Clear() {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	c.units = make(map[string]*models.UnitResponse)
-	c.catalogues = make(map[string]*models.CatalogueResponse)
+	s := c.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lru.Init()
+	s.items = make(map[Key]*list.Element)
+	s.cost = 0
 }
 
 // ClearUnit removes a specific unit from cache
 func (c *Cache) ClearUnit(id string) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	delete(c.units, id)
+	c.delete(c.key(KindUnit, id, ""))
 }
 
 // ClearCatalogue removes a specific catalogue from cache
 func (c *Cache) ClearCatalogue(id string) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	delete(c.catalogues, id)
+	c.delete(c.key(KindCatalogue, id, ""))
 }
 
+// ClearRevision removes every value cached for a revision, once its snapshot is no longer served
+func (c *Cache) ClearRevision(revision string) {
+	s := c.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, el := range s.items {
+		if key.Revision == revision {
+			s.remove(el)
+		}
+	}
+}
 
+func (c *Cache) delete(key Key) {
+	s := c.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if el, ok := s.items[key]; ok {
+		s.remove(el)
+	}
+}
+
+// Stats reports the size of the cache and its hits, misses and evictions since it was created
+func (c *Cache) Stats() models.CacheStats {
+	s := c.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := models.CacheStats{
+		Capacity:  s.capacity,
+		Cost:      s.cost,
+		Entries:   len(s.items),
+		Evictions: s.evictions,
+		Kinds:     make([]models.CacheKindStats, 0, len(s.kinds)),
+	}
+	entries := make(map[Kind]int)
+	for key := range s.items {
+		entries[key.Kind]++
+	}
+	for kind, counts := range s.kinds {
+		stats.Hits += counts.hits
+		stats.Misses += counts.misses
+		stats.Kinds = append(stats.Kinds, models.CacheKindStats{
+			Kind:    string(kind),
+			Entries: entries[kind],
+			Hits:    counts.hits,
+			Misses:  counts.misses,
+		})
+	}
+	sort.Slice(stats.Kinds, func(i, j int) bool { return stats.Kinds[i].Kind < stats.Kinds[j].Kind })
+	return stats
+}
+
+// kindStats returns the counters of a kind; s.mu must be held
+func (s *store) kindStats(kind Kind) *kindStats {
+	stats, ok := s.kinds[kind]
+	if !ok {
+		stats = &kindStats{}
+		s.kinds[kind] = stats
+	}
+	return stats
+}
+
+// peek returns a cached value without counting it or marking it used
+func (s *store) peek(key Key) (interface{}, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if el, ok := s.items[key]; ok {
+		return el.Value.(*entry).value, true
+	}
+	return nil, false
+}
+
+// remove drops an element; s.mu must be held
+func (s *store) remove(el *list.Element) {
+	e := s.lru.Remove(el).(*entry)
+	delete(s.items, e.key)
+	s.cost -= e.cost
+}
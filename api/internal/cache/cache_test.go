@@ -0,0 +1,156 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"grimoire-api/internal/models"
+)
+
+func TestEvictsLeastRecentlyUsed(t *testing.T) {
+	c := New(3)
+	c.SetUnit("a", &models.UnitResponse{ID: "a"})
+	c.SetUnit("b", &models.UnitResponse{ID: "b"})
+	c.SetUnit("c", &models.UnitResponse{ID: "c"})
+
+	// Reading a makes b the least recently used
+	_, found := c.GetUnit("a")
+	require.True(t, found)
+	c.SetUnit("d", &models.UnitResponse{ID: "d"})
+
+	_, found = c.GetUnit("b")
+	assert.False(t, found)
+	for _, id := range []string{"a", "c", "d"} {
+		_, found := c.GetUnit(id)
+		assert.True(t, found, id)
+	}
+
+	// A catalogue costs one per unit, so caching it evicts units to make room
+	c.SetCatalogue("cat", &models.CatalogueResponse{Units: make([]models.UnitSummary, 1)})
+	stats := c.Stats()
+	assert.Equal(t, 3, stats.Cost)
+	assert.Equal(t, 2, stats.Entries)
+	assert.Equal(t, uint64(3), stats.Evictions)
+
+	// A value larger than the whole cache isn't cached
+	c.SetCatalogue("huge", &models.CatalogueResponse{Units: make([]models.UnitSummary, 3)})
+	_, found = c.GetCatalogue("huge")
+	assert.False(t, found)
+}
+
+func TestRevisionsAreSeparate(t *testing.T) {
+	shared := NewCache()
+	first := shared.ForRevision("rev1")
+	second := shared.ForRevision("rev2")
+
+	first.SetUnit("u", &models.UnitResponse{Costs: map[string]int{"pts": 80}})
+	second.SetUnit("u", &models.UnitResponse{Costs: map[string]int{"pts": 90}})
+
+	unit, found := first.GetUnit("u")
+	require.True(t, found)
+	assert.Equal(t, 80, unit.Costs["pts"])
+	unit, found = second.GetUnit("u")
+	require.True(t, found)
+	assert.Equal(t, 90, unit.Costs["pts"])
+
+	shared.ClearRevision("rev1")
+	_, found = first.GetUnit("u")
+	assert.False(t, found)
+	_, found = second.GetUnit("u")
+	assert.True(t, found)
+}
+
+func TestLoadTransformsOnce(t *testing.T) {
+	c := NewCache()
+	var calls atomic.Int32
+	release := make(chan struct{})
+	load := func(ctx context.Context) (*models.UnitResponse, error) {
+		calls.Add(1)
+		<-release
+		return &models.UnitResponse{ID: "u"}, nil
+	}
+
+	var wg sync.WaitGroup
+	results := make([]*models.UnitResponse, 10)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			unit, err := c.Unit(context.Background(), "u", load)
+			assert.NoError(t, err)
+			results[i] = unit
+		}(i)
+	}
+	// Let the goroutines pile up on the first load before it finishes
+	for c.Stats().Misses < uint64(len(results)) {
+		runtime.Gosched()
+	}
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), calls.Load())
+	for _, unit := range results {
+		assert.Same(t, results[0], unit)
+	}
+
+	// Errors aren't cached
+	_, err := c.Unit(context.Background(), "broken", func(ctx context.Context) (*models.UnitResponse, error) { return nil, errors.New("not found") })
+	assert.Error(t, err)
+	_, found := c.GetUnit("broken")
+	assert.False(t, found)
+}
+
+func TestLoadCancellation(t *testing.T) {
+	c := NewCache()
+	started := make(chan struct{})
+	slow := func(ctx context.Context) (*models.UnitResponse, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	// The caller running the load gives up
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		_, err := c.Unit(ctx, "u", slow)
+		done <- err
+	}()
+	<-started
+
+	// A second caller waiting on the same load isn't failed by the first one's cancellation:
+	// it loads the unit itself
+	waiter := make(chan *models.UnitResponse)
+	go func() {
+		unit, err := c.Unit(context.Background(), "u", func(ctx context.Context) (*models.UnitResponse, error) {
+			return &models.UnitResponse{ID: "u"}, nil
+		})
+		assert.NoError(t, err)
+		waiter <- unit
+	}()
+	for c.Stats().Misses < 2 {
+		runtime.Gosched()
+	}
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+	unit := <-waiter
+	require.NotNil(t, unit)
+	assert.Equal(t, "u", unit.ID)
+
+	// A caller whose context is already done returns at once
+	expired, cancelExpired := context.WithCancel(context.Background())
+	cancelExpired()
+	_, err := c.Unit(expired, "other", func(ctx context.Context) (*models.UnitResponse, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+}
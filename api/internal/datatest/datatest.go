@@ -0,0 +1,83 @@
+// Package datatest sets up copies of the fixture dataset, and git repositories of it, for tests
+// that change the data.
+package datatest
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// FixtureDir returns the directory of the checked-in fixture dataset
+func FixtureDir() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "..", "..", "testdata", "wh40k-fixture")
+}
+
+// CopyFixture copies the fixture dataset into a new temporary directory, which it returns
+func CopyFixture(t testing.TB) string {
+	t.Helper()
+	dir := t.TempDir()
+	entries, err := os.ReadDir(FixtureDir())
+	require.NoError(t, err)
+	for _, entry := range entries {
+		data, err := os.ReadFile(filepath.Join(FixtureDir(), entry.Name()))
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, entry.Name()), data, 0o644))
+	}
+	return dir
+}
+
+// EditFile replaces text in a data file, failing if the text isn't present
+func EditFile(t testing.TB, path, old, replacement string) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Contains(t, string(data), old)
+	require.NoError(t, os.WriteFile(path, []byte(strings.Replace(string(data), old, replacement, 1)), 0o644))
+}
+
+// Repo is a throwaway git repository whose first commit is the fixture dataset
+type Repo struct {
+	Dir string
+	t   testing.TB
+}
+
+// NewRepo creates a repository of the fixture dataset, skipping the test when git isn't installed
+func NewRepo(t testing.TB) *Repo {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	repo := &Repo{Dir: CopyFixture(t), t: t}
+	repo.Git("init", "-q")
+	repo.Commit("initial data")
+	return repo
+}
+
+// Git runs a git command in the repository
+func (r *Repo) Git(args ...string) {
+	r.t.Helper()
+	cmd := exec.Command("git", append([]string{"-C", r.Dir, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+	out, err := cmd.CombinedOutput()
+	require.NoError(r.t, err, string(out))
+}
+
+// Commit commits every change in the repository, or an empty commit if there are none
+func (r *Repo) Commit(message string) {
+	r.t.Helper()
+	r.Git("add", "-A")
+	r.Git("commit", "-q", "--allow-empty", "-m", message)
+}
+
+// Edit replaces text in one of the repository's files
+func (r *Repo) Edit(file, old, replacement string) {
+	r.t.Helper()
+	EditFile(r.t, filepath.Join(r.Dir, file), old, replacement)
+}
@@ -0,0 +1,374 @@
+package diff
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"grimoire-api/internal/models"
+	"grimoire-api/internal/parser"
+)
+
+// unitState is a transformed unit and where it came from
+type unitState struct {
+	catalogue string
+	unit      *models.UnitResponse
+}
+
+// Compare reports the unit-level differences between two loaded datasets.
+// Units are matched by their catalogue entryLink ID, so a changed name is a rename
+// rather than a removal and an addition. It stops with the context's error once the context is done.
+func Compare(ctx context.Context, from, to *parser.Parser) (*models.DataDiff, error) {
+	result := &models.DataDiff{
+		Catalogues: compareCatalogues(from, to),
+		Added:      make([]models.DiffUnitRef, 0),
+		Removed:    make([]models.DiffUnitRef, 0),
+		Renamed:    make([]models.UnitRename, 0),
+		Changed:    make([]models.UnitDiff, 0),
+	}
+	if gs := from.GetGameSystem(); gs != nil {
+		result.From.GameSystemRevision = gs.Revision
+	}
+	if gs := to.GetGameSystem(); gs != nil {
+		result.To.GameSystemRevision = gs.Revision
+	}
+
+	before, err := collectUnits(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+	after, err := collectUnits(ctx, to)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, id := range sortedKeys(before) {
+		old := before[id]
+		current, exists := after[id]
+		if !exists {
+			result.Removed = append(result.Removed, unitRef(id, old))
+			continue
+		}
+
+		if old.unit.Name != current.unit.Name {
+			result.Renamed = append(result.Renamed, models.UnitRename{
+				ID:        id,
+				From:      old.unit.Name,
+				To:        current.unit.Name,
+				Catalogue: current.catalogue,
+			})
+		}
+
+		if change := compareUnit(id, old, current); change != nil {
+			result.Changed = append(result.Changed, *change)
+		}
+	}
+
+	for _, id := range sortedKeys(after) {
+		if _, exists := before[id]; !exists {
+			result.Added = append(result.Added, unitRef(id, after[id]))
+		}
+	}
+
+	result.Summary = models.DiffSummary{
+		Added:   len(result.Added),
+		Removed: len(result.Removed),
+		Renamed: len(result.Renamed),
+		Changed: len(result.Changed),
+	}
+	for _, change := range result.Changed {
+		if change.Points != nil {
+			result.Summary.PointsChanged++
+		}
+		if len(change.Stats) > 0 {
+			result.Summary.StatsChanged++
+		}
+		if len(change.Weapons) > 0 {
+			result.Summary.WeaponsChanged++
+		}
+		if len(change.Abilities) > 0 {
+			result.Summary.AbilitiesChanged++
+		}
+	}
+
+	return result, nil
+}
+
+// collectUnits transforms every root unit of a dataset, keyed by entryLink ID, until the context is done
+func collectUnits(ctx context.Context, p *parser.Parser) (map[string]unitState, error) {
+	resolver := parser.NewLinkResolver(p)
+	transformer := parser.NewTransformer(resolver)
+
+	roots, err := resolver.ResolveRootUnitsContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	units := make(map[string]unitState)
+	for _, root := range roots {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		// Uploaded catalogues aren't part of any data revision
+		if p.IsHomebrew(root.Catalogue.ID) {
+			continue
+		}
+		units[root.EntryLink.ID] = unitState{
+			catalogue: root.Catalogue.Name,
+			unit:      transformer.TransformUnit(root.Entry, root.Catalogue.ID),
+		}
+	}
+	return units, nil
+}
+
+// compareCatalogues lists catalogues and libraries that were added, removed or revised
+func compareCatalogues(from, to *parser.Parser) []models.CatalogueRevisionDiff {
+	before := allCatalogues(from)
+	after := allCatalogues(to)
+
+	ids := make(map[string]bool)
+	for id := range before {
+		ids[id] = true
+	}
+	for id := range after {
+		ids[id] = true
+	}
+
+	result := make([]models.CatalogueRevisionDiff, 0)
+	for _, id := range sortedKeys(ids) {
+		old, hadOld := before[id]
+		current, hasCurrent := after[id]
+
+		change := models.CatalogueRevisionDiff{ID: id}
+		switch {
+		case hadOld && hasCurrent:
+			if old.Revision == current.Revision {
+				continue
+			}
+			change.Name = current.Name
+			change.FromRevision = old.Revision
+			change.ToRevision = current.Revision
+		case hadOld:
+			change.Name = old.Name
+			change.FromRevision = old.Revision
+		default:
+			change.Name = current.Name
+			change.ToRevision = current.Revision
+		}
+		result = append(result, change)
+	}
+	return result
+}
+
+func allCatalogues(p *parser.Parser) map[string]*models.Catalogue {
+	result := p.GetAllCatalogues()
+	for id := range result {
+		if p.IsHomebrew(id) {
+			delete(result, id)
+		}
+	}
+	for id, lib := range p.GetAllLibraries() {
+		result[id] = lib
+	}
+	return result
+}
+
+// compareUnit returns the changes to a unit, or nil if nothing but its name changed
+func compareUnit(id string, old, current unitState) *models.UnitDiff {
+	change := &models.UnitDiff{
+		ID:        id,
+		Name:      current.unit.Name,
+		Catalogue: current.catalogue,
+		Points:    comparePoints(old.unit, current.unit),
+		Stats:     compareStats(old.unit.Profiles, current.unit.Profiles),
+		Weapons:   compareWeapons(old.unit.Weapons, current.unit.Weapons),
+		Abilities: compareAbilities(old.unit.Profiles, current.unit.Profiles),
+	}
+
+	if change.Points == nil && len(change.Stats) == 0 && len(change.Weapons) == 0 && len(change.Abilities) == 0 {
+		return nil
+	}
+	return change
+}
+
+// comparePoints compares base pts and tiered costs
+func comparePoints(old, current *models.UnitResponse) *models.PointsDiff {
+	oldTiers := tiers(old.TieredCosts)
+	currentTiers := tiers(current.TieredCosts)
+
+	if old.Costs["pts"] == current.Costs["pts"] && EqualTiers(oldTiers, currentTiers) {
+		return nil
+	}
+
+	return &models.PointsDiff{
+		From:      old.Costs["pts"],
+		To:        current.Costs["pts"],
+		FromTiers: oldTiers,
+		ToTiers:   currentTiers,
+	}
+}
+
+func tiers(tiered *models.TieredCosts) []models.CostTier {
+	if tiered == nil {
+		return nil
+	}
+	return tiered.Tiers
+}
+
+// EqualTiers reports whether two sets of cost tiers are the same
+func EqualTiers(a, b []models.CostTier) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// compareStats compares the unit profile characteristics
+func compareStats(old, current *models.UnitProfiles) []models.FieldDiff {
+	return compareFields(unitStats(old), unitStats(current), []string{"M", "T", "SV", "W", "LD", "OC"})
+}
+
+func unitStats(profiles *models.UnitProfiles) map[string]string {
+	if profiles == nil || profiles.Unit == nil {
+		return map[string]string{}
+	}
+	unit := profiles.Unit
+	return map[string]string{
+		"M":  unit.Movement,
+		"T":  fmt.Sprint(unit.Toughness),
+		"SV": unit.Save,
+		"W":  fmt.Sprint(unit.Wounds),
+		"LD": unit.Leadership,
+		"OC": fmt.Sprint(unit.ObjectiveControl),
+	}
+}
+
+// compareWeapons compares weapon profiles by type and name
+func compareWeapons(old, current *models.WeaponSet) []models.WeaponDiff {
+	var result []models.WeaponDiff
+	result = append(result, compareWeaponMaps("ranged", rangedWeapons(old), rangedWeapons(current))...)
+	result = append(result, compareWeaponMaps("melee", meleeWeapons(old), meleeWeapons(current))...)
+	return result
+}
+
+var weaponFields = []string{"Range", "A", "BS", "WS", "S", "AP", "D", "Keywords"}
+
+func compareWeaponMaps(weaponType string, old, current map[string]map[string]string) []models.WeaponDiff {
+	var result []models.WeaponDiff
+	for _, name := range sortedKeys(old) {
+		if _, exists := current[name]; !exists {
+			result = append(result, models.WeaponDiff{Name: name, Type: weaponType, Change: "removed"})
+			continue
+		}
+		if fields := compareFields(old[name], current[name], weaponFields); len(fields) > 0 {
+			result = append(result, models.WeaponDiff{Name: name, Type: weaponType, Change: "changed", Fields: fields})
+		}
+	}
+	for _, name := range sortedKeys(current) {
+		if _, exists := old[name]; !exists {
+			result = append(result, models.WeaponDiff{Name: name, Type: weaponType, Change: "added"})
+		}
+	}
+	return result
+}
+
+// rangedWeapons indexes ranged weapon characteristics by weapon name
+func rangedWeapons(weapons *models.WeaponSet) map[string]map[string]string {
+	result := make(map[string]map[string]string)
+	if weapons == nil {
+		return result
+	}
+	for _, w := range weapons.Ranged {
+		if _, exists := result[w.Name]; exists {
+			continue
+		}
+		result[w.Name] = map[string]string{
+			"Range": w.Range, "A": w.Attacks, "BS": w.BallisticSkill, "S": w.Strength,
+			"AP": w.ArmorPenetration, "D": w.Damage, "Keywords": strings.Join(w.Keywords, ", "),
+		}
+	}
+	return result
+}
+
+// meleeWeapons indexes melee weapon characteristics by weapon name
+func meleeWeapons(weapons *models.WeaponSet) map[string]map[string]string {
+	result := make(map[string]map[string]string)
+	if weapons == nil {
+		return result
+	}
+	for _, w := range weapons.Melee {
+		if _, exists := result[w.Name]; exists {
+			continue
+		}
+		result[w.Name] = map[string]string{
+			"Range": w.Range, "A": w.Attacks, "WS": w.WeaponSkill, "S": w.Strength,
+			"AP": w.ArmorPenetration, "D": w.Damage, "Keywords": strings.Join(w.Keywords, ", "),
+		}
+	}
+	return result
+}
+
+// compareAbilities compares ability descriptions by ability name
+func compareAbilities(old, current *models.UnitProfiles) []models.AbilityDiff {
+	before := abilities(old)
+	after := abilities(current)
+
+	var result []models.AbilityDiff
+	for _, name := range sortedKeys(before) {
+		text, exists := after[name]
+		switch {
+		case !exists:
+			result = append(result, models.AbilityDiff{Name: name, Change: "removed", From: before[name]})
+		case text != before[name]:
+			result = append(result, models.AbilityDiff{Name: name, Change: "changed", From: before[name], To: text})
+		}
+	}
+	for _, name := range sortedKeys(after) {
+		if _, exists := before[name]; !exists {
+			result = append(result, models.AbilityDiff{Name: name, Change: "added", To: after[name]})
+		}
+	}
+	return result
+}
+
+func abilities(profiles *models.UnitProfiles) map[string]string {
+	result := make(map[string]string)
+	if profiles == nil {
+		return result
+	}
+	for _, ability := range profiles.Abilities {
+		if _, exists := result[ability.Name]; !exists {
+			result[ability.Name] = ability.Description
+		}
+	}
+	return result
+}
+
+// compareFields compares the named fields of two value maps in order
+func compareFields(old, current map[string]string, fields []string) []models.FieldDiff {
+	var result []models.FieldDiff
+	for _, field := range fields {
+		if old[field] != current[field] {
+			result = append(result, models.FieldDiff{Field: field, From: old[field], To: current[field]})
+		}
+	}
+	return result
+}
+
+func unitRef(id string, state unitState) models.DiffUnitRef {
+	return models.DiffUnitRef{ID: id, Name: state.unit.Name, Catalogue: state.catalogue}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
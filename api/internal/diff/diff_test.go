@@ -0,0 +1,149 @@
+package diff
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"grimoire-api/internal/datatest"
+	"grimoire-api/internal/models"
+	"grimoire-api/internal/parser"
+)
+
+const fixtureDir = "../../testdata/wh40k-fixture"
+
+const daemonsFile = "Chaos - Fixture Daemons.cat"
+
+// editDaemons makes a small balance update to the daemons fixture in dir
+func editDaemons(t *testing.T, dir string) {
+	t.Helper()
+	path := filepath.Join(dir, daemonsFile)
+	datatest.EditFile(t, path, `revision="9"`, `revision="10"`)
+	datatest.EditFile(t, path, `<cost name="pts" typeId="51b2-306e-1021-d207" value="420"/>`, `<cost name="pts" typeId="51b2-306e-1021-d207" value="400"/>`)
+	datatest.EditFile(t, path, `<characteristic name="T" typeId="d29d-cf75-fc2d-34a4">13</characteristic>`, `<characteristic name="T" typeId="d29d-cf75-fc2d-34a4">14</characteristic>`)
+	datatest.EditFile(t, path, `name="Fixture Bloodthirster" hidden="false" collective="false" import="true" type="model"`, `name="Fixture Greater Bloodthirster" hidden="false" collective="false" import="true" type="model"`)
+	datatest.EditFile(t, path, `<entryLink id="el-fixture-bloodthirster" name="Fixture Bloodthirster"`, `<entryLink id="el-fixture-bloodthirster" name="Fixture Greater Bloodthirster"`)
+	datatest.EditFile(t, path, `<entryLink id="el-fixture-bloodletters" name="Fixture Bloodletters" hidden="false" collective="false" import="true" targetId="se-fixture-bloodletters" type="selectionEntry"/>`, "")
+}
+
+func loadDir(t *testing.T, dir string) *parser.Parser {
+	t.Helper()
+	p, err := parser.LoadDataDir(dir)
+	require.NoError(t, err)
+	return p
+}
+
+// compare compares two datasets, failing the test on an error
+func compare(t *testing.T, from, to *parser.Parser) *models.DataDiff {
+	t.Helper()
+	result, err := Compare(context.Background(), from, to)
+	require.NoError(t, err)
+	return result
+}
+
+func TestCompareIdenticalData(t *testing.T) {
+	p := loadDir(t, fixtureDir)
+
+	result := compare(t, p, loadDir(t, fixtureDir))
+
+	assert.Empty(t, result.Catalogues)
+	assert.Empty(t, result.Added)
+	assert.Empty(t, result.Removed)
+	assert.Empty(t, result.Renamed)
+	assert.Empty(t, result.Changed)
+}
+
+func TestCompareChangedData(t *testing.T) {
+	dir := datatest.CopyFixture(t)
+	editDaemons(t, dir)
+
+	result := compare(t, loadDir(t, fixtureDir), loadDir(t, dir))
+
+	require.Len(t, result.Catalogues, 1)
+	assert.Equal(t, "cat-fixture-daemons", result.Catalogues[0].ID)
+	assert.Equal(t, "9", result.Catalogues[0].FromRevision)
+	assert.Equal(t, "10", result.Catalogues[0].ToRevision)
+
+	require.Len(t, result.Removed, 1)
+	assert.Equal(t, "el-fixture-bloodletters", result.Removed[0].ID)
+	assert.Empty(t, result.Added)
+
+	require.Len(t, result.Renamed, 1)
+	assert.Equal(t, "Fixture Bloodthirster", result.Renamed[0].From)
+	assert.Equal(t, "Fixture Greater Bloodthirster", result.Renamed[0].To)
+
+	require.Len(t, result.Changed, 1)
+	change := result.Changed[0]
+	assert.Equal(t, "el-fixture-bloodthirster", change.ID)
+	require.NotNil(t, change.Points)
+	assert.Equal(t, 420, change.Points.From)
+	assert.Equal(t, 400, change.Points.To)
+	require.Len(t, change.Stats, 1)
+	assert.Equal(t, "T", change.Stats[0].Field)
+	assert.Equal(t, "13", change.Stats[0].From)
+	assert.Equal(t, "14", change.Stats[0].To)
+
+	assert.Equal(t, 1, result.Summary.PointsChanged)
+	assert.Equal(t, 1, result.Summary.StatsChanged)
+
+	// The reverse comparison sees the removed unit as added
+	reverse := compare(t, loadDir(t, dir), loadDir(t, fixtureDir))
+	require.Len(t, reverse.Added, 1)
+	assert.Equal(t, "el-fixture-bloodletters", reverse.Added[0].ID)
+}
+
+func TestWriteText(t *testing.T) {
+	dir := datatest.CopyFixture(t)
+	editDaemons(t, dir)
+
+	result := compare(t, loadDir(t, fixtureDir), loadDir(t, dir))
+	result.From.Label = "before"
+	result.To.Label = "after"
+
+	var out bytes.Buffer
+	require.NoError(t, WriteText(&out, result))
+
+	text := out.String()
+	assert.Contains(t, text, "Diff before -> after")
+	assert.Contains(t, text, "- Fixture Bloodletters (Chaos - Fixture Daemons)")
+	assert.Contains(t, text, "> Fixture Bloodthirster renamed to Fixture Greater Bloodthirster")
+	assert.Contains(t, text, "points: 420 -> 400")
+	assert.Contains(t, text, "T: 13 -> 14")
+}
+
+func TestLoadSourceGitRevisions(t *testing.T) {
+	repo := datatest.NewRepo(t)
+	editDaemons(t, repo.Dir)
+	repo.Commit("balance update")
+
+	from, fromSource, err := LoadSource(context.Background(), repo.Dir, "HEAD~1", false)
+	require.NoError(t, err)
+	to, toSource, err := LoadSource(context.Background(), repo.Dir, "HEAD", false)
+	require.NoError(t, err)
+
+	assert.Equal(t, "HEAD~1", fromSource.Label)
+	assert.Len(t, fromSource.Commit, 40)
+	assert.NotEqual(t, fromSource.Commit, toSource.Commit)
+
+	result := compare(t, from, to)
+	assert.Equal(t, 1, result.Summary.Removed)
+	assert.Equal(t, 1, result.Summary.Changed)
+
+	// Directories are only accepted when allowed
+	_, _, err = LoadSource(context.Background(), repo.Dir, fixtureDir, false)
+	assert.Error(t, err)
+	_, _, err = LoadSource(context.Background(), repo.Dir, "--output=/tmp/x", false)
+	assert.Error(t, err)
+
+	// Loading a commit stops once its context is done
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = LoadCommit(ctx, repo.Dir, toSource.Commit)
+	assert.ErrorIs(t, err, context.Canceled)
+	_, err = Compare(ctx, from, to)
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,67 @@
+package diff
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"grimoire-api/internal/gitdata"
+	"grimoire-api/internal/models"
+	"grimoire-api/internal/parser"
+)
+
+// LoadSource loads one side of a diff. spec is a directory of data files when allowDirs
+// is set and such a directory exists; otherwise it is a git revision of repoDir.
+func LoadSource(ctx context.Context, repoDir, spec string, allowDirs bool) (*parser.Parser, models.DiffSource, error) {
+	source := models.DiffSource{Label: spec}
+
+	if allowDirs {
+		if info, err := os.Stat(spec); err == nil && info.IsDir() {
+			p, err := parser.LoadDataDir(spec)
+			if err != nil {
+				return nil, source, fmt.Errorf("failed to load %s: %w", spec, err)
+			}
+			return p, source, nil
+		}
+	}
+
+	commit, err := gitdata.ResolveCommit(ctx, repoDir, spec)
+	if err != nil {
+		return nil, source, err
+	}
+	source.Commit = commit
+
+	p, err := LoadCommit(ctx, repoDir, commit)
+	if err != nil {
+		return nil, source, err
+	}
+	return p, source, nil
+}
+
+// LoadCommit parses the data files of a commit through a temporary checkout. It stops with the
+// context's error once the context is done. If some catalogue files failed to load, the parser
+// holding the rest is returned together with an error wrapping the *parser.LoadErrors.
+func LoadCommit(ctx context.Context, repoDir, commit string) (*parser.Parser, error) {
+	tmpDir, err := os.MkdirTemp("", "grimoire-rev-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	if err := gitdata.ExtractRevision(ctx, repoDir, commit, tmpDir); err != nil {
+		return nil, err
+	}
+
+	p := parser.NewParser(tmpDir)
+	p.SetQuiet(true)
+	if err := p.LoadGameSystem(); err != nil {
+		return nil, fmt.Errorf("failed to load revision %s: %w", commit, err)
+	}
+	if err := p.LoadAllCataloguesContext(ctx); err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return p, fmt.Errorf("failed to load revision %s: %w", commit, err)
+	}
+	return p, nil
+}
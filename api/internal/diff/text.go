@@ -0,0 +1,86 @@
+package diff
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"grimoire-api/internal/models"
+)
+
+// WriteText writes a human-readable summary of a diff, one change per line
+func WriteText(w io.Writer, d *models.DataDiff) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Diff %s -> %s\n", sourceLabel(d.From), sourceLabel(d.To))
+	fmt.Fprintf(&b, "%d added, %d removed, %d renamed, %d changed (%d points, %d stats, %d weapons, %d abilities)\n",
+		d.Summary.Added, d.Summary.Removed, d.Summary.Renamed, d.Summary.Changed,
+		d.Summary.PointsChanged, d.Summary.StatsChanged, d.Summary.WeaponsChanged, d.Summary.AbilitiesChanged)
+
+	for _, cat := range d.Catalogues {
+		fmt.Fprintf(&b, "~ catalogue %s: revision %s -> %s\n", cat.Name, orNone(cat.FromRevision), orNone(cat.ToRevision))
+	}
+	for _, unit := range d.Added {
+		fmt.Fprintf(&b, "+ %s (%s)\n", unit.Name, unit.Catalogue)
+	}
+	for _, unit := range d.Removed {
+		fmt.Fprintf(&b, "- %s (%s)\n", unit.Name, unit.Catalogue)
+	}
+	for _, rename := range d.Renamed {
+		fmt.Fprintf(&b, "> %s renamed to %s (%s)\n", rename.From, rename.To, rename.Catalogue)
+	}
+	for _, change := range d.Changed {
+		fmt.Fprintf(&b, "~ %s (%s)\n", change.Name, change.Catalogue)
+		if change.Points != nil {
+			fmt.Fprintf(&b, "    points: %d -> %d%s\n", change.Points.From, change.Points.To,
+				tierChange(change.Points.FromTiers, change.Points.ToTiers))
+		}
+		for _, stat := range change.Stats {
+			fmt.Fprintf(&b, "    %s: %s -> %s\n", stat.Field, orNone(stat.From), orNone(stat.To))
+		}
+		for _, weapon := range change.Weapons {
+			fmt.Fprintf(&b, "    %s weapon %s %s\n", weapon.Type, weapon.Name, weapon.Change)
+			for _, field := range weapon.Fields {
+				fmt.Fprintf(&b, "      %s: %s -> %s\n", field.Field, orNone(field.From), orNone(field.To))
+			}
+		}
+		for _, ability := range change.Abilities {
+			fmt.Fprintf(&b, "    ability %s %s\n", ability.Name, ability.Change)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func sourceLabel(source models.DiffSource) string {
+	if source.Commit != "" && source.Commit != source.Label {
+		return fmt.Sprintf("%s (%.12s)", source.Label, source.Commit)
+	}
+	return source.Label
+}
+
+func tierChange(from, to []models.CostTier) string {
+	if len(from) == 0 && len(to) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" [tiers %s -> %s]", formatTiers(from), formatTiers(to))
+}
+
+func formatTiers(tiers []models.CostTier) string {
+	if len(tiers) == 0 {
+		return "none"
+	}
+	parts := make([]string, 0, len(tiers))
+	for _, tier := range tiers {
+		parts = append(parts, fmt.Sprintf("%d+: %d", tier.MinModels, tier.Cost))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func orNone(value string) string {
+	if value == "" {
+		return "(none)"
+	}
+	return value
+}
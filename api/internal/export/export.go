@@ -0,0 +1,367 @@
+// Package export writes the whole dataset as normalized tables for analysis outside the API: a
+// zip of CSV or NDJSON files, or a SQLite database. Every export carries a manifest naming the
+// revisions it was built from and describing the tables, their keys and how they link.
+package export
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"grimoire-api/internal/models"
+	"grimoire-api/internal/service"
+)
+
+// Export formats
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+	FormatSQLite = "sqlite"
+)
+
+// Formats lists the export formats
+var Formats = []string{FormatCSV, FormatNDJSON, FormatSQLite}
+
+// Column types
+const (
+	TypeText    = "text"
+	TypeInteger = "integer"
+	TypeBoolean = "boolean"
+)
+
+// Column is a column of a table
+type Column struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// ForeignKey links columns of a table to the key of another
+type ForeignKey struct {
+	Columns    []string `json:"columns"`
+	Table      string   `json:"table"`
+	References []string `json:"references"`
+}
+
+// Table is a normalized table of the export
+type Table struct {
+	Name        string       `json:"name"`
+	File        string       `json:"file,omitempty"` // Entry in the zip, for CSV and NDJSON
+	Columns     []Column     `json:"columns"`
+	PrimaryKey  []string     `json:"primaryKey"`
+	ForeignKeys []ForeignKey `json:"foreignKeys,omitempty"`
+	RowCount    int          `json:"rows"` // Counted as the export is written
+}
+
+// Source is a file the data was loaded from
+type Source struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Revision string `json:"revision"`
+	Library  bool   `json:"library,omitempty"`
+	Homebrew bool   `json:"homebrew,omitempty"`
+}
+
+// Manifest describes an export
+type Manifest struct {
+	Format     string    `json:"format"`
+	ExportedAt time.Time `json:"exportedAt"`
+	Revision   string    `json:"revision"` // Revision of the snapshot it was exported from
+	GameSystem Source    `json:"gameSystem"`
+	Catalogues []Source  `json:"catalogues"`
+	Tables     []*Table  `json:"tables"`
+}
+
+// Dataset is the data of an export, ready to be written in any format. Its rows are read from the
+// repositories as it is written, so a whole export is never held in memory.
+type Dataset struct {
+	Revision   string
+	GameSystem Source
+	Catalogues []Source
+	Tables     []*Table // The row counts are in the manifest Write returns
+
+	repositories service.Repositories
+	catalogues   []models.CatalogueInfo
+	units        []models.UnitSummary
+	tables       tables
+}
+
+// tables are the tables of an export, which rows adds to
+type tables struct {
+	catalogues, units, profiles, weapons, abilities, costs, costTiers *Table
+}
+
+// emitFunc adds a row to a table of an export being written
+type emitFunc func(table *Table, values ...interface{}) error
+
+// unitKey is the key of the units table, which the tables of unit details reference
+var unitKey = ForeignKey{Columns: []string{"catalogue_id", "unit_id"}, Table: "units", References: []string{"catalogue_id", "id"}}
+
+// Build describes the tables of an export of every unit of the repositories, with its profile,
+// weapons, abilities and costs, and lists the units to write. Units are keyed by catalogue and
+// entryLink ID.
+func Build(ctx context.Context, revision string, repositories service.Repositories) (*Dataset, error) {
+	gameSystem, err := repositories.GameSystem.GetGameSystem(ctx)
+	if err != nil {
+		return nil, err
+	}
+	catalogueInfos, err := repositories.Catalogues.ListCatalogues(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(catalogueInfos, func(i, j int) bool { return catalogueInfos[i].Name < catalogueInfos[j].Name })
+	summaries, _, _, err := repositories.Units.ListUnitsWithWarnings(ctx, service.UnitQuery{})
+	if err != nil {
+		return nil, err
+	}
+
+	catalogues := &Table{
+		Name: "catalogues",
+		Columns: []Column{
+			{"id", TypeText}, {"name", TypeText}, {"revision", TypeText}, {"homebrew", TypeBoolean},
+		},
+		PrimaryKey: []string{"id"},
+	}
+	units := &Table{
+		Name: "units",
+		Columns: []Column{
+			{"catalogue_id", TypeText}, {"id", TypeText}, {"selection_entry_id", TypeText}, {"name", TypeText},
+			{"type", TypeText}, {"faction_id", TypeText}, {"faction", TypeText}, {"points", TypeInteger},
+			{"legends", TypeBoolean},
+		},
+		PrimaryKey:  []string{"catalogue_id", "id"},
+		ForeignKeys: []ForeignKey{{Columns: []string{"catalogue_id"}, Table: "catalogues", References: []string{"id"}}},
+	}
+	profiles := &Table{
+		Name: "profiles",
+		Columns: []Column{
+			{"catalogue_id", TypeText}, {"unit_id", TypeText}, {"movement", TypeText}, {"toughness", TypeInteger},
+			{"save", TypeText}, {"wounds", TypeInteger}, {"leadership", TypeText}, {"objective_control", TypeInteger},
+			{"transport_capacity", TypeText},
+		},
+		PrimaryKey:  []string{"catalogue_id", "unit_id"},
+		ForeignKeys: []ForeignKey{unitKey},
+	}
+	weapons := &Table{
+		Name: "weapons",
+		Columns: []Column{
+			{"catalogue_id", TypeText}, {"unit_id", TypeText}, {"position", TypeInteger}, {"type", TypeText},
+			{"name", TypeText}, {"range", TypeText}, {"attacks", TypeText}, {"skill", TypeText},
+			{"strength", TypeText}, {"armor_penetration", TypeText}, {"damage", TypeText}, {"keywords", TypeText},
+		},
+		PrimaryKey:  []string{"catalogue_id", "unit_id", "position"},
+		ForeignKeys: []ForeignKey{unitKey},
+	}
+	abilities := &Table{
+		Name: "abilities",
+		Columns: []Column{
+			{"catalogue_id", TypeText}, {"unit_id", TypeText}, {"position", TypeInteger}, {"kind", TypeText},
+			{"rule_id", TypeText}, {"name", TypeText}, {"description", TypeText},
+		},
+		PrimaryKey:  []string{"catalogue_id", "unit_id", "position"},
+		ForeignKeys: []ForeignKey{unitKey},
+	}
+	costs := &Table{
+		Name: "costs",
+		Columns: []Column{
+			{"catalogue_id", TypeText}, {"unit_id", TypeText}, {"type", TypeText}, {"value", TypeInteger},
+		},
+		PrimaryKey:  []string{"catalogue_id", "unit_id", "type"},
+		ForeignKeys: []ForeignKey{unitKey},
+	}
+	costTiers := &Table{
+		Name: "cost_tiers",
+		Columns: []Column{
+			{"catalogue_id", TypeText}, {"unit_id", TypeText}, {"min_models", TypeInteger}, {"cost", TypeInteger},
+		},
+		PrimaryKey:  []string{"catalogue_id", "unit_id", "min_models"},
+		ForeignKeys: []ForeignKey{unitKey},
+	}
+
+	dataset := &Dataset{
+		Revision: revision,
+		GameSystem: Source{
+			ID:       gameSystem.ID,
+			Name:     gameSystem.Name,
+			Revision: gameSystem.Revision,
+		},
+		Tables:       []*Table{catalogues, units, profiles, weapons, abilities, costs, costTiers},
+		repositories: repositories,
+		catalogues:   catalogueInfos,
+		units:        summaries,
+		tables:       tables{catalogues, units, profiles, weapons, abilities, costs, costTiers},
+	}
+	// The manifest names the revision of every catalogue and of the libraries they link
+	var libraries []Source
+	seen := make(map[string]bool)
+	for _, catalogue := range catalogueInfos {
+		dataset.Catalogues = append(dataset.Catalogues, source(catalogue))
+
+		response, err := repositories.Catalogues.GetCatalogue(ctx, catalogue.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, linked := range response.LinkedCatalogues {
+			if linked.Library && !seen[linked.ID] {
+				seen[linked.ID] = true
+				libraries = append(libraries, source(linked))
+			}
+		}
+	}
+	sort.Slice(libraries, func(i, j int) bool { return libraries[i].Name < libraries[j].Name })
+	dataset.Catalogues = append(dataset.Catalogues, libraries...)
+
+	return dataset, nil
+}
+
+// rows passes every row of the dataset to emit, a unit at a time, until emit fails
+func (d *Dataset) rows(ctx context.Context, emit emitFunc) error {
+	t := d.tables
+	var failed error
+	add := func(table *Table, values ...interface{}) {
+		if failed == nil {
+			failed = emit(table, values...)
+		}
+	}
+
+	for _, catalogue := range d.catalogues {
+		add(t.catalogues, catalogue.ID, catalogue.Name, catalogue.Revision, catalogue.Homebrew)
+	}
+	if failed != nil {
+		return failed
+	}
+
+	for _, summary := range d.units {
+		if summary.Catalogue == nil {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		unit, err := d.repositories.Units.GetUnit(ctx, summary.ID)
+		if err != nil {
+			return fmt.Errorf("failed to export unit %s: %w", summary.ID, err)
+		}
+		catalogueID := summary.Catalogue.ID
+
+		var factionID, faction string
+		if unit.Faction != nil {
+			factionID, faction = unit.Faction.ID, unit.Faction.Name
+		}
+		add(t.units, catalogueID, summary.ID, summary.TargetID, unit.Name, unit.Type, factionID, faction,
+			unit.Costs["pts"], strings.Contains(unit.Name, service.LegendsMarker))
+
+		if unit.Profiles != nil && unit.Profiles.Unit != nil {
+			stats := unit.Profiles.Unit
+			transport := ""
+			if unit.Profiles.Transport != nil {
+				transport = unit.Profiles.Transport.Capacity
+			}
+			add(t.profiles, catalogueID, summary.ID, stats.Movement, stats.Toughness, stats.Save, stats.Wounds,
+				stats.Leadership, stats.ObjectiveControl, transport)
+		}
+
+		if unit.Weapons != nil {
+			position := 0
+			for _, weapon := range unit.Weapons.Ranged {
+				position++
+				add(t.weapons, catalogueID, summary.ID, position, "ranged", weapon.Name, weapon.Range, weapon.Attacks,
+					weapon.BallisticSkill, weapon.Strength, weapon.ArmorPenetration, weapon.Damage, strings.Join(weapon.Keywords, ", "))
+			}
+			for _, weapon := range unit.Weapons.Melee {
+				position++
+				add(t.weapons, catalogueID, summary.ID, position, "melee", weapon.Name, weapon.Range, weapon.Attacks,
+					weapon.WeaponSkill, weapon.Strength, weapon.ArmorPenetration, weapon.Damage, strings.Join(weapon.Keywords, ", "))
+			}
+		}
+
+		// Abilities of the unit's profiles, then the shared rules it links to
+		position := 0
+		if unit.Profiles != nil {
+			for _, ability := range unit.Profiles.Abilities {
+				position++
+				add(t.abilities, catalogueID, summary.ID, position, "ability", "", ability.Name, ability.Description)
+			}
+		}
+		for _, rule := range unit.Rules {
+			position++
+			add(t.abilities, catalogueID, summary.ID, position, "rule", rule.ID, rule.Name, "")
+		}
+
+		costTypes := make([]string, 0, len(unit.Costs))
+		for costType := range unit.Costs {
+			costTypes = append(costTypes, costType)
+		}
+		sort.Strings(costTypes)
+		for _, costType := range costTypes {
+			add(t.costs, catalogueID, summary.ID, costType, unit.Costs[costType])
+		}
+		if unit.TieredCosts != nil {
+			for _, tier := range unit.TieredCosts.Tiers {
+				add(t.costTiers, catalogueID, summary.ID, tier.MinModels, tier.Cost)
+			}
+		}
+		if failed != nil {
+			return failed
+		}
+	}
+	return nil
+}
+
+// source describes a catalogue in the manifest
+func source(catalogue models.CatalogueInfo) Source {
+	return Source{
+		ID:       catalogue.ID,
+		Name:     catalogue.Name,
+		Revision: catalogue.Revision,
+		Library:  catalogue.Library,
+		Homebrew: catalogue.Homebrew,
+	}
+}
+
+// manifest describes the dataset exported in format, before its rows are counted
+func (d *Dataset) manifest(format string) *Manifest {
+	manifest := &Manifest{
+		Format:     format,
+		ExportedAt: time.Now().UTC().Truncate(time.Second),
+		Revision:   d.Revision,
+		GameSystem: d.GameSystem,
+		Catalogues: d.Catalogues,
+	}
+	for _, table := range d.Tables {
+		described := *table
+		described.File = ""
+		if format == FormatCSV || format == FormatNDJSON {
+			described.File = table.Name + "." + format
+		}
+		manifest.Tables = append(manifest.Tables, &described)
+	}
+	return manifest
+}
+
+// FileName names the file of an export in format
+func FileName(format, revision string) string {
+	if format == FormatSQLite {
+		return fmt.Sprintf("grimoire-%s.db", revision)
+	}
+	return fmt.Sprintf("grimoire-%s-%s.zip", revision, format)
+}
+
+// ContentType is the media type of an export in format
+func ContentType(format string) string {
+	if format == FormatSQLite {
+		return "application/vnd.sqlite3"
+	}
+	return "application/zip"
+}
+
+// ValidFormat reports whether format is one of Formats
+func ValidFormat(format string) bool {
+	for _, f := range Formats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
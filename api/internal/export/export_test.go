@@ -0,0 +1,253 @@
+package export
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"grimoire-api/internal/snapshot"
+)
+
+const fixtureDir = "../../testdata/wh40k-fixture"
+
+func loadDataset(t *testing.T) *Dataset {
+	snap, err := snapshot.Load(snapshot.Config{DataDir: fixtureDir, Quiet: true})
+	require.NoError(t, err)
+	dataset, err := Build(context.Background(), snap.Revision, snap.Repositories)
+	require.NoError(t, err)
+	return dataset
+}
+
+// readZip returns the entries of a zip export by name
+func readZip(t *testing.T, data []byte) map[string][]byte {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	entries := make(map[string][]byte)
+	for _, file := range archive.File {
+		r, err := file.Open()
+		require.NoError(t, err)
+		entries[file.Name], err = io.ReadAll(r)
+		require.NoError(t, err)
+		r.Close()
+	}
+	return entries
+}
+
+func TestBuild(t *testing.T) {
+	dataset := loadDataset(t)
+	manifest, err := dataset.Write(context.Background(), io.Discard, FormatNDJSON)
+	require.NoError(t, err)
+
+	tables := make(map[string]*Table)
+	for _, table := range manifest.Tables {
+		tables[table.Name] = table
+	}
+	assert.Equal(t, 3, tables["catalogues"].RowCount)
+	assert.Equal(t, 7, tables["units"].RowCount)
+	assert.Equal(t, 3, tables["weapons"].RowCount)
+	assert.Equal(t, 1, tables["cost_tiers"].RowCount)
+
+	// Libraries are listed in the manifest with the catalogues that link them
+	assert.Equal(t, "Warhammer 40,000 10th Edition", dataset.GameSystem.Name)
+	var library *Source
+	for i := range dataset.Catalogues {
+		if dataset.Catalogues[i].Library {
+			library = &dataset.Catalogues[i]
+		}
+	}
+	require.NotNil(t, library)
+	assert.Equal(t, "lib-fixture-astartes", library.ID)
+}
+
+func TestWriteCSV(t *testing.T) {
+	dataset := loadDataset(t)
+	var out bytes.Buffer
+	_, err := dataset.Write(context.Background(), &out, FormatCSV)
+	require.NoError(t, err)
+	entries := readZip(t, out.Bytes())
+
+	var manifest Manifest
+	require.NoError(t, json.Unmarshal(entries[ManifestFile], &manifest))
+	assert.Equal(t, dataset.Revision, manifest.Revision)
+	assert.Equal(t, FormatCSV, manifest.Format)
+
+	for _, table := range manifest.Tables {
+		records, err := csv.NewReader(bytes.NewReader(entries[table.File])).ReadAll()
+		require.NoError(t, err, table.File)
+		require.Len(t, records, table.RowCount+1, table.File)
+		assert.Len(t, records[0], len(table.Columns), table.File)
+	}
+
+	units, err := csv.NewReader(bytes.NewReader(entries["units.csv"])).ReadAll()
+	require.NoError(t, err)
+	assert.Contains(t, units, []string{"cat-fixture-marines", "el-fixture-captain", "se-fixture-captain", "Fixture Captain",
+		"model", "fac-fixture-astartes", "Faction: Adeptus Astartes", "80", "false"})
+}
+
+func TestWriteNDJSON(t *testing.T) {
+	dataset := loadDataset(t)
+	var out bytes.Buffer
+	_, err := dataset.Write(context.Background(), &out, FormatNDJSON)
+	require.NoError(t, err)
+	entries := readZip(t, out.Bytes())
+
+	var weapons []map[string]interface{}
+	scanner := bufio.NewScanner(bytes.NewReader(entries["weapons.ndjson"]))
+	for scanner.Scan() {
+		var weapon map[string]interface{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &weapon))
+		weapons = append(weapons, weapon)
+	}
+	require.Len(t, weapons, 3)
+	assert.Equal(t, "Master-crafted bolter", weapons[0]["name"])
+	assert.Equal(t, float64(1), weapons[0]["position"])
+	assert.Equal(t, "el-fixture-captain", weapons[0]["unit_id"])
+}
+
+func TestWriteSQLite(t *testing.T) {
+	dataset := loadDataset(t)
+	path := filepath.Join(t.TempDir(), "export.db")
+	file, err := os.Create(path)
+	require.NoError(t, err)
+	_, err = dataset.Write(context.Background(), file, FormatSQLite)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	db, err := sql.Open("sqlite", path)
+	require.NoError(t, err)
+	defer db.Close()
+
+	// Weapons join to their units and units to their catalogues
+	var catalogue string
+	err = db.QueryRow(`SELECT c.name FROM weapons w
+		JOIN units u ON u.catalogue_id = w.catalogue_id AND u.id = w.unit_id
+		JOIN catalogues c ON c.id = u.catalogue_id
+		WHERE w.name = 'Bolt rifle'`).Scan(&catalogue)
+	require.NoError(t, err)
+	assert.Equal(t, "Imperium - Fixture Marines", catalogue)
+
+	var legends int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM units WHERE legends`).Scan(&legends))
+	assert.Equal(t, 1, legends)
+
+	var manifestJSON string
+	require.NoError(t, db.QueryRow(`SELECT value FROM manifest WHERE name = ?`, ManifestFile).Scan(&manifestJSON))
+	var manifest Manifest
+	require.NoError(t, json.Unmarshal([]byte(manifestJSON), &manifest))
+	assert.Equal(t, dataset.Revision, manifest.Revision)
+	assert.Empty(t, manifest.Tables[0].File)
+}
+
+func TestWriteUnknownFormat(t *testing.T) {
+	dataset := loadDataset(t)
+	_, err := dataset.Write(context.Background(), io.Discard, "xlsx")
+	assert.Error(t, err)
+}
+
+func TestWriteCanceled(t *testing.T) {
+	dataset := loadDataset(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := dataset.Write(ctx, io.Discard, FormatCSV)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestFiles(t *testing.T) {
+	snap, err := snapshot.Load(snapshot.Config{DataDir: fixtureDir, Quiet: true})
+	require.NoError(t, err)
+	dir := t.TempDir()
+	files := NewFiles(dir, 0)
+
+	// An export is written once per revision and format
+	open := func(ctx context.Context, format string) (string, error) {
+		t.Helper()
+		file, err := files.Open(ctx, snap.Revision, format, snap.Repositories)
+		if err != nil {
+			return "", err
+		}
+		require.NoError(t, file.Close())
+		return file.Name(), nil
+	}
+	path, err := open(context.Background(), FormatCSV)
+	require.NoError(t, err)
+	again, err := open(context.Background(), FormatCSV)
+	require.NoError(t, err)
+	assert.Equal(t, path, again)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, readZip(t, data), ManifestFile)
+
+	sqlitePath, err := open(context.Background(), FormatSQLite)
+	require.NoError(t, err)
+	assert.NotEqual(t, path, sqlitePath)
+
+	// The exports of revisions that are no longer retained are removed
+	files.Retain([]string{snap.Revision})
+	assert.FileExists(t, path)
+	files.Retain(nil)
+	assert.NoFileExists(t, path)
+	assert.NoFileExists(t, sqlitePath)
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	// A request giving up doesn't stop the write
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = open(ctx, FormatCSV)
+	assert.ErrorIs(t, err, context.Canceled)
+	path, err = open(context.Background(), FormatCSV)
+	require.NoError(t, err)
+	assert.FileExists(t, path)
+
+	// An export opened before its revision is dropped can still be read to the end
+	file, err := files.Open(context.Background(), snap.Revision, FormatCSV, snap.Repositories)
+	require.NoError(t, err)
+	defer file.Close()
+	files.Retain(nil)
+	assert.NoFileExists(t, path)
+	data, err = io.ReadAll(file)
+	require.NoError(t, err)
+	assert.Contains(t, readZip(t, data), ManifestFile)
+}
+
+func TestFilesOpenWhileRetaining(t *testing.T) {
+	snap, err := snapshot.Load(snapshot.Config{DataDir: fixtureDir, Quiet: true})
+	require.NoError(t, err)
+	files := NewFiles(t.TempDir(), 0)
+
+	// Dropping the revision between writing an export and sending it never fails the download
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 5; j++ {
+				files.Retain(nil)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			file, err := files.Open(context.Background(), snap.Revision, FormatNDJSON, snap.Repositories)
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer file.Close()
+			_, err = io.Copy(io.Discard, file)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+}
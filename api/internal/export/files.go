@@ -0,0 +1,159 @@
+package export
+
+import (
+	"context"
+	"os"
+	"sync"
+
+	"grimoire-api/internal/service"
+)
+
+// DefaultWrites is how many exports may be written at once
+const DefaultWrites = 1
+
+// Files keeps the exports written from each snapshot revision in a directory, so an export is
+// written once per revision and format however often it is downloaded. Each write reads every unit,
+// so only a few run at once; they carry on when the requests waiting for them give up, for the
+// next request to find.
+type Files struct {
+	writes chan struct{}
+
+	mu    sync.Mutex
+	dir   string
+	files map[fileKey]*file
+}
+
+type fileKey struct {
+	revision, format string
+}
+
+// file is an export being written, or written to path
+type file struct {
+	done chan struct{} // Closed once path or err is set
+	path string
+	err  error
+}
+
+// NewFiles keeps exports in dir, or in a temporary directory made for the first one if dir is empty,
+// writing up to writes of them at once (DefaultWrites if writes is 0)
+func NewFiles(dir string, writes int) *Files {
+	if writes <= 0 {
+		writes = DefaultWrites
+	}
+	return &Files{dir: dir, writes: make(chan struct{}, writes), files: make(map[fileKey]*file)}
+}
+
+// Open opens the export in format of the snapshot with revision, writing it from repositories the
+// first time it is asked for. It waits for the file until ctx is done. The file is opened before
+// Retain can remove it, and stays readable once opened; the caller closes it.
+func (f *Files) Open(ctx context.Context, revision, format string, repositories service.Repositories) (*os.File, error) {
+	key := fileKey{revision: revision, format: format}
+	for {
+		f.mu.Lock()
+		cached, exists := f.files[key]
+		if !exists {
+			cached = &file{done: make(chan struct{})}
+			f.files[key] = cached
+			go f.write(key, cached, repositories)
+		}
+		f.mu.Unlock()
+
+		select {
+		case <-cached.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if cached.err != nil {
+			return nil, cached.err
+		}
+
+		f.mu.Lock()
+		if f.files[key] != cached {
+			// Retain dropped the revision, removing the file, once it was written; write it again
+			f.mu.Unlock()
+			continue
+		}
+		out, err := os.Open(cached.path)
+		f.mu.Unlock()
+		return out, err
+	}
+}
+
+// write writes an export once a write is free. A failed export is forgotten, so the next request
+// tries again.
+func (f *Files) write(key fileKey, cached *file, repositories service.Repositories) {
+	defer close(cached.done)
+	f.writes <- struct{}{}
+	defer func() { <-f.writes }()
+
+	cached.path, cached.err = f.writeFile(key, repositories)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.files[key] != cached {
+		// Retain dropped its revision while it was written
+		if cached.err == nil {
+			os.Remove(cached.path)
+		}
+		return
+	}
+	if cached.err != nil {
+		delete(f.files, key)
+	}
+}
+
+// writeFile writes an export to a new file of the directory, removing it unless it is complete
+func (f *Files) writeFile(key fileKey, repositories service.Repositories) (string, error) {
+	ctx := context.Background()
+	dataset, err := Build(ctx, key.revision, repositories)
+	if err != nil {
+		return "", err
+	}
+	f.mu.Lock()
+	if f.dir == "" {
+		f.dir, err = os.MkdirTemp("", "grimoire-exports-*")
+	}
+	dir := f.dir
+	f.mu.Unlock()
+	if err != nil {
+		return "", err
+	}
+	out, err := os.CreateTemp(dir, "*-"+FileName(key.format, key.revision))
+	if err != nil {
+		return "", err
+	}
+	_, err = dataset.Write(ctx, out, key.format)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(out.Name())
+		return "", err
+	}
+	return out.Name(), nil
+}
+
+// Retain removes the exports of every revision but revisions, once their snapshots are dropped
+func (f *Files) Retain(revisions []string) {
+	retained := make(map[string]bool, len(revisions))
+	for _, revision := range revisions {
+		retained[revision] = true
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for key, cached := range f.files {
+		if retained[key.revision] {
+			continue
+		}
+		delete(f.files, key)
+		select {
+		case <-cached.done:
+			if cached.err == nil {
+				os.Remove(cached.path)
+			}
+		default:
+			// Still being written; write removes it when it's done
+		}
+	}
+}
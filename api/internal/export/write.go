@@ -0,0 +1,326 @@
+package export
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	_ "modernc.org/sqlite"
+)
+
+// ManifestFile is the entry of the manifest in a CSV or NDJSON export, and its key in the
+// manifest table of a SQLite export
+const ManifestFile = "manifest.json"
+
+// Write writes the dataset to w in format and returns its manifest, with the row count of every
+// table. CSV and NDJSON exports are zips with a file per table and the manifest; a SQLite export is
+// a database with a table per table and a manifest table. Both are written to temporary files
+// first, so rows are streamed from the repositories rather than held in memory.
+func (d *Dataset) Write(ctx context.Context, w io.Writer, format string) (*Manifest, error) {
+	if !ValidFormat(format) {
+		return nil, fmt.Errorf("unknown export format %q; use one of %s", format, strings.Join(Formats, ", "))
+	}
+	manifest := d.manifest(format)
+	described := make(map[*Table]*Table, len(d.Tables))
+	for i, table := range d.Tables {
+		described[table] = manifest.Tables[i]
+	}
+
+	if format == FormatSQLite {
+		return manifest, d.writeSQLite(ctx, w, manifest, described)
+	}
+	return manifest, d.writeZip(ctx, w, manifest, described)
+}
+
+// writeZip writes each table to a temporary file as its rows are read, then zips them up after
+// the manifest
+func (d *Dataset) writeZip(ctx context.Context, w io.Writer, manifest *Manifest, described map[*Table]*Table) error {
+	dir, err := os.MkdirTemp("", "grimoire-export-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	files := make(map[*Table]*os.File, len(d.Tables))
+	writers := make(map[*Table]rowWriter, len(d.Tables))
+	defer func() {
+		for _, file := range files {
+			file.Close()
+		}
+	}()
+	for _, table := range d.Tables {
+		file, err := os.Create(filepath.Join(dir, described[table].File))
+		if err != nil {
+			return err
+		}
+		files[table] = file
+		if manifest.Format == FormatCSV {
+			writers[table], err = newCSVWriter(file, table)
+		} else {
+			writers[table], err = newNDJSONWriter(file, table)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to write %s: %w", table.Name, err)
+		}
+	}
+
+	err = d.rows(ctx, func(table *Table, values ...interface{}) error {
+		described[table].RowCount++
+		if err := writers[table].write(values); err != nil {
+			return fmt.Errorf("failed to write %s: %w", table.Name, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, table := range d.Tables {
+		if err := writers[table].flush(); err != nil {
+			return fmt.Errorf("failed to write %s: %w", table.Name, err)
+		}
+	}
+
+	archive := zip.NewWriter(w)
+	manifestJSON, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	create := func(name string) (io.Writer, error) {
+		return archive.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: manifest.ExportedAt})
+	}
+	entry, err := create(ManifestFile)
+	if err != nil {
+		return err
+	}
+	if _, err := entry.Write(manifestJSON); err != nil {
+		return err
+	}
+
+	for _, table := range d.Tables {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		entry, err := create(described[table].File)
+		if err != nil {
+			return err
+		}
+		file := files[table]
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		if _, err := io.Copy(entry, file); err != nil {
+			return fmt.Errorf("failed to write %s: %w", table.Name, err)
+		}
+	}
+	return archive.Close()
+}
+
+// rowWriter writes the rows of a table in the format of an export
+type rowWriter interface {
+	write(row []interface{}) error
+	flush() error
+}
+
+// csvWriter writes a table as CSV with a header row. Booleans are true or false.
+type csvWriter struct {
+	out    *csv.Writer
+	record []string
+}
+
+func newCSVWriter(w io.Writer, table *Table) (*csvWriter, error) {
+	out := csv.NewWriter(w)
+	header := make([]string, len(table.Columns))
+	for i, column := range table.Columns {
+		header[i] = column.Name
+	}
+	if err := out.Write(header); err != nil {
+		return nil, err
+	}
+	return &csvWriter{out: out, record: make([]string, len(table.Columns))}, nil
+}
+
+func (c *csvWriter) write(row []interface{}) error {
+	for i, value := range row {
+		switch v := value.(type) {
+		case string:
+			c.record[i] = v
+		case int:
+			c.record[i] = strconv.Itoa(v)
+		case bool:
+			c.record[i] = strconv.FormatBool(v)
+		}
+	}
+	return c.out.Write(c.record)
+}
+
+func (c *csvWriter) flush() error {
+	c.out.Flush()
+	return c.out.Error()
+}
+
+// ndjsonWriter writes a table as a JSON object per line, with the fields in column order
+type ndjsonWriter struct {
+	out   *bufio.Writer
+	names [][]byte
+	line  bytes.Buffer
+}
+
+func newNDJSONWriter(w io.Writer, table *Table) (*ndjsonWriter, error) {
+	names := make([][]byte, len(table.Columns))
+	for i, column := range table.Columns {
+		name, err := json.Marshal(column.Name)
+		if err != nil {
+			return nil, err
+		}
+		names[i] = name
+	}
+	return &ndjsonWriter{out: bufio.NewWriter(w), names: names}, nil
+}
+
+func (n *ndjsonWriter) write(row []interface{}) error {
+	n.line.Reset()
+	n.line.WriteByte('{')
+	for i, value := range row {
+		if i > 0 {
+			n.line.WriteByte(',')
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		n.line.Write(n.names[i])
+		n.line.WriteByte(':')
+		n.line.Write(encoded)
+	}
+	n.line.WriteString("}\n")
+	_, err := n.out.Write(n.line.Bytes())
+	return err
+}
+
+func (n *ndjsonWriter) flush() error {
+	return n.out.Flush()
+}
+
+// writeSQLite writes the tables to a temporary database, with their keys, and copies it to w
+func (d *Dataset) writeSQLite(ctx context.Context, w io.Writer, manifest *Manifest, described map[*Table]*Table) error {
+	tmp, err := os.CreateTemp("", "grimoire-export-*.db")
+	if err != nil {
+		return err
+	}
+	tmp.Close()
+	defer os.Remove(tmp.Name())
+
+	dsn := (&url.URL{Scheme: "file", OmitHost: true, Path: tmp.Name(), RawQuery: "_pragma=journal_mode(OFF)&_pragma=synchronous(OFF)"}).String()
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	inserts := make(map[*Table]*sql.Stmt, len(d.Tables))
+	defer func() {
+		for _, insert := range inserts {
+			insert.Close()
+		}
+	}()
+	for _, table := range d.Tables {
+		if _, err := tx.ExecContext(ctx, createTable(table)); err != nil {
+			return fmt.Errorf("failed to create %s: %w", table.Name, err)
+		}
+		columns := make([]string, len(table.Columns))
+		for i, column := range table.Columns {
+			columns[i] = quote(column.Name)
+		}
+		insert, err := tx.PrepareContext(ctx, fmt.Sprintf(`INSERT INTO %s (%s) VALUES (%s)`, quote(table.Name),
+			strings.Join(columns, ", "), strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ")))
+		if err != nil {
+			return err
+		}
+		inserts[table] = insert
+	}
+
+	err = d.rows(ctx, func(table *Table, values ...interface{}) error {
+		described[table].RowCount++
+		if _, err := inserts[table].ExecContext(ctx, values...); err != nil {
+			return fmt.Errorf("failed to write %s: %w", table.Name, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// The manifest goes in last, once the rows are counted
+	manifestJSON, err := json.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `CREATE TABLE manifest (name TEXT PRIMARY KEY, value TEXT NOT NULL)`); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO manifest (name, value) VALUES (?, ?), ('revision', ?)`, ManifestFile, string(manifestJSON), d.Revision); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	if err := db.Close(); err != nil {
+		return err
+	}
+
+	file, err := os.Open(tmp.Name())
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = io.Copy(w, file)
+	return err
+}
+
+// createTable is the CREATE TABLE statement of a table, with its primary and foreign keys
+func createTable(table *Table) string {
+	var definitions []string
+	for _, column := range table.Columns {
+		sqlType := "TEXT"
+		if column.Type == TypeInteger || column.Type == TypeBoolean {
+			sqlType = "INTEGER"
+		}
+		definitions = append(definitions, quote(column.Name)+" "+sqlType+" NOT NULL")
+	}
+	definitions = append(definitions, "PRIMARY KEY ("+quoteAll(table.PrimaryKey)+")")
+	for _, key := range table.ForeignKeys {
+		definitions = append(definitions, fmt.Sprintf("FOREIGN KEY (%s) REFERENCES %s (%s)",
+			quoteAll(key.Columns), quote(key.Table), quoteAll(key.References)))
+	}
+	return "CREATE TABLE " + quote(table.Name) + " (\n\t" + strings.Join(definitions, ",\n\t") + "\n)"
+}
+
+func quote(name string) string {
+	return `"` + name + `"`
+}
+
+func quoteAll(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = quote(name)
+	}
+	return strings.Join(quoted, ", ")
+}
@@ -0,0 +1,180 @@
+// Package gitdata reads BattleScribe data files from revisions of the local data git repository
+package gitdata
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// revisionPattern limits revisions to branch, tag and commit syntax so they can't be read as git options
+var revisionPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._/~^@{}-]*$`)
+
+// ValidRevision reports whether rev looks like a git revision
+func ValidRevision(rev string) bool {
+	return revisionPattern.MatchString(rev) && !strings.Contains(rev, "..")
+}
+
+// ResolveCommit resolves a revision to its full commit hash
+func ResolveCommit(ctx context.Context, repoDir, rev string) (string, error) {
+	if !ValidRevision(rev) {
+		return "", fmt.Errorf("invalid git revision: %s", rev)
+	}
+	out, err := run(ctx, repoDir, "rev-parse", "--verify", "--quiet", rev+"^{commit}")
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		return "", fmt.Errorf("unknown git revision %s in %s", rev, repoDir)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// ExtractRevision writes the .gst and .cat files of a commit into destDir. The archive is untarred
+// as git writes it, so a whole revision is never held in memory.
+func ExtractRevision(ctx context.Context, repoDir, commit, destDir string) error {
+	if !ValidRevision(commit) {
+		return fmt.Errorf("invalid git revision: %s", commit)
+	}
+
+	cmd := exec.CommandContext(ctx, "git", "-C", repoDir, "archive", "--format=tar", commit)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to archive %s: %w", commit, err)
+	}
+
+	extractErr := extractTar(stdout, destDir)
+	if extractErr == nil {
+		// git may still be writing the padding after the end of the archive
+		_, extractErr = io.Copy(io.Discard, stdout)
+	} else {
+		// Nothing reads the rest, so stop git rather than leave it blocked on the pipe
+		cmd.Process.Kill()
+	}
+	waitErr := cmd.Wait()
+
+	switch {
+	case ctx.Err() != nil:
+		return ctx.Err()
+	case extractErr != nil:
+		return fmt.Errorf("failed to read archive of %s: %w", commit, extractErr)
+	case waitErr != nil:
+		return fmt.Errorf("failed to archive %s: git archive: %w: %s", commit, waitErr, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// extractTar writes the data files of a tar stream into destDir
+func extractTar(r io.Reader, destDir string) error {
+	reader := tar.NewReader(r)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if header.Typeflag != tar.TypeReg || !IsDataFile(header.Name) || !filepath.IsLocal(header.Name) {
+			continue
+		}
+
+		target := filepath.Join(destDir, filepath.FromSlash(header.Name))
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return err
+		}
+		file, err := os.Create(target)
+		if err != nil {
+			return err
+		}
+		_, copyErr := io.Copy(file, reader)
+		closeErr := file.Close()
+		if copyErr != nil {
+			return copyErr
+		}
+		if closeErr != nil {
+			return closeErr
+		}
+	}
+}
+
+// Commit is a commit of the data repository
+type Commit struct {
+	Hash string
+	Date time.Time
+}
+
+// DataCommits lists the commits that touched data files, oldest first. When since is set
+// only commits after it are listed.
+func DataCommits(ctx context.Context, repoDir, since string) ([]Commit, error) {
+	rangeSpec := "HEAD"
+	if since != "" {
+		if !ValidRevision(since) {
+			return nil, fmt.Errorf("invalid git revision: %s", since)
+		}
+		rangeSpec = since + "..HEAD"
+	}
+
+	out, err := run(ctx, repoDir, "log", "--reverse", "--format=%H %cI", rangeSpec, "--", "*.gst", "*.cat")
+	if err != nil {
+		return nil, err
+	}
+
+	var commits []Commit
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		hash, date, found := strings.Cut(line, " ")
+		if !found {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, date)
+		if err != nil {
+			return nil, fmt.Errorf("unexpected commit date %q: %w", date, err)
+		}
+		commits = append(commits, Commit{Hash: hash, Date: parsed})
+	}
+	return commits, nil
+}
+
+// IsAncestor reports whether ancestor is reachable from commit
+func IsAncestor(ctx context.Context, repoDir, ancestor, commit string) bool {
+	if !ValidRevision(ancestor) || !ValidRevision(commit) {
+		return false
+	}
+	_, err := run(ctx, repoDir, "merge-base", "--is-ancestor", ancestor, commit)
+	return err == nil
+}
+
+// IsDataFile reports whether a path is a BattleScribe game system or catalogue file
+func IsDataFile(path string) bool {
+	lower := strings.ToLower(path)
+	return strings.HasSuffix(lower, ".gst") || strings.HasSuffix(lower, ".cat")
+}
+
+// run executes a git command in repoDir and returns its standard output. The command is killed
+// once ctx is done.
+func run(ctx context.Context, repoDir string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", repoDir}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
@@ -0,0 +1,101 @@
+// Package graphql serves the game system, factions, catalogues, units and rules as one GraphQL
+// graph, so a client can fetch a catalogue with its units, profiles and weapons in one request.
+// Resolvers read the snapshot's repositories through per-request loaders that batch and cache
+// lookups, and queries are measured against depth and complexity limits before they run.
+package graphql
+
+import (
+	"context"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+
+	"grimoire-api/internal/service"
+)
+
+// Request is a GraphQL request, as sent in a POST body or the query string of a GET
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+// Response is the result of a request. Data is absent when the request failed before running.
+type Response struct {
+	Data   interface{} `json:"data,omitempty"`
+	Errors []Error     `json:"errors,omitempty"`
+}
+
+// Error is an error of a request, or of the field at Path
+type Error struct {
+	Message   string        `json:"message"`
+	Locations []Location    `json:"locations,omitempty"`
+	Path      []interface{} `json:"path,omitempty"`
+}
+
+// Location is a position in the query
+type Location struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// Schema executes requests against the graph
+type Schema struct {
+	schema graphql.Schema
+	limits Limits
+}
+
+// NewSchema builds the schema, refusing queries beyond limits
+func NewSchema(limits Limits) (*Schema, error) {
+	schema, err := newSchema()
+	if err != nil {
+		return nil, err
+	}
+	return &Schema{schema: schema, limits: limits}, nil
+}
+
+// Execute runs request against the repositories of a snapshot. ok is false when the request was
+// refused before running: it didn't parse, validate or fit the limits.
+func (s *Schema) Execute(ctx context.Context, repositories service.Repositories, request Request) (response *Response, ok bool) {
+	return s.execute(ctx, newLoaders(ctx, repositories), request)
+}
+
+func (s *Schema) execute(ctx context.Context, l *loaders, request Request) (*Response, bool) {
+	document, err := parser.Parse(parser.ParseParams{
+		Source: source.NewSource(&source.Source{Body: []byte(request.Query), Name: "GraphQL request"}),
+	})
+	if err != nil {
+		return &Response{Errors: toErrors(gqlerrors.FormatErrors(err))}, false
+	}
+	if validation := graphql.ValidateDocument(&s.schema, document, nil); !validation.IsValid {
+		return &Response{Errors: toErrors(validation.Errors)}, false
+	}
+	if err := s.limits.check(&s.schema, document, request.Variables); err != nil {
+		return &Response{Errors: []Error{{Message: err.Error()}}}, false
+	}
+
+	result := graphql.Execute(graphql.ExecuteParams{
+		Schema:        s.schema,
+		AST:           document,
+		OperationName: request.OperationName,
+		Args:          request.Variables,
+		Context:       context.WithValue(ctx, loadersKey{}, l),
+	})
+	return &Response{Data: result.Data, Errors: toErrors(result.Errors)}, true
+}
+
+func toErrors(formatted []gqlerrors.FormattedError) []Error {
+	if len(formatted) == 0 {
+		return nil
+	}
+	errors := make([]Error, len(formatted))
+	for i, err := range formatted {
+		errors[i] = Error{Message: err.Message, Path: err.Path}
+		for _, location := range err.Locations {
+			errors[i].Locations = append(errors[i].Locations, Location{Line: location.Line, Column: location.Column})
+		}
+	}
+	return errors
+}
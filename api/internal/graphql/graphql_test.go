@@ -0,0 +1,153 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"grimoire-api/internal/service"
+	"grimoire-api/internal/snapshot"
+)
+
+const fixtureDir = "../../testdata/wh40k-fixture"
+
+func loadRepositories(t *testing.T) service.Repositories {
+	snap, err := snapshot.Load(snapshot.Config{DataDir: fixtureDir, Quiet: true})
+	require.NoError(t, err)
+	return snap.Repositories
+}
+
+// run executes query and decodes its data into data, returning its field errors
+func run(t *testing.T, schema *Schema, l *loaders, query string, variables map[string]interface{}, data interface{}) []Error {
+	response, ok := schema.execute(context.Background(), l, Request{Query: query, Variables: variables})
+	require.True(t, ok, "%+v", response.Errors)
+	encoded, err := json.Marshal(response.Data)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(encoded, data))
+	return response.Errors
+}
+
+func TestCatalogueUnitsAreBatched(t *testing.T) {
+	repositories := loadRepositories(t)
+	schema, err := NewSchema(DefaultLimits)
+	require.NoError(t, err)
+	l := newLoaders(context.Background(), repositories)
+
+	var data struct {
+		Catalogue struct {
+			Name  string
+			Units []struct {
+				ID      string
+				Name    string
+				Points  *int
+				Weapons *struct {
+					Ranged []struct{ Name string }
+					Melee  []struct{ Name string }
+				}
+				Faction *struct{ ID string }
+			}
+		}
+	}
+	errors := run(t, schema, l, `{
+		catalogue(id: "cat-fixture-marines") {
+			name
+			units { id name points weapons { ranged { name } melee { name } } faction { id } }
+		}
+	}`, nil, &data)
+
+	// The fixture's broken entryLink fails the fields that load it, without failing the other units
+	require.NotEmpty(t, errors)
+	for _, err := range errors {
+		assert.Contains(t, err.Message, "el-fixture-broken")
+	}
+	assert.NotEmpty(t, data.Catalogue.Name)
+	require.NotEmpty(t, data.Catalogue.Units)
+	var captain bool
+	for _, unit := range data.Catalogue.Units {
+		if unit.ID == "el-fixture-captain" {
+			captain = true
+			require.NotNil(t, unit.Weapons)
+			assert.NotEmpty(t, append(unit.Weapons.Ranged, unit.Weapons.Melee...))
+		}
+	}
+	assert.True(t, captain)
+	// Every unit of the catalogue is fetched in one batch, not one lookup per unit
+	assert.Equal(t, 1, l.units.batches)
+	assert.Equal(t, 1, l.catalogues.batches)
+}
+
+func TestUnitsPage(t *testing.T) {
+	repositories := loadRepositories(t)
+	schema, err := NewSchema(DefaultLimits)
+	require.NoError(t, err)
+
+	var data struct {
+		Units struct {
+			Items   []struct{ ID string }
+			Total   int
+			Limit   int
+			HasMore bool
+		}
+	}
+	errors := run(t, schema, newLoaders(context.Background(), repositories),
+		`query Page($limit: Int) { units(limit: $limit) { items { id } total limit hasMore } }`,
+		map[string]interface{}{"limit": float64(2)}, &data)
+	assert.Empty(t, errors)
+
+	assert.Len(t, data.Units.Items, 2)
+	assert.Equal(t, 2, data.Units.Limit)
+	assert.Greater(t, data.Units.Total, 2)
+	assert.True(t, data.Units.HasMore)
+}
+
+func TestFieldErrors(t *testing.T) {
+	schema, err := NewSchema(DefaultLimits)
+	require.NoError(t, err)
+
+	response, ok := schema.Execute(context.Background(), loadRepositories(t), Request{Query: `{ unit(id: "missing") { name } }`})
+	assert.True(t, ok)
+	require.Len(t, response.Errors, 1)
+	assert.Equal(t, []interface{}{"unit"}, response.Errors[0].Path)
+	assert.Equal(t, map[string]interface{}{"unit": nil}, response.Data)
+}
+
+func TestRefusedRequests(t *testing.T) {
+	repositories := loadRepositories(t)
+	schema, err := NewSchema(Limits{MaxDepth: 4, MaxComplexity: 500})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		query     string
+		variables map[string]interface{}
+		message   string
+	}{
+		{"syntax", `{ units {`, nil, "Syntax Error"},
+		{"unknown field", `{ nope }`, nil, "Cannot query field"},
+		{"depth", `{ catalogues { linkedCatalogues { linkedCatalogues { linkedCatalogues { id } } } } }`, nil, "nested 5 levels deep"},
+		{"complexity", `{ catalogues { units { id name } } }`, nil, "complexity"},
+		{"page size", `{ units(limit: 100) { items { id name points abilities { name description } } } }`, nil, "complexity"},
+		{"page size variable", `query($n: Int) { units(limit: $n) { items { id name points abilities { name description } } } }`,
+			map[string]interface{}{"n": float64(100)}, "complexity"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			l := newLoaders(context.Background(), repositories)
+			response, ok := schema.execute(context.Background(), l, Request{Query: test.query, Variables: test.variables})
+			assert.False(t, ok)
+			assert.Nil(t, response.Data)
+			require.NotEmpty(t, response.Errors)
+			assert.Contains(t, response.Errors[0].Message, test.message)
+			// Nothing was fetched for a refused query
+			assert.Zero(t, l.units.batches)
+		})
+	}
+
+	// A small page of the same fields fits
+	response, ok := schema.Execute(context.Background(), repositories, Request{Query: `{ units(limit: 5) { items { id name points abilities { name description } } } }`})
+	assert.True(t, ok)
+	assert.Empty(t, response.Errors)
+}
@@ -0,0 +1,206 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// Limits bound what a single query can ask for, before it runs
+type Limits struct {
+	MaxDepth      int // Nesting of fields, counting the root fields as 1
+	MaxComplexity int // Estimated fields resolved, with lists counted at their limit or usual length
+}
+
+// DefaultLimits allow a catalogue or a page of units with everything about them, but not the
+// units of every catalogue
+var DefaultLimits = Limits{MaxDepth: 10, MaxComplexity: 5000}
+
+// listSizes estimates the length of list fields without a limit argument, by parent type and
+// field; other lists are estimated at defaultListSize
+var listSizes = map[string]int{
+	"Query.catalogues":           50,
+	"Query.factions":             50,
+	"Query.rules":                200,
+	"Catalogue.units":            100,
+	"Catalogue.linkedCatalogues": 5,
+	"Faction.subFactions":        5,
+	"Faction.libraries":          5,
+}
+
+const defaultListSize = 10
+
+// analysis measures the depth and complexity of a query document
+type analysis struct {
+	schema    *graphql.Schema
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+}
+
+// check measures every operation of document and fails if one exceeds the limits
+func (l Limits) check(schema *graphql.Schema, document *ast.Document, variables map[string]interface{}) error {
+	a := &analysis{schema: schema, fragments: make(map[string]*ast.FragmentDefinition), variables: variables}
+	var operations []*ast.OperationDefinition
+	for _, definition := range document.Definitions {
+		switch definition := definition.(type) {
+		case *ast.FragmentDefinition:
+			a.fragments[definition.Name.Value] = definition
+		case *ast.OperationDefinition:
+			operations = append(operations, definition)
+		}
+	}
+
+	for _, operation := range operations {
+		if operation.Operation != ast.OperationTypeQuery {
+			continue // The schema only has queries, and validation rejects the others
+		}
+		depth, complexity := a.selections(schema.QueryType(), operation.SelectionSet, 1, DefaultPageSize, operation.VariableDefinitions)
+		if l.MaxDepth > 0 && depth > l.MaxDepth {
+			return fmt.Errorf("query is nested %d levels deep; the limit is %d", depth, l.MaxDepth)
+		}
+		if l.MaxComplexity > 0 && complexity > l.MaxComplexity {
+			return fmt.Errorf("query complexity is %d; the limit is %d. Ask for fewer fields or smaller pages", complexity, l.MaxComplexity)
+		}
+	}
+	return nil
+}
+
+// selections returns the depth and complexity of a selection set on parent, at depth. Each field
+// costs 1, plus the complexity of its selections, times the estimated length of a list. Fields
+// of the introspection schema are free. pageSize is the limit of the unit list being selected.
+func (a *analysis) selections(parent *graphql.Object, set *ast.SelectionSet, depth, pageSize int, definitions []*ast.VariableDefinition) (maxDepth, complexity int) {
+	if set == nil {
+		return 0, 0
+	}
+	for _, selection := range set.Selections {
+		var d, c int
+		switch selection := selection.(type) {
+		case *ast.Field:
+			name := selection.Name.Value
+			if strings.HasPrefix(name, "__") {
+				continue
+			}
+			field, found := parent.Fields()[name]
+			if !found {
+				continue
+			}
+			d, c = depth, 1
+			child, list := namedObject(field.Type)
+			if child == nil {
+				break
+			}
+			size, limited := a.limit(field, selection, definitions)
+			childPageSize := pageSize
+			if limited && !list {
+				childPageSize = size // A page of units, whose items are the list
+			}
+			childDepth, childComplexity := a.selections(child, selection.SelectionSet, depth+1, childPageSize, definitions)
+			d = max(d, childDepth)
+			if list {
+				switch {
+				case limited:
+				case parent.Name() == "UnitList":
+					size = pageSize
+				case listSizes[parent.Name()+"."+name] > 0:
+					size = listSizes[parent.Name()+"."+name]
+				default:
+					size = defaultListSize
+				}
+				childComplexity *= size
+			}
+			c += childComplexity
+		case *ast.InlineFragment:
+			d, c = a.selections(a.condition(parent, selection.TypeCondition), selection.SelectionSet, depth, pageSize, definitions)
+		case *ast.FragmentSpread:
+			fragment, found := a.fragments[selection.Name.Value]
+			if !found {
+				continue
+			}
+			d, c = a.selections(a.condition(parent, fragment.TypeCondition), fragment.SelectionSet, depth, pageSize, definitions)
+		}
+		maxDepth = max(maxDepth, d)
+		complexity += c
+	}
+	return maxDepth, complexity
+}
+
+// limit is the value of a field's limit argument: given as a literal or a variable, or else its
+// default. limited is false for a field without a limit argument.
+func (a *analysis) limit(field *graphql.FieldDefinition, selection *ast.Field, definitions []*ast.VariableDefinition) (size int, limited bool) {
+	var argument *graphql.Argument
+	for _, arg := range field.Args {
+		if arg.Name() == "limit" {
+			argument = arg
+		}
+	}
+	if argument == nil {
+		return 0, false
+	}
+	size = MaxPageSize
+	if n, ok := argument.DefaultValue.(int); ok {
+		size = n
+	}
+	for _, given := range selection.Arguments {
+		if given.Name.Value != "limit" {
+			continue
+		}
+		switch value := given.Value.(type) {
+		case *ast.IntValue:
+			if n, err := strconv.Atoi(value.Value); err == nil {
+				size = n
+			}
+		case *ast.Variable:
+			size = MaxPageSize
+			for _, definition := range definitions {
+				if definition.Variable.Name.Value != value.Name.Value {
+					continue
+				}
+				if defaultValue, ok := definition.DefaultValue.(*ast.IntValue); ok {
+					if n, err := strconv.Atoi(defaultValue.Value); err == nil {
+						size = n
+					}
+				}
+			}
+			switch n := a.variables[value.Name.Value].(type) {
+			case float64:
+				size = int(n)
+			case int:
+				size = n
+			}
+		}
+	}
+	// Out of range limits fail when the field resolves
+	return max(min(size, MaxPageSize), 1), true
+}
+
+// condition is the type a fragment applies to, or parent when it has no type condition
+func (a *analysis) condition(parent *graphql.Object, condition *ast.Named) *graphql.Object {
+	if condition == nil {
+		return parent
+	}
+	if object, ok := a.schema.Type(condition.Name.Value).(*graphql.Object); ok {
+		return object
+	}
+	return parent
+}
+
+// namedObject unwraps a field type to its object type, reporting whether it is a list. Scalar
+// and enum fields have no object type.
+func namedObject(t graphql.Type) (object *graphql.Object, list bool) {
+	for {
+		switch wrapped := t.(type) {
+		case *graphql.NonNull:
+			t = wrapped.OfType
+		case *graphql.List:
+			list = true
+			t = wrapped.OfType
+		case *graphql.Object:
+			return wrapped, list
+		default:
+			return nil, list
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package graphql
+
+import (
+	"context"
+	"sync"
+
+	"grimoire-api/internal/models"
+	"grimoire-api/internal/service"
+)
+
+// maxFetches bounds the concurrent fetches of one batch
+const maxFetches = 8
+
+// loader fetches values by key for one request, like a dataloader. Resolvers ask for keys and get
+// a thunk; the executor calls thunks level by level, so every key asked for while a level resolved
+// is fetched together, concurrently, when the first thunk runs. Each key is fetched once.
+type loader[V any] struct {
+	ctx   context.Context
+	fetch func(ctx context.Context, key string) (V, error)
+
+	mu      sync.Mutex
+	pending []string
+	results map[string]*result[V]
+	batches int // Batches dispatched, for tests
+}
+
+type result[V any] struct {
+	value V
+	err   error
+	done  bool
+}
+
+func newLoader[V any](ctx context.Context, fetch func(ctx context.Context, key string) (V, error)) *loader[V] {
+	return &loader[V]{ctx: ctx, fetch: fetch, results: make(map[string]*result[V])}
+}
+
+// load queues key for the next batch and returns a thunk of its value
+func (l *loader[V]) load(key string) func() (V, error) {
+	l.mu.Lock()
+	if _, found := l.results[key]; !found {
+		l.results[key] = &result[V]{}
+		l.pending = append(l.pending, key)
+	}
+	l.mu.Unlock()
+
+	return func() (V, error) {
+		l.mu.Lock()
+		done := l.results[key].done
+		l.mu.Unlock()
+		if !done {
+			l.dispatch()
+		}
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		r := l.results[key]
+		return r.value, r.err
+	}
+}
+
+// dispatch fetches every queued key with a bounded pool of workers
+func (l *loader[V]) dispatch() {
+	l.mu.Lock()
+	keys := l.pending
+	l.pending = nil
+	if len(keys) > 0 {
+		l.batches++
+	}
+	l.mu.Unlock()
+
+	fetched := make([]result[V], len(keys))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(maxFetches, len(keys)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				value, err := l.fetch(l.ctx, keys[i])
+				fetched[i] = result[V]{value: value, err: err, done: true}
+			}
+		}()
+	}
+	for i := range keys {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	l.mu.Lock()
+	for i, key := range keys {
+		*l.results[key] = fetched[i]
+	}
+	l.mu.Unlock()
+}
+
+// loaders are the loaders of one request, over the repositories of its snapshot
+type loaders struct {
+	repositories service.Repositories
+	units        *loader[*models.UnitResponse]
+	catalogues   *loader[*models.CatalogueResponse]
+	factions     *loader[*models.FactionResponse]
+	rules        *loader[*models.RuleResponse]
+}
+
+func newLoaders(ctx context.Context, repositories service.Repositories) *loaders {
+	return &loaders{
+		repositories: repositories,
+		units:        newLoader(ctx, repositories.Units.GetUnit),
+		catalogues:   newLoader(ctx, repositories.Catalogues.GetCatalogue),
+		factions:     newLoader(ctx, repositories.Factions.GetFaction),
+		rules:        newLoader(ctx, repositories.Rules.GetRule),
+	}
+}
+
+type loadersKey struct{}
+
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
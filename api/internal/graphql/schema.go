@@ -0,0 +1,697 @@
+package graphql
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/graphql-go/graphql"
+
+	"grimoire-api/internal/models"
+	"grimoire-api/internal/service"
+)
+
+// Page sizes of the unit lists
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// unitSource is a unit being resolved: its ID, and its summary when it came from a list, so
+// fields the summary holds don't load the whole unit
+type unitSource struct {
+	id      string
+	summary *models.UnitSummary
+}
+
+// catalogueSource is a catalogue being resolved; fields beyond its info load the catalogue
+type catalogueSource struct {
+	info models.CatalogueInfo
+}
+
+// ruleSource is a shared rule being resolved, named by a unit or loaded in full
+type ruleSource struct {
+	info models.RuleInfo
+	rule *models.RuleResponse
+}
+
+// cost is one entry of a unit's costs
+type cost struct {
+	Type  string `json:"type"`
+	Value int    `json:"value"`
+}
+
+// unitList is a page of units
+type unitList struct {
+	Items   []*unitSource
+	Total   int  `json:"total"`
+	Limit   int  `json:"limit"`
+	Offset  int  `json:"offset"`
+	HasMore bool `json:"hasMore"`
+}
+
+// newSchema builds the schema. Types that refer to each other declare their fields in thunks.
+func newSchema() (graphql.Schema, error) {
+	category := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Category",
+		Description: "A category, such as a faction keyword or a battlefield role",
+		Fields: graphql.Fields{
+			"id":      &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"name":    &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"primary": &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+		},
+	})
+	costType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "CostType",
+		Fields: graphql.Fields{
+			"id":               &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"name":             &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"defaultCostLimit": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"hidden":           &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+		},
+	})
+	profileType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "ProfileType",
+		Fields: graphql.Fields{
+			"id":              &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"name":            &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"characteristics": &graphql.Field{Type: nonNullList(graphql.String)},
+		},
+	})
+	gameSystem := graphql.NewObject(graphql.ObjectConfig{
+		Name: "GameSystem",
+		Fields: graphql.Fields{
+			"id":                  &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"name":                &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"revision":            &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"battleScribeVersion": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"profileTypes":        &graphql.Field{Type: nonNullList(profileType)},
+			"categories":          &graphql.Field{Type: nonNullList(category)},
+			"costTypes":           &graphql.Field{Type: nonNullList(costType)},
+		},
+	})
+	publication := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Publication",
+		Fields: graphql.Fields{
+			"id":              &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"name":            &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"shortName":       &graphql.Field{Type: graphql.String},
+			"publicationDate": &graphql.Field{Type: graphql.String},
+		},
+	})
+	modelProfile := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "ModelProfile",
+		Description: "The characteristics of a unit's models",
+		Fields: graphql.Fields{
+			"movement":         &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"toughness":        &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"save":             &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"wounds":           &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"leadership":       &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"objectiveControl": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		},
+	})
+	ability := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Ability",
+		Fields: graphql.Fields{
+			"name":        &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"description": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		},
+	})
+	weaponFields := func(skill string) graphql.Fields {
+		return graphql.Fields{
+			"name":             &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"range":            &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"attacks":          &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			skill:              &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"strength":         &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"armorPenetration": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"damage":           &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"keywords":         &graphql.Field{Type: nonNullList(graphql.String)},
+		}
+	}
+	rangedWeapon := graphql.NewObject(graphql.ObjectConfig{Name: "RangedWeapon", Fields: weaponFields("ballisticSkill")})
+	meleeWeapon := graphql.NewObject(graphql.ObjectConfig{Name: "MeleeWeapon", Fields: weaponFields("weaponSkill")})
+	weaponSet := graphql.NewObject(graphql.ObjectConfig{
+		Name: "WeaponSet",
+		Fields: graphql.Fields{
+			"ranged": &graphql.Field{Type: nonNullList(rangedWeapon)},
+			"melee":  &graphql.Field{Type: nonNullList(meleeWeapon)},
+		},
+	})
+	costEntry := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Cost",
+		Fields: graphql.Fields{
+			"type":  &graphql.Field{Type: graphql.NewNonNull(graphql.String), Description: "Cost type name, such as pts"},
+			"value": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		},
+	})
+	costTier := graphql.NewObject(graphql.ObjectConfig{
+		Name: "CostTier",
+		Fields: graphql.Fields{
+			"minModels": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"cost":      &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		},
+	})
+	tieredCosts := graphql.NewObject(graphql.ObjectConfig{
+		Name: "TieredCosts",
+		Fields: graphql.Fields{
+			"baseCost": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"tiers":    &graphql.Field{Type: nonNullList(costTier)},
+		},
+	})
+
+	var catalogue, faction, rule, unit, unitListType *graphql.Object
+	catalogue = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Catalogue",
+		Description: "A catalogue or library of the data",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":       &graphql.Field{Type: graphql.NewNonNull(graphql.ID), Resolve: catalogueInfo(func(c models.CatalogueInfo) interface{} { return c.ID })},
+				"name":     &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: catalogueInfo(func(c models.CatalogueInfo) interface{} { return c.Name })},
+				"revision": &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: catalogueInfo(func(c models.CatalogueInfo) interface{} { return c.Revision })},
+				"library":  &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean), Resolve: catalogueInfo(func(c models.CatalogueInfo) interface{} { return c.Library })},
+				"homebrew": &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean), Resolve: catalogueInfo(func(c models.CatalogueInfo) interface{} { return c.Homebrew })},
+				"gameSystemId": &graphql.Field{Type: graphql.ID, Resolve: catalogueField(func(c *models.CatalogueResponse) interface{} {
+					return c.GameSystemID
+				})},
+				"linkedCatalogues": &graphql.Field{Type: loadedList(catalogue), Resolve: catalogueField(func(c *models.CatalogueResponse) interface{} {
+					return catalogueSources(c.LinkedCatalogues)
+				})},
+				"publications": &graphql.Field{Type: loadedList(publication), Resolve: catalogueField(func(c *models.CatalogueResponse) interface{} {
+					return c.Publications
+				})},
+				"units": &graphql.Field{
+					Type:        graphql.NewList(unit),
+					Description: "Units at the root of the catalogue, including those its catalogueLinks import",
+					Resolve: catalogueField(func(c *models.CatalogueResponse) interface{} {
+						return unitSources(c.Units)
+					}),
+				},
+			}
+		}),
+	})
+
+	faction = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Faction",
+		Description: "A faction derived from the faction keyword categories",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":           &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+				"name":         &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+				"superFaction": &graphql.Field{Type: graphql.String},
+				"parentId":     &graphql.Field{Type: graphql.ID},
+				"keyword":      &graphql.Field{Type: category},
+				"unitCount":    &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+				"catalogue": &graphql.Field{Type: graphql.NewNonNull(catalogue), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return &catalogueSource{info: p.Source.(*models.FactionResponse).Catalogue}, nil
+				}},
+				"subFactions": &graphql.Field{Type: nonNullList(catalogue), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return catalogueSources(p.Source.(*models.FactionResponse).SubFactions), nil
+				}},
+				"libraries": &graphql.Field{Type: nonNullList(catalogue), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return catalogueSources(p.Source.(*models.FactionResponse).Libraries), nil
+				}},
+				"units": &graphql.Field{
+					Type: graphql.NewNonNull(unitListType),
+					Args: pageArgs(),
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						query := service.UnitQuery{Factions: []string{p.Source.(*models.FactionResponse).ID}}
+						if err := page(p.Args, &query); err != nil {
+							return nil, err
+						}
+						return listUnits(p, query)
+					},
+				},
+			}
+		}),
+	})
+
+	rule = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Rule",
+		Description: "A shared rule of the game system, a catalogue or a library",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id": &graphql.Field{Type: graphql.NewNonNull(graphql.ID), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(*ruleSource).info.ID, nil
+				}},
+				"name": &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(*ruleSource).info.Name, nil
+				}},
+				"description": &graphql.Field{Type: graphql.String, Resolve: ruleField(func(r *models.RuleResponse) interface{} {
+					return r.Description
+				})},
+				"catalogue": &graphql.Field{
+					Type:        catalogue,
+					Description: "Catalogue or library defining the rule; null for the game system",
+					Resolve: ruleField(func(r *models.RuleResponse) interface{} {
+						if r.Catalogue == nil {
+							return nil
+						}
+						return &catalogueSource{info: *r.Catalogue}
+					}),
+				},
+			}
+		}),
+	})
+
+	unit = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Unit",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id": &graphql.Field{
+					Type:        graphql.NewNonNull(graphql.ID),
+					Description: "entryLink ID of the unit in its catalogue",
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return p.Source.(*unitSource).id, nil
+					},
+				},
+				"name": &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if summary := p.Source.(*unitSource).summary; summary != nil {
+						return summary.Name, nil
+					}
+					return unitField(func(u *models.UnitResponse) interface{} { return u.Name })(p)
+				}},
+				"points": &graphql.Field{Type: graphql.Int, Description: "Base points cost", Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if summary := p.Source.(*unitSource).summary; summary != nil {
+						return points(summary.Costs), nil
+					}
+					return unitField(func(u *models.UnitResponse) interface{} { return points(u.Costs) })(p)
+				}},
+				"type": &graphql.Field{Type: graphql.String, Resolve: unitField(func(u *models.UnitResponse) interface{} {
+					return u.Type
+				})},
+				"costs": &graphql.Field{Type: loadedList(costEntry), Resolve: unitField(func(u *models.UnitResponse) interface{} {
+					return costs(u.Costs)
+				})},
+				"tieredCosts": &graphql.Field{Type: tieredCosts, Resolve: unitField(func(u *models.UnitResponse) interface{} {
+					return u.TieredCosts
+				})},
+				"profile": &graphql.Field{Type: modelProfile, Resolve: unitField(func(u *models.UnitResponse) interface{} {
+					if u.Profiles == nil {
+						return nil
+					}
+					return u.Profiles.Unit
+				})},
+				"transportCapacity": &graphql.Field{Type: graphql.String, Resolve: unitField(func(u *models.UnitResponse) interface{} {
+					if u.Profiles == nil || u.Profiles.Transport == nil {
+						return nil
+					}
+					return u.Profiles.Transport.Capacity
+				})},
+				"abilities": &graphql.Field{Type: loadedList(ability), Resolve: unitField(func(u *models.UnitResponse) interface{} {
+					if u.Profiles == nil {
+						return nil
+					}
+					return u.Profiles.Abilities
+				})},
+				"weapons": &graphql.Field{Type: weaponSet, Resolve: unitField(func(u *models.UnitResponse) interface{} {
+					if u.Weapons == nil {
+						return &models.WeaponSet{}
+					}
+					return u.Weapons
+				})},
+				"categories": &graphql.Field{Type: loadedList(category), Resolve: unitField(func(u *models.UnitResponse) interface{} {
+					return u.Categories
+				})},
+				"rules": &graphql.Field{Type: loadedList(rule), Resolve: unitField(func(u *models.UnitResponse) interface{} {
+					rules := make([]*ruleSource, len(u.Rules))
+					for i, info := range u.Rules {
+						rules[i] = &ruleSource{info: info}
+					}
+					return rules
+				})},
+				"faction": &graphql.Field{Type: faction, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					l := loadersFrom(p.Context)
+					unit := l.units.load(p.Source.(*unitSource).id)
+					return func() (interface{}, error) {
+						u, err := unit()
+						if err != nil || u.Faction == nil {
+							return nil, err
+						}
+						return l.factions.load(u.Faction.ID)()
+					}, nil
+				}},
+				"catalogue": &graphql.Field{Type: catalogue, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if summary := p.Source.(*unitSource).summary; summary != nil && summary.Catalogue != nil {
+						return &catalogueSource{info: *summary.Catalogue}, nil
+					}
+					return unitField(func(u *models.UnitResponse) interface{} {
+						if u.Catalogue == nil {
+							return nil
+						}
+						return &catalogueSource{info: *u.Catalogue}
+					})(p)
+				}},
+			}
+		}),
+	})
+
+	unitListType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "UnitList",
+		Fields: graphql.Fields{
+			"items":   &graphql.Field{Type: unitListOf(unit)},
+			"total":   &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"limit":   &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"offset":  &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"hasMore": &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+		},
+	})
+
+	searchResult := graphql.NewObject(graphql.ObjectConfig{
+		Name: "SearchResult",
+		Fields: graphql.Fields{
+			"type":    &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"id":      &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"name":    &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"summary": &graphql.Field{Type: graphql.String},
+			"unit": &graphql.Field{Type: graphql.NewNonNull(unit), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return &unitSource{id: p.Source.(models.SearchResult).ID}, nil
+			}},
+		},
+	})
+
+	legends := graphql.NewEnum(graphql.EnumConfig{
+		Name: "Legends",
+		Values: graphql.EnumValueConfigMap{
+			"INCLUDE": &graphql.EnumValueConfig{Value: string(service.LegendsInclude)},
+			"EXCLUDE": &graphql.EnumValueConfig{Value: string(service.LegendsExclude)},
+			"ONLY":    &graphql.EnumValueConfig{Value: string(service.LegendsOnly)},
+		},
+	})
+	unitSort := graphql.NewEnum(graphql.EnumConfig{
+		Name: "UnitSort",
+		Values: graphql.EnumValueConfigMap{
+			"NAME":      &graphql.EnumValueConfig{Value: service.SortByName},
+			"POINTS":    &graphql.EnumValueConfig{Value: service.SortByPoints},
+			"TOUGHNESS": &graphql.EnumValueConfig{Value: service.SortByToughness},
+			"WOUNDS":    &graphql.EnumValueConfig{Value: service.SortByWounds},
+			"OC":        &graphql.EnumValueConfig{Value: service.SortByOC},
+			"CATALOGUE": &graphql.EnumValueConfig{Value: service.SortByCatalogue},
+		},
+	})
+
+	unitsArgs := pageArgs()
+	filters := graphql.FieldConfigArgument{
+		"faction":    {Type: graphql.NewList(graphql.NewNonNull(graphql.String)), Description: "Faction ID, name, keyword, catalogue or super-faction; any of several"},
+		"category":   {Type: graphql.NewList(graphql.NewNonNull(graphql.String)), Description: "Category name substring; any of several"},
+		"catalogue":  {Type: graphql.NewList(graphql.NewNonNull(graphql.String)), Description: "Catalogue ID or name; any of several"},
+		"search":     {Type: graphql.String, Description: "Unit name substring"},
+		"minPoints":  {Type: graphql.Int},
+		"maxPoints":  {Type: graphql.Int},
+		"legends":    {Type: legends, DefaultValue: string(service.LegendsInclude)},
+		"sort":       {Type: unitSort, DefaultValue: service.SortByName},
+		"descending": {Type: graphql.Boolean, DefaultValue: false},
+	}
+	for name, arg := range filters {
+		unitsArgs[name] = arg
+	}
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"gameSystem": &graphql.Field{Type: graphql.NewNonNull(gameSystem), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return loadersFrom(p.Context).repositories.GameSystem.GetGameSystem(p.Context)
+			}},
+			"catalogues": &graphql.Field{Type: nonNullList(catalogue), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				catalogues, err := loadersFrom(p.Context).repositories.Catalogues.ListCatalogues(p.Context)
+				if err != nil {
+					return nil, err
+				}
+				return catalogueSources(catalogues), nil
+			}},
+			"catalogue": &graphql.Field{
+				Type: catalogue,
+				Args: graphql.FieldConfigArgument{"id": {Type: graphql.NewNonNull(graphql.ID)}},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					thunk := loadersFrom(p.Context).catalogues.load(p.Args["id"].(string))
+					return func() (interface{}, error) {
+						c, err := thunk()
+						if err != nil {
+							return nil, err
+						}
+						return &catalogueSource{info: models.CatalogueInfo{ID: c.ID, Name: c.Name, Revision: c.Revision, Library: c.Library}}, nil
+					}, nil
+				},
+			},
+			"factions": &graphql.Field{Type: nonNullList(faction), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				factions, err := loadersFrom(p.Context).repositories.Factions.ListFactions(p.Context)
+				if err != nil {
+					return nil, err
+				}
+				sources := make([]*models.FactionResponse, len(factions))
+				for i := range factions {
+					sources[i] = &factions[i]
+				}
+				return sources, nil
+			}},
+			"faction": &graphql.Field{
+				Type:        faction,
+				Description: "A faction by ID, name, keyword or catalogue",
+				Args:        graphql.FieldConfigArgument{"name": {Type: graphql.NewNonNull(graphql.String)}},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return thunk(loadersFrom(p.Context).factions.load(p.Args["name"].(string))), nil
+				},
+			},
+			"units": &graphql.Field{
+				Type: graphql.NewNonNull(unitListType),
+				Args: unitsArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					query := service.UnitQuery{
+						Factions:   stringList(p.Args["faction"]),
+						Categories: stringList(p.Args["category"]),
+						Catalogues: stringList(p.Args["catalogue"]),
+						Legends:    service.LegendsFilter(p.Args["legends"].(string)),
+						Sort:       p.Args["sort"].(string),
+						Descending: p.Args["descending"].(bool),
+					}
+					query.Search, _ = p.Args["search"].(string)
+					query.MinPoints, _ = p.Args["minPoints"].(int)
+					query.MaxPoints, _ = p.Args["maxPoints"].(int)
+					if query.MinPoints < 0 || query.MaxPoints < 0 {
+						return nil, fmt.Errorf("points must not be negative")
+					}
+					if query.MaxPoints > 0 && query.MinPoints > query.MaxPoints {
+						return nil, fmt.Errorf("minPoints must not exceed maxPoints")
+					}
+					if err := page(p.Args, &query); err != nil {
+						return nil, err
+					}
+					return listUnits(p, query)
+				},
+			},
+			"unit": &graphql.Field{
+				Type:        unit,
+				Description: "A unit by entryLink or selectionEntry ID",
+				Args:        graphql.FieldConfigArgument{"id": {Type: graphql.NewNonNull(graphql.ID)}},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					id := p.Args["id"].(string)
+					thunk := loadersFrom(p.Context).units.load(id)
+					return func() (interface{}, error) {
+						if _, err := thunk(); err != nil {
+							return nil, err
+						}
+						return &unitSource{id: id}, nil
+					}, nil
+				},
+			},
+			"search": &graphql.Field{
+				Type: nonNullList(searchResult),
+				Args: graphql.FieldConfigArgument{
+					"query": {Type: graphql.NewNonNull(graphql.String)},
+					"limit": {Type: graphql.Int, DefaultValue: DefaultPageSize},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					limit := p.Args["limit"].(int)
+					if limit < 1 || limit > MaxPageSize {
+						return nil, fmt.Errorf("limit must be between 1 and %d", MaxPageSize)
+					}
+					results, _, err := loadersFrom(p.Context).repositories.Units.SearchUnitsWithWarnings(p.Context, p.Args["query"].(string), limit)
+					return results, err
+				},
+			},
+			"rules": &graphql.Field{Type: nonNullList(rule), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				rules, err := loadersFrom(p.Context).repositories.Rules.ListRules(p.Context)
+				if err != nil {
+					return nil, err
+				}
+				sources := make([]*ruleSource, len(rules))
+				for i := range rules {
+					sources[i] = &ruleSource{info: models.RuleInfo{ID: rules[i].ID, Name: rules[i].Name}, rule: &rules[i]}
+				}
+				return sources, nil
+			}},
+			"rule": &graphql.Field{
+				Type: rule,
+				Args: graphql.FieldConfigArgument{"id": {Type: graphql.NewNonNull(graphql.ID)}},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					thunk := loadersFrom(p.Context).rules.load(p.Args["id"].(string))
+					return func() (interface{}, error) {
+						r, err := thunk()
+						if err != nil {
+							return nil, err
+						}
+						return &ruleSource{info: models.RuleInfo{ID: r.ID, Name: r.Name}, rule: r}, nil
+					}, nil
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: query})
+}
+
+func nonNullList(of graphql.Type) graphql.Output {
+	return graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(of)))
+}
+
+// loadedList is a list of a field that loads its parent. graphql-go fails the whole response when
+// a non-null field fails after loading, so fields that load are nullable: when the load fails the
+// field is null, with an error at its path.
+func loadedList(of graphql.Type) graphql.Output {
+	return graphql.NewList(graphql.NewNonNull(of))
+}
+
+// unitListOf is a list of units whose entries may be null: a unit that fails to resolve is null,
+// with an error at its path, rather than failing the whole list
+func unitListOf(unit *graphql.Object) graphql.Output {
+	return graphql.NewNonNull(graphql.NewList(unit))
+}
+
+// thunk adapts a loader's thunk to the executor's
+func thunk[V any](load func() (V, error)) func() (interface{}, error) {
+	return func() (interface{}, error) {
+		return load()
+	}
+}
+
+// unitField resolves a field of the whole unit, loading it in the request's next batch of units
+func unitField(get func(*models.UnitResponse) interface{}) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		load := loadersFrom(p.Context).units.load(p.Source.(*unitSource).id)
+		return func() (interface{}, error) {
+			unit, err := load()
+			if err != nil {
+				return nil, err
+			}
+			return get(unit), nil
+		}, nil
+	}
+}
+
+// catalogueInfo resolves a field of the catalogue's info, which its source holds
+func catalogueInfo(get func(models.CatalogueInfo) interface{}) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		return get(p.Source.(*catalogueSource).info), nil
+	}
+}
+
+// catalogueField resolves a field of the whole catalogue, loading it in the next batch
+func catalogueField(get func(*models.CatalogueResponse) interface{}) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		load := loadersFrom(p.Context).catalogues.load(p.Source.(*catalogueSource).info.ID)
+		return func() (interface{}, error) {
+			catalogue, err := load()
+			if err != nil {
+				return nil, err
+			}
+			return get(catalogue), nil
+		}, nil
+	}
+}
+
+// ruleField resolves a field of the whole rule, loading it in the next batch unless it's loaded
+func ruleField(get func(*models.RuleResponse) interface{}) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		source := p.Source.(*ruleSource)
+		if source.rule != nil {
+			return get(source.rule), nil
+		}
+		load := loadersFrom(p.Context).rules.load(source.info.ID)
+		return func() (interface{}, error) {
+			rule, err := load()
+			if err != nil {
+				return nil, err
+			}
+			return get(rule), nil
+		}, nil
+	}
+}
+
+// pageArgs are the arguments of a paginated unit list
+func pageArgs() graphql.FieldConfigArgument {
+	return graphql.FieldConfigArgument{
+		"limit":  {Type: graphql.Int, DefaultValue: DefaultPageSize, Description: fmt.Sprintf("Page size, 1 to %d", MaxPageSize)},
+		"offset": {Type: graphql.Int, DefaultValue: 0},
+	}
+}
+
+// page sets the limit and offset of query from the arguments
+func page(args map[string]interface{}, query *service.UnitQuery) error {
+	query.Limit = args["limit"].(int)
+	query.Offset = args["offset"].(int)
+	if query.Limit < 1 || query.Limit > MaxPageSize {
+		return fmt.Errorf("limit must be between 1 and %d", MaxPageSize)
+	}
+	if query.Offset < 0 {
+		return fmt.Errorf("offset must not be negative")
+	}
+	return nil
+}
+
+func listUnits(p graphql.ResolveParams, query service.UnitQuery) (*unitList, error) {
+	summaries, total, _, err := loadersFrom(p.Context).repositories.Units.ListUnitsWithWarnings(p.Context, query)
+	if err != nil {
+		return nil, err
+	}
+	return &unitList{
+		Items:   unitSources(summaries),
+		Total:   total,
+		Limit:   query.Limit,
+		Offset:  query.Offset,
+		HasMore: query.Offset+query.Limit < total,
+	}, nil
+}
+
+func unitSources(summaries []models.UnitSummary) []*unitSource {
+	sources := make([]*unitSource, len(summaries))
+	for i := range summaries {
+		sources[i] = &unitSource{id: summaries[i].ID, summary: &summaries[i]}
+	}
+	return sources
+}
+
+func catalogueSources(infos []models.CatalogueInfo) []*catalogueSource {
+	sources := make([]*catalogueSource, len(infos))
+	for i, info := range infos {
+		sources[i] = &catalogueSource{info: info}
+	}
+	return sources
+}
+
+// points is the base pts cost, or nil for a unit without one
+func points(costs map[string]int) interface{} {
+	if value, found := costs["pts"]; found {
+		return value
+	}
+	return nil
+}
+
+// costs lists a unit's costs by type name
+func costs(values map[string]int) []cost {
+	list := make([]cost, 0, len(values))
+	for costType, value := range values {
+		list = append(list, cost{Type: costType, Value: value})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Type < list[j].Type })
+	return list
+}
+
+// stringList converts a list argument to strings
+func stringList(arg interface{}) []string {
+	values, _ := arg.([]interface{})
+	list := make([]string, 0, len(values))
+	for _, value := range values {
+		list = append(list, value.(string))
+	}
+	return list
+}
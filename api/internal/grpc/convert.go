@@ -0,0 +1,221 @@
+package grpc
+
+import (
+	"grimoire-api/internal/models"
+	grimoirev1 "grimoire-api/pkg/pb/grimoire/v1"
+)
+
+// convert converts every item of a slice, keeping nil slices nil
+func convert[T, P any](items []T, toProto func(T) *P) []*P {
+	if items == nil {
+		return nil
+	}
+	converted := make([]*P, len(items))
+	for i, item := range items {
+		converted[i] = toProto(item)
+	}
+	return converted
+}
+
+// unitToProto converts a unit, dropping its resolution warnings unless debug is set
+func unitToProto(unit *models.UnitResponse, debug bool) *grimoirev1.Unit {
+	converted := &grimoirev1.Unit{
+		Id:          unit.ID,
+		Name:        unit.Name,
+		Type:        unit.Type,
+		Publication: publicationToProto(unit.Publication),
+		Weapons:     weaponsToProto(unit.Weapons),
+		Categories:  convert(unit.Categories, categoryToProto),
+		Rules:       convert(unit.Rules, ruleToProto),
+		Costs:       costsToProto(unit.Costs),
+		TieredCosts: tieredCostsToProto(unit.TieredCosts),
+		Overlays:    convert(unit.Overlays, overlayToProto),
+	}
+	if profiles := unit.Profiles; profiles != nil {
+		converted.Profiles = &grimoirev1.UnitProfiles{
+			Abilities: convert(profiles.Abilities, func(a models.AbilityProfile) *grimoirev1.AbilityProfile {
+				return &grimoirev1.AbilityProfile{Name: a.Name, Description: a.Description}
+			}),
+		}
+		if p := profiles.Unit; p != nil {
+			converted.Profiles.Unit = &grimoirev1.UnitProfile{
+				Movement:         p.Movement,
+				Toughness:        int32(p.Toughness),
+				Save:             p.Save,
+				Wounds:           int32(p.Wounds),
+				Leadership:       p.Leadership,
+				ObjectiveControl: int32(p.ObjectiveControl),
+			}
+		}
+		if profiles.Transport != nil {
+			converted.Profiles.Transport = &grimoirev1.TransportProfile{Capacity: profiles.Transport.Capacity}
+		}
+	}
+	if c := unit.Constraints; c != nil {
+		converted.Constraints = &grimoirev1.UnitConstraints{
+			MaxPerRoster: int32(c.MaxPerRoster),
+			MinPerRoster: int32(c.MinPerRoster),
+			MaxPerForce:  int32(c.MaxPerForce),
+			MinPerForce:  int32(c.MinPerForce),
+		}
+	}
+	if unit.Faction != nil {
+		converted.Faction = &grimoirev1.FactionInfo{Id: unit.Faction.ID, Name: unit.Faction.Name}
+	}
+	if unit.Catalogue != nil {
+		converted.Catalogue = catalogueInfoToProto(*unit.Catalogue)
+	}
+	if debug {
+		converted.Warnings = convert(unit.Warnings, warningToProto)
+	}
+	return converted
+}
+
+// catalogueToProto converts a catalogue, dropping its resolution warnings unless debug is set
+func catalogueToProto(catalogue *models.CatalogueResponse, debug bool) *grimoirev1.Catalogue {
+	converted := &grimoirev1.Catalogue{
+		Id:               catalogue.ID,
+		Name:             catalogue.Name,
+		Revision:         catalogue.Revision,
+		Library:          catalogue.Library,
+		GameSystemId:     catalogue.GameSystemID,
+		LinkedCatalogues: convert(catalogue.LinkedCatalogues, catalogueInfoToProto),
+		Units:            convert(catalogue.Units, unitSummaryToProto),
+		Publications: convert(catalogue.Publications, func(p models.PublicationInfo) *grimoirev1.PublicationInfo {
+			return publicationToProto(&p)
+		}),
+	}
+	if debug {
+		converted.Warnings = convert(catalogue.Warnings, warningToProto)
+	}
+	return converted
+}
+
+func unitSummaryToProto(summary models.UnitSummary) *grimoirev1.UnitSummary {
+	converted := &grimoirev1.UnitSummary{
+		Id:          summary.ID,
+		Name:        summary.Name,
+		TargetId:    summary.TargetID,
+		Costs:       costsToProto(summary.Costs),
+		TieredCosts: tieredCostsToProto(summary.TieredCosts),
+		Type:        summary.Type,
+		Overlays:    convert(summary.Overlays, overlayToProto),
+	}
+	if summary.Catalogue != nil {
+		converted.Catalogue = catalogueInfoToProto(*summary.Catalogue)
+	}
+	return converted
+}
+
+func weaponsToProto(weapons *models.WeaponSet) *grimoirev1.WeaponSet {
+	if weapons == nil {
+		return nil
+	}
+	return &grimoirev1.WeaponSet{
+		Ranged: convert(weapons.Ranged, func(w models.RangedWeapon) *grimoirev1.RangedWeapon {
+			return &grimoirev1.RangedWeapon{
+				Name:             w.Name,
+				Range:            w.Range,
+				Attacks:          w.Attacks,
+				BallisticSkill:   w.BallisticSkill,
+				Strength:         w.Strength,
+				ArmorPenetration: w.ArmorPenetration,
+				Damage:           w.Damage,
+				Keywords:         w.Keywords,
+			}
+		}),
+		Melee: convert(weapons.Melee, func(w models.MeleeWeapon) *grimoirev1.MeleeWeapon {
+			return &grimoirev1.MeleeWeapon{
+				Name:             w.Name,
+				Range:            w.Range,
+				Attacks:          w.Attacks,
+				WeaponSkill:      w.WeaponSkill,
+				Strength:         w.Strength,
+				ArmorPenetration: w.ArmorPenetration,
+				Damage:           w.Damage,
+				Keywords:         w.Keywords,
+			}
+		}),
+	}
+}
+
+func costsToProto(costs map[string]int) map[string]int32 {
+	if costs == nil {
+		return nil
+	}
+	converted := make(map[string]int32, len(costs))
+	for name, value := range costs {
+		converted[name] = int32(value)
+	}
+	return converted
+}
+
+func tieredCostsToProto(costs *models.TieredCosts) *grimoirev1.TieredCosts {
+	if costs == nil {
+		return nil
+	}
+	return &grimoirev1.TieredCosts{
+		BaseCost: int32(costs.BaseCost),
+		Tiers: convert(costs.Tiers, func(t models.CostTier) *grimoirev1.CostTier {
+			return &grimoirev1.CostTier{MinModels: int32(t.MinModels), Cost: int32(t.Cost)}
+		}),
+	}
+}
+
+func publicationToProto(publication *models.PublicationInfo) *grimoirev1.PublicationInfo {
+	if publication == nil {
+		return nil
+	}
+	return &grimoirev1.PublicationInfo{
+		Id:              publication.ID,
+		Name:            publication.Name,
+		ShortName:       publication.ShortName,
+		PublicationDate: publication.PublicationDate,
+		Page:            publication.Page,
+	}
+}
+
+func categoryToProto(category models.CategoryInfo) *grimoirev1.CategoryInfo {
+	return &grimoirev1.CategoryInfo{Id: category.ID, Name: category.Name, Primary: category.Primary}
+}
+
+func ruleToProto(rule models.RuleInfo) *grimoirev1.RuleInfo {
+	return &grimoirev1.RuleInfo{Id: rule.ID, Name: rule.Name}
+}
+
+func catalogueInfoToProto(info models.CatalogueInfo) *grimoirev1.CatalogueInfo {
+	return &grimoirev1.CatalogueInfo{
+		Id:       info.ID,
+		Name:     info.Name,
+		Revision: info.Revision,
+		Library:  info.Library,
+		Homebrew: info.Homebrew,
+	}
+}
+
+func overlayToProto(mark models.OverlayMark) *grimoirev1.OverlayMark {
+	return &grimoirev1.OverlayMark{
+		Overlay:  mark.Overlay,
+		TargetId: mark.TargetID,
+		Field:    mark.Field,
+		Original: mark.Original,
+		Value:    mark.Value,
+	}
+}
+
+func warningToProto(warning models.ResolutionWarning) *grimoirev1.ResolutionWarning {
+	return &grimoirev1.ResolutionWarning{
+		Code:        warning.Code,
+		Message:     warning.Message,
+		CatalogueId: warning.CatalogueID,
+		EntryId:     warning.EntryID,
+		LinkId:      warning.LinkID,
+		TargetId:    warning.TargetID,
+		File:        warning.File,
+		Line:        int32(warning.Line),
+	}
+}
+
+func searchResultToProto(result models.SearchResult) *grimoirev1.SearchResult {
+	return &grimoirev1.SearchResult{Type: result.Type, Id: result.ID, Name: result.Name, Summary: result.Summary}
+}
@@ -0,0 +1,225 @@
+// Package grpc serves units and catalogues over gRPC, for Go tools that would rather consume typed
+// protobufs than JSON. The messages are generated from proto/grimoire/v1/grimoire.proto into
+// pkg/pb/grimoire/v1 (run make proto after changing it) and mirror the models the REST API returns.
+package grpc
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+
+	"grimoire-api/internal/models"
+	"grimoire-api/internal/service"
+	"grimoire-api/internal/snapshot"
+	grimoirev1 "grimoire-api/pkg/pb/grimoire/v1"
+)
+
+// RevisionKey is the metadata that pins a call to a snapshot, like the X-Data-Revision header.
+// Every response sends the revision it was answered from under the same key.
+const RevisionKey = "x-data-revision"
+
+// Search limits, as for GET /api/v1/search
+const (
+	defaultSearchLimit = 50
+	maxSearchLimit     = 200
+)
+
+// NewServer creates a gRPC server for the snapshots of a store, with the reflection service so
+// grpcurl can list and call it without the .proto files
+func NewServer(snapshots *snapshot.Store, options ...grpc.ServerOption) *grpc.Server {
+	server := grpc.NewServer(options...)
+	grimoirev1.RegisterGrimoireServiceServer(server, &grimoireServer{snapshots: snapshots})
+	reflection.Register(server)
+	return server
+}
+
+// grimoireServer implements GrimoireService over the repositories of a snapshot
+type grimoireServer struct {
+	grimoirev1.UnimplementedGrimoireServiceServer
+	snapshots *snapshot.Store
+}
+
+// snapshot picks the snapshot a call reads from: the one named by RevisionKey, or else the current one
+func (s *grimoireServer) snapshot(ctx context.Context) (*snapshot.Snapshot, metadata.MD, error) {
+	snap := s.snapshots.Current()
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if revisions := md.Get(RevisionKey); len(revisions) > 0 && revisions[0] != "" {
+			pinned, found := s.snapshots.Get(revisions[0])
+			if !found {
+				return nil, nil, status.Errorf(codes.FailedPrecondition, "snapshot %s is no longer available; the current revision is %s", revisions[0], snap.Revision)
+			}
+			snap = pinned
+		}
+	}
+	return snap, metadata.Pairs(RevisionKey, snap.Revision), nil
+}
+
+// GetUnit returns a unit by entryLink or selectionEntry ID
+func (s *grimoireServer) GetUnit(ctx context.Context, request *grimoirev1.GetUnitRequest) (*grimoirev1.Unit, error) {
+	if request.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "unit ID is required")
+	}
+	snap, header, err := s.snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	grpc.SetHeader(ctx, header)
+
+	unit, err := getUnit(ctx, snap.Repositories.Units, request.GetId(), request.GetDebug())
+	if err != nil {
+		return nil, toStatus(err, codes.NotFound)
+	}
+	return unitToProto(unit, request.GetDebug()), nil
+}
+
+// ListUnits streams every unit matching the filters, in full
+func (s *grimoireServer) ListUnits(request *grimoirev1.ListUnitsRequest, stream grpc.ServerStreamingServer[grimoirev1.Unit]) error {
+	ctx := stream.Context()
+	query, err := unitQuery(request)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	snap, header, err := s.snapshot(ctx)
+	if err != nil {
+		return err
+	}
+	if err := stream.SendHeader(header); err != nil {
+		return err
+	}
+
+	units := snap.Repositories.Units
+	summaries, _, _, err := units.ListUnitsWithWarnings(ctx, query)
+	if err != nil {
+		return toStatus(err, codes.InvalidArgument)
+	}
+	for _, summary := range summaries {
+		unit, err := getUnit(ctx, units, summary.ID, request.GetDebug())
+		if err != nil {
+			return toStatus(fmt.Errorf("failed to export unit %s: %w", summary.ID, err), codes.Internal)
+		}
+		if err := stream.Send(unitToProto(unit, request.GetDebug())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getUnit reads a unit, with its resolution warnings in debug mode
+func getUnit(ctx context.Context, units service.UnitRepository, id string, debug bool) (*models.UnitResponse, error) {
+	if debug {
+		return units.GetUnitWithWarnings(ctx, id)
+	}
+	return units.GetUnit(ctx, id)
+}
+
+// unitQuery converts the filters of a ListUnits request
+func unitQuery(request *grimoirev1.ListUnitsRequest) (service.UnitQuery, error) {
+	query := service.UnitQuery{
+		Factions:   request.GetFactions(),
+		Categories: request.GetCategories(),
+		Catalogues: request.GetCatalogues(),
+		Search:     request.GetSearch(),
+		MinPoints:  int(request.GetMinPoints()),
+		MaxPoints:  int(request.GetMaxPoints()),
+		Descending: request.GetDescending(),
+		Limit:      int(request.GetLimit()),
+		Offset:     int(request.GetOffset()),
+	}
+	if query.MinPoints < 0 || query.MaxPoints < 0 {
+		return query, fmt.Errorf("points must not be negative")
+	}
+	if query.MaxPoints > 0 && query.MinPoints > query.MaxPoints {
+		return query, fmt.Errorf("min_points must not exceed max_points")
+	}
+	if query.Limit < 0 || query.Offset < 0 {
+		return query, fmt.Errorf("limit and offset must not be negative")
+	}
+
+	switch request.GetLegends() {
+	case grimoirev1.Legends_LEGENDS_UNSPECIFIED, grimoirev1.Legends_LEGENDS_INCLUDE:
+		query.Legends = service.LegendsInclude
+	case grimoirev1.Legends_LEGENDS_EXCLUDE:
+		query.Legends = service.LegendsExclude
+	case grimoirev1.Legends_LEGENDS_ONLY:
+		query.Legends = service.LegendsOnly
+	default:
+		return query, fmt.Errorf("unknown legends filter %d", request.GetLegends())
+	}
+
+	sorts := map[grimoirev1.UnitSort]string{
+		grimoirev1.UnitSort_UNIT_SORT_UNSPECIFIED: service.SortByName,
+		grimoirev1.UnitSort_UNIT_SORT_NAME:        service.SortByName,
+		grimoirev1.UnitSort_UNIT_SORT_POINTS:      service.SortByPoints,
+		grimoirev1.UnitSort_UNIT_SORT_TOUGHNESS:   service.SortByToughness,
+		grimoirev1.UnitSort_UNIT_SORT_WOUNDS:      service.SortByWounds,
+		grimoirev1.UnitSort_UNIT_SORT_OC:          service.SortByOC,
+		grimoirev1.UnitSort_UNIT_SORT_CATALOGUE:   service.SortByCatalogue,
+	}
+	sort, found := sorts[request.GetSort()]
+	if !found {
+		return query, fmt.Errorf("unknown sort %d", request.GetSort())
+	}
+	query.Sort = sort
+	return query, nil
+}
+
+// Search finds units by name
+func (s *grimoireServer) Search(ctx context.Context, request *grimoirev1.SearchRequest) (*grimoirev1.SearchResponse, error) {
+	if request.GetQuery() == "" {
+		return nil, status.Error(codes.InvalidArgument, "search query is required")
+	}
+	limit := int(request.GetLimit())
+	if limit == 0 {
+		limit = defaultSearchLimit
+	}
+	if limit < 1 || limit > maxSearchLimit {
+		return nil, status.Errorf(codes.InvalidArgument, "limit must be between 1 and %d", maxSearchLimit)
+	}
+	snap, header, err := s.snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	grpc.SetHeader(ctx, header)
+
+	results, _, err := snap.Repositories.Units.SearchUnitsWithWarnings(ctx, request.GetQuery(), limit)
+	if err != nil {
+		return nil, toStatus(err, codes.Internal)
+	}
+	return &grimoirev1.SearchResponse{
+		Query:   request.GetQuery(),
+		Results: convert(results, searchResultToProto),
+		Total:   int32(len(results)),
+	}, nil
+}
+
+// GetCatalogue returns a catalogue or library with the units at its root
+func (s *grimoireServer) GetCatalogue(ctx context.Context, request *grimoirev1.GetCatalogueRequest) (*grimoirev1.Catalogue, error) {
+	if request.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "catalogue ID is required")
+	}
+	snap, header, err := s.snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	grpc.SetHeader(ctx, header)
+
+	catalogue, err := snap.Repositories.Catalogues.GetCatalogue(ctx, request.GetId())
+	if err != nil {
+		return nil, toStatus(err, codes.NotFound)
+	}
+	return catalogueToProto(catalogue, request.GetDebug()), nil
+}
+
+// toStatus converts err to a status with code, or to the status of a context error, as the REST
+// handlers answer 504 or 503 for those
+func toStatus(err error, code codes.Code) error {
+	if s := status.FromContextError(err); s.Code() != codes.Unknown {
+		return s.Err()
+	}
+	return status.Error(code, err.Error())
+}
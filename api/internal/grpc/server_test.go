@@ -0,0 +1,221 @@
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"grimoire-api/internal/service"
+	"grimoire-api/internal/snapshot"
+	grimoirev1 "grimoire-api/pkg/pb/grimoire/v1"
+)
+
+const fixtureDir = "../../testdata/wh40k-fixture"
+
+// dial serves the fixture in memory and returns a connection to it
+func dial(t *testing.T) (*grpc.ClientConn, *snapshot.Store) {
+	snapshots := snapshot.NewStore(snapshot.Config{DataDir: fixtureDir, Quiet: true})
+	_, _, err := snapshots.Reload()
+	require.NoError(t, err)
+
+	listener := bufconn.Listen(1 << 20)
+	server := NewServer(snapshots)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///fixture",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn, snapshots
+}
+
+// populated decodes JSON and drops null, false, zero and empty values, which protojson leaves out
+func populated(t *testing.T, data []byte) interface{} {
+	var value interface{}
+	require.NoError(t, json.Unmarshal(data, &value))
+	var prune func(interface{}) interface{}
+	prune = func(value interface{}) interface{} {
+		switch value := value.(type) {
+		case map[string]interface{}:
+			for key, field := range value {
+				if field = prune(field); field == nil {
+					delete(value, key)
+				} else {
+					value[key] = field
+				}
+			}
+			if len(value) == 0 {
+				return nil
+			}
+		case []interface{}:
+			if len(value) == 0 {
+				return nil
+			}
+			for i := range value {
+				value[i] = prune(value[i])
+			}
+		case bool:
+			if !value {
+				return nil
+			}
+		case float64:
+			if value == 0 {
+				return nil
+			}
+		case string:
+			if value == "" {
+				return nil
+			}
+		}
+		return value
+	}
+	return prune(value)
+}
+
+// assertMirrors checks that a message has the same JSON as the model it was converted from
+func assertMirrors(t *testing.T, model interface{}, message proto.Message) {
+	modelJSON, err := json.Marshal(model)
+	require.NoError(t, err)
+	messageJSON, err := protojson.Marshal(message)
+	require.NoError(t, err)
+	assert.Equal(t, populated(t, modelJSON), populated(t, messageJSON))
+}
+
+func TestGetUnit(t *testing.T) {
+	conn, snapshots := dial(t)
+	client := grimoirev1.NewGrimoireServiceClient(conn)
+
+	var header metadata.MD
+	unit, err := client.GetUnit(context.Background(), &grimoirev1.GetUnitRequest{Id: "el-fixture-captain", Debug: true}, grpc.Header(&header))
+	require.NoError(t, err)
+	assert.Equal(t, "el-fixture-captain", unit.GetId())
+	assert.NotEmpty(t, unit.GetWeapons().GetRanged())
+	assert.Equal(t, []string{snapshots.Current().Revision}, header.Get(RevisionKey))
+
+	model, err := snapshots.Current().Repositories.Units.GetUnit(context.Background(), "el-fixture-captain")
+	require.NoError(t, err)
+	assertMirrors(t, model, unit)
+
+	_, err = client.GetUnit(context.Background(), &grimoirev1.GetUnitRequest{Id: "missing"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	_, err = client.GetUnit(context.Background(), &grimoirev1.GetUnitRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// A call pinned to a revision that is no longer retained fails, like a 410 from the REST API
+	pinned := metadata.AppendToOutgoingContext(context.Background(), RevisionKey, "gone")
+	_, err = client.GetUnit(pinned, &grimoirev1.GetUnitRequest{Id: "el-fixture-captain"})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
+
+func TestListUnits(t *testing.T) {
+	conn, snapshots := dial(t)
+	client := grimoirev1.NewGrimoireServiceClient(conn)
+
+	receive := func(request *grimoirev1.ListUnitsRequest) ([]*grimoirev1.Unit, error) {
+		stream, err := client.ListUnits(context.Background(), request)
+		require.NoError(t, err)
+		var units []*grimoirev1.Unit
+		for {
+			unit, err := stream.Recv()
+			if err == io.EOF {
+				return units, nil
+			}
+			if err != nil {
+				return units, err
+			}
+			units = append(units, unit)
+		}
+	}
+
+	units, err := receive(&grimoirev1.ListUnitsRequest{})
+	require.NoError(t, err)
+	summaries, total, _, err := snapshots.Current().Repositories.Units.ListUnitsWithWarnings(context.Background(), service.UnitQuery{})
+	require.NoError(t, err)
+	require.Len(t, units, total)
+	for i, unit := range units {
+		assert.Equal(t, summaries[i].ID, unit.GetId())
+		assert.NotNil(t, unit.GetProfiles(), unit.GetId())
+	}
+
+	sorted, err := receive(&grimoirev1.ListUnitsRequest{
+		Factions:   []string{"fac-fixture-astartes"},
+		Legends:    grimoirev1.Legends_LEGENDS_EXCLUDE,
+		Sort:       grimoirev1.UnitSort_UNIT_SORT_POINTS,
+		Descending: true,
+		Limit:      2,
+	})
+	require.NoError(t, err)
+	require.Len(t, sorted, 2)
+	assert.GreaterOrEqual(t, sorted[0].GetCosts()["pts"], sorted[1].GetCosts()["pts"])
+
+	_, err = receive(&grimoirev1.ListUnitsRequest{MinPoints: 200, MaxPoints: 100})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestSearch(t *testing.T) {
+	conn, _ := dial(t)
+	client := grimoirev1.NewGrimoireServiceClient(conn)
+
+	results, err := client.Search(context.Background(), &grimoirev1.SearchRequest{Query: "fixture", Limit: 3})
+	require.NoError(t, err)
+	assert.Equal(t, "fixture", results.GetQuery())
+	assert.Len(t, results.GetResults(), 3)
+	assert.Equal(t, int32(3), results.GetTotal())
+
+	_, err = client.Search(context.Background(), &grimoirev1.SearchRequest{Query: "fixture", Limit: 1000})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestGetCatalogue(t *testing.T) {
+	conn, snapshots := dial(t)
+	client := grimoirev1.NewGrimoireServiceClient(conn)
+
+	catalogue, err := client.GetCatalogue(context.Background(), &grimoirev1.GetCatalogueRequest{Id: "cat-fixture-marines", Debug: true})
+	require.NoError(t, err)
+	assert.NotEmpty(t, catalogue.GetUnits())
+
+	model, err := snapshots.Current().Repositories.Catalogues.GetCatalogue(context.Background(), "cat-fixture-marines")
+	require.NoError(t, err)
+	assertMirrors(t, model, catalogue)
+
+	// Warnings are only sent for debug requests
+	plain, err := client.GetCatalogue(context.Background(), &grimoirev1.GetCatalogueRequest{Id: "cat-fixture-marines"})
+	require.NoError(t, err)
+	assert.Empty(t, plain.GetWarnings())
+
+	_, err = client.GetCatalogue(context.Background(), &grimoirev1.GetCatalogueRequest{Id: "missing"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestReflection(t *testing.T) {
+	conn, _ := dial(t)
+	stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+	}))
+	response, err := stream.Recv()
+	require.NoError(t, err)
+
+	var services []string
+	for _, s := range response.GetListServicesResponse().GetService() {
+		services = append(services, s.GetName())
+	}
+	assert.Contains(t, services, "grimoire.v1.GrimoireService")
+}
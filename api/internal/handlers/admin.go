@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"grimoire-api/internal/models"
+	"grimoire-api/internal/service"
+	"grimoire-api/internal/snapshot"
+	"grimoire-api/pkg/response"
+)
+
+// AdminHandler handles maintenance HTTP requests about the loaded data
+type AdminHandler struct {
+	snapshots *snapshot.Store
+}
+
+// NewAdminHandler creates a new admin handler
+func NewAdminHandler(snapshots *snapshot.Store) *AdminHandler {
+	return &AdminHandler{snapshots: snapshots}
+}
+
+// GetDataQuality handles GET /api/v1/admin/data-quality
+// Issues can be filtered by rule, severity and catalogue
+func (h *AdminHandler) GetDataQuality(c *gin.Context) {
+	filter := service.DataQualityFilter{
+		Rule:      c.Query("rule"),
+		Severity:  c.Query("severity"),
+		Catalogue: c.Query("catalogue"),
+	}
+	if filter.Severity != "" && filter.Severity != models.SeverityError && filter.Severity != models.SeverityWarning {
+		response.BadRequest(c, "severity must be error or warning")
+		return
+	}
+
+	snap, ok := parsedSnapshot(c, h.snapshots)
+	if !ok {
+		return
+	}
+	response.Success(c, snap.DataQuality.GetReport(filter))
+}
+
+// GetOverlays handles GET /api/v1/admin/overlays
+// Lists the local overlays applied to the snapshot and the patches that no longer match the data
+func (h *AdminHandler) GetOverlays(c *gin.Context) {
+	snap, ok := parsedSnapshot(c, h.snapshots)
+	if !ok {
+		return
+	}
+	response.Success(c, snap.Overlays)
+}
+
+// ListSnapshots handles GET /api/v1/admin/snapshots
+// Lists the snapshots that requests can still be pinned to, newest first
+func (h *AdminHandler) ListSnapshots(c *gin.Context) {
+	response.Success(c, h.snapshots.List())
+}
+
+// GetCacheStats handles GET /api/v1/admin/cache
+// Reports the size of the response cache shared by the snapshots, and its hits, misses and evictions
+func (h *AdminHandler) GetCacheStats(c *gin.Context) {
+	response.Success(c, h.snapshots.Cache().Stats())
+}
+
+// Reload handles POST /api/v1/admin/reload
+// Loads a new snapshot if any data, overlay or homebrew file changed, and returns the current one
+func (h *AdminHandler) Reload(c *gin.Context) {
+	snap, _, err := h.snapshots.Reload()
+	if err != nil {
+		response.InternalServerError(c, err.Error())
+		return
+	}
+
+	c.Header(RevisionHeader, snap.Revision)
+	info := snap.Info()
+	info.Current = true
+	response.Success(c, info)
+}
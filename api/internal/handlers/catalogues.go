@@ -1,19 +1,27 @@
 package handlers
 
 import (
+	"net/http"
+
 	"github.com/gin-gonic/gin"
 	"grimoire-api/internal/service"
+	"grimoire-api/internal/snapshot"
 	"grimoire-api/pkg/response"
 )
 
 // CatalogueHandler handles catalogue-related HTTP requests
 type CatalogueHandler struct {
-	service *service.CatalogueService
+	snapshots *snapshot.Store
 }
 
 // NewCatalogueHandler creates a new catalogue handler
-func NewCatalogueHandler(catalogueService *service.CatalogueService) *CatalogueHandler {
-	return &CatalogueHandler{service: catalogueService}
+func NewCatalogueHandler(snapshots *snapshot.Store) *CatalogueHandler {
+	return &CatalogueHandler{snapshots: snapshots}
+}
+
+// service returns the catalogue repository of the request's snapshot
+func (h *CatalogueHandler) service(c *gin.Context) service.CatalogueRepository {
+	return snapshotFor(c, h.snapshots).Repositories.Catalogues
 }
 
 // GetCatalogue handles GET /api/v1/catalogues/:id
@@ -24,20 +32,20 @@ func (h *CatalogueHandler) GetCatalogue(c *gin.Context) {
 		return
 	}
 
-	catalogue, err := h.service.GetCatalogue(id)
+	catalogue, err := h.service(c).GetCatalogue(c.Request.Context(), id)
 	if err != nil {
-		response.NotFound(c, err.Error())
+		fail(c, err, http.StatusNotFound)
 		return
 	}
 
-	response.Success(c, catalogue)
+	response.Success(c, catalogueForRequest(c, catalogue))
 }
 
 // ListCatalogues handles GET /api/v1/catalogues
 func (h *CatalogueHandler) ListCatalogues(c *gin.Context) {
-	catalogues, err := h.service.ListCatalogues()
+	catalogues, err := h.service(c).ListCatalogues(c.Request.Context())
 	if err != nil {
-		response.InternalServerError(c, err.Error())
+		fail(c, err, http.StatusInternalServerError)
 		return
 	}
 
@@ -52,13 +60,37 @@ func (h *CatalogueHandler) GetCatalogueUnits(c *gin.Context) {
 		return
 	}
 
-	units, err := h.service.GetCatalogueUnits(id)
+	units, warnings, err := h.service(c).GetCatalogueUnitsWithWarnings(c.Request.Context(), id)
 	if err != nil {
-		response.NotFound(c, err.Error())
+		fail(c, err, http.StatusNotFound)
 		return
 	}
 
-	response.Success(c, units)
+	response.SuccessWithWarnings(c, units, warningsForRequest(c, warnings))
 }
 
+// GetCatalogueGraph handles GET /api/v1/catalogues/graph
+// format selects json (default), dot or mermaid; focus limits the graph to one catalogue's
+// imports and dependents
+func (h *CatalogueHandler) GetCatalogueGraph(c *gin.Context) {
+	snap, ok := parsedSnapshot(c, h.snapshots)
+	if !ok {
+		return
+	}
+	graph, err := snap.Catalogues.GetCatalogueGraph(c.Request.Context(), c.Query("focus"))
+	if err != nil {
+		fail(c, err, http.StatusNotFound)
+		return
+	}
 
+	switch c.DefaultQuery("format", "json") {
+	case "json":
+		response.Success(c, graph)
+	case "dot":
+		c.Data(http.StatusOK, "text/vnd.graphviz; charset=utf-8", []byte(service.RenderCatalogueGraphDOT(graph)))
+	case "mermaid":
+		c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(service.RenderCatalogueGraphMermaid(graph)))
+	default:
+		response.BadRequest(c, "format must be json, dot or mermaid")
+	}
+}
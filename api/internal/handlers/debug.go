@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"grimoire-api/internal/models"
+)
+
+// debugMode reports whether the request asked for resolution warnings with ?debug=true
+func debugMode(c *gin.Context) bool {
+	return c.Query("debug") == "true"
+}
+
+// unitForRequest drops the unit's warnings unless the request is in debug mode.
+// The unit may be shared with the cache, so it is copied rather than modified.
+func unitForRequest(c *gin.Context, unit *models.UnitResponse) *models.UnitResponse {
+	if debugMode(c) || len(unit.Warnings) == 0 {
+		return unit
+	}
+	stripped := *unit
+	stripped.Warnings = nil
+	return &stripped
+}
+
+// catalogueForRequest drops the catalogue's warnings unless the request is in debug mode
+func catalogueForRequest(c *gin.Context, catalogue *models.CatalogueResponse) *models.CatalogueResponse {
+	if debugMode(c) || len(catalogue.Warnings) == 0 {
+		return catalogue
+	}
+	stripped := *catalogue
+	stripped.Warnings = nil
+	return &stripped
+}
+
+// warningsForRequest returns warnings in debug mode and nothing otherwise
+func warningsForRequest(c *gin.Context, warnings []models.ResolutionWarning) []models.ResolutionWarning {
+	if !debugMode(c) {
+		return nil
+	}
+	return warnings
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"grimoire-api/internal/service"
+	"grimoire-api/internal/snapshot"
+	"grimoire-api/pkg/response"
+)
+
+// DiffHandler handles data revision diff HTTP requests
+type DiffHandler struct {
+	snapshots *snapshot.Store
+}
+
+// NewDiffHandler creates a new diff handler
+func NewDiffHandler(snapshots *snapshot.Store) *DiffHandler {
+	return &DiffHandler{snapshots: snapshots}
+}
+
+// GetDiff handles GET /api/v1/diff?from=&to=
+// from and to are git revisions of the data repository; to defaults to the loaded data
+func (h *DiffHandler) GetDiff(c *gin.Context) {
+	from := c.Query("from")
+	if from == "" {
+		response.BadRequest(c, "from revision is required")
+		return
+	}
+	to := c.DefaultQuery("to", service.CurrentRevision)
+
+	snap, ok := parsedSnapshot(c, h.snapshots)
+	if !ok {
+		return
+	}
+	result, err := snap.Diffs.Diff(c.Request.Context(), from, to)
+	if err != nil {
+		fail(c, err, http.StatusBadRequest)
+		return
+	}
+
+	response.Success(c, result)
+}
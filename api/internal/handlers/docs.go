@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"grimoire-api/internal/openapi"
+)
+
+// DocsHandler serves the OpenAPI document and the docs page rendering it
+type DocsHandler struct {
+	spec []byte
+}
+
+// NewDocsHandler creates a new docs handler serving document
+func NewDocsHandler(document *openapi.Document) (*DocsHandler, error) {
+	spec, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return &DocsHandler{spec: spec}, nil
+}
+
+// GetSpec handles GET /api/v1/openapi.json
+func (h *DocsHandler) GetSpec(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json; charset=utf-8", h.spec)
+}
+
+// GetDocs handles GET /api/v1/docs
+func (h *DocsHandler) GetDocs(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", openapi.DocsPage)
+}
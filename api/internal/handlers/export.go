@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"grimoire-api/internal/export"
+	"grimoire-api/internal/snapshot"
+	"grimoire-api/pkg/response"
+)
+
+// ExportHandler handles dataset export HTTP requests
+type ExportHandler struct {
+	snapshots *snapshot.Store
+	files     *export.Files
+}
+
+// NewExportHandler creates a new export handler serving the exports written to files
+func NewExportHandler(snapshots *snapshot.Store, files *export.Files) *ExportHandler {
+	return &ExportHandler{snapshots: snapshots, files: files}
+}
+
+// GetExport handles GET /api/v1/export/:format
+// Sends every unit of the snapshot as normalized tables: a zip of CSV or NDJSON files, or a SQLite
+// database, each with a manifest of the data revisions. An export is written once per snapshot
+// and format, and only sent once it is complete.
+func (h *ExportHandler) GetExport(c *gin.Context) {
+	format := c.Param("format")
+	if !export.ValidFormat(format) {
+		response.BadRequest(c, "format must be one of "+strings.Join(export.Formats, ", "))
+		return
+	}
+
+	// Exports of the snapshots that are no longer retained can't be asked for again
+	var revisions []string
+	for _, info := range h.snapshots.List() {
+		revisions = append(revisions, info.Revision)
+	}
+	h.files.Retain(revisions)
+
+	snap := snapshotFor(c, h.snapshots)
+	file, err := h.files.Open(c.Request.Context(), snap.Revision, format, snap.Repositories)
+	if err != nil {
+		fail(c, err, http.StatusInternalServerError)
+		return
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		fail(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.Header("Content-Type", export.ContentType(format))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", export.FileName(format, snap.Revision)))
+	http.ServeContent(c.Writer, c.Request, "", info.ModTime(), file)
+}
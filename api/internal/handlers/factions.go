@@ -1,58 +1,61 @@
 package handlers
 
 import (
-	"strings"
+	"net/http"
 
 	"github.com/gin-gonic/gin"
+
 	"grimoire-api/internal/service"
+	"grimoire-api/internal/snapshot"
 	"grimoire-api/pkg/response"
 )
 
 // FactionHandler handles faction-related HTTP requests
 type FactionHandler struct {
-	unitService      *service.UnitService
-	catalogueService *service.CatalogueService
+	snapshots *snapshot.Store
 }
 
 // NewFactionHandler creates a new faction handler
-func NewFactionHandler(unitService *service.UnitService, catalogueService *service.CatalogueService) *FactionHandler {
-	return &FactionHandler{
-		unitService:      unitService,
-		catalogueService: catalogueService,
-	}
+func NewFactionHandler(snapshots *snapshot.Store) *FactionHandler {
+	return &FactionHandler{snapshots: snapshots}
+}
+
+// service returns the faction repository of the request's snapshot
+func (h *FactionHandler) service(c *gin.Context) service.FactionRepository {
+	return snapshotFor(c, h.snapshots).Repositories.Factions
 }
 
 // ListFactions handles GET /api/v1/factions
 func (h *FactionHandler) ListFactions(c *gin.Context) {
-	catalogues, err := h.catalogueService.ListCatalogues()
+	factions, err := h.service(c).ListFactions(c.Request.Context())
 	if err != nil {
-		response.InternalServerError(c, err.Error())
+		fail(c, err, http.StatusInternalServerError)
 		return
 	}
 
-	// Group catalogues by faction
-	factionMap := make(map[string][]string)
-	for _, cat := range catalogues {
-		// Extract faction from catalogue name
-		faction := extractFactionFromName(cat.Name)
-		if faction != "" {
-			factionMap[faction] = append(factionMap[faction], cat.Name)
-		}
+	response.Success(c, factions)
+}
+
+// GetFaction handles GET /api/v1/factions/:name
+// The name can be a faction ID, name, keyword or catalogue
+func (h *FactionHandler) GetFaction(c *gin.Context) {
+	name := c.Param("name")
+	if name == "" {
+		response.BadRequest(c, "faction name is required")
+		return
 	}
 
-	// Convert to response format
-	factions := make([]map[string]interface{}, 0, len(factionMap))
-	for faction, catalogues := range factionMap {
-		factions = append(factions, map[string]interface{}{
-			"name":       faction,
-			"catalogues": catalogues,
-		})
+	faction, err := h.service(c).GetFaction(c.Request.Context(), name)
+	if err != nil {
+		fail(c, err, http.StatusNotFound)
+		return
 	}
 
-	response.Success(c, factions)
+	response.Success(c, faction)
 }
 
 // GetFactionUnits handles GET /api/v1/factions/:name/units
+// A super-faction name such as "Imperium" returns the units of all its factions
 func (h *FactionHandler) GetFactionUnits(c *gin.Context) {
 	factionName := c.Param("name")
 	if factionName == "" {
@@ -60,29 +63,24 @@ func (h *FactionHandler) GetFactionUnits(c *gin.Context) {
 		return
 	}
 
-	units, _, err := h.unitService.ListUnits(factionName, "", "", 1000, 0)
+	repositories := snapshotFor(c, h.snapshots).Repositories
+	factions, err := repositories.Factions.FindFactions(c.Request.Context(), factionName)
 	if err != nil {
-		response.InternalServerError(c, err.Error())
+		fail(c, err, http.StatusInternalServerError)
 		return
 	}
-
-	response.Success(c, units)
-}
-
-// extractFactionFromName extracts faction name from catalogue name
-func extractFactionFromName(name string) string {
-	// Remove prefixes like "Imperium - ", "Chaos - ", "Aeldari - "
-	parts := strings.Split(name, " - ")
-	if len(parts) > 1 {
-		return parts[0]
-	}
-	
-	// For simple names like "Necrons.cat", "Orks.cat"
-	if strings.Contains(name, " ") {
-		return strings.Split(name, " ")[0]
+	if len(factions) == 0 {
+		response.NotFound(c, "faction not found: "+factionName)
+		return
 	}
-	
-	return name
-}
 
+	units, _, warnings, err := repositories.Units.ListUnitsWithWarnings(c.Request.Context(), service.UnitQuery{
+		Factions: []string{factionName},
+	})
+	if err != nil {
+		fail(c, err, http.StatusInternalServerError)
+		return
+	}
 
+	response.SuccessWithWarnings(c, units, warningsForRequest(c, warnings))
+}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"grimoire-api/internal/graphql"
+	"grimoire-api/internal/snapshot"
+)
+
+// GraphQLHandler handles GraphQL requests
+type GraphQLHandler struct {
+	snapshots *snapshot.Store
+	schema    *graphql.Schema
+}
+
+// NewGraphQLHandler creates a new GraphQL handler
+func NewGraphQLHandler(snapshots *snapshot.Store, schema *graphql.Schema) *GraphQLHandler {
+	return &GraphQLHandler{snapshots: snapshots, schema: schema}
+}
+
+// Query handles GET and POST /api/v1/graphql
+// A POST sends the request as JSON; a GET sends query, operationName and variables (as JSON) in
+// the query string. Requests that fail to parse, validate or fit the limits answer 400.
+func (h *GraphQLHandler) Query(c *gin.Context) {
+	var request graphql.Request
+	if c.Request.Method == http.MethodPost {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			graphQLError(c, "invalid request body: "+err.Error())
+			return
+		}
+	} else {
+		request.Query = c.Query("query")
+		request.OperationName = c.Query("operationName")
+		if variables := c.Query("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &request.Variables); err != nil {
+				graphQLError(c, "variables must be a JSON object: "+err.Error())
+				return
+			}
+		}
+	}
+	if request.Query == "" {
+		graphQLError(c, "query is required")
+		return
+	}
+
+	result, ok := h.schema.Execute(c.Request.Context(), snapshotFor(c, h.snapshots).Repositories, request)
+	status := http.StatusOK
+	if !ok {
+		status = http.StatusBadRequest
+	}
+	c.JSON(status, result)
+}
+
+// graphQLError answers 400 with a GraphQL error, the shape GraphQL clients expect
+func graphQLError(c *gin.Context, message string) {
+	c.JSON(http.StatusBadRequest, graphql.Response{Errors: []graphql.Error{{Message: message}}})
+}
@@ -1,63 +1,97 @@
-package handlers
+package handlers_test
 
 import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 
-	"grimoire-api/internal/cache"
+	"grimoire-api/internal/export"
+	"grimoire-api/internal/handlers"
+	"grimoire-api/internal/homebrew"
+	"grimoire-api/internal/openapi"
 	"grimoire-api/internal/parser"
+	"grimoire-api/internal/server"
 	"grimoire-api/internal/service"
+	"grimoire-api/internal/snapshot"
 )
 
 func setupTestRouter(t *testing.T) *gin.Engine {
+	return newTestRouter(t, getTestDataDir(t))
+}
+
+// setupFixtureRouter builds a router over the small checked-in fixture dataset
+func setupFixtureRouter(t *testing.T) *gin.Engine {
+	return newTestRouter(t, "../../testdata/wh40k-fixture")
+}
+
+func newTestRouter(t *testing.T, dataDir string) *gin.Engine {
+	return newOverlayTestRouter(t, dataDir, "")
+}
+
+// newOverlayTestRouter builds a router with the overlays in overlayDir applied, if overlayDir is set
+func newOverlayTestRouter(t *testing.T, dataDir, overlayDir string) *gin.Engine {
+	return newHomebrewTestRouter(t, dataDir, overlayDir, t.TempDir())
+}
+
+// testToken is the homebrew upload and admin token of the test routers
+const testToken = "test-token"
+
+// newHomebrewTestRouter builds a router saving homebrew uploads to homebrewDir
+func newHomebrewTestRouter(t *testing.T, dataDir, overlayDir, homebrewDir string) *gin.Engine {
 	gin.SetMode(gin.TestMode)
 
-	dataDir := getTestDataDir(t)
-	
-	p := parser.NewParser(dataDir)
-	if err := p.LoadGameSystem(); err != nil {
-		t.Fatalf("Failed to load game system: %v", err)
-	}
-	if err := p.LoadAllCatalogues(); err != nil {
-		t.Fatalf("Failed to load catalogues: %v", err)
-	}
+	homebrewStore := homebrew.NewStore(homebrewDir)
+	snapshots := snapshot.NewStore(snapshot.Config{
+		DataDir:    dataDir,
+		OverlayDir: overlayDir,
+		Homebrew:   homebrewStore,
+		Quiet:      true,
+	})
+	return newStoreTestRouter(t, snapshots, homebrewStore, dataDir)
+}
 
-	resolver := parser.NewLinkResolver(p)
-	transformer := parser.NewTransformer(resolver)
-	cache := cache.NewCache()
-
-	unitService := service.NewUnitService(p, resolver, transformer, cache)
-	catalogueService := service.NewCatalogueService(p, resolver, transformer, cache)
-
-	unitHandler := NewUnitHandler(unitService)
-	catalogueHandler := NewCatalogueHandler(catalogueService)
-	factionHandler := NewFactionHandler(unitService, catalogueService)
-	searchHandler := NewSearchHandler(unitService)
-	gameSystemHandler := NewGameSystemHandler(p)
-
-	router := gin.New()
-	v1 := router.Group("/api/v1")
-	{
-		v1.GET("/game-system", gameSystemHandler.GetGameSystem)
-		v1.GET("/catalogues", catalogueHandler.ListCatalogues)
-		v1.GET("/catalogues/:id", catalogueHandler.GetCatalogue)
-		v1.GET("/catalogues/:id/units", catalogueHandler.GetCatalogueUnits)
-		v1.GET("/units", unitHandler.ListUnits)
-		v1.GET("/units/:id", unitHandler.GetUnit)
-		v1.GET("/units/:id/weapons", unitHandler.GetUnitWeapons)
-		v1.GET("/factions", factionHandler.ListFactions)
-		v1.GET("/factions/:name/units", factionHandler.GetFactionUnits)
-		v1.GET("/search", searchHandler.Search)
+// newStoreTestRouter builds a router serving the snapshots of a store
+func newStoreTestRouter(t *testing.T, snapshots *snapshot.Store, homebrewStore *homebrew.Store, dataDir string) *gin.Engine {
+	if _, _, err := snapshots.Reload(); err != nil {
+		t.Fatalf("Failed to load data: %v", err)
+	}
+	historyService := service.NewHistoryService(dataDir, filepath.Join(t.TempDir(), "history.json.gz"))
+
+	router, err := server.NewRouter(server.Config{
+		Snapshots:     snapshots,
+		History:       historyService,
+		Homebrew:      homebrewStore,
+		Exports:       export.NewFiles(t.TempDir(), 0),
+		HomebrewToken: testToken,
+		AdminToken:    testToken,
+		Quiet:         true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to set up routes: %v", err)
 	}
-
 	return router
 }
 
+// adminRequest builds a request carrying the admin token of the test routers
+func adminRequest(method, path string) *http.Request {
+	req := httptest.NewRequest(method, path, nil)
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	return req
+}
+
 func TestGetUnitHandler(t *testing.T) {
 	router := setupTestRouter(t)
 
@@ -100,7 +134,7 @@ func TestGetCatalogueHandler(t *testing.T) {
 	p := parser.NewParser(dataDir)
 	p.LoadGameSystem()
 	p.LoadAllCatalogues()
-	
+
 	catalogues := p.GetAllCatalogues()
 	if len(catalogues) == 0 {
 		t.Skip("No catalogues available")
@@ -181,7 +215,7 @@ func TestGetCatalogueUnitsHandler(t *testing.T) {
 	p := parser.NewParser(dataDir)
 	p.LoadGameSystem()
 	p.LoadAllCatalogues()
-	
+
 	catalogues := p.GetAllCatalogues()
 	if len(catalogues) == 0 {
 		t.Skip("No catalogues available")
@@ -316,3 +350,534 @@ func getTestDataDir(t *testing.T) string {
 	return dataDir
 }
 
+func TestGetCatalogueGraphHandler(t *testing.T) {
+	router := setupFixtureRouter(t)
+
+	req := httptest.NewRequest("GET", "/api/v1/catalogues/graph", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "transitiveImports")
+
+	req2 := httptest.NewRequest("GET", "/api/v1/catalogues/graph?format=dot&focus=lib-fixture-astartes", nil)
+	w2 := httptest.NewRecorder()
+	router.ServeHTTP(w2, req2)
+
+	assert.Equal(t, http.StatusOK, w2.Code)
+	assert.Contains(t, w2.Body.String(), "digraph catalogues")
+	assert.NotContains(t, w2.Body.String(), "cat-fixture-daemons")
+
+	req3 := httptest.NewRequest("GET", "/api/v1/catalogues/graph?format=svg", nil)
+	w3 := httptest.NewRecorder()
+	router.ServeHTTP(w3, req3)
+
+	assert.Equal(t, http.StatusBadRequest, w3.Code)
+}
+
+func TestGetUnitHistoryHandlerNotReady(t *testing.T) {
+	router := setupFixtureRouter(t)
+
+	// The fixture directory is not a git repository, so no history index is ever built
+	req := httptest.NewRequest("GET", "/api/v1/units/el-fixture-captain/history", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+func TestGetDataQualityHandler(t *testing.T) {
+	router := setupFixtureRouter(t)
+
+	req := adminRequest("GET", "/api/v1/admin/data-quality")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "el-fixture-broken")
+	assert.Contains(t, w.Body.String(), "rule-fixture-oath")
+
+	req2 := adminRequest("GET", "/api/v1/admin/data-quality?severity=error")
+	w2 := httptest.NewRecorder()
+	router.ServeHTTP(w2, req2)
+
+	assert.Equal(t, http.StatusOK, w2.Code)
+	assert.Contains(t, w2.Body.String(), "el-fixture-broken")
+	assert.NotContains(t, w2.Body.String(), "\"targetId\":\"rule-fixture-oath\"")
+
+	req3 := adminRequest("GET", "/api/v1/admin/data-quality?severity=fatal")
+	w3 := httptest.NewRecorder()
+	router.ServeHTTP(w3, req3)
+
+	assert.Equal(t, http.StatusBadRequest, w3.Code)
+
+	// Every admin route needs the admin token
+	for _, path := range []string{"/api/v1/admin/data-quality", "/api/v1/admin/overlays", "/api/v1/admin/snapshots", "/api/v1/admin/cache"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		assert.Equal(t, http.StatusUnauthorized, w.Code, path)
+	}
+}
+
+func TestResolutionWarningsDebugMode(t *testing.T) {
+	router := setupFixtureRouter(t)
+
+	paths := map[string]string{
+		"/api/v1/catalogues/cat-fixture-marines":       "/api/v1/catalogues/cat-fixture-marines?debug=true",
+		"/api/v1/catalogues/cat-fixture-marines/units": "/api/v1/catalogues/cat-fixture-marines/units?debug=true",
+		"/api/v1/units":            "/api/v1/units?debug=true",
+		"/api/v1/search?q=fixture": "/api/v1/search?q=fixture&debug=true",
+	}
+	for path, debugPath := range paths {
+		req := httptest.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code, path)
+		assert.NotContains(t, w.Body.String(), "\"warnings\"", path)
+
+		req2 := httptest.NewRequest("GET", debugPath, nil)
+		w2 := httptest.NewRecorder()
+		router.ServeHTTP(w2, req2)
+
+		assert.Equal(t, http.StatusOK, w2.Code, debugPath)
+		assert.Contains(t, w2.Body.String(), "\"warnings\"", debugPath)
+		assert.Contains(t, w2.Body.String(), "se-fixture-does-not-exist", debugPath)
+	}
+
+	// Search only reports the skipped links whose names match the query
+	req := httptest.NewRequest("GET", "/api/v1/search?q=captain&debug=true", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "se-fixture-does-not-exist")
+}
+
+func TestExplainUnitHandler(t *testing.T) {
+	router := setupFixtureRouter(t)
+
+	req := httptest.NewRequest("GET", "/api/v1/units/el-fixture-captain/explain", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "\"file\":\"Library - Fixture Astartes.cat\"")
+	assert.Contains(t, w.Body.String(), "el-cap-bolter")
+
+	req2 := httptest.NewRequest("GET", "/api/v1/units/el-fixture-broken/explain", nil)
+	w2 := httptest.NewRecorder()
+	router.ServeHTTP(w2, req2)
+
+	assert.Equal(t, http.StatusNotFound, w2.Code)
+}
+
+func TestOverlaysHandler(t *testing.T) {
+	overlayDir := t.TempDir()
+	overlay := `{"patches": [{"id": "el-fixture-captain", "costs": {"pts": 90}}, {"id": "se-fixture-removed", "name": "Removed"}]}`
+	if err := os.WriteFile(filepath.Join(overlayDir, "errata.json"), []byte(overlay), 0o644); err != nil {
+		t.Fatalf("Failed to write overlay: %v", err)
+	}
+	router := newOverlayTestRouter(t, "../../testdata/wh40k-fixture", overlayDir)
+
+	req := httptest.NewRequest("GET", "/api/v1/units/el-fixture-captain", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "\"pts\":90")
+	assert.Contains(t, w.Body.String(), "\"overlays\":[{\"overlay\":\"errata\",\"targetId\":\"el-fixture-captain\",\"field\":\"costs.pts\",\"value\":\"90\"}]")
+
+	req2 := adminRequest("GET", "/api/v1/admin/overlays")
+	w2 := httptest.NewRecorder()
+	router.ServeHTTP(w2, req2)
+
+	assert.Equal(t, http.StatusOK, w2.Code)
+	assert.Contains(t, w2.Body.String(), "\"applied\":1")
+	assert.Contains(t, w2.Body.String(), "se-fixture-removed")
+
+	// Without overlays units carry no marks
+	plain := setupFixtureRouter(t)
+	req3 := httptest.NewRequest("GET", "/api/v1/units/el-fixture-captain", nil)
+	w3 := httptest.NewRecorder()
+	plain.ServeHTTP(w3, req3)
+
+	assert.NotContains(t, w3.Body.String(), "\"overlays\"")
+}
+
+// homebrewCatalogue is a small uploaded catalogue whose unit reuses an official ID
+const homebrewCatalogue = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<catalogue id="cat-campaign" name="Homebrew - Campaign" revision="1" library="false" gameSystemId="sys-352e-adc2-7639" type="catalogue" xmlns="http://www.battlescribe.net/schema/catalogueSchema">
+  <sharedSelectionEntries>
+    <selectionEntry id="se-fixture-captain" name="Campaign Captain" hidden="false" type="model">
+      <costs>
+        <cost name="pts" typeId="51b2-306e-1021-d207" value="95"/>
+      </costs>
+    </selectionEntry>
+  </sharedSelectionEntries>
+  <entryLinks>
+    <entryLink id="el-campaign-captain" name="Campaign Captain" hidden="false" targetId="se-fixture-captain" type="selectionEntry"/>
+  </entryLinks>
+</catalogue>`
+
+func uploadRequest(t *testing.T, filename, content, token string) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", filename)
+	if err != nil {
+		t.Fatalf("Failed to create form file: %v", err)
+	}
+	part.Write([]byte(content))
+	form.Close()
+
+	req := httptest.NewRequest("POST", "/api/v1/catalogues", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return req
+}
+
+func TestHomebrewCatalogueHandlers(t *testing.T) {
+	homebrewDir := t.TempDir()
+	router := newHomebrewTestRouter(t, "../../testdata/wh40k-fixture", "", homebrewDir)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, uploadRequest(t, "campaign.cat", homebrewCatalogue, "wrong-token"))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w2 := httptest.NewRecorder()
+	router.ServeHTTP(w2, uploadRequest(t, "campaign.cat", `<catalogue id="x" gameSystemId="sys-other"/>`, testToken))
+	assert.Equal(t, http.StatusUnprocessableEntity, w2.Code)
+
+	w3 := httptest.NewRecorder()
+	router.ServeHTTP(w3, uploadRequest(t, "campaign.cat", homebrewCatalogue, testToken))
+	assert.Equal(t, http.StatusCreated, w3.Code)
+	assert.Contains(t, w3.Body.String(), "\"id\":\"homebrew:cat-campaign\"")
+
+	// The upload is served alongside the official data without replacing the official captain
+	req4 := httptest.NewRequest("GET", "/api/v1/units/homebrew:cat-campaign:el-campaign-captain", nil)
+	w4 := httptest.NewRecorder()
+	router.ServeHTTP(w4, req4)
+	assert.Equal(t, http.StatusOK, w4.Code)
+	assert.Contains(t, w4.Body.String(), "Campaign Captain")
+
+	req5 := httptest.NewRequest("GET", "/api/v1/units/el-fixture-captain", nil)
+	w5 := httptest.NewRecorder()
+	router.ServeHTTP(w5, req5)
+	assert.Equal(t, http.StatusOK, w5.Code)
+	assert.NotContains(t, w5.Body.String(), "Campaign Captain")
+
+	// Uploads are loaded again on restart
+	restarted := newHomebrewTestRouter(t, "../../testdata/wh40k-fixture", "", homebrewDir)
+	req6 := httptest.NewRequest("GET", "/api/v1/catalogues", nil)
+	w6 := httptest.NewRecorder()
+	restarted.ServeHTTP(w6, req6)
+	assert.Contains(t, w6.Body.String(), "\"homebrew\":true")
+
+	for _, id := range []string{"homebrew:cat-campaign", "cat-fixture-marines"} {
+		req := httptest.NewRequest("DELETE", "/api/v1/catalogues/"+id, nil)
+		req.Header.Set("Authorization", "Bearer "+testToken)
+		w := httptest.NewRecorder()
+		restarted.ServeHTTP(w, req)
+		if id == "cat-fixture-marines" {
+			assert.Equal(t, http.StatusNotFound, w.Code, id)
+		} else {
+			assert.Equal(t, http.StatusNoContent, w.Code, id)
+		}
+	}
+	req7 := httptest.NewRequest("GET", "/api/v1/units/homebrew:cat-campaign:el-campaign-captain", nil)
+	w7 := httptest.NewRecorder()
+	restarted.ServeHTTP(w7, req7)
+	assert.Equal(t, http.StatusNotFound, w7.Code)
+}
+
+func TestSnapshotPinning(t *testing.T) {
+	overlayDir := t.TempDir()
+	router := newOverlayTestRouter(t, "../../testdata/wh40k-fixture", overlayDir)
+
+	get := func(path string, header string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		if header != "" {
+			req.Header.Set(handlers.RevisionHeader, header)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("/api/v1/units/el-fixture-captain", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "\"pts\":80")
+	original := w.Header().Get(handlers.RevisionHeader)
+	assert.NotEmpty(t, original)
+
+	// Reload with an overlay changing the captain's points
+	overlay := `{"patches": [{"id": "el-fixture-captain", "costs": {"pts": 90}}]}`
+	if err := os.WriteFile(filepath.Join(overlayDir, "errata.json"), []byte(overlay), 0o644); err != nil {
+		t.Fatalf("Failed to write overlay: %v", err)
+	}
+	req := httptest.NewRequest("POST", "/api/v1/admin/reload", nil)
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	w2 := httptest.NewRecorder()
+	router.ServeHTTP(w2, req)
+	assert.Equal(t, http.StatusOK, w2.Code)
+	reloaded := w2.Header().Get(handlers.RevisionHeader)
+	assert.NotEqual(t, original, reloaded)
+
+	w3 := get("/api/v1/units/el-fixture-captain", "")
+	assert.Contains(t, w3.Body.String(), "\"pts\":90")
+	assert.Equal(t, reloaded, w3.Header().Get(handlers.RevisionHeader))
+
+	// Pinned requests still read the snapshot they started with
+	w4 := get("/api/v1/units/el-fixture-captain?snapshot="+original, "")
+	assert.Contains(t, w4.Body.String(), "\"pts\":80")
+	assert.Equal(t, original, w4.Header().Get(handlers.RevisionHeader))
+
+	w5 := get("/api/v1/units/el-fixture-captain", original)
+	assert.Contains(t, w5.Body.String(), "\"pts\":80")
+
+	w6 := get("/api/v1/units?snapshot=unknown", "")
+	assert.Equal(t, http.StatusGone, w6.Code)
+
+	w7 := httptest.NewRecorder()
+	router.ServeHTTP(w7, adminRequest("GET", "/api/v1/admin/snapshots"))
+	assert.Contains(t, w7.Body.String(), original)
+	assert.Contains(t, w7.Body.String(), reloaded)
+}
+
+func TestCacheStatsHandler(t *testing.T) {
+	router := setupFixtureRouter(t)
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w
+	}
+
+	// A miss then a hit for the unit, and one cached list shared by both pages
+	get("/api/v1/units/el-fixture-captain")
+	get("/api/v1/units/el-fixture-captain")
+	get("/api/v1/units?limit=1")
+	get("/api/v1/units?limit=1&offset=1")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, adminRequest("GET", "/api/v1/admin/cache"))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	assert.Contains(t, w.Body.String(), `{"kind":"unit","entries":1,"hits":1,"misses":1}`)
+	assert.Contains(t, w.Body.String(), `{"kind":"unitList","entries":1,"hits":1,"misses":1}`)
+	assert.Contains(t, w.Body.String(), `"evictions":0`)
+}
+
+func TestRequestTimeout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	snapshots := snapshot.NewStore(snapshot.Config{DataDir: "../../testdata/wh40k-fixture", Quiet: true})
+	if _, _, err := snapshots.Reload(); err != nil {
+		t.Fatalf("Failed to load data: %v", err)
+	}
+	expired, err := server.NewRouter(server.Config{Snapshots: snapshots, SlowRequestTimeout: time.Nanosecond, Quiet: true})
+	assert.NoError(t, err)
+	router, err := server.NewRouter(server.Config{Snapshots: snapshots, Quiet: true})
+	assert.NoError(t, err)
+
+	// Past the deadline the list isn't built and the client is told why
+	w := httptest.NewRecorder()
+	expired.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/units", nil))
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+	assert.Contains(t, w.Body.String(), "the request did not finish within 1ns")
+
+	// A client that goes away cancels the work
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/units", nil).WithContext(ctx))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	// Nothing failed is cached, so the same list is served within the deadline
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/units", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "el-fixture-captain")
+}
+
+func TestRulesHandlers(t *testing.T) {
+	router := setupFixtureRouter(t)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/rules", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Deep Strike")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/rules/rule-fixture-deep-strike", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "rule-fixture-deep-strike")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/rules/missing", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestDatabaseRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	dataDir := "../../testdata/wh40k-fixture"
+	database := filepath.Join(t.TempDir(), "grimoire.db")
+	if _, err := snapshot.Import(context.Background(), snapshot.Config{DataDir: dataDir, Quiet: true}, database); err != nil {
+		t.Fatalf("Failed to import data: %v", err)
+	}
+	homebrewStore := homebrew.NewStore(t.TempDir())
+	snapshots := snapshot.NewStore(snapshot.Config{DataDir: dataDir, Homebrew: homebrewStore, Quiet: true, Database: database})
+	router := newStoreTestRouter(t, snapshots, homebrewStore, dataDir)
+
+	// The repositories are served from the database
+	for path, want := range map[string]string{
+		"/api/v1/units/el-fixture-captain":         "Fixture Captain",
+		"/api/v1/units?faction=Adeptus%20Astartes": "el-fixture-land-raider",
+		"/api/v1/catalogues/cat-fixture-marines":   "Imperium - Fixture Marines",
+		"/api/v1/factions/imperium/units":          "el-fixture-sanguinary-guard",
+		"/api/v1/search?q=blood":                   "el-fixture-bloodthirster",
+		"/api/v1/rules":                            "Deep Strike",
+		"/api/v1/game-system":                      "costTypes",
+	} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		assert.Equal(t, http.StatusOK, w.Code, path)
+		assert.Contains(t, w.Body.String(), want, path)
+	}
+
+	// Routes that need the parsed XML say so
+	for _, path := range []string{"/api/v1/units/el-fixture-captain/explain", "/api/v1/catalogues/graph", "/api/v1/admin/data-quality", "/api/v1/diff?from=HEAD"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, adminRequest("GET", path))
+		assert.Equal(t, http.StatusNotImplemented, w.Code, path)
+	}
+}
+
+func TestExportHandler(t *testing.T) {
+	router := setupFixtureRouter(t)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/export/csv", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/zip", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Header().Get("Content-Disposition"), "-csv.zip")
+	assert.Equal(t, strconv.Itoa(w.Body.Len()), w.Header().Get("Content-Length"))
+	archive, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+	assert.NoError(t, err)
+	assert.Equal(t, "manifest.json", archive.File[0].Name)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/export/sqlite", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, bytes.HasPrefix(w.Body.Bytes(), []byte("SQLite format 3")))
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/export/xlsx", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGraphQLHandler(t *testing.T) {
+	router := setupFixtureRouter(t)
+
+	post := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api/v1/graphql", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := post(`{"query": "query Unit($id: ID!) { unit(id: $id) { name weapons { ranged { name } } } }", "variables": {"id": "el-fixture-captain"}}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"data":{"unit":{"name":`)
+	assert.NotContains(t, w.Body.String(), `"errors"`)
+
+	// A query beyond the limits is refused before it runs
+	w2 := post(`{"query": "{ catalogues { units { abilities { name } weapons { ranged { name } melee { name } } } } }"}`)
+	assert.Equal(t, http.StatusBadRequest, w2.Code)
+	assert.Contains(t, w2.Body.String(), "complexity")
+	assert.NotContains(t, w2.Body.String(), `"data"`)
+
+	w3 := post(`{"query": `)
+	assert.Equal(t, http.StatusBadRequest, w3.Code)
+	assert.Contains(t, w3.Body.String(), "invalid request body")
+
+	req := httptest.NewRequest("GET", "/api/v1/graphql?query=%7Bunit(id:%22el-fixture-captain%22)%7Bid%7D%7D&variables=nope", nil)
+	w4 := httptest.NewRecorder()
+	router.ServeHTTP(w4, req)
+	assert.Equal(t, http.StatusBadRequest, w4.Code)
+	assert.Contains(t, w4.Body.String(), "variables must be a JSON object")
+}
+
+// TestOpenAPIContract checks every route's responses against the served OpenAPI document, so a
+// handler can't change its response shape without the models or the routes table following
+func TestOpenAPIContract(t *testing.T) {
+	router := setupFixtureRouter(t)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", openapi.SpecPath, nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	var document openapi.Document
+	if err := json.Unmarshal(w.Body.Bytes(), &document); err != nil {
+		t.Fatalf("Failed to decode the OpenAPI document: %v", err)
+	}
+	assert.Equal(t, openapi.Version, document.OpenAPI)
+
+	requests := []struct {
+		method string
+		path   string
+		status int
+	}{
+		{"GET", "/api/v1/game-system", http.StatusOK},
+		{"GET", "/api/v1/catalogues", http.StatusOK},
+		{"GET", "/api/v1/catalogues/graph", http.StatusOK},
+		{"GET", "/api/v1/catalogues/graph?format=mermaid", http.StatusOK},
+		{"GET", "/api/v1/catalogues/graph?format=svg", http.StatusBadRequest},
+		{"GET", "/api/v1/catalogues/cat-fixture-marines", http.StatusOK},
+		{"GET", "/api/v1/catalogues/cat-fixture-marines?debug=true", http.StatusOK},
+		{"GET", "/api/v1/catalogues/missing", http.StatusNotFound},
+		{"GET", "/api/v1/catalogues/cat-fixture-marines/units?debug=true", http.StatusOK},
+		{"DELETE", "/api/v1/catalogues/homebrew:missing", http.StatusNotFound},
+		{"GET", "/api/v1/rules", http.StatusOK},
+		{"GET", "/api/v1/rules/rule-fixture-deep-strike", http.StatusOK},
+		{"GET", "/api/v1/units?limit=3&debug=true", http.StatusOK},
+		{"GET", "/api/v1/units?order=sideways", http.StatusBadRequest},
+		{"GET", "/api/v1/units/el-fixture-captain", http.StatusOK},
+		{"GET", "/api/v1/units/el-fixture-captain?debug=true", http.StatusOK},
+		{"GET", "/api/v1/units/missing", http.StatusNotFound},
+		{"GET", "/api/v1/units/el-fixture-captain/explain", http.StatusOK},
+		{"GET", "/api/v1/units/el-fixture-captain/weapons", http.StatusOK},
+		{"GET", "/api/v1/units/el-fixture-captain/history", http.StatusServiceUnavailable},
+		{"GET", "/api/v1/factions", http.StatusOK},
+		{"GET", "/api/v1/factions/fac-fixture-astartes", http.StatusOK},
+		{"GET", "/api/v1/factions/imperium/units?debug=true", http.StatusOK},
+		{"GET", "/api/v1/search?q=fixture&debug=true", http.StatusOK},
+		{"GET", "/api/v1/search", http.StatusBadRequest},
+		{"GET", "/api/v1/export/ndjson", http.StatusOK},
+		{"GET", "/api/v1/graphql?query=%7BgameSystem%7Bname%7D%7D", http.StatusOK},
+		{"GET", "/api/v1/graphql?query=%7Bnope%7D", http.StatusBadRequest},
+		{"GET", "/api/v1/admin/data-quality", http.StatusOK},
+		{"GET", "/api/v1/admin/overlays", http.StatusOK},
+		{"GET", "/api/v1/admin/snapshots", http.StatusOK},
+		{"GET", "/api/v1/admin/cache", http.StatusOK},
+		{"POST", "/api/v1/admin/reload", http.StatusOK},
+		{"GET", "/api/v1/units?snapshot=gone", http.StatusGone},
+		{"GET", openapi.DocsPath, http.StatusOK},
+	}
+	for _, r := range requests {
+		req := httptest.NewRequest(r.method, r.path, nil)
+		req.Header.Set("Authorization", "Bearer "+testToken)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if !assert.Equal(t, r.status, w.Code, r.method+" "+r.path) {
+			continue
+		}
+		path, _, _ := strings.Cut(r.path, "?")
+		assert.NoError(t, document.ValidateResponse(r.method, path, w.Code, w.Header().Get("Content-Type"), w.Body.Bytes()))
+	}
+
+	// A response shape that drifts from the models is caught
+	drifted := []byte(`{"data":{"id":"x","name":"Fixture","revision":"1","library":false,"battleScribe":"2"}}`)
+	assert.Error(t, document.ValidateResponse("GET", "/api/v1/catalogues/x", http.StatusOK, "application/json", drifted))
+}
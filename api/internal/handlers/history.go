@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"grimoire-api/internal/service"
+	"grimoire-api/pkg/response"
+)
+
+// HistoryHandler handles unit points history HTTP requests
+type HistoryHandler struct {
+	service *service.HistoryService
+}
+
+// NewHistoryHandler creates a new history handler
+func NewHistoryHandler(historyService *service.HistoryService) *HistoryHandler {
+	return &HistoryHandler{service: historyService}
+}
+
+// GetUnitHistory handles GET /api/v1/units/:id/history
+func (h *HistoryHandler) GetUnitHistory(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		response.BadRequest(c, "unit ID is required")
+		return
+	}
+
+	unitHistory, err := h.service.GetUnitHistory(id)
+	if errors.Is(err, service.ErrHistoryNotReady) {
+		response.Error(c, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	if err != nil {
+		response.NotFound(c, err.Error())
+		return
+	}
+
+	response.Success(c, unitHistory)
+}
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"grimoire-api/internal/homebrew"
+	"grimoire-api/internal/models"
+	"grimoire-api/internal/snapshot"
+	"grimoire-api/pkg/response"
+)
+
+// HomebrewHandler handles uploading and deleting homebrew catalogues
+type HomebrewHandler struct {
+	store     *homebrew.Store
+	snapshots *snapshot.Store
+}
+
+// NewHomebrewHandler creates a new homebrew handler. Each change loads a new snapshot.
+func NewHomebrewHandler(store *homebrew.Store, snapshots *snapshot.Store) *HomebrewHandler {
+	return &HomebrewHandler{store: store, snapshots: snapshots}
+}
+
+// RequireToken rejects requests without an "Authorization: Bearer <token>" header matching token.
+// With an empty token every request is rejected, so the endpoint is off until a token is configured.
+func RequireToken(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token == "" {
+			response.Error(c, http.StatusForbidden, "this endpoint is disabled until a token is configured")
+			c.Abort()
+			return
+		}
+		given, found := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !found || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			response.Error(c, http.StatusUnauthorized, "a valid bearer token is required")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// UploadCatalogue handles POST /api/v1/catalogues
+// The catalogue is sent as the multipart form field "file", a .cat or .catz
+func (h *HomebrewHandler) UploadCatalogue(c *gin.Context) {
+	current := h.snapshots.Current()
+	if !requireParsed(c, current) {
+		return
+	}
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, homebrew.MaxCatalogueSize)
+	header, err := c.FormFile("file")
+	if err != nil {
+		response.BadRequest(c, "a .cat or .catz file is required in the \"file\" form field")
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	// Validated against the current data, which the new snapshot is loaded from
+	catalogue, err := h.store.Add(current.Parser, header.Filename, data)
+	var invalid *homebrew.ValidationError
+	if errors.As(err, &invalid) {
+		response.Error(c, http.StatusUnprocessableEntity, invalid.Error())
+		return
+	}
+	if err != nil {
+		response.InternalServerError(c, err.Error())
+		return
+	}
+	if !h.reload(c) {
+		return
+	}
+
+	response.Created(c, models.CatalogueInfo{
+		ID:       catalogue.ID,
+		Name:     catalogue.Name,
+		Revision: catalogue.Revision,
+		Homebrew: true,
+	})
+}
+
+// DeleteCatalogue handles DELETE /api/v1/catalogues/:id
+// Only uploaded catalogues can be deleted
+func (h *HomebrewHandler) DeleteCatalogue(c *gin.Context) {
+	if !requireParsed(c, h.snapshots.Current()) {
+		return
+	}
+	err := h.store.Delete(c.Param("id"))
+	if errors.Is(err, homebrew.ErrNotFound) {
+		response.NotFound(c, err.Error())
+		return
+	}
+	if err != nil {
+		response.InternalServerError(c, err.Error())
+		return
+	}
+	if !h.reload(c) {
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// reload publishes a snapshot with the change and names it in the response
+func (h *HomebrewHandler) reload(c *gin.Context) bool {
+	snap, _, err := h.snapshots.Reload()
+	if err != nil {
+		response.InternalServerError(c, err.Error())
+		return false
+	}
+	c.Header(RevisionHeader, snap.Revision)
+	return true
+}
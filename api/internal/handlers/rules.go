@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"grimoire-api/internal/service"
+	"grimoire-api/internal/snapshot"
+	"grimoire-api/pkg/response"
+)
+
+// RuleHandler handles shared rule HTTP requests
+type RuleHandler struct {
+	snapshots *snapshot.Store
+}
+
+// NewRuleHandler creates a new rule handler
+func NewRuleHandler(snapshots *snapshot.Store) *RuleHandler {
+	return &RuleHandler{snapshots: snapshots}
+}
+
+// service returns the rule repository of the request's snapshot
+func (h *RuleHandler) service(c *gin.Context) service.RuleRepository {
+	return snapshotFor(c, h.snapshots).Repositories.Rules
+}
+
+// ListRules handles GET /api/v1/rules
+// Lists the shared rules of the game system, libraries and catalogues
+func (h *RuleHandler) ListRules(c *gin.Context) {
+	rules, err := h.service(c).ListRules(c.Request.Context())
+	if err != nil {
+		fail(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	response.Success(c, rules)
+}
+
+// GetRule handles GET /api/v1/rules/:id
+func (h *RuleHandler) GetRule(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		response.BadRequest(c, "rule ID is required")
+		return
+	}
+
+	rule, err := h.service(c).GetRule(c.Request.Context(), id)
+	if err != nil {
+		fail(c, err, http.StatusNotFound)
+		return
+	}
+
+	response.Success(c, rule)
+}
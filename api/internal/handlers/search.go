@@ -1,21 +1,23 @@
 package handlers
 
 import (
+	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
-	"grimoire-api/internal/service"
+	"grimoire-api/internal/models"
+	"grimoire-api/internal/snapshot"
 	"grimoire-api/pkg/response"
 )
 
 // SearchHandler handles search-related HTTP requests
 type SearchHandler struct {
-	unitService *service.UnitService
+	snapshots *snapshot.Store
 }
 
 // NewSearchHandler creates a new search handler
-func NewSearchHandler(unitService *service.UnitService) *SearchHandler {
-	return &SearchHandler{unitService: unitService}
+func NewSearchHandler(snapshots *snapshot.Store) *SearchHandler {
+	return &SearchHandler{snapshots: snapshots}
 }
 
 // Search handles GET /api/v1/search
@@ -33,16 +35,15 @@ func (h *SearchHandler) Search(c *gin.Context) {
 		}
 	}
 
-	results, err := h.unitService.SearchUnits(query, limit)
+	results, warnings, err := snapshotFor(c, h.snapshots).Repositories.Units.SearchUnitsWithWarnings(c.Request.Context(), query, limit)
 	if err != nil {
-		response.InternalServerError(c, err.Error())
+		fail(c, err, http.StatusInternalServerError)
 		return
 	}
 
-	response.Success(c, map[string]interface{}{
-		"query":   query,
-		"results": results,
-		"total":   len(results),
-	})
+	response.SuccessWithWarnings(c, models.SearchResponse{
+		Query:   query,
+		Results: results,
+		Total:   len(results),
+	}, warningsForRequest(c, warnings))
 }
-
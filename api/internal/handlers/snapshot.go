@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"grimoire-api/internal/snapshot"
+	"grimoire-api/pkg/response"
+)
+
+// RevisionHeader names the snapshot a request was answered from, and pins a request to a snapshot
+const RevisionHeader = "X-Data-Revision"
+
+const snapshotKey = "snapshot"
+
+// PinSnapshot picks the snapshot a request reads from: the one named by ?snapshot= or the
+// X-Data-Revision header, or else the current one. Pinning the revision of a first page keeps
+// later pages consistent while the data is reloaded. The revision used is sent back in X-Data-Revision.
+func PinSnapshot(snapshots *snapshot.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		revision := c.Query("snapshot")
+		if revision == "" {
+			revision = c.GetHeader(RevisionHeader)
+		}
+
+		snap := snapshots.Current()
+		if revision != "" {
+			pinned, found := snapshots.Get(revision)
+			if !found {
+				response.Error(c, http.StatusGone, fmt.Sprintf("snapshot %s is no longer available; the current revision is %s", revision, snap.Revision))
+				c.Abort()
+				return
+			}
+			snap = pinned
+		}
+
+		c.Set(snapshotKey, snap)
+		c.Header(RevisionHeader, snap.Revision)
+		c.Next()
+	}
+}
+
+// snapshotFor returns the snapshot PinSnapshot picked for the request, or the current one
+func snapshotFor(c *gin.Context, snapshots *snapshot.Store) *snapshot.Snapshot {
+	if snap, exists := c.Get(snapshotKey); exists {
+		return snap.(*snapshot.Snapshot)
+	}
+	return snapshots.Current()
+}
+
+// parsedSnapshot returns the request's snapshot for routes that need the parsed XML. A snapshot
+// of a database only serves its repositories, so those routes answer 501 from it.
+func parsedSnapshot(c *gin.Context, snapshots *snapshot.Store) (*snapshot.Snapshot, bool) {
+	snap := snapshotFor(c, snapshots)
+	if !requireParsed(c, snap) {
+		return nil, false
+	}
+	return snap, true
+}
+
+// requireParsed answers 501 unless snap was parsed from the XML
+func requireParsed(c *gin.Context, snap *snapshot.Snapshot) bool {
+	if snap.Parser == nil {
+		response.NotImplemented(c, "not available when serving from a database; run the server on the XML data")
+		return false
+	}
+	return true
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"grimoire-api/pkg/response"
+)
+
+const timeoutKey = "timeout"
+
+// Timeout gives a request a deadline of d. Services stop work once it passes, and the handler
+// answers 504 Gateway Timeout instead of the result.
+func Timeout(d time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), d)
+		defer cancel()
+
+		c.Set(timeoutKey, d)
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// fail sends the response for a service error: 504 when the request ran out of time, 503 when it
+// was cancelled, and status with the error message otherwise
+func fail(c *gin.Context, err error, status int) {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		message := "the request took too long to answer"
+		if d, exists := c.Get(timeoutKey); exists {
+			message = fmt.Sprintf("the request did not finish within %s", d.(time.Duration))
+		}
+		response.GatewayTimeout(c, message)
+	case errors.Is(err, context.Canceled):
+		response.ServiceUnavailable(c, "the request was cancelled before it finished")
+	default:
+		response.Error(c, status, err.Error())
+	}
+}
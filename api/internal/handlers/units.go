@@ -1,21 +1,30 @@
 package handlers
 
 import (
+	"fmt"
+	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"grimoire-api/internal/service"
+	"grimoire-api/internal/snapshot"
 	"grimoire-api/pkg/response"
 )
 
 // UnitHandler handles unit-related HTTP requests
 type UnitHandler struct {
-	service *service.UnitService
+	snapshots *snapshot.Store
 }
 
 // NewUnitHandler creates a new unit handler
-func NewUnitHandler(unitService *service.UnitService) *UnitHandler {
-	return &UnitHandler{service: unitService}
+func NewUnitHandler(snapshots *snapshot.Store) *UnitHandler {
+	return &UnitHandler{snapshots: snapshots}
+}
+
+// service returns the unit repository of the request's snapshot
+func (h *UnitHandler) service(c *gin.Context) service.UnitRepository {
+	return snapshotFor(c, h.snapshots).Repositories.Units
 }
 
 // GetUnit handles GET /api/v1/units/:id
@@ -26,21 +35,23 @@ func (h *UnitHandler) GetUnit(c *gin.Context) {
 		return
 	}
 
-	unit, err := h.service.GetUnit(id)
+	units := h.service(c)
+	get := units.GetUnit
+	if debugMode(c) {
+		get = units.GetUnitWithWarnings
+	}
+	unit, err := get(c.Request.Context(), id)
 	if err != nil {
-		response.NotFound(c, err.Error())
+		fail(c, err, http.StatusNotFound)
 		return
 	}
 
-	response.Success(c, unit)
+	response.Success(c, unitForRequest(c, unit))
 }
 
 // ListUnits handles GET /api/v1/units
+// faction, category and catalogue may be repeated to match any of several values
 func (h *UnitHandler) ListUnits(c *gin.Context) {
-	faction := c.Query("faction")
-	category := c.Query("category")
-	search := c.Query("search")
-
 	limit := 100
 	if limitStr := c.Query("limit"); limitStr != "" {
 		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 1000 {
@@ -55,13 +66,73 @@ func (h *UnitHandler) ListUnits(c *gin.Context) {
 		}
 	}
 
-	units, total, err := h.service.ListUnits(faction, category, search, limit, offset)
+	query := service.UnitQuery{
+		Factions:   c.QueryArray("faction"),
+		Categories: c.QueryArray("category"),
+		Catalogues: c.QueryArray("catalogue"),
+		Search:     c.Query("search"),
+		Sort:       strings.ToLower(c.DefaultQuery("sort", service.SortByName)),
+		Limit:      limit,
+		Offset:     offset,
+	}
+
+	var err error
+	if query.MinPoints, err = parsePoints(c.Query("minPoints")); err != nil {
+		response.BadRequest(c, "minPoints "+err.Error())
+		return
+	}
+	if query.MaxPoints, err = parsePoints(c.Query("maxPoints")); err != nil {
+		response.BadRequest(c, "maxPoints "+err.Error())
+		return
+	}
+
+	if query.MaxPoints > 0 && query.MinPoints > query.MaxPoints {
+		response.BadRequest(c, "minPoints must not exceed maxPoints")
+		return
+	}
+
+	if query.Legends, err = service.ParseLegendsFilter(c.Query("legends")); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	switch order := strings.ToLower(c.DefaultQuery("order", "asc")); order {
+	case "asc":
+	case "desc":
+		query.Descending = true
+	default:
+		response.BadRequest(c, "order must be asc or desc")
+		return
+	}
+
+	units, total, warnings, err := h.service(c).ListUnitsWithWarnings(c.Request.Context(), query)
 	if err != nil {
-		response.InternalServerError(c, err.Error())
+		fail(c, err, http.StatusBadRequest)
 		return
 	}
 
-	response.Paginated(c, units, total, limit, offset)
+	response.PaginatedWithWarnings(c, units, total, limit, offset, warningsForRequest(c, warnings))
+}
+
+// ExplainUnit handles GET /api/v1/units/:id/explain
+func (h *UnitHandler) ExplainUnit(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		response.BadRequest(c, "unit ID is required")
+		return
+	}
+
+	snap, ok := parsedSnapshot(c, h.snapshots)
+	if !ok {
+		return
+	}
+	explanation, err := snap.Units.ExplainUnit(c.Request.Context(), id)
+	if err != nil {
+		fail(c, err, http.StatusNotFound)
+		return
+	}
+
+	response.Success(c, explanation)
 }
 
 // GetUnitWeapons handles GET /api/v1/units/:id/weapons
@@ -72,13 +143,23 @@ func (h *UnitHandler) GetUnitWeapons(c *gin.Context) {
 		return
 	}
 
-	weapons, err := h.service.GetUnitWeapons(id)
+	weapons, err := h.service(c).GetUnitWeapons(c.Request.Context(), id)
 	if err != nil {
-		response.NotFound(c, err.Error())
+		fail(c, err, http.StatusNotFound)
 		return
 	}
 
 	response.Success(c, weapons)
 }
 
-
+// parsePoints parses an optional points bound, returning 0 when empty
+func parsePoints(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	points, err := strconv.Atoi(value)
+	if err != nil || points < 0 {
+		return 0, fmt.Errorf("must be a non-negative integer")
+	}
+	return points, nil
+}
@@ -0,0 +1,126 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"grimoire-api/internal/datatest"
+)
+
+const daemonsFile = "Chaos - Fixture Daemons.cat"
+
+func TestUpdateRecordsPointsChanges(t *testing.T) {
+	repo := datatest.NewRepo(t)
+
+	repo.Edit(daemonsFile, `revision="9"`, `revision="10"`)
+	repo.Edit(daemonsFile, `value="420"`, `value="400"`)
+	repo.Commit("balance update")
+
+	// A rename with no cost change is still recorded, under the same ID
+	repo.Edit(daemonsFile, `<entryLink id="el-fixture-bloodthirster" name="Fixture Bloodthirster"`, `<entryLink id="el-fixture-bloodthirster" name="Fixture Greater Bloodthirster"`)
+	repo.Commit("rename")
+
+	// Commits that don't touch data files are not indexed
+	require.NoError(t, os.WriteFile(filepath.Join(repo.Dir, "README.md"), []byte("notes"), 0o644))
+	repo.Commit("docs")
+
+	idx := NewIndex()
+	indexed, err := Update(repo.Dir, idx, "")
+	require.NoError(t, err)
+	assert.Equal(t, 3, indexed)
+	assert.Len(t, idx.Head, 40)
+
+	unit, found := idx.Lookup("el-fixture-bloodthirster")
+	require.True(t, found)
+	assert.Equal(t, "Fixture Greater Bloodthirster", unit.Name)
+	assert.Equal(t, "se-fixture-bloodthirster", unit.EntryID)
+	require.Len(t, unit.Points, 3)
+	assert.Equal(t, 420, unit.Points[0].Points)
+	assert.Equal(t, "9", unit.Points[0].CatalogueRevision)
+	assert.Equal(t, 400, unit.Points[1].Points)
+	assert.Equal(t, "10", unit.Points[1].CatalogueRevision)
+	assert.Equal(t, "Fixture Greater Bloodthirster", unit.Points[2].Name)
+	assert.Equal(t, 400, unit.Points[2].Points)
+
+	// Units in catalogues that didn't change keep a single record
+	intercessors, found := idx.Lookup("el-fixture-intercessors")
+	require.True(t, found)
+	require.Len(t, intercessors.Points, 1)
+	assert.Equal(t, 80, intercessors.Points[0].Points)
+	assert.NotEmpty(t, intercessors.Points[0].Tiers)
+
+	// Lookup also accepts the selectionEntry ID
+	byEntry, found := idx.Lookup("se-fixture-bloodthirster")
+	require.True(t, found)
+	assert.Same(t, unit, byEntry)
+
+	_, found = idx.Lookup("does-not-exist")
+	assert.False(t, found)
+}
+
+func TestUpdateIsIncremental(t *testing.T) {
+	repo := datatest.NewRepo(t)
+	path := filepath.Join(t.TempDir(), "history.json.gz")
+
+	// The index is saved as it is updated
+	idx := NewIndex()
+	indexed, err := Update(repo.Dir, idx, path)
+	require.NoError(t, err)
+	assert.Equal(t, 1, indexed)
+
+	loaded, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, idx.Head, loaded.Head)
+	assert.Equal(t, len(idx.Units), len(loaded.Units))
+
+	indexed, err = Update(repo.Dir, loaded, "")
+	require.NoError(t, err)
+	assert.Equal(t, 0, indexed)
+
+	repo.Edit(daemonsFile, `value="110"`, `value="120"`)
+	repo.Commit("bloodletters up")
+
+	indexed, err = Update(repo.Dir, loaded, "")
+	require.NoError(t, err)
+	assert.Equal(t, 1, indexed)
+
+	unit, found := loaded.Lookup("el-fixture-bloodletters")
+	require.True(t, found)
+	require.Len(t, unit.Points, 2)
+	assert.Equal(t, 110, unit.Points[0].Points)
+	assert.Equal(t, 120, unit.Points[1].Points)
+}
+
+func TestUpdateIndexesCataloguesThatParsed(t *testing.T) {
+	repo := datatest.NewRepo(t)
+
+	// The marines catalogue no longer parses, while the daemons change their points
+	repo.Edit("Imperium - Fixture Marines.cat", `<catalogue `, `<catalogue <`)
+	repo.Edit(daemonsFile, `value="110"`, `value="120"`)
+	repo.Commit("broken marines")
+
+	idx := NewIndex()
+	indexed, err := Update(repo.Dir, idx, "")
+	require.NoError(t, err)
+	assert.Equal(t, 2, indexed)
+
+	bloodletters, found := idx.Lookup("el-fixture-bloodletters")
+	require.True(t, found)
+	require.Len(t, bloodletters.Points, 2)
+	assert.Equal(t, 120, bloodletters.Points[1].Points)
+
+	intercessors, found := idx.Lookup("el-fixture-intercessors")
+	require.True(t, found)
+	assert.Len(t, intercessors.Points, 1)
+}
+
+func TestLoadMissingIndex(t *testing.T) {
+	idx, err := Load(filepath.Join(t.TempDir(), "missing.json.gz"))
+	require.NoError(t, err)
+	assert.Empty(t, idx.Head)
+	assert.Empty(t, idx.Units)
+}
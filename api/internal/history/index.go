@@ -0,0 +1,95 @@
+// Package history indexes how unit points changed across commits of the data git repository
+package history
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"grimoire-api/internal/models"
+)
+
+// indexVersion is bumped whenever the on-disk format changes, forcing a rebuild
+const indexVersion = 1
+
+// Index is the points history of every unit seen in the indexed commits
+type Index struct {
+	Version int                            `json:"version"`
+	Head    string                         `json:"head"` // Last indexed commit
+	Units   map[string]*models.UnitHistory `json:"units"`
+	Entries map[string]string              `json:"entries"` // selectionEntry ID -> unit ID
+}
+
+// NewIndex creates an empty index
+func NewIndex() *Index {
+	return &Index{
+		Version: indexVersion,
+		Units:   make(map[string]*models.UnitHistory),
+		Entries: make(map[string]string),
+	}
+}
+
+// Lookup finds a unit's history by entryLink ID or selectionEntry ID
+func (idx *Index) Lookup(id string) (*models.UnitHistory, bool) {
+	if unit, exists := idx.Units[id]; exists {
+		return unit, true
+	}
+	if unitID, exists := idx.Entries[id]; exists {
+		unit, exists := idx.Units[unitID]
+		return unit, exists
+	}
+	return nil, false
+}
+
+// Load reads an index written by Save. A missing file or an index in an older format
+// yields an empty index.
+func Load(path string) (*Index, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return NewIndex(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history index %s: %w", path, err)
+	}
+	defer reader.Close()
+
+	idx := NewIndex()
+	if err := json.NewDecoder(reader).Decode(idx); err != nil {
+		return nil, fmt.Errorf("failed to decode history index %s: %w", path, err)
+	}
+	if idx.Version != indexVersion {
+		return NewIndex(), nil
+	}
+	return idx, nil
+}
+
+// Save writes the index as gzipped JSON, replacing path atomically
+func (idx *Index) Save(path string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".history-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	writer := gzip.NewWriter(tmp)
+	if err := json.NewEncoder(writer).Encode(idx); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,175 @@
+package history
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"runtime"
+	"sync"
+
+	"grimoire-api/internal/diff"
+	"grimoire-api/internal/gitdata"
+	"grimoire-api/internal/models"
+	"grimoire-api/internal/parser"
+)
+
+// unitCost is a unit's cost in one commit
+type unitCost struct {
+	entryID           string
+	name              string
+	catalogue         string
+	catalogueRevision string
+	points            int
+	tiers             []models.CostTier
+}
+
+// maxWorkers bounds how many commits are loaded at once, since each holds a whole parsed dataset
+const maxWorkers = 4
+
+// Update indexes the data commits made since the index was last updated and returns how
+// many were indexed. If the indexed head is no longer in the history, for example after a
+// force push, the index is rebuilt from scratch. When indexPath is set the index is saved
+// there after every batch of commits, so an interrupted update resumes where it stopped.
+func Update(repoDir string, idx *Index, indexPath string) (int, error) {
+	// Indexing runs in the background until it is done
+	ctx := context.Background()
+	head, err := gitdata.ResolveCommit(ctx, repoDir, "HEAD")
+	if err != nil {
+		return 0, err
+	}
+	if idx.Head == head {
+		return 0, nil
+	}
+	if idx.Head != "" && !gitdata.IsAncestor(ctx, repoDir, idx.Head, head) {
+		*idx = *NewIndex()
+	}
+
+	commits, err := gitdata.DataCommits(ctx, repoDir, idx.Head)
+	if err != nil {
+		return 0, err
+	}
+	if len(commits) == 0 {
+		idx.Head = head
+		return 0, save(idx, indexPath)
+	}
+
+	// Commits are loaded in parallel a batch at a time, then recorded in order
+	workers := min(runtime.NumCPU(), maxWorkers)
+	batchSize := workers * 4
+	for start := 0; start < len(commits); start += batchSize {
+		end := min(start+batchSize, len(commits))
+		batch := commits[start:end]
+		costs := make([]map[string]unitCost, len(batch))
+
+		var wg sync.WaitGroup
+		sem := make(chan struct{}, workers)
+		for i, commit := range batch {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func() {
+				defer wg.Done()
+				defer func() { <-sem }()
+
+				p, err := diff.LoadCommit(ctx, repoDir, commit.Hash)
+				var loadErrs *parser.LoadErrors
+				if errors.As(err, &loadErrs) {
+					// Units of the catalogues that parsed are still indexed
+					log.Printf("Indexing commit %.12s without %d catalogue files that failed to load", commit.Hash, len(loadErrs.Report.Errors))
+				} else if err != nil {
+					// A commit that can't be read at all shouldn't stop the rest of the history
+					log.Printf("Skipping commit %.12s in points history: %v", commit.Hash, err)
+					return
+				}
+				costs[i] = collectCosts(p)
+			}()
+		}
+		wg.Wait()
+
+		for i, commit := range batch {
+			if costs[i] != nil {
+				idx.record(commit, costs[i])
+			}
+		}
+
+		idx.Head = batch[len(batch)-1].Hash
+		if end == len(commits) {
+			idx.Head = head
+		}
+		if err := save(idx, indexPath); err != nil {
+			return end, err
+		}
+	}
+
+	return len(commits), nil
+}
+
+// save writes the index to path, if set
+func save(idx *Index, path string) error {
+	if path == "" {
+		return nil
+	}
+	if err := idx.Save(path); err != nil {
+		return fmt.Errorf("failed to save history index: %w", err)
+	}
+	return nil
+}
+
+// collectCosts reads the cost of every root unit of a dataset, keyed by entryLink ID
+func collectCosts(p *parser.Parser) map[string]unitCost {
+	resolver := parser.NewLinkResolver(p)
+	transformer := parser.NewTransformer(resolver)
+
+	costs := make(map[string]unitCost)
+	for _, root := range resolver.ResolveRootUnits() {
+		unit := transformer.TransformUnit(root.Entry, root.Catalogue.ID)
+		cost := unitCost{
+			entryID:           root.EntryLink.TargetID,
+			name:              unit.Name,
+			catalogue:         root.Catalogue.Name,
+			catalogueRevision: root.Catalogue.Revision,
+			points:            unit.Costs["pts"],
+		}
+		if unit.TieredCosts != nil {
+			cost.tiers = unit.TieredCosts.Tiers
+		}
+		costs[root.EntryLink.ID] = cost
+	}
+	return costs
+}
+
+// record adds a commit's costs, skipping units whose cost, name and catalogue revision
+// are unchanged since their last record
+func (idx *Index) record(commit gitdata.Commit, costs map[string]unitCost) {
+	for id, cost := range costs {
+		unit, exists := idx.Units[id]
+		if !exists {
+			unit = &models.UnitHistory{ID: id, Points: make([]models.PointsRecord, 0)}
+			idx.Units[id] = unit
+		}
+		unit.EntryID = cost.entryID
+		unit.Name = cost.name
+		unit.Catalogue = cost.catalogue
+		// A shared entry linked from several catalogues resolves to the lowest link ID
+		if claimed, exists := idx.Entries[cost.entryID]; !exists || id < claimed {
+			idx.Entries[cost.entryID] = id
+		}
+
+		if n := len(unit.Points); n > 0 {
+			last := unit.Points[n-1]
+			if last.Points == cost.points && last.Name == cost.name &&
+				last.CatalogueRevision == cost.catalogueRevision && diff.EqualTiers(last.Tiers, cost.tiers) {
+				continue
+			}
+		}
+
+		unit.Points = append(unit.Points, models.PointsRecord{
+			Commit:            commit.Hash,
+			Date:              commit.Date,
+			CatalogueRevision: cost.catalogueRevision,
+			Name:              cost.name,
+			Points:            cost.points,
+			Tiers:             cost.tiers,
+		})
+	}
+}
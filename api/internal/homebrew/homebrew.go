@@ -0,0 +1,245 @@
+// Package homebrew stores catalogues uploaded through the API, such as homebrew units for narrative
+// campaigns. Uploads are validated against the loaded data, moved into their own ID namespace and
+// saved to a local directory, and loaded into every snapshot of the data from then on.
+package homebrew
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"grimoire-api/internal/lint"
+	"grimoire-api/internal/models"
+	"grimoire-api/internal/parser"
+)
+
+// MaxCatalogueSize is the largest catalogue accepted, after unzipping a .catz
+const MaxCatalogueSize = 32 << 20
+
+// ErrNotFound is returned when deleting a catalogue that wasn't uploaded
+var ErrNotFound = errors.New("homebrew catalogue not found")
+
+// ValidationError lists why an upload was rejected
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid catalogue: " + strings.Join(e.Problems, "; ")
+}
+
+// Store keeps uploaded catalogues in a directory and loads them into each new snapshot of the data
+type Store struct {
+	dir string
+
+	mu    sync.Mutex
+	files map[string]string // Catalogue ID -> saved file name
+}
+
+// NewStore creates a store saving uploads to dir
+func NewStore(dir string) *Store {
+	return &Store{
+		dir:   dir,
+		files: make(map[string]string),
+	}
+}
+
+// Dir returns the directory uploads are saved to
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// LoadAll loads the catalogues saved in the store's directory into p, which must still be loading.
+// Files that no longer validate, for example after a data update removed something they link to,
+// are skipped and returned as errors.
+func (s *Store) LoadAll(p *parser.Parser) []error {
+	entries, err := os.ReadDir(s.dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return []error{fmt.Errorf("failed to read homebrew directory: %w", err)}
+	}
+
+	var errs []error
+	for _, entry := range entries {
+		if entry.IsDir() || !isCatalogueFile(entry.Name()) {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(s.dir, entry.Name()))
+		if err == nil {
+			var catalogue *models.Catalogue
+			if catalogue, err = prepare(p, entry.Name(), data); err == nil {
+				p.AddHomebrewCatalogue(catalogue, "homebrew/"+entry.Name())
+				s.mu.Lock()
+				s.files[catalogue.ID] = entry.Name()
+				s.mu.Unlock()
+			}
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", entry.Name(), err))
+		}
+	}
+	return errs
+}
+
+// Add validates an uploaded .cat or .catz file against the data in p and saves it, replacing an
+// earlier upload of the same catalogue. It is loaded with the next snapshot. Invalid uploads
+// return a *ValidationError.
+func (s *Store) Add(p *parser.Parser, filename string, data []byte) (*models.Catalogue, error) {
+	catalogue, err := prepare(p, filename, data)
+	if err != nil {
+		return nil, err
+	}
+	savedFile := savedFileName(catalogue.ID, filename)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create homebrew directory: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(s.dir, savedFile), data, 0o644); err != nil {
+		return nil, fmt.Errorf("failed to save %s: %w", savedFile, err)
+	}
+	// An earlier upload of the catalogue in the other format
+	if previous, exists := s.files[catalogue.ID]; exists && previous != savedFile {
+		os.Remove(filepath.Join(s.dir, previous))
+	}
+	s.files[catalogue.ID] = savedFile
+
+	return catalogue, nil
+}
+
+// Delete removes the saved file of an uploaded catalogue. It is left out of the next snapshot.
+func (s *Store) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, exists := s.files[id]
+	if !exists {
+		return ErrNotFound
+	}
+	delete(s.files, id)
+	if err := os.Remove(filepath.Join(s.dir, file)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove %s: %w", file, err)
+	}
+	return nil
+}
+
+// prepare decodes, namespaces and validates an upload
+func prepare(p *parser.Parser, filename string, data []byte) (*models.Catalogue, error) {
+	if !isCatalogueFile(filename) {
+		return nil, &ValidationError{Problems: []string{"file must be a .cat or .catz"}}
+	}
+	xmlData := data
+	if strings.EqualFold(filepath.Ext(filename), ".catz") {
+		var err error
+		if xmlData, err = unzipCatalogue(data); err != nil {
+			return nil, &ValidationError{Problems: []string{err.Error()}}
+		}
+	}
+
+	catalogue, err := parser.DecodeCatalogue(bytes.NewReader(xmlData))
+	if err != nil {
+		return nil, &ValidationError{Problems: []string{err.Error()}}
+	}
+	if catalogue.ID == "" {
+		return nil, &ValidationError{Problems: []string{"catalogue has no id"}}
+	}
+
+	file := "homebrew/" + savedFileName(catalogue.ID, filename)
+	namespace(catalogue)
+	if err := validate(p, catalogue, file); err != nil {
+		return nil, err
+	}
+	return catalogue, nil
+}
+
+// savedFileName names the saved copy of an upload after the catalogue's original ID, so a new
+// version of a catalogue replaces the old one
+func savedFileName(id, filename string) string {
+	id = strings.TrimPrefix(id, Prefix)
+	return fileName(id) + strings.ToLower(filepath.Ext(filename))
+}
+
+// validate checks a namespaced catalogue against the loaded game system and data
+func validate(p *parser.Parser, catalogue *models.Catalogue, file string) error {
+	var problems []string
+
+	if catalogue.Library {
+		problems = append(problems, "libraries can't be uploaded, only catalogues")
+	}
+	if gs := p.GetGameSystem(); gs == nil || catalogue.GameSystemID != gs.ID {
+		problems = append(problems, fmt.Sprintf("gameSystemId %q doesn't match the loaded game system", catalogue.GameSystemID))
+	}
+
+	for _, catLink := range catalogue.CatalogueLinks {
+		if catLink.TargetID == catalogue.ID {
+			continue
+		}
+		_, isLibrary := p.GetLibrary(catLink.TargetID)
+		_, isCatalogue := p.GetCatalogue(catLink.TargetID)
+		if !isLibrary && !isCatalogue {
+			problems = append(problems, fmt.Sprintf("catalogueLink %q targets catalogue %s, which is not loaded", catLink.Name, catLink.TargetID))
+		}
+	}
+
+	issues := lint.CheckCatalogue(p, catalogue, file)
+	sort.SliceStable(issues, func(i, j int) bool { return issues[i].Line < issues[j].Line })
+	for _, issue := range issues {
+		problems = append(problems, fmt.Sprintf("line %d: %s", issue.Line, issue.Message))
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// unzipCatalogue returns the .cat file inside a .catz archive
+func unzipCatalogue(data []byte) ([]byte, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid .catz: %w", err)
+	}
+	for _, file := range archive.File {
+		if !strings.EqualFold(filepath.Ext(file.Name), ".cat") {
+			continue
+		}
+		r, err := file.Open()
+		if err != nil {
+			return nil, fmt.Errorf("invalid .catz: %w", err)
+		}
+		defer r.Close()
+		content, err := io.ReadAll(io.LimitReader(r, MaxCatalogueSize+1))
+		if err != nil {
+			return nil, fmt.Errorf("invalid .catz: %w", err)
+		}
+		if len(content) > MaxCatalogueSize {
+			return nil, fmt.Errorf("catalogue is larger than %d MB", MaxCatalogueSize>>20)
+		}
+		return content, nil
+	}
+	return nil, fmt.Errorf(".catz contains no .cat file")
+}
+
+func isCatalogueFile(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	return ext == ".cat" || ext == ".catz"
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+// fileName turns a catalogue ID into a safe file name
+func fileName(id string) string {
+	return unsafeFileChars.ReplaceAllString(id, "_")
+}
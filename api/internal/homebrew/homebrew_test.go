@@ -0,0 +1,142 @@
+package homebrew
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"grimoire-api/internal/parser"
+)
+
+const fixtureDir = "../../testdata/wh40k-fixture"
+
+// campaignCatalogue reuses an official selectionEntry ID and links to an official library entry
+const campaignCatalogue = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<catalogue id="cat-campaign" name="Homebrew - Campaign" revision="1" battleScribeVersion="2.03" library="false" gameSystemId="sys-352e-adc2-7639" gameSystemRevision="7" type="catalogue" xmlns="http://www.battlescribe.net/schema/catalogueSchema">
+  <catalogueLinks>
+    <catalogueLink id="cl-campaign-lib" name="Library - Fixture Astartes" targetId="lib-fixture-astartes" type="catalogue" importRootEntries="true"/>
+  </catalogueLinks>
+  <sharedSelectionEntries>
+    <selectionEntry id="se-fixture-captain" name="Campaign Captain" hidden="false" collective="false" import="true" type="model">
+      <profiles>
+        <profile id="prof-campaign-captain" name="Campaign Captain" hidden="false" typeId="c547-1836-d8a-ff4f" typeName="Unit">
+          <characteristics>
+            <characteristic name="M" typeId="e703-ecb6-5ce7-aec1">6&quot;</characteristic>
+            <characteristic name="T" typeId="d29d-cf75-fc2d-34a4">5</characteristic>
+            <characteristic name="SV" typeId="450-a17e-9d5e-29da">2+</characteristic>
+            <characteristic name="W" typeId="750a-a2ec-90d3-21fe">6</characteristic>
+            <characteristic name="LD" typeId="58d2-b879-49c7-43bc">6+</characteristic>
+            <characteristic name="OC" typeId="bef7-942a-1a23-59f8">1</characteristic>
+          </characteristics>
+        </profile>
+      </profiles>
+      <entryLinks>
+        <entryLink id="el-campaign-bolter" name="Master-crafted bolter" hidden="false" collective="false" import="true" targetId="se-fixture-mc-bolter" type="selectionEntry"/>
+      </entryLinks>
+      <costs>
+        <cost name="pts" typeId="51b2-306e-1021-d207" value="95"/>
+      </costs>
+    </selectionEntry>
+  </sharedSelectionEntries>
+  <entryLinks>
+    <entryLink id="el-campaign-captain" name="Campaign Captain" hidden="false" collective="false" import="true" targetId="se-fixture-captain" type="selectionEntry"/>
+  </entryLinks>
+</catalogue>`
+
+func loadFixture(t *testing.T) *parser.Parser {
+	t.Helper()
+	p, err := parser.LoadDataDir(fixtureDir)
+	require.NoError(t, err)
+	return p
+}
+
+func TestAddNamespacesIDs(t *testing.T) {
+	store := NewStore(t.TempDir())
+
+	catalogue, err := store.Add(loadFixture(t), "campaign.cat", []byte(campaignCatalogue))
+	require.NoError(t, err)
+	assert.Equal(t, "homebrew:cat-campaign", catalogue.ID)
+
+	// Uploads are loaded into the next dataset
+	p := loadFixture(t)
+	assert.Empty(t, store.LoadAll(p))
+	assert.True(t, p.IsHomebrew(catalogue.ID))
+
+	// Links to the catalogue's own entries follow the namespace, links to official data don't
+	link := catalogue.EntryLinks[0]
+	assert.Equal(t, "homebrew:cat-campaign:el-campaign-captain", link.ID)
+	assert.Equal(t, "homebrew:cat-campaign:se-fixture-captain", link.TargetID)
+	assert.Equal(t, "lib-fixture-astartes", catalogue.CatalogueLinks[0].TargetID)
+	assert.Equal(t, "se-fixture-mc-bolter", catalogue.SharedSelectionEntries[0].EntryLinks[0].TargetID)
+
+	// The official entry with the reused ID isn't shadowed
+	entry, sourceID, found := p.FindSelectionEntryByID("se-fixture-captain")
+	require.True(t, found)
+	assert.Equal(t, "lib-fixture-astartes", sourceID)
+	assert.Equal(t, "Fixture Captain", entry.Name)
+
+	file, _ := p.GetCatalogueFile(catalogue.ID)
+	assert.Equal(t, "homebrew/cat-campaign.cat", file)
+}
+
+func TestAddRejectsInvalidCatalogues(t *testing.T) {
+	store := NewStore(t.TempDir())
+	p := loadFixture(t)
+
+	tests := map[string]struct {
+		catalogue string
+		problem   string
+	}{
+		"wrong game system": {strings.Replace(campaignCatalogue, `gameSystemId="sys-352e-adc2-7639"`, `gameSystemId="sys-other"`, 1), "gameSystemId"},
+		"unresolved link":   {strings.Replace(campaignCatalogue, `targetId="se-fixture-mc-bolter"`, `targetId="se-missing"`, 1), "se-missing"},
+		"missing library":   {strings.Replace(campaignCatalogue, `targetId="lib-fixture-astartes"`, `targetId="lib-missing"`, 1), "lib-missing"},
+		"library":           {strings.Replace(campaignCatalogue, `library="false"`, `library="true"`, 1), "libraries"},
+		"not XML":           {"not a catalogue", "invalid XML"},
+	}
+	for name, tt := range tests {
+		_, err := store.Add(p, "campaign.cat", []byte(tt.catalogue))
+		var invalid *ValidationError
+		require.ErrorAs(t, err, &invalid, name)
+		assert.Contains(t, err.Error(), tt.problem, name)
+	}
+
+	_, err := store.Add(p, "campaign.xml", []byte(campaignCatalogue))
+	assert.Error(t, err)
+	entries, _ := os.ReadDir(store.Dir())
+	assert.Empty(t, entries)
+}
+
+func TestStorePersistsAndDeletes(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStore(dir)
+
+	var archive bytes.Buffer
+	w := zip.NewWriter(&archive)
+	f, err := w.Create("Homebrew - Campaign.cat")
+	require.NoError(t, err)
+	_, err = f.Write([]byte(campaignCatalogue))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	_, err = store.Add(loadFixture(t), "Homebrew - Campaign.catz", archive.Bytes())
+	require.NoError(t, err)
+	assert.FileExists(t, filepath.Join(dir, "cat-campaign.catz"))
+
+	// A new store over the same directory loads the upload again
+	reloaded := NewStore(dir)
+	p := loadFixture(t)
+	assert.Empty(t, reloaded.LoadAll(p))
+	assert.True(t, p.IsHomebrew("homebrew:cat-campaign"))
+
+	require.NoError(t, reloaded.Delete("homebrew:cat-campaign"))
+	_, err = os.Stat(filepath.Join(dir, "cat-campaign.catz"))
+	assert.True(t, os.IsNotExist(err))
+
+	assert.ErrorIs(t, reloaded.Delete("cat-fixture-marines"), ErrNotFound)
+}
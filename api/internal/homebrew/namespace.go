@@ -0,0 +1,67 @@
+package homebrew
+
+import (
+	"reflect"
+	"strings"
+
+	"grimoire-api/internal/models"
+)
+
+// Prefix starts the ID of every uploaded catalogue and of every element defined in one
+const Prefix = "homebrew:"
+
+// CatalogueID returns the ID an uploaded catalogue is served under
+func CatalogueID(originalID string) string {
+	return Prefix + originalID
+}
+
+// namespace moves every ID defined in an uploaded catalogue into the catalogue's own namespace and
+// rewrites the references to them, so uploads can neither collide with nor shadow official IDs.
+// References to IDs the catalogue doesn't define, such as official libraries and the game system's
+// categories, are left alone.
+func namespace(catalogue *models.Catalogue) {
+	originalID := catalogue.ID
+	ids := make(map[string]string)
+	walkAttrs(reflect.ValueOf(catalogue).Elem(), func(name string, value *string) {
+		if name == "id" && *value != "" {
+			ids[*value] = Prefix + originalID + ":" + *value
+		}
+	})
+	ids[originalID] = CatalogueID(originalID)
+
+	walkAttrs(reflect.ValueOf(catalogue).Elem(), func(name string, value *string) {
+		if namespaced, ok := ids[*value]; ok {
+			*value = namespaced
+		}
+	})
+}
+
+// walkAttrs calls fn for every string field decoded from an XML attribute, with the attribute name
+func walkAttrs(v reflect.Value, fn func(name string, value *string)) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			walkAttrs(v.Elem(), fn)
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			walkAttrs(v.Index(i), fn)
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			tag := field.Tag.Get("xml")
+			if !field.IsExported() || tag == "-" {
+				continue
+			}
+			if field.Type.Kind() == reflect.String {
+				if name, isAttr := strings.CutSuffix(tag, ",attr"); isAttr {
+					fn(name, v.Field(i).Addr().Interface().(*string))
+				}
+				continue
+			}
+			walkAttrs(v.Field(i), fn)
+		}
+	}
+}
@@ -0,0 +1,463 @@
+// Package lint checks a loaded dataset for data-quality problems such as unresolved links,
+// units without profiles or points, duplicate IDs and cyclic links
+package lint
+
+import (
+	"fmt"
+	"sort"
+
+	"grimoire-api/internal/models"
+	"grimoire-api/internal/parser"
+)
+
+// Kinds of definitions that links can target
+const (
+	kindEntry    = "selectionEntry"
+	kindGroup    = "selectionEntryGroup"
+	kindProfile  = "profile"
+	kindRule     = "rule"
+	kindCategory = "categoryEntry"
+)
+
+// source is the file and catalogue a definition or link is in
+type source struct {
+	file      string
+	catalogue *models.Catalogue // nil for the game system
+}
+
+// definition is an element with an ID that links can target
+type definition struct {
+	kind   string
+	name   string
+	source source
+	line   int // 0 for kinds whose position isn't recorded
+}
+
+// checker accumulates the issues found in one dataset
+type checker struct {
+	parser      *parser.Parser
+	resolver    *parser.LinkResolver
+	transformer *parser.Transformer
+
+	sources []source
+	defs    map[string][]definition
+	edges   map[string][]string // Entry or group ID -> entries and groups it contains or links to
+	issues  []models.DataQualityIssue
+	units   int
+}
+
+func newChecker(p *parser.Parser) *checker {
+	resolver := parser.NewLinkResolver(p)
+	return &checker{
+		parser:      p,
+		resolver:    resolver,
+		transformer: parser.NewTransformer(resolver),
+		defs:        make(map[string][]definition),
+		edges:       make(map[string][]string),
+		issues:      make([]models.DataQualityIssue, 0),
+	}
+}
+
+// Run checks every catalogue and library in a dataset and reports the issues found
+func Run(p *parser.Parser) *models.DataQualityReport {
+	c := newChecker(p)
+
+	c.collectSources()
+	for _, src := range c.sources {
+		c.indexDefinitions(src)
+	}
+	for _, src := range c.sources {
+		c.checkLinks(src)
+	}
+	c.checkDuplicates()
+	c.checkEntryCycles()
+	c.checkCatalogueCycles()
+	c.checkUnits()
+	c.checkLoadErrors()
+
+	return c.report()
+}
+
+// CheckCatalogue checks the entryLinks and infoLinks of a catalogue that isn't loaded yet against
+// the loaded dataset, as if it were loaded from file. A loaded catalogue with the same ID is left out,
+// since the new one replaces it.
+func CheckCatalogue(p *parser.Parser, catalogue *models.Catalogue, file string) []models.DataQualityIssue {
+	c := newChecker(p)
+
+	c.collectSources()
+	for _, src := range c.sources {
+		if src.catalogue == nil || src.catalogue.ID != catalogue.ID {
+			c.indexDefinitions(src)
+		}
+	}
+	candidate := source{file: file, catalogue: catalogue}
+	c.indexDefinitions(candidate)
+	c.checkLinks(candidate)
+
+	return c.issues
+}
+
+// collectSources lists the game system and every catalogue and library, ordered by file
+func (c *checker) collectSources() {
+	if c.parser.GetGameSystem() != nil {
+		c.sources = append(c.sources, source{file: parser.GameSystemFile})
+	}
+
+	var catalogues []source
+	add := func(all map[string]*models.Catalogue) {
+		for id, cat := range all {
+			file, _ := c.parser.GetCatalogueFile(id)
+			catalogues = append(catalogues, source{file: file, catalogue: cat})
+		}
+	}
+	add(c.parser.GetAllCatalogues())
+	add(c.parser.GetAllLibraries())
+	sort.Slice(catalogues, func(i, j int) bool { return catalogues[i].file < catalogues[j].file })
+
+	c.sources = append(c.sources, catalogues...)
+}
+
+// indexDefinitions records every targetable element defined in a source
+func (c *checker) indexDefinitions(src source) {
+	if src.catalogue == nil {
+		gs := c.parser.GetGameSystem()
+		for _, category := range gs.CategoryEntries {
+			c.define(category.ID, kindCategory, category.Name, src, 0)
+		}
+		for _, profile := range gs.SharedProfiles {
+			c.define(profile.ID, kindProfile, profile.Name, src, profile.Pos.Line)
+		}
+		for _, rule := range gs.SharedRules {
+			c.define(rule.ID, kindRule, rule.Name, src, 0)
+		}
+		return
+	}
+
+	cat := src.catalogue
+	for _, category := range cat.CategoryEntries {
+		c.define(category.ID, kindCategory, category.Name, src, 0)
+	}
+	for _, profile := range cat.SharedProfiles {
+		c.define(profile.ID, kindProfile, profile.Name, src, profile.Pos.Line)
+	}
+	for _, rule := range cat.SharedRules {
+		c.define(rule.ID, kindRule, rule.Name, src, 0)
+	}
+	for i := range cat.SharedSelectionEntries {
+		c.defineEntry(&cat.SharedSelectionEntries[i], src)
+	}
+	for i := range cat.SharedSelectionEntryGroups {
+		c.defineGroup(&cat.SharedSelectionEntryGroups[i], src)
+	}
+}
+
+func (c *checker) define(id, kind, name string, src source, line int) {
+	if id == "" {
+		return
+	}
+	c.defs[id] = append(c.defs[id], definition{kind: kind, name: name, source: src, line: line})
+}
+
+func (c *checker) defineEntry(entry *models.SelectionEntry, src source) {
+	c.define(entry.ID, kindEntry, entry.Name, src, entry.Pos.Line)
+	for _, profile := range entry.Profiles {
+		c.define(profile.ID, kindProfile, profile.Name, src, profile.Pos.Line)
+	}
+	for i := range entry.SelectionEntries {
+		c.defineEntry(&entry.SelectionEntries[i], src)
+	}
+	for i := range entry.SelectionEntryGroups {
+		c.defineGroup(&entry.SelectionEntryGroups[i], src)
+	}
+}
+
+func (c *checker) defineGroup(group *models.SelectionEntryGroup, src source) {
+	c.define(group.ID, kindGroup, group.Name, src, 0)
+	for i := range group.SelectionEntries {
+		c.defineEntry(&group.SelectionEntries[i], src)
+	}
+	for i := range group.SelectionEntryGroups {
+		c.defineGroup(&group.SelectionEntryGroups[i], src)
+	}
+}
+
+// defined reports whether an element of the given kind has the ID
+func (c *checker) defined(id, kind string) bool {
+	for _, def := range c.defs[id] {
+		if def.kind == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// checkLinks checks every entryLink and infoLink in a source and records the link graph
+func (c *checker) checkLinks(src source) {
+	if src.catalogue == nil {
+		return
+	}
+	cat := src.catalogue
+	for i := range cat.EntryLinks {
+		c.checkEntryLink("", &cat.EntryLinks[i], src)
+	}
+	for i := range cat.SharedSelectionEntries {
+		c.checkEntry(&cat.SharedSelectionEntries[i], src)
+	}
+	for i := range cat.SharedSelectionEntryGroups {
+		c.checkGroup(&cat.SharedSelectionEntryGroups[i], src)
+	}
+}
+
+func (c *checker) checkEntry(entry *models.SelectionEntry, src source) {
+	for _, infoLink := range entry.InfoLinks {
+		c.checkInfoLink(entry, infoLink, src)
+	}
+	for i := range entry.EntryLinks {
+		c.checkEntryLink(entry.ID, &entry.EntryLinks[i], src)
+	}
+	for i := range entry.SelectionEntries {
+		c.edge(entry.ID, entry.SelectionEntries[i].ID)
+		c.checkEntry(&entry.SelectionEntries[i], src)
+	}
+	for i := range entry.SelectionEntryGroups {
+		c.edge(entry.ID, entry.SelectionEntryGroups[i].ID)
+		c.checkGroup(&entry.SelectionEntryGroups[i], src)
+	}
+}
+
+func (c *checker) checkGroup(group *models.SelectionEntryGroup, src source) {
+	for i := range group.EntryLinks {
+		c.checkEntryLink(group.ID, &group.EntryLinks[i], src)
+	}
+	for i := range group.SelectionEntries {
+		c.edge(group.ID, group.SelectionEntries[i].ID)
+		c.checkEntry(&group.SelectionEntries[i], src)
+	}
+	for i := range group.SelectionEntryGroups {
+		c.edge(group.ID, group.SelectionEntryGroups[i].ID)
+		c.checkGroup(&group.SelectionEntryGroups[i], src)
+	}
+}
+
+// checkEntryLink checks that a link resolves; parentID is the entry or group containing it,
+// empty for catalogue root links
+func (c *checker) checkEntryLink(parentID string, link *models.EntryLink, src source) {
+	kind := kindEntry
+	if link.Type == kindGroup {
+		kind = kindGroup
+	} else if link.Type != kindEntry {
+		return
+	}
+
+	if link.TargetID == "" || !c.defined(link.TargetID, kind) {
+		c.add(models.DataQualityIssue{
+			Rule:      models.RuleUnresolvedLink,
+			Severity:  models.SeverityError,
+			Message:   fmt.Sprintf("entryLink %q targets %s %q, which is not defined in any file", link.Name, kind, link.TargetID),
+			Line:      link.Pos.Line,
+			EntryID:   link.ID,
+			EntryName: link.Name,
+			TargetID:  link.TargetID,
+		}, src)
+	} else if parentID != "" {
+		c.edge(parentID, link.TargetID)
+	}
+
+	for i := range link.EntryLinks {
+		c.checkEntryLink(link.TargetID, &link.EntryLinks[i], src)
+	}
+}
+
+func (c *checker) checkInfoLink(entry *models.SelectionEntry, link models.InfoLink, src source) {
+	kind := link.Type
+	if kind != kindProfile && kind != kindRule {
+		return
+	}
+	if link.TargetID != "" && c.defined(link.TargetID, kind) {
+		return
+	}
+	c.add(models.DataQualityIssue{
+		Rule:      models.RuleMissingInfoTarget,
+		Severity:  models.SeverityWarning,
+		Message:   fmt.Sprintf("infoLink %q on %q targets %s %q, which is not defined in any file", link.Name, entry.Name, kind, link.TargetID),
+		Line:      entry.Pos.Line,
+		EntryID:   entry.ID,
+		EntryName: entry.Name,
+		TargetID:  link.TargetID,
+	}, src)
+}
+
+func (c *checker) edge(from, to string) {
+	if from != "" && to != "" {
+		c.edges[from] = append(c.edges[from], to)
+	}
+}
+
+// checkDuplicates reports IDs that are defined in more than one file
+func (c *checker) checkDuplicates() {
+	ids := make([]string, 0, len(c.defs))
+	for id := range c.defs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		defs := c.defs[id]
+		var files []string
+		seen := make(map[string]bool)
+		for _, def := range defs {
+			if !seen[def.source.file] {
+				seen[def.source.file] = true
+				files = append(files, def.source.file)
+			}
+		}
+		if len(files) < 2 {
+			continue
+		}
+
+		first := defs[0]
+		c.add(models.DataQualityIssue{
+			Rule:      models.RuleDuplicateID,
+			Severity:  models.SeverityError,
+			Message:   fmt.Sprintf("%s %q uses ID %s, which is also defined in %d other files", first.kind, first.name, id, len(files)-1),
+			Line:      first.line,
+			EntryID:   id,
+			EntryName: first.name,
+			Related:   files[1:],
+		}, first.source)
+	}
+}
+
+// checkEntryCycles reports entries and groups that contain or link to themselves
+func (c *checker) checkEntryCycles() {
+	nodes := make([]string, 0, len(c.edges))
+	for id := range c.edges {
+		nodes = append(nodes, id)
+	}
+	sort.Strings(nodes)
+
+	for _, cycle := range parser.FindCycles(nodes, c.edges) {
+		first := c.defs[cycle[0]][0]
+		c.add(models.DataQualityIssue{
+			Rule:      models.RuleCyclicLink,
+			Severity:  models.SeverityError,
+			Message:   fmt.Sprintf("%d entries link to each other in a cycle, starting at %q", len(cycle), first.name),
+			Line:      first.line,
+			EntryID:   cycle[0],
+			EntryName: first.name,
+			Related:   cycle,
+		}, first.source)
+	}
+}
+
+// checkCatalogueCycles reports catalogues that import each other
+func (c *checker) checkCatalogueCycles() {
+	graph := c.resolver.ResolveCatalogueGraph()
+	for _, cycle := range graph.Cycles {
+		node, _ := graph.Node(cycle[0])
+		file, _ := c.parser.GetCatalogueFile(cycle[0])
+		c.add(models.DataQualityIssue{
+			Rule:     models.RuleCyclicLink,
+			Severity: models.SeverityError,
+			Message:  fmt.Sprintf("%d catalogues import each other in a cycle", len(cycle)),
+			Related:  cycle,
+		}, source{file: file, catalogue: node.Catalogue})
+	}
+}
+
+// checkUnits reports root units with no Unit profile or no points cost
+func (c *checker) checkUnits() {
+	for _, root := range c.resolver.ResolveRootUnits() {
+		if root.Entry.Type != "unit" && root.Entry.Type != "model" {
+			continue
+		}
+		c.units++
+
+		file, _ := c.parser.GetCatalogueFile(root.Catalogue.ID)
+		src := source{file: file, catalogue: root.Catalogue}
+		unit := c.transformer.TransformUnit(root.Entry, root.Catalogue.ID)
+
+		if unit.Profiles == nil || unit.Profiles.Unit == nil {
+			c.add(models.DataQualityIssue{
+				Rule:      models.RuleMissingUnitProfile,
+				Severity:  models.SeverityWarning,
+				Message:   fmt.Sprintf("unit %q has no resolvable Unit profile", unit.Name),
+				Line:      root.EntryLink.Pos.Line,
+				EntryID:   root.EntryLink.ID,
+				EntryName: unit.Name,
+				TargetID:  root.EntryLink.TargetID,
+			}, src)
+		}
+		if unit.Costs["pts"] == 0 && unit.TieredCosts == nil {
+			c.add(models.DataQualityIssue{
+				Rule:      models.RuleMissingPoints,
+				Severity:  models.SeverityWarning,
+				Message:   fmt.Sprintf("unit %q has no pts cost", unit.Name),
+				Line:      root.EntryLink.Pos.Line,
+				EntryID:   root.EntryLink.ID,
+				EntryName: unit.Name,
+				TargetID:  root.EntryLink.TargetID,
+			}, src)
+		}
+	}
+}
+
+// checkLoadErrors reports the catalogue files that failed to parse when the dataset was loaded.
+// Only the files that did load are checked otherwise.
+func (c *checker) checkLoadErrors() {
+	report := c.parser.LoadReport()
+	if report == nil {
+		return
+	}
+	for _, loadErr := range report.Errors {
+		c.add(models.DataQualityIssue{
+			Rule:     models.RuleLoadError,
+			Severity: models.SeverityError,
+			Message:  "file failed to load: " + loadErr.Error,
+		}, source{file: loadErr.File})
+	}
+}
+
+// add attributes an issue to its source and records it
+func (c *checker) add(issue models.DataQualityIssue, src source) {
+	issue.File = src.file
+	if src.catalogue != nil {
+		issue.CatalogueID = src.catalogue.ID
+		issue.CatalogueName = src.catalogue.Name
+	}
+	c.issues = append(c.issues, issue)
+}
+
+// report sorts the issues by file, rule and entry and summarizes them
+func (c *checker) report() *models.DataQualityReport {
+	sort.SliceStable(c.issues, func(i, j int) bool {
+		a, b := c.issues[i], c.issues[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Rule != b.Rule {
+			return a.Rule < b.Rule
+		}
+		return a.EntryID < b.EntryID
+	})
+
+	files := len(c.sources)
+	if report := c.parser.LoadReport(); report != nil {
+		files += len(report.Errors)
+	}
+	summary := models.DataQualitySummary{
+		Files:  files,
+		Units:  c.units,
+		ByRule: make(map[string]int),
+	}
+	for _, issue := range c.issues {
+		summary.ByRule[issue.Rule]++
+		if issue.Severity == models.SeverityError {
+			summary.Errors++
+		} else {
+			summary.Warnings++
+		}
+	}
+
+	return &models.DataQualityReport{Summary: summary, Issues: c.issues}
+}
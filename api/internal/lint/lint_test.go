@@ -0,0 +1,142 @@
+package lint
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"grimoire-api/internal/datatest"
+	"grimoire-api/internal/models"
+	"grimoire-api/internal/parser"
+)
+
+const fixtureDir = "../../testdata/wh40k-fixture"
+
+// brokenCatalogue has one of each problem the fixture dataset doesn't already have
+const brokenCatalogue = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<catalogue id="cat-fixture-broken" name="Xenos - Fixture Broken" revision="1" battleScribeVersion="2.03" library="false" gameSystemId="sys-352e-adc2-7639" gameSystemRevision="7" type="catalogue" xmlns="http://www.battlescribe.net/schema/catalogueSchema">
+  <catalogueLinks>
+    <catalogueLink id="cl-broken-self" name="Xenos - Fixture Broken" targetId="cat-fixture-broken" type="catalogue" importRootEntries="false"/>
+  </catalogueLinks>
+  <sharedSelectionEntries>
+    <selectionEntry id="se-broken-blank" name="Blank Unit" hidden="false" collective="false" import="true" type="unit"/>
+    <selectionEntry id="se-fixture-captain" name="Copied Captain" hidden="false" collective="false" import="true" type="upgrade"/>
+    <selectionEntry id="se-broken-loop-a" name="Loop A" hidden="false" collective="false" import="true" type="upgrade">
+      <entryLinks>
+        <entryLink id="el-broken-loop-a" name="Loop B" hidden="false" collective="false" import="true" targetId="se-broken-loop-b" type="selectionEntry"/>
+      </entryLinks>
+    </selectionEntry>
+    <selectionEntry id="se-broken-loop-b" name="Loop B" hidden="false" collective="false" import="true" type="upgrade">
+      <entryLinks>
+        <entryLink id="el-broken-loop-b" name="Loop A" hidden="false" collective="false" import="true" targetId="se-broken-loop-a" type="selectionEntry"/>
+      </entryLinks>
+    </selectionEntry>
+  </sharedSelectionEntries>
+  <entryLinks>
+    <entryLink id="el-broken-blank" name="Blank Unit" hidden="false" collective="false" import="true" targetId="se-broken-blank" type="selectionEntry"/>
+  </entryLinks>
+</catalogue>
+`
+
+func loadFixture(t *testing.T, extra map[string]string) *parser.Parser {
+	t.Helper()
+	dir := datatest.CopyFixture(t)
+	for name, content := range extra {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+
+	p := parser.NewParser(dir)
+	p.SetQuiet(true)
+	require.NoError(t, p.LoadGameSystem())
+	require.NoError(t, p.LoadAllCatalogues())
+	return p
+}
+
+// issuesFor returns the issues reported under rule
+func issuesFor(report *models.DataQualityReport, rule string) []models.DataQualityIssue {
+	var issues []models.DataQualityIssue
+	for _, issue := range report.Issues {
+		if issue.Rule == rule {
+			issues = append(issues, issue)
+		}
+	}
+	return issues
+}
+
+func TestRunFixture(t *testing.T) {
+	report := Run(loadFixture(t, nil))
+
+	assert.Equal(t, 5, report.Summary.Files)
+	assert.Equal(t, 7, report.Summary.Units)
+	assert.Equal(t, 1, report.Summary.Errors)
+	assert.Equal(t, 1, report.Summary.Warnings)
+
+	unresolved := issuesFor(report, models.RuleUnresolvedLink)
+	require.Len(t, unresolved, 1)
+	assert.Equal(t, "el-fixture-broken", unresolved[0].EntryID)
+	assert.Equal(t, "se-fixture-does-not-exist", unresolved[0].TargetID)
+	assert.Equal(t, "Imperium - Fixture Marines.cat", unresolved[0].File)
+	assert.Equal(t, 21, unresolved[0].Line)
+	assert.Equal(t, "cat-fixture-marines", unresolved[0].CatalogueID)
+
+	// The Oath of Moment rule is missing; Deep Strike resolves to the game system's shared rule
+	infoLinks := issuesFor(report, models.RuleMissingInfoTarget)
+	require.Len(t, infoLinks, 1)
+	assert.Equal(t, "rule-fixture-oath", infoLinks[0].TargetID)
+	assert.Equal(t, "Library - Fixture Astartes.cat", infoLinks[0].File)
+}
+
+func TestRunReportsLoadErrors(t *testing.T) {
+	dir := datatest.CopyFixture(t)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Xenos - Fixture Unreadable.cat"), []byte("<catalogue"), 0o644))
+
+	p := parser.NewParser(dir)
+	p.SetQuiet(true)
+	require.NoError(t, p.LoadGameSystem())
+	var loadErrs *parser.LoadErrors
+	require.ErrorAs(t, p.LoadAllCatalogues(), &loadErrs)
+
+	report := Run(p)
+	assert.Equal(t, 6, report.Summary.Files)
+	assert.Equal(t, 7, report.Summary.Units)
+	assert.Equal(t, 2, report.Summary.Errors)
+
+	loadErrors := issuesFor(report, models.RuleLoadError)
+	require.Len(t, loadErrors, 1)
+	assert.Equal(t, "Xenos - Fixture Unreadable.cat", loadErrors[0].File)
+	assert.Equal(t, models.SeverityError, loadErrors[0].Severity)
+
+	// The catalogues that loaded are still checked
+	assert.Len(t, issuesFor(report, models.RuleUnresolvedLink), 1)
+}
+
+func TestRunBrokenCatalogue(t *testing.T) {
+	report := Run(loadFixture(t, map[string]string{"Xenos - Fixture Broken.cat": brokenCatalogue}))
+
+	profiles := issuesFor(report, models.RuleMissingUnitProfile)
+	require.Len(t, profiles, 1)
+	assert.Equal(t, "el-broken-blank", profiles[0].EntryID)
+	assert.Equal(t, "Xenos - Fixture Broken.cat", profiles[0].File)
+	assert.Equal(t, 21, profiles[0].Line)
+
+	points := issuesFor(report, models.RuleMissingPoints)
+	require.Len(t, points, 1)
+	assert.Equal(t, "el-broken-blank", points[0].EntryID)
+
+	duplicates := issuesFor(report, models.RuleDuplicateID)
+	require.Len(t, duplicates, 1)
+	assert.Equal(t, "se-fixture-captain", duplicates[0].EntryID)
+	assert.Len(t, duplicates[0].Related, 1)
+
+	cycles := issuesFor(report, models.RuleCyclicLink)
+	require.Len(t, cycles, 2)
+	var related [][]string
+	for _, cycle := range cycles {
+		related = append(related, cycle.Related)
+	}
+	assert.Contains(t, related, []string{"se-broken-loop-a", "se-broken-loop-b"})
+	assert.Contains(t, related, []string{"cat-fixture-broken"})
+}
@@ -0,0 +1,22 @@
+package models
+
+// This file contains JSON response models for the response cache
+
+// CacheStats describes the response cache shared by all snapshots
+type CacheStats struct {
+	Capacity  int              `json:"capacity"` // Maximum total cost: one per unit a value holds
+	Cost      int              `json:"cost"`
+	Entries   int              `json:"entries"`
+	Hits      uint64           `json:"hits"`
+	Misses    uint64           `json:"misses"`
+	Evictions uint64           `json:"evictions"`
+	Kinds     []CacheKindStats `json:"kinds"`
+}
+
+// CacheKindStats counts the cached values of one kind: unit, catalogue, unitList or search
+type CacheKindStats struct {
+	Kind    string `json:"kind"`
+	Entries int    `json:"entries"`
+	Hits    uint64 `json:"hits"`
+	Misses  uint64 `json:"misses"`
+}
@@ -0,0 +1,54 @@
+package models
+
+// This file contains JSON response models for data-quality reports
+
+// Data-quality rules
+const (
+	RuleMissingUnitProfile = "missing-unit-profile"     // Unit with no resolvable Unit profile
+	RuleUnresolvedLink     = "unresolved-entry-link"    // entryLink whose targetId doesn't resolve
+	RuleMissingInfoTarget  = "missing-info-link-target" // infoLink to a missing profile or rule
+	RuleMissingPoints      = "missing-points"           // Unit with no pts cost
+	RuleDuplicateID        = "duplicate-id"             // ID defined in more than one file
+	RuleCyclicLink         = "cyclic-link"              // Catalogue imports or entry links that form a cycle
+	RuleLoadError          = "load-error"               // Catalogue file that failed to parse
+
+	// Only reported as response warnings
+	RuleUnresolvedCatalogueLink = "unresolved-catalogue-link" // catalogueLink to a catalogue that isn't loaded
+	RuleUnparseableCost         = "unparseable-cost"          // Cost value that isn't a number
+)
+
+// Data-quality issue severities
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// DataQualityReport lists the data-quality issues found across every catalogue
+type DataQualityReport struct {
+	Summary DataQualitySummary `json:"summary"`
+	Issues  []DataQualityIssue `json:"issues"`
+}
+
+// DataQualitySummary counts what was checked and what was found
+type DataQualitySummary struct {
+	Files    int            `json:"files"`
+	Units    int            `json:"units"`
+	Errors   int            `json:"errors"`
+	Warnings int            `json:"warnings"`
+	ByRule   map[string]int `json:"byRule"`
+}
+
+// DataQualityIssue is one problem in the data, attributed to the file and catalogue it is in
+type DataQualityIssue struct {
+	Rule          string   `json:"rule"`
+	Severity      string   `json:"severity"`
+	Message       string   `json:"message"`
+	File          string   `json:"file"`
+	Line          int      `json:"line,omitempty"` // Line of the element in File, when it is recorded
+	CatalogueID   string   `json:"catalogueId,omitempty"`
+	CatalogueName string   `json:"catalogueName,omitempty"`
+	EntryID       string   `json:"entryId,omitempty"`
+	EntryName     string   `json:"entryName,omitempty"`
+	TargetID      string   `json:"targetId,omitempty"`
+	Related       []string `json:"related,omitempty"` // Other files or IDs involved, such as the members of a cycle
+}
@@ -0,0 +1,99 @@
+package models
+
+// This file contains JSON response models for data revision diffs
+
+// DataDiff describes what changed between two loaded datasets
+type DataDiff struct {
+	From       DiffSource              `json:"from"`
+	To         DiffSource              `json:"to"`
+	Summary    DiffSummary             `json:"summary"`
+	Catalogues []CatalogueRevisionDiff `json:"catalogues"`
+	Added      []DiffUnitRef           `json:"added"`
+	Removed    []DiffUnitRef           `json:"removed"`
+	Renamed    []UnitRename            `json:"renamed"`
+	Changed    []UnitDiff              `json:"changed"`
+}
+
+// DiffSource identifies one side of a diff
+type DiffSource struct {
+	Label              string `json:"label"`            // Directory or git revision as given
+	Commit             string `json:"commit,omitempty"` // Resolved commit hash for git revisions
+	GameSystemRevision string `json:"gameSystemRevision,omitempty"`
+}
+
+// DiffSummary counts the changes in a diff
+type DiffSummary struct {
+	Added            int `json:"added"`
+	Removed          int `json:"removed"`
+	Renamed          int `json:"renamed"`
+	Changed          int `json:"changed"`
+	PointsChanged    int `json:"pointsChanged"`
+	StatsChanged     int `json:"statsChanged"`
+	WeaponsChanged   int `json:"weaponsChanged"`
+	AbilitiesChanged int `json:"abilitiesChanged"`
+}
+
+// CatalogueRevisionDiff records a catalogue that was added, removed or revised
+type CatalogueRevisionDiff struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	FromRevision string `json:"fromRevision,omitempty"`
+	ToRevision   string `json:"toRevision,omitempty"`
+}
+
+// DiffUnitRef identifies a unit in a diff
+type DiffUnitRef struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Catalogue string `json:"catalogue"`
+}
+
+// UnitRename records a unit whose ID is unchanged but whose name changed
+type UnitRename struct {
+	ID        string `json:"id"`
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Catalogue string `json:"catalogue"`
+}
+
+// UnitDiff lists the changes to a single unit
+type UnitDiff struct {
+	ID        string        `json:"id"`
+	Name      string        `json:"name"`
+	Catalogue string        `json:"catalogue"`
+	Points    *PointsDiff   `json:"points,omitempty"`
+	Stats     []FieldDiff   `json:"stats,omitempty"`
+	Weapons   []WeaponDiff  `json:"weapons,omitempty"`
+	Abilities []AbilityDiff `json:"abilities,omitempty"`
+}
+
+// PointsDiff records a change in base points or tiered costs
+type PointsDiff struct {
+	From      int        `json:"from"`
+	To        int        `json:"to"`
+	FromTiers []CostTier `json:"fromTiers,omitempty"`
+	ToTiers   []CostTier `json:"toTiers,omitempty"`
+}
+
+// FieldDiff records a single changed value
+type FieldDiff struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// WeaponDiff records an added, removed or changed weapon profile
+type WeaponDiff struct {
+	Name   string      `json:"name"`
+	Type   string      `json:"type"`   // "ranged" or "melee"
+	Change string      `json:"change"` // "added", "removed" or "changed"
+	Fields []FieldDiff `json:"fields,omitempty"`
+}
+
+// AbilityDiff records an added, removed or reworded ability
+type AbilityDiff struct {
+	Name   string `json:"name"`
+	Change string `json:"change"` // "added", "removed" or "changed"
+	From   string `json:"from,omitempty"`
+	To     string `json:"to,omitempty"`
+}
@@ -0,0 +1,58 @@
+package models
+
+// This file contains JSON response models for explaining how a unit was resolved
+
+// UnitExplanation describes where each part of a transformed unit came from
+type UnitExplanation struct {
+	ID             string              `json:"id"`
+	Name           string              `json:"name"`
+	EntryLink      *SourceRef          `json:"entryLink,omitempty"` // Root entryLink the unit was found through
+	SelectionEntry SourceRef           `json:"selectionEntry"`      // selectionEntry the unit resolved to
+	Overrides      []EntryLinkOverride `json:"overrides"`           // Values the entryLink merged over the selectionEntry
+	Profiles       []ExplainedValue    `json:"profiles"`
+	Weapons        []ExplainedValue    `json:"weapons"`
+	Costs          []ExplainedValue    `json:"costs"`
+	Categories     []ExplainedValue    `json:"categories"`
+	Modifiers      []ExplainedModifier `json:"modifiers"`
+	Unit           *UnitResponse       `json:"unit"` // The unit as GET /units/:id returns it
+}
+
+// SourceRef identifies an element in the data and the file it is defined in
+type SourceRef struct {
+	Kind        string `json:"kind"` // selectionEntry, selectionEntryGroup, entryLink, infoLink, profile, ...
+	ID          string `json:"id"`
+	Name        string `json:"name,omitempty"`
+	CatalogueID string `json:"catalogueId,omitempty"`
+	File        string `json:"file,omitempty"`
+	Line        int    `json:"line,omitempty"`   // Line the element's start tag ends on, when recorded
+	Offset      int64  `json:"offset,omitempty"` // Byte offset just past the start tag
+}
+
+// ExplainedValue is one profile, weapon, cost or category with the element that defined it
+type ExplainedValue struct {
+	ID     string      `json:"id"`
+	Name   string      `json:"name"`
+	Type   string      `json:"type"`
+	Value  string      `json:"value,omitempty"`
+	Source SourceRef   `json:"source"`
+	Chain  []SourceRef `json:"chain"` // Elements followed from the unit down to the source
+}
+
+// ExplainedModifier is a modifier on the unit and whether the transformer applied it
+type ExplainedModifier struct {
+	Type    string    `json:"type"`
+	Field   string    `json:"field"`
+	Value   string    `json:"value"`
+	Applied bool      `json:"applied"`
+	Effect  string    `json:"effect,omitempty"` // What the modifier changed, such as tieredCosts
+	Source  SourceRef `json:"source"`
+}
+
+// EntryLinkOverride is one value MergeEntryLinkWithSelectionEntry took from the entryLink
+type EntryLinkOverride struct {
+	Field    string `json:"field"`  // name, costs, categoryLinks, constraints or modifiers
+	Action   string `json:"action"` // replaced or added
+	ID       string `json:"id,omitempty"`
+	Original string `json:"original,omitempty"`
+	Value    string `json:"value"`
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// This file contains JSON response models for unit points history
+
+// UnitHistory is how a unit's points moved across revisions of the data
+type UnitHistory struct {
+	ID        string         `json:"id"`        // Catalogue entryLink ID, stable across renames
+	EntryID   string         `json:"entryId"`   // Target selectionEntry ID
+	Name      string         `json:"name"`      // Name at the latest indexed revision
+	Catalogue string         `json:"catalogue"` // Catalogue at the latest indexed revision
+	Points    []PointsRecord `json:"points"`
+}
+
+// PointsRecord is a unit's cost at one revision. A record is only kept when the cost,
+// the name or the catalogue revision changed since the previous one.
+type PointsRecord struct {
+	Commit            string     `json:"commit"`
+	Date              time.Time  `json:"date"`
+	CatalogueRevision string     `json:"catalogueRevision"`
+	Name              string     `json:"name"`
+	Points            int        `json:"points"`
+	Tiers             []CostTier `json:"tiers,omitempty"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// This file contains JSON response models for data loading
+
+// LoadReport describes one load of the catalogue files in the data directory
+type LoadReport struct {
+	Files    int           `json:"files"`  // Catalogue files found
+	Loaded   int           `json:"loaded"` // Files that parsed and are served
+	Errors   []LoadError   `json:"errors"`
+	Duration time.Duration `json:"-"`
+}
+
+// LoadError is a catalogue file that failed to load
+type LoadError struct {
+	File  string `json:"file"` // Relative to the data directory
+	Error string `json:"error"`
+}
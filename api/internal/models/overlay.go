@@ -0,0 +1,37 @@
+package models
+
+// This file contains models for local data overlays
+
+// OverlayMark records a value an overlay changed. Marks are attached to the patched elements
+// and returned with the units that use them.
+type OverlayMark struct {
+	Overlay  string `json:"overlay"`
+	TargetID string `json:"targetId"`
+	Field    string `json:"field"` // name, costs.<name> or characteristics.<name>
+	Original string `json:"original,omitempty"`
+	Value    string `json:"value"`
+}
+
+// OverlayReport lists the overlays applied after loading and the patches that could not be applied
+type OverlayReport struct {
+	Overlays  []OverlaySummary  `json:"overlays"`
+	Conflicts []OverlayConflict `json:"conflicts"`
+}
+
+// OverlaySummary describes one overlay file
+type OverlaySummary struct {
+	Name        string `json:"name"`
+	File        string `json:"file"`
+	Description string `json:"description,omitempty"`
+	Patches     int    `json:"patches"` // Patches in the file
+	Applied     int    `json:"applied"` // Values changed, counting each element a patch matched
+}
+
+// OverlayConflict is a patch, or one value in a patch, that could not be applied
+type OverlayConflict struct {
+	Overlay  string `json:"overlay"`
+	File     string `json:"file"`
+	TargetID string `json:"targetId"`
+	Field    string `json:"field,omitempty"`
+	Reason   string `json:"reason"`
+}
@@ -4,16 +4,18 @@ import "encoding/xml"
 
 // Profile represents a unit, weapon, ability, or transport profile
 type Profile struct {
-	XMLName        xml.Name         `xml:"profile"`
-	ID             string           `xml:"id,attr"`
-	Name           string           `xml:"name,attr"`
-	TypeID         string           `xml:"typeId,attr"`
-	TypeName       string           `xml:"typeName,attr"`
-	Hidden         string           `xml:"hidden,attr"`
-	PublicationID  string           `xml:"publicationId,attr"`
-	Page           string           `xml:"page,attr"`
+	XMLName         xml.Name         `xml:"profile"`
+	ID              string           `xml:"id,attr"`
+	Name            string           `xml:"name,attr"`
+	TypeID          string           `xml:"typeId,attr"`
+	TypeName        string           `xml:"typeName,attr"`
+	Hidden          bool             `xml:"hidden,attr"`
+	PublicationID   string           `xml:"publicationId,attr"`
+	Page            string           `xml:"page,attr"`
 	Characteristics []Characteristic `xml:"characteristics>characteristic"`
-	Modifiers      []Modifier       `xml:"modifiers>modifier"`
+	Modifiers       []Modifier       `xml:"modifiers>modifier"`
+	Pos             SourcePos        `xml:"-"`
+	Overlays        []OverlayMark    `xml:"-"`
 }
 
 // Characteristic represents a single characteristic value
@@ -24,4 +26,11 @@ type Characteristic struct {
 	Value   string   `xml:",chardata"`
 }
 
-
+// Rule represents a shared game rule that infoLinks can reference
+type Rule struct {
+	XMLName     xml.Name `xml:"rule"`
+	ID          string   `xml:"id,attr"`
+	Name        string   `xml:"name,attr"`
+	Hidden      bool     `xml:"hidden,attr"`
+	Description string   `xml:"description"`
+}
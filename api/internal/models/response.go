@@ -4,19 +4,21 @@ package models
 
 // UnitResponse represents a unit in API responses
 type UnitResponse struct {
-	ID          string                 `json:"id"`
-	Name        string                 `json:"name"`
-	Type        string                 `json:"type"`
-	Publication *PublicationInfo       `json:"publication,omitempty"`
-	Profiles    *UnitProfiles          `json:"profiles"`
-	Weapons     *WeaponSet             `json:"weapons"`
-	Categories  []CategoryInfo         `json:"categories"`
-	Rules       []RuleInfo             `json:"rules"`
-	Costs       map[string]int         `json:"costs"`
-	TieredCosts *TieredCosts           `json:"tieredCosts,omitempty"`
-	Constraints *UnitConstraints       `json:"constraints,omitempty"`
-	Faction     *FactionInfo          `json:"faction,omitempty"`
-	Catalogue   *CatalogueInfo        `json:"catalogue,omitempty"`
+	ID          string              `json:"id"`
+	Name        string              `json:"name"`
+	Type        string              `json:"type"`
+	Publication *PublicationInfo    `json:"publication,omitempty"`
+	Profiles    *UnitProfiles       `json:"profiles"`
+	Weapons     *WeaponSet          `json:"weapons"`
+	Categories  []CategoryInfo      `json:"categories"`
+	Rules       []RuleInfo          `json:"rules"`
+	Costs       map[string]int      `json:"costs"`
+	TieredCosts *TieredCosts        `json:"tieredCosts,omitempty"`
+	Constraints *UnitConstraints    `json:"constraints,omitempty"`
+	Faction     *FactionInfo        `json:"faction,omitempty"`
+	Catalogue   *CatalogueInfo      `json:"catalogue,omitempty"`
+	Warnings    []ResolutionWarning `json:"warnings,omitempty"` // Only returned with ?debug=true
+	Overlays    []OverlayMark       `json:"overlays,omitempty"` // Values changed by local overlays
 }
 
 // UnitProfiles contains all profile types for a unit
@@ -60,16 +62,36 @@ type CategoryInfo struct {
 	Primary bool   `json:"primary"`
 }
 
+// ResolutionWarning explains why a link or value was left out of a response
+type ResolutionWarning struct {
+	Code        string `json:"code"` // One of the data-quality rules, such as unresolved-entry-link
+	Message     string `json:"message"`
+	CatalogueID string `json:"catalogueId,omitempty"`
+	EntryID     string `json:"entryId,omitempty"` // Entry containing the failed link or value
+	LinkID      string `json:"linkId,omitempty"`
+	TargetID    string `json:"targetId,omitempty"`
+	File        string `json:"file,omitempty"` // File the link or value is defined in
+	Line        int    `json:"line,omitempty"`
+}
+
 // RuleInfo represents a game rule reference
 type RuleInfo struct {
 	ID   string `json:"id"`
 	Name string `json:"name"`
 }
 
+// RuleResponse represents a shared rule in API responses
+type RuleResponse struct {
+	ID          string         `json:"id"`
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Catalogue   *CatalogueInfo `json:"catalogue,omitempty"` // Catalogue or library defining it; none for the game system
+}
+
 // TieredCosts represents costs that vary based on model count
 type TieredCosts struct {
-	BaseCost int          `json:"baseCost"` // Base cost (minimum model count)
-	Tiers    []CostTier   `json:"tiers"`    // Cost tiers based on model count
+	BaseCost int        `json:"baseCost"` // Base cost (minimum model count)
+	Tiers    []CostTier `json:"tiers"`    // Cost tiers based on model count
 }
 
 // CostTier represents a cost tier
@@ -88,11 +110,11 @@ type UnitConstraints struct {
 
 // PublicationInfo represents publication metadata
 type PublicationInfo struct {
-	ID             string `json:"id"`
-	Name           string `json:"name"`
-	ShortName      string `json:"shortName,omitempty"`
+	ID              string `json:"id"`
+	Name            string `json:"name"`
+	ShortName       string `json:"shortName,omitempty"`
 	PublicationDate string `json:"publicationDate,omitempty"`
-	Page           string `json:"page,omitempty"`
+	Page            string `json:"page,omitempty"`
 }
 
 // FactionInfo represents faction information
@@ -107,61 +129,100 @@ type CatalogueInfo struct {
 	Name     string `json:"name"`
 	Revision string `json:"revision"`
 	Library  bool   `json:"library"`
+	Homebrew bool   `json:"homebrew,omitempty"` // Uploaded through POST /api/v1/catalogues
 }
 
 // CatalogueResponse represents a catalogue in API responses
 type CatalogueResponse struct {
-	ID                string            `json:"id"`
-	Name              string            `json:"name"`
-	Revision          string            `json:"revision"`
-	Library           bool              `json:"library"`
-	GameSystemID      string            `json:"gameSystemId"`
-	LinkedCatalogues  []CatalogueInfo   `json:"linkedCatalogues"`
-	Units             []UnitSummary     `json:"units"`
-	Publications      []PublicationInfo `json:"publications"`
+	ID               string              `json:"id"`
+	Name             string              `json:"name"`
+	Revision         string              `json:"revision"`
+	Library          bool                `json:"library"`
+	GameSystemID     string              `json:"gameSystemId"`
+	LinkedCatalogues []CatalogueInfo     `json:"linkedCatalogues"`
+	Units            []UnitSummary       `json:"units"`
+	Publications     []PublicationInfo   `json:"publications"`
+	Warnings         []ResolutionWarning `json:"warnings,omitempty"` // Only returned with ?debug=true
+}
+
+// CatalogueGraphResponse represents the catalogueLink import graph
+type CatalogueGraphResponse struct {
+	Nodes  []CatalogueGraphNode `json:"nodes"`
+	Edges  []CatalogueGraphEdge `json:"edges"`
+	Cycles [][]string           `json:"cycles"`
+}
+
+// CatalogueGraphNode represents a catalogue or library in the import graph
+type CatalogueGraphNode struct {
+	ID                string   `json:"id"`
+	Name              string   `json:"name"`
+	Revision          string   `json:"revision"`
+	Library           bool     `json:"library"`
+	Imports           []string `json:"imports"`
+	ImportedBy        []string `json:"importedBy"`
+	TransitiveImports []string `json:"transitiveImports"`
+	Dependents        []string `json:"dependents"`
+	InCycle           bool     `json:"inCycle"`
+}
+
+// CatalogueGraphEdge represents a catalogueLink between two catalogues
+type CatalogueGraphEdge struct {
+	From              string `json:"from"`
+	To                string `json:"to"`
+	Name              string `json:"name"`
+	ImportRootEntries bool   `json:"importRootEntries"`
+	Resolved          bool   `json:"resolved"`
 }
 
 // UnitSummary represents a summary of a unit (for lists)
 type UnitSummary struct {
-	ID          string       `json:"id"`
-	Name        string       `json:"name"`
-	TargetID    string       `json:"targetId,omitempty"`
+	ID          string         `json:"id"`
+	Name        string         `json:"name"`
+	TargetID    string         `json:"targetId,omitempty"`
 	Costs       map[string]int `json:"costs"`
-	TieredCosts *TieredCosts `json:"tieredCosts,omitempty"`
-	Type        string       `json:"type,omitempty"`
+	TieredCosts *TieredCosts   `json:"tieredCosts,omitempty"`
+	Type        string         `json:"type,omitempty"`
+	Catalogue   *CatalogueInfo `json:"catalogue,omitempty"`
+	Overlays    []OverlayMark  `json:"overlays,omitempty"` // Values changed by local overlays
 }
 
 // GameSystemResponse represents game system information
 type GameSystemResponse struct {
-	ID              string          `json:"id"`
-	Name            string          `json:"name"`
-	Revision        string          `json:"revision"`
-	BattleScribeVersion string      `json:"battleScribeVersion"`
-	ProfileTypes    []ProfileTypeInfo `json:"profileTypes"`
-	Categories      []CategoryInfo  `json:"categories"`
-	CostTypes       []CostTypeInfo  `json:"costTypes"`
+	ID                  string            `json:"id"`
+	Name                string            `json:"name"`
+	Revision            string            `json:"revision"`
+	BattleScribeVersion string            `json:"battleScribeVersion"`
+	ProfileTypes        []ProfileTypeInfo `json:"profileTypes"`
+	Categories          []CategoryInfo    `json:"categories"`
+	CostTypes           []CostTypeInfo    `json:"costTypes"`
 }
 
 // ProfileTypeInfo represents a profile type
 type ProfileTypeInfo struct {
-	ID                string   `json:"id"`
-	Name              string   `json:"name"`
-	Characteristics   []string `json:"characteristics"`
+	ID              string   `json:"id"`
+	Name            string   `json:"name"`
+	Characteristics []string `json:"characteristics"`
 }
 
 // CostTypeInfo represents a cost type
 type CostTypeInfo struct {
-	ID              string `json:"id"`
-	Name            string `json:"name"`
+	ID               string `json:"id"`
+	Name             string `json:"name"`
 	DefaultCostLimit string `json:"defaultCostLimit"`
-	Hidden          bool   `json:"hidden"`
+	Hidden           bool   `json:"hidden"`
 }
 
-// FactionResponse represents faction information
+// FactionResponse represents a faction derived from faction keyword categories
 type FactionResponse struct {
-	Name     string         `json:"name"`
-	Catalogues []CatalogueInfo `json:"catalogues"`
-	Units    []UnitSummary  `json:"units"`
+	ID           string          `json:"id"`
+	Name         string          `json:"name"`
+	SuperFaction string          `json:"superFaction,omitempty"`
+	ParentID     string          `json:"parentId,omitempty"`
+	Keyword      *CategoryInfo   `json:"keyword,omitempty"`
+	Catalogue    CatalogueInfo   `json:"catalogue"`
+	SubFactions  []CatalogueInfo `json:"subFactions"`
+	Libraries    []CatalogueInfo `json:"libraries"`
+	UnitCount    int             `json:"unitCount"`
 }
 
 // SearchResponse represents search results
@@ -174,10 +235,8 @@ type SearchResponse struct {
 
 // SearchResult represents a single search result
 type SearchResult struct {
-	Type     string `json:"type"` // "unit", "weapon", "ability"
-	ID       string `json:"id"`
-	Name     string `json:"name"`
-	Summary  string `json:"summary,omitempty"`
+	Type    string `json:"type"` // "unit", "weapon", "ability"
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Summary string `json:"summary,omitempty"`
 }
-
-
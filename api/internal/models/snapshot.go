@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// This file contains JSON response models for data snapshots
+
+// SnapshotInfo describes one loaded snapshot of the data
+type SnapshotInfo struct {
+	Revision   string    `json:"revision"` // Send as ?snapshot= or X-Data-Revision to pin requests to this snapshot
+	LoadedAt   time.Time `json:"loadedAt"`
+	Compiled   bool      `json:"compiled"` // Loaded from a compiled snapshot file instead of parsing the XML
+	Database   bool      `json:"database"` // Served from a database written by grimoire import
+	Catalogues int       `json:"catalogues"`
+	Libraries  int       `json:"libraries"`
+	Current    bool      `json:"current"`
+}
+
+// Readiness reports whether the current snapshot has finished warming up
+type Readiness struct {
+	Status   string `json:"status"` // ready or warming
+	Revision string `json:"revision"`
+	Warmed   int    `json:"warmed"` // Units transformed so far
+	Units    int    `json:"units"`  // Units to transform, once known
+}
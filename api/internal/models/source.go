@@ -0,0 +1,62 @@
+package models
+
+import "encoding/xml"
+
+// This file records where elements were found while decoding BattleScribe XML
+
+// SourcePos is the position of an element in the file it was decoded from.
+// Both values point just past the element's start tag, so Line is the line the start tag ends on.
+type SourcePos struct {
+	Line   int   // 1-based
+	Offset int64 // Bytes from the start of the file
+}
+
+// positionOf returns the decoder's current position, just after a start tag has been read
+func positionOf(d *xml.Decoder) SourcePos {
+	line, _ := d.InputPos()
+	return SourcePos{Line: line, Offset: d.InputOffset()}
+}
+
+// UnmarshalXML decodes a selectionEntry and records its position
+func (e *SelectionEntry) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain SelectionEntry
+	pos := positionOf(d)
+	if err := d.DecodeElement((*plain)(e), &start); err != nil {
+		return err
+	}
+	e.Pos = pos
+	return nil
+}
+
+// UnmarshalXML decodes an entryLink and records its position
+func (l *EntryLink) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain EntryLink
+	pos := positionOf(d)
+	if err := d.DecodeElement((*plain)(l), &start); err != nil {
+		return err
+	}
+	l.Pos = pos
+	return nil
+}
+
+// UnmarshalXML decodes a profile and records its position
+func (p *Profile) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain Profile
+	pos := positionOf(d)
+	if err := d.DecodeElement((*plain)(p), &start); err != nil {
+		return err
+	}
+	p.Pos = pos
+	return nil
+}
+
+// UnmarshalXML decodes a cost and records its position
+func (c *Cost) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain Cost
+	pos := positionOf(d)
+	if err := d.DecodeElement((*plain)(c), &start); err != nil {
+		return err
+	}
+	c.Pos = pos
+	return nil
+}
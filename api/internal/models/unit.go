@@ -7,9 +7,9 @@ type SelectionEntry struct {
 	XMLName              xml.Name              `xml:"selectionEntry"`
 	ID                   string                `xml:"id,attr"`
 	Name                 string                `xml:"name,attr"`
-	Hidden               string                `xml:"hidden,attr"`
-	Collective           string                `xml:"collective,attr"`
-	Import               string                `xml:"import,attr"`
+	Hidden               bool                  `xml:"hidden,attr"`
+	Collective           bool                  `xml:"collective,attr"`
+	Import               bool                  `xml:"import,attr"`
 	Type                 string                `xml:"type,attr"`
 	PublicationID        string                `xml:"publicationId,attr"`
 	Page                 string                `xml:"page,attr"`
@@ -25,6 +25,8 @@ type SelectionEntry struct {
 	Modifiers            []Modifier            `xml:"modifiers>modifier"`
 	ModifierGroups       []ModifierGroup       `xml:"modifierGroups>modifierGroup"`
 	Comment              string                `xml:"comment"`
+	Pos                  SourcePos             `xml:"-"`
+	Overlays             []OverlayMark         `xml:"-"`
 }
 
 // SelectionEntryGroup groups related selection entries
@@ -32,9 +34,9 @@ type SelectionEntryGroup struct {
 	XMLName              xml.Name              `xml:"selectionEntryGroup"`
 	ID                   string                `xml:"id,attr"`
 	Name                 string                `xml:"name,attr"`
-	Hidden               string                `xml:"hidden,attr"`
-	Collapsible          string                `xml:"collapsible,attr"`
-	Flatten              string                `xml:"flatten,attr"`
+	Hidden               bool                  `xml:"hidden,attr"`
+	Collapsible          bool                  `xml:"collapsible,attr"`
+	Flatten              bool                  `xml:"flatten,attr"`
 	SortIndex            string                `xml:"sortIndex,attr"`
 	SelectionEntries     []SelectionEntry      `xml:"selectionEntries>selectionEntry"`
 	SelectionEntryGroups []SelectionEntryGroup `xml:"selectionEntryGroups>selectionEntryGroup"`
@@ -45,94 +47,93 @@ type SelectionEntryGroup struct {
 
 // Constraint enforces game rules
 type Constraint struct {
-	XMLName              xml.Name `xml:"constraint"`
-	ID                   string   `xml:"id,attr"`
-	Type                 string   `xml:"type,attr"`
-	Value                string   `xml:"value,attr"`
-	Field                string   `xml:"field,attr"`
-	Scope                string   `xml:"scope,attr"`
-	Shared               string   `xml:"shared,attr"`
-	IncludeChildSelections string `xml:"includeChildSelections,attr"`
-	IncludeChildForces    string  `xml:"includeChildForces,attr"`
-	PercentValue         string   `xml:"percentValue,attr"`
-	Negative             string   `xml:"negative,attr"`
+	XMLName                xml.Name `xml:"constraint"`
+	ID                     string   `xml:"id,attr"`
+	Type                   string   `xml:"type,attr"`
+	Value                  string   `xml:"value,attr"`
+	Field                  string   `xml:"field,attr"`
+	Scope                  string   `xml:"scope,attr"`
+	Shared                 bool     `xml:"shared,attr"`
+	IncludeChildSelections bool     `xml:"includeChildSelections,attr"`
+	IncludeChildForces     bool     `xml:"includeChildForces,attr"`
+	PercentValue           bool     `xml:"percentValue,attr"`
+	Negative               bool     `xml:"negative,attr"`
 }
 
 // Modifier applies conditional changes
 type Modifier struct {
-	XMLName         xml.Name       `xml:"modifier"`
-	ID              string         `xml:"id,attr"`
-	Type            string         `xml:"type,attr"`
-	Value           string         `xml:"value,attr"`
-	Field           string         `xml:"field,attr"`
-	Scope           string         `xml:"scope,attr"`
-	Affects         string         `xml:"affects,attr"`
-	Join            string         `xml:"join,attr"`
-	Conditions      []Condition    `xml:"conditions>condition"`
+	XMLName         xml.Name         `xml:"modifier"`
+	ID              string           `xml:"id,attr"`
+	Type            string           `xml:"type,attr"`
+	Value           string           `xml:"value,attr"`
+	Field           string           `xml:"field,attr"`
+	Scope           string           `xml:"scope,attr"`
+	Affects         string           `xml:"affects,attr"`
+	Join            string           `xml:"join,attr"`
+	Conditions      []Condition      `xml:"conditions>condition"`
 	ConditionGroups []ConditionGroup `xml:"conditionGroups>conditionGroup"`
-	Repeats         []Repeat       `xml:"repeats>repeat"`
+	Repeats         []Repeat         `xml:"repeats>repeat"`
 }
 
 // ModifierGroup groups related modifiers
 type ModifierGroup struct {
-	XMLName         xml.Name       `xml:"modifierGroup"`
-	ID              string         `xml:"id,attr"`
-	Type            string         `xml:"type,attr"`
-	Modifiers       []Modifier     `xml:"modifiers>modifier"`
+	XMLName         xml.Name         `xml:"modifierGroup"`
+	ID              string           `xml:"id,attr"`
+	Type            string           `xml:"type,attr"`
+	Modifiers       []Modifier       `xml:"modifiers>modifier"`
 	ConditionGroups []ConditionGroup `xml:"conditionGroups>conditionGroup"`
-	Comment         string         `xml:"comment"`
+	Comment         string           `xml:"comment"`
 }
 
 // Condition defines a condition for modifiers
 type Condition struct {
-	XMLName              xml.Name `xml:"condition"`
-	ID                   string   `xml:"id,attr"`
-	Type                 string   `xml:"type,attr"`
-	Value                string   `xml:"value,attr"`
-	Field                string   `xml:"field,attr"`
-	Scope                string   `xml:"scope,attr"`
-	ChildID              string   `xml:"childId,attr"`
-	Shared               string   `xml:"shared,attr"`
-	IncludeChildSelections string `xml:"includeChildSelections,attr"`
-	IncludeChildForces    string  `xml:"includeChildForces,attr"`
-	PercentValue         string   `xml:"percentValue,attr"`
+	XMLName                xml.Name `xml:"condition"`
+	ID                     string   `xml:"id,attr"`
+	Type                   string   `xml:"type,attr"`
+	Value                  string   `xml:"value,attr"`
+	Field                  string   `xml:"field,attr"`
+	Scope                  string   `xml:"scope,attr"`
+	ChildID                string   `xml:"childId,attr"`
+	Shared                 bool     `xml:"shared,attr"`
+	IncludeChildSelections bool     `xml:"includeChildSelections,attr"`
+	IncludeChildForces     bool     `xml:"includeChildForces,attr"`
+	PercentValue           bool     `xml:"percentValue,attr"`
 }
 
 // ConditionGroup groups conditions with AND/OR logic
 type ConditionGroup struct {
-	XMLName         xml.Name       `xml:"conditionGroup"`
-	ID              string         `xml:"id,attr"`
-	Type            string         `xml:"type,attr"`
-	Conditions      []Condition    `xml:"conditions>condition"`
+	XMLName         xml.Name         `xml:"conditionGroup"`
+	ID              string           `xml:"id,attr"`
+	Type            string           `xml:"type,attr"`
+	Conditions      []Condition      `xml:"conditions>condition"`
 	ConditionGroups []ConditionGroup `xml:"conditionGroups>conditionGroup"`
 }
 
 // Repeat defines a repeat pattern for modifiers
 type Repeat struct {
-	XMLName xml.Name `xml:"repeat"`
-	Value   string   `xml:"value,attr"`
-	Repeats string   `xml:"repeats,attr"`
-	Field   string   `xml:"field,attr"`
-	RoundUp string   `xml:"roundUp,attr"`
-	IncludeChildSelections string `xml:"includeChildSelections,attr"`
+	XMLName                xml.Name `xml:"repeat"`
+	Value                  string   `xml:"value,attr"`
+	Repeats                string   `xml:"repeats,attr"`
+	Field                  string   `xml:"field,attr"`
+	RoundUp                bool     `xml:"roundUp,attr"`
+	IncludeChildSelections bool     `xml:"includeChildSelections,attr"`
 }
 
 // Cost represents a point cost or other resource cost
 type Cost struct {
-	XMLName xml.Name `xml:"cost"`
-	Name    string   `xml:"name,attr"`
-	TypeID  string   `xml:"typeId,attr"`
-	Value   string   `xml:"value,attr"`
+	XMLName xml.Name  `xml:"cost"`
+	Name    string    `xml:"name,attr"`
+	TypeID  string    `xml:"typeId,attr"`
+	Value   string    `xml:"value,attr"`
+	Pos     SourcePos `xml:"-"`
 }
 
 // InfoLink references a game rule
 type InfoLink struct {
-	XMLName xml.Name `xml:"infoLink"`
-	ID      string   `xml:"id,attr"`
-	Name    string   `xml:"name,attr"`
-	TargetID string  `xml:"targetId,attr"`
-	Type    string   `xml:"type,attr"`
-	Hidden  string   `xml:"hidden,attr"`
+	XMLName  xml.Name `xml:"infoLink"`
+	ID       string   `xml:"id,attr"`
+	Name     string   `xml:"name,attr"`
+	TargetID string   `xml:"targetId,attr"`
+	Type     string   `xml:"type,attr"`
+	Hidden   bool     `xml:"hidden,attr"`
 }
-
-
@@ -0,0 +1,182 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Schema is an OpenAPI 3.0 schema object, restricted to what the response models need
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties interface{}        `json:"additionalProperties,omitempty"` // false, or the *Schema of map values
+}
+
+// schemaRef is the prefix of references to component schemas
+const schemaRef = "#/components/schemas/"
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// schemas reflects Go types into component schemas, named after their types
+type schemas struct {
+	components map[string]*Schema
+	types      map[string]reflect.Type
+}
+
+func newSchemas() *schemas {
+	return &schemas{components: make(map[string]*Schema), types: make(map[string]reflect.Type)}
+}
+
+// of returns the schema of values of t as encoding/json marshals them. Named structs become
+// components and are referenced; a nil pointer, slice or map is null only where nullable is set.
+func (s *schemas) of(t reflect.Type) *Schema {
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return s.of(t.Elem())
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Interface:
+		return &Schema{}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: s.of(t.Elem())}
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			panic(fmt.Sprintf("openapi: map key of %s is not a string", t))
+		}
+		return &Schema{Type: "object", AdditionalProperties: s.of(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.object(t)
+		}
+		return s.component(t)
+	}
+	panic(fmt.Sprintf("openapi: no schema for %s", t))
+}
+
+// component registers a named struct as a component schema and references it
+func (s *schemas) component(t reflect.Type) *Schema {
+	name := t.Name()
+	if strings.ContainsAny(name, "[]") {
+		panic(fmt.Sprintf("openapi: generic type %s can't be a component", t))
+	}
+	if existing, found := s.types[name]; found {
+		if existing != t {
+			panic(fmt.Sprintf("openapi: %s and %s are both named %s", existing, t, name))
+		}
+		return &Schema{Ref: schemaRef + name}
+	}
+	s.types[name] = t
+	s.components[name] = nil // Registered before its fields, so recursive types refer to it
+	s.components[name] = s.object(t)
+	return &Schema{Ref: schemaRef + name}
+}
+
+// object is the schema of a struct's JSON fields, embedded structs included. Fields without
+// omitempty are always present, and no other properties are allowed.
+func (s *schemas) object(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema), AdditionalProperties: false}
+	s.fields(t, schema)
+	return schema
+}
+
+func (s *schemas) fields(t reflect.Type, schema *Schema) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				s.fields(embedded, schema)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property := s.of(field.Type)
+		omitEmpty := strings.Contains(","+options+",", ",omitempty,")
+		if !omitEmpty {
+			schema.Required = append(schema.Required, name)
+			switch field.Type.Kind() {
+			case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface:
+				property = nullable(property)
+			}
+		}
+		schema.Properties[name] = property
+	}
+}
+
+// nullable allows null besides the values of schema. A reference can't carry nullable in
+// OpenAPI 3.0, so it is wrapped.
+func nullable(schema *Schema) *Schema {
+	if schema.Ref != "" {
+		return &Schema{Nullable: true, AllOf: []*Schema{schema}}
+	}
+	if schema.Type == "" {
+		return schema
+	}
+	schema.Nullable = true
+	return schema
+}
+
+// UnmarshalJSON decodes additionalProperties as false or a *Schema, so a decoded document
+// validates like a generated one
+func (s *Schema) UnmarshalJSON(data []byte) error {
+	type plain Schema
+	var decoded struct {
+		*plain
+		AdditionalProperties json.RawMessage `json:"additionalProperties,omitempty"`
+	}
+	decoded.plain = (*plain)(s)
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	s.AdditionalProperties = nil
+	switch additional := decoded.AdditionalProperties; {
+	case len(additional) == 0:
+	case string(additional) == "true" || string(additional) == "false":
+		s.AdditionalProperties = string(additional) == "true"
+	default:
+		var schema Schema
+		if err := json.Unmarshal(additional, &schema); err != nil {
+			return err
+		}
+		s.AdditionalProperties = &schema
+	}
+	return nil
+}
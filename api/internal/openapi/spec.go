@@ -0,0 +1,470 @@
+// Package openapi describes the API as an OpenAPI 3 document. Schemas are reflected from the
+// response models, operations from the routes registered on the router, so the document can't
+// drift from the code without Generate or the contract test failing.
+package openapi
+
+import (
+	_ "embed"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"grimoire-api/internal/graphql"
+	"grimoire-api/internal/models"
+	"grimoire-api/pkg/response"
+)
+
+// Version is the OpenAPI version of the generated document
+const Version = "3.0.3"
+
+// Paths of the document and of the docs page rendering it
+const (
+	SpecPath = "/api/v1/openapi.json"
+	DocsPath = "/api/v1/docs"
+)
+
+// DocsPage is a self-contained page that renders the document served at SpecPath
+//
+//go:embed docs.html
+var DocsPage []byte
+
+// Document is an OpenAPI document
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+// Info describes the API
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem holds the operations of a path, keyed by lower-case HTTP method
+type PathItem map[string]*Operation
+
+// Operation is one route
+type Operation struct {
+	OperationID string                `json:"operationId"`
+	Summary     string                `json:"summary"`
+	Tags        []string              `json:"tags"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+// Parameter is a path or query parameter
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+	Explode     *bool   `json:"explode,omitempty"`
+}
+
+// RequestBody is the body an operation accepts
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response is one status of an operation
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType is the schema of a body in one content type
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components holds the schemas and security schemes operations refer to
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes"`
+}
+
+// SecurityScheme describes how a token is sent
+type SecurityScheme struct {
+	Type   string `json:"type"`
+	Scheme string `json:"scheme"`
+}
+
+const (
+	jsonType = "application/json"
+	apiPath  = "/api/v1"
+)
+
+// route documents one route registered in main.go
+type route struct {
+	summary  string
+	tag      string
+	query    []Parameter
+	data     interface{} // Zero value of the data in the response envelope; nil for no body
+	body     interface{} // Zero value of a body sent without the envelope, on success and the listed errors
+	request  interface{} // Zero value of the JSON request body
+	list     bool        // Paginated envelope with total, limit, offset and hasMore
+	warnings bool        // Lists resolution warnings with ?debug=true
+	status   int         // Success status, 200 unless set
+	errors   []int
+	media    []string // Other content types of the success response, as strings or files
+	upload   bool     // Multipart upload of a catalogue file
+	token    bool     // Needs a bearer token
+}
+
+var (
+	debug     = queryParam("debug", "true lists resolution warnings for links and values left out of the response", "boolean")
+	exploding = true
+)
+
+// pathParams describes the path parameters of the routes
+var pathParams = map[string]string{
+	"id":     "ID, as listed by the collection",
+	"name":   "Faction ID or name",
+	"format": "csv, ndjson or sqlite",
+}
+
+// routes documents every route under /api/v1, keyed by method and gin path
+var routes = map[string]route{
+	"GET /game-system": {summary: "Get the game system", tag: "Game system", data: models.GameSystemResponse{}},
+
+	"GET /catalogues": {summary: "List catalogues and libraries", tag: "Catalogues", data: []models.CatalogueInfo{}},
+	"GET /catalogues/graph": {summary: "Get the catalogueLink import graph", tag: "Catalogues", data: models.CatalogueGraphResponse{},
+		query: []Parameter{
+			queryParam("focus", "Catalogue ID or name to limit the graph to its imports and dependents", "string"),
+			enumParam("format", "json, or a Graphviz or Mermaid diagram", "json", "dot", "mermaid"),
+		},
+		media:  []string{"text/vnd.graphviz", "text/plain"},
+		errors: []int{http.StatusBadRequest, http.StatusNotFound, http.StatusNotImplemented}},
+	"GET /catalogues/:id": {summary: "Get a catalogue with its units", tag: "Catalogues", data: models.CatalogueResponse{},
+		query: []Parameter{debug}, errors: []int{http.StatusNotFound, http.StatusGatewayTimeout}},
+	"GET /catalogues/:id/units": {summary: "List the units of a catalogue", tag: "Catalogues", data: []models.UnitSummary{},
+		query: []Parameter{debug}, warnings: true, errors: []int{http.StatusNotFound, http.StatusGatewayTimeout}},
+	"POST /catalogues": {summary: "Upload a homebrew catalogue", tag: "Homebrew", data: models.CatalogueInfo{},
+		status: http.StatusCreated, upload: true, token: true,
+		errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusUnprocessableEntity, http.StatusNotImplemented}},
+	"DELETE /catalogues/:id": {summary: "Delete a homebrew catalogue", tag: "Homebrew",
+		status: http.StatusNoContent, token: true,
+		errors: []int{http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusNotImplemented}},
+
+	"GET /rules": {summary: "List shared rules", tag: "Rules", data: []models.RuleResponse{}},
+	"GET /rules/:id": {summary: "Get a shared rule", tag: "Rules", data: models.RuleResponse{},
+		errors: []int{http.StatusNotFound, http.StatusGatewayTimeout}},
+
+	"GET /units": {summary: "List units", tag: "Units", data: []models.UnitSummary{}, list: true, warnings: true,
+		query: []Parameter{
+			queryParam("limit", "Page size, 1 to 1000; defaults to 100", "integer"),
+			queryParam("offset", "Units to skip", "integer"),
+			arrayParam("faction", "Faction ID, name, keyword, catalogue or super-faction; repeat to match any"),
+			arrayParam("category", "Category name substring; repeat to match any"),
+			arrayParam("catalogue", "Catalogue ID or name; repeat to match any"),
+			queryParam("search", "Unit name substring", "string"),
+			queryParam("minPoints", "Minimum base points cost", "integer"),
+			queryParam("maxPoints", "Maximum base points cost", "integer"),
+			enumParam("legends", "Whether Legends units are listed", "include", "exclude", "only"),
+			enumParam("sort", "Sort key; defaults to name", "name", "points", "toughness", "wounds", "oc", "catalogue"),
+			enumParam("order", "Sort order; defaults to asc", "asc", "desc"),
+			debug,
+		},
+		errors: []int{http.StatusBadRequest, http.StatusGatewayTimeout}},
+	"GET /units/:id": {summary: "Get a unit", tag: "Units", data: models.UnitResponse{},
+		query: []Parameter{debug}, errors: []int{http.StatusNotFound, http.StatusGatewayTimeout}},
+	"GET /units/:id/explain": {summary: "Explain how a unit's values were resolved", tag: "Units", data: models.UnitExplanation{},
+		errors: []int{http.StatusNotFound, http.StatusNotImplemented, http.StatusGatewayTimeout}},
+	"GET /units/:id/weapons": {summary: "Get a unit's weapons", tag: "Units", data: models.WeaponSet{},
+		errors: []int{http.StatusNotFound, http.StatusGatewayTimeout}},
+	"GET /units/:id/history": {summary: "Get a unit's points history", tag: "Units", data: models.UnitHistory{},
+		errors: []int{http.StatusNotFound, http.StatusServiceUnavailable}},
+
+	"GET /factions": {summary: "List factions", tag: "Factions", data: []models.FactionResponse{}},
+	"GET /factions/:name": {summary: "Get a faction", tag: "Factions", data: models.FactionResponse{},
+		errors: []int{http.StatusNotFound}},
+	"GET /factions/:name/units": {summary: "List the units of a faction", tag: "Factions", data: []models.UnitSummary{},
+		query: []Parameter{debug}, warnings: true, errors: []int{http.StatusNotFound, http.StatusGatewayTimeout}},
+
+	"GET /search": {summary: "Search units by name", tag: "Search", data: models.SearchResponse{}, warnings: true,
+		query: []Parameter{
+			required(queryParam("q", "Text to search for", "string")),
+			queryParam("limit", "Maximum results, 1 to 200; defaults to 50", "integer"),
+			debug,
+		},
+		errors: []int{http.StatusBadRequest, http.StatusGatewayTimeout}},
+
+	"GET /diff": {summary: "Compare two data revisions", tag: "Diff", data: models.DataDiff{},
+		query: []Parameter{
+			required(queryParam("from", "Git revision to compare from", "string")),
+			queryParam("to", "Git revision to compare to; defaults to the loaded data", "string"),
+		},
+		errors: []int{http.StatusBadRequest, http.StatusNotImplemented, http.StatusGatewayTimeout}},
+
+	"GET /export/:format": {summary: "Export the dataset as CSV, NDJSON or SQLite", tag: "Export",
+		media:  []string{"application/zip", "application/vnd.sqlite3"},
+		errors: []int{http.StatusBadRequest, http.StatusGatewayTimeout}},
+
+	"GET /admin/data-quality": {summary: "Get the data-quality report", tag: "Admin", data: models.DataQualityReport{}, token: true,
+		query: []Parameter{
+			queryParam("rule", "Only issues of this rule", "string"),
+			enumParam("severity", "Only issues of this severity", "error", "warning"),
+			queryParam("catalogue", "Only issues in this catalogue", "string"),
+		},
+		errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotImplemented}},
+	"GET /admin/overlays": {summary: "Get the applied overlays", tag: "Admin", data: models.OverlayReport{}, token: true,
+		errors: []int{http.StatusUnauthorized, http.StatusForbidden, http.StatusNotImplemented}},
+	"GET /admin/snapshots": {summary: "List the retained snapshots", tag: "Admin", data: []models.SnapshotInfo{}, token: true,
+		errors: []int{http.StatusUnauthorized, http.StatusForbidden}},
+	"GET /admin/cache": {summary: "Get response cache statistics", tag: "Admin", data: models.CacheStats{}, token: true,
+		errors: []int{http.StatusUnauthorized, http.StatusForbidden}},
+	"GET /graphql": {summary: "Run a GraphQL query", tag: "GraphQL", body: graphql.Response{},
+		query: []Parameter{
+			required(queryParam("query", "GraphQL query", "string")),
+			queryParam("operationName", "Operation to run, when the query has several", "string"),
+			queryParam("variables", "Variables, as a JSON object", "string"),
+		},
+		errors: []int{http.StatusBadRequest}},
+	"POST /graphql": {summary: "Run a GraphQL query", tag: "GraphQL", body: graphql.Response{}, request: graphql.Request{},
+		errors: []int{http.StatusBadRequest}},
+
+	"POST /admin/reload": {summary: "Reload the data", tag: "Admin", data: models.SnapshotInfo{}, token: true,
+		errors: []int{http.StatusUnauthorized, http.StatusForbidden, http.StatusInternalServerError}},
+}
+
+// Generate documents the /api/v1 routes of the router, and the routes serving the document. It
+// fails on a route missing from the documented routes, so new routes get documented.
+func Generate(registered gin.RoutesInfo) (doc *Document, err error) {
+	defer func() {
+		// Reflection panics on models that can't be described
+		if r := recover(); r != nil {
+			doc, err = nil, fmt.Errorf("%v", r)
+		}
+	}()
+
+	s := newSchemas()
+	errorSchema := s.of(reflect.TypeOf(response.ErrorResponse{}))
+	doc = &Document{
+		OpenAPI: Version,
+		Info: Info{
+			Title:   "Warhammer 40K 10th Edition API",
+			Version: "1.0.0",
+			Description: "Units, catalogues and rules from the BattleScribe data. Every /api/v1 request can be pinned to " +
+				"a retained snapshot with ?snapshot= or the X-Data-Revision header.",
+		},
+		Paths: make(map[string]PathItem),
+		Components: Components{
+			Schemas:         s.components,
+			SecuritySchemes: map[string]SecurityScheme{"bearerAuth": {Type: "http", Scheme: "bearer"}},
+		},
+	}
+
+	var undocumented []string
+	for _, registered := range registered {
+		if !strings.HasPrefix(registered.Path, apiPath+"/") || registered.Path == SpecPath || registered.Path == DocsPath {
+			continue
+		}
+		key := registered.Method + " " + strings.TrimPrefix(registered.Path, apiPath)
+		r, found := routes[key]
+		if !found {
+			undocumented = append(undocumented, registered.Method+" "+registered.Path)
+			continue
+		}
+		operation := r.operation(s, errorSchema, registered.Path)
+		operation.OperationID = operationID(registered.Handler)
+		doc.add(registered.Method, registered.Path, operation)
+	}
+	if len(undocumented) > 0 {
+		sort.Strings(undocumented)
+		return nil, fmt.Errorf("routes missing from the OpenAPI routes table: %s", strings.Join(undocumented, ", "))
+	}
+
+	doc.add(http.MethodGet, SpecPath, &Operation{
+		OperationID: "getOpenAPI",
+		Summary:     "Get this OpenAPI document",
+		Tags:        []string{"Docs"},
+		Responses: map[string]*Response{"200": {
+			Description: "OpenAPI " + Version + " document",
+			Content:     map[string]MediaType{jsonType: {Schema: &Schema{Type: "object"}}},
+		}},
+	})
+	doc.add(http.MethodGet, DocsPath, &Operation{
+		OperationID: "getDocs",
+		Summary:     "Browse this document",
+		Tags:        []string{"Docs"},
+		Responses: map[string]*Response{"200": {
+			Description: "HTML page",
+			Content:     map[string]MediaType{"text/html": {Schema: &Schema{Type: "string"}}},
+		}},
+	})
+	return doc, nil
+}
+
+func (d *Document) add(method, path string, operation *Operation) {
+	path = openAPIPath(path)
+	if d.Paths[path] == nil {
+		d.Paths[path] = make(PathItem)
+	}
+	d.Paths[path][strings.ToLower(method)] = operation
+}
+
+// operation documents r, registered at path
+func (r route) operation(s *schemas, errorSchema *Schema, path string) *Operation {
+	operation := &Operation{
+		Summary:   r.summary,
+		Tags:      []string{r.tag},
+		Responses: make(map[string]*Response),
+	}
+	for _, segment := range strings.Split(path, "/") {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			operation.Parameters = append(operation.Parameters, Parameter{
+				Name: segment[1:], In: "path", Description: pathParams[segment[1:]], Required: true, Schema: &Schema{Type: "string"},
+			})
+		}
+	}
+	operation.Parameters = append(operation.Parameters, r.query...)
+	operation.Parameters = append(operation.Parameters,
+		queryParam("snapshot", "Revision of a retained snapshot to answer from, as listed by /admin/snapshots", "string"))
+
+	status := r.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	success := &Response{Description: http.StatusText(status), Content: make(map[string]MediaType)}
+	if r.data != nil {
+		success.Content[jsonType] = MediaType{Schema: r.envelope(s)}
+	}
+	if r.body != nil {
+		success.Content[jsonType] = MediaType{Schema: s.of(reflect.TypeOf(r.body))}
+	}
+	for _, media := range r.media {
+		schema := &Schema{Type: "string"}
+		if !strings.HasPrefix(media, "text/") {
+			schema.Format = "binary"
+		}
+		success.Content[media] = MediaType{Schema: schema}
+	}
+	if len(success.Content) == 0 {
+		success.Content = nil
+	}
+	operation.Responses[strconv.Itoa(status)] = success
+
+	for _, code := range r.errors {
+		schema := errorSchema
+		if r.body != nil {
+			schema = s.of(reflect.TypeOf(r.body))
+		}
+		operation.Responses[strconv.Itoa(code)] = &Response{
+			Description: http.StatusText(code),
+			Content:     map[string]MediaType{jsonType: {Schema: schema}},
+		}
+	}
+	// Any request can be pinned to a snapshot that is no longer retained
+	operation.Responses[strconv.Itoa(http.StatusGone)] = &Response{
+		Description: http.StatusText(http.StatusGone),
+		Content:     map[string]MediaType{jsonType: {Schema: errorSchema}},
+	}
+	operation.Responses["default"] = &Response{
+		Description: "Error",
+		Content:     map[string]MediaType{jsonType: {Schema: errorSchema}},
+	}
+
+	if r.request != nil {
+		operation.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]MediaType{jsonType: {Schema: s.of(reflect.TypeOf(r.request))}},
+		}
+	}
+	if r.upload {
+		operation.RequestBody = &RequestBody{
+			Required: true,
+			Content: map[string]MediaType{"multipart/form-data": {Schema: &Schema{
+				Type:       "object",
+				Properties: map[string]*Schema{"file": {Type: "string", Format: "binary", Description: "A .cat or .catz file"}},
+				Required:   []string{"file"},
+			}}},
+		}
+	}
+	if r.token {
+		operation.Security = []map[string][]string{{"bearerAuth": {}}}
+	}
+	return operation
+}
+
+// envelope is the schema of the response.SuccessResponse or response.PaginatedResponse holding
+// the route's data
+func (r route) envelope(s *schemas) *Schema {
+	dataType := reflect.TypeOf(r.data)
+	data := s.of(dataType)
+	if dataType.Kind() == reflect.Slice {
+		data = nullable(data)
+	}
+	envelope := &Schema{
+		Type:                 "object",
+		Properties:           map[string]*Schema{"data": data},
+		Required:             []string{"data"},
+		AdditionalProperties: false,
+	}
+	if r.list {
+		for _, name := range []string{"total", "limit", "offset"} {
+			envelope.Properties[name] = &Schema{Type: "integer"}
+		}
+		envelope.Properties["hasMore"] = &Schema{Type: "boolean"}
+		envelope.Required = append(envelope.Required, "total", "limit", "offset", "hasMore")
+	}
+	if r.warnings {
+		envelope.Properties["warnings"] = &Schema{
+			Type:        "array",
+			Items:       s.of(reflect.TypeOf(models.ResolutionWarning{})),
+			Description: "Only listed with ?debug=true",
+		}
+	}
+	return envelope
+}
+
+func queryParam(name, description, schemaType string) Parameter {
+	return Parameter{Name: name, In: "query", Description: description, Schema: &Schema{Type: schemaType}}
+}
+
+func enumParam(name, description string, values ...string) Parameter {
+	return Parameter{Name: name, In: "query", Description: description, Schema: &Schema{Type: "string", Enum: values}}
+}
+
+func arrayParam(name, description string) Parameter {
+	return Parameter{
+		Name: name, In: "query", Description: description, Explode: &exploding,
+		Schema: &Schema{Type: "array", Items: &Schema{Type: "string"}},
+	}
+}
+
+func required(parameter Parameter) Parameter {
+	parameter.Required = true
+	return parameter
+}
+
+// openAPIPath turns gin's :id and *path parameters into {id} and {path}
+func openAPIPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// operationID names an operation after its handler method, such as getUnit for
+// grimoire-api/internal/handlers.(*UnitHandler).GetUnit-fm
+func operationID(handler string) string {
+	name := strings.TrimSuffix(handler[strings.LastIndex(handler, ".")+1:], "-fm")
+	if name == "" {
+		return name
+	}
+	return strings.ToLower(name[:1]) + name[1:]
+}
@@ -0,0 +1,102 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestGenerate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	handler := func(c *gin.Context) {}
+	router.GET("/api/v1/units/:id", handler)
+	router.GET("/api/v1/catalogues/graph", handler)
+	router.GET("/health", handler)
+
+	doc, err := Generate(router.Routes())
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if doc.Paths["/api/v1/units/{id}"]["get"] == nil {
+		t.Errorf("expected GET /api/v1/units/{id}, got paths %v", doc.Paths)
+	}
+	if doc.Paths["/health"] != nil {
+		t.Error("routes outside /api/v1 should not be documented")
+	}
+	if doc.Paths[SpecPath]["get"] == nil || doc.Paths[DocsPath]["get"] == nil {
+		t.Error("expected the docs routes to be documented")
+	}
+	graph := doc.Paths["/api/v1/catalogues/graph"]["get"].Responses["200"]
+	if _, found := graph.Content["text/vnd.graphviz"]; !found {
+		t.Errorf("expected the DOT format of the graph, got %v", graph.Content)
+	}
+
+	unit := doc.Components.Schemas["UnitResponse"]
+	if unit == nil {
+		t.Fatal("expected a UnitResponse component")
+	}
+	if !contains(unit.Required, "costs") || contains(unit.Required, "faction") {
+		t.Errorf("expected costs required and the omitempty faction optional, got %v", unit.Required)
+	}
+	if !unit.Properties["weapons"].Nullable {
+		t.Error("expected the weapons pointer to be nullable")
+	}
+
+	router.GET("/api/v1/undocumented", handler)
+	if _, err := Generate(router.Routes()); err == nil || !strings.Contains(err.Error(), "GET /api/v1/undocumented") {
+		t.Errorf("expected an error naming the undocumented route, got %v", err)
+	}
+}
+
+func TestValidateResponse(t *testing.T) {
+	router := gin.New()
+	router.GET("/api/v1/units/:id", func(c *gin.Context) {})
+	router.GET("/api/v1/rules", func(c *gin.Context) {})
+	generated, err := Generate(router.Routes())
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	// Validate the document as clients see it
+	encoded, err := json.Marshal(generated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc Document
+	if err := json.Unmarshal(encoded, &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		path   string
+		status int
+		body   string
+		valid  bool
+	}{
+		{"rules", "/api/v1/rules", 200, `{"data":[{"id":"r","name":"Rule","description":""}]}`, true},
+		{"no rules", "/api/v1/rules", 200, `{"data":null}`, true},
+		{"missing field", "/api/v1/rules", 200, `{"data":[{"id":"r","name":"Rule"}]}`, false},
+		{"unknown field", "/api/v1/rules", 200, `{"data":[{"id":"r","name":"Rule","description":"","text":""}]}`, false},
+		{"wrong type", "/api/v1/rules", 200, `{"data":[{"id":1,"name":"Rule","description":""}]}`, false},
+		{"unit", "/api/v1/units/u", 200, `{"data":{"id":"u","name":"Unit","type":"unit","profiles":null,"weapons":null,` +
+			`"categories":null,"rules":[],"costs":{"pts":90}}}`, true},
+		{"fractional cost", "/api/v1/units/u", 200, `{"data":{"id":"u","name":"Unit","type":"unit","profiles":null,"weapons":null,` +
+			`"categories":null,"rules":[],"costs":{"pts":90.5}}}`, false},
+		{"not found", "/api/v1/units/u", 404, `{"error":"Not Found","message":"unit not found"}`, true},
+		{"undocumented status", "/api/v1/units/u", 201, `{}`, false},
+		{"undocumented route", "/api/v1/units", 200, `{}`, false},
+	}
+	for _, tt := range tests {
+		err := doc.ValidateResponse(http.MethodGet, tt.path, tt.status, "application/json; charset=utf-8", []byte(tt.body))
+		if tt.valid && err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+		}
+		if !tt.valid && err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ValidateResponse checks a response to a request for path, such as /api/v1/units/abc, against
+// the document: its status and content type must be documented for the operation, and a JSON
+// body must match the schema.
+func (d *Document) ValidateResponse(method, path string, status int, contentType string, body []byte) error {
+	template, operation := d.operationFor(method, path)
+	if operation == nil {
+		return fmt.Errorf("%s %s is not documented", method, path)
+	}
+	documented, found := operation.Responses[strconv.Itoa(status)]
+	if !found {
+		if documented, found = operation.Responses["default"]; !found || status < 400 {
+			return fmt.Errorf("%s %s: status %d is not documented", method, template, status)
+		}
+	}
+
+	if len(documented.Content) == 0 {
+		if len(body) > 0 {
+			return fmt.Errorf("%s %s: status %d is documented without a body", method, template, status)
+		}
+		return nil
+	}
+	media, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return fmt.Errorf("%s %s: bad content type %q", method, template, contentType)
+	}
+	mediaType, found := documented.Content[media]
+	if !found {
+		return fmt.Errorf("%s %s: content type %s is not documented for status %d", method, template, media, status)
+	}
+	if media != jsonType {
+		return nil
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return fmt.Errorf("%s %s: %w", method, template, err)
+	}
+	if err := d.Validate(mediaType.Schema, value); err != nil {
+		return fmt.Errorf("%s %s (%d): %w", method, template, status, err)
+	}
+	return nil
+}
+
+// operationFor finds the operation whose path template matches path. Literal segments win over
+// parameters, so /catalogues/graph isn't taken for /catalogues/{id}.
+func (d *Document) operationFor(method, path string) (string, *Operation) {
+	segments := strings.Split(strings.TrimSuffix(path, "/"), "/")
+	best, bestLiterals := "", -1
+	for template, item := range d.Paths {
+		if item[strings.ToLower(method)] == nil {
+			continue
+		}
+		templateSegments := strings.Split(template, "/")
+		if len(templateSegments) != len(segments) {
+			continue
+		}
+		literals := 0
+		for i, segment := range templateSegments {
+			if strings.HasPrefix(segment, "{") {
+				continue
+			}
+			if segment != segments[i] {
+				literals = -1
+				break
+			}
+			literals++
+		}
+		if literals > bestLiterals {
+			best, bestLiterals = template, literals
+		}
+	}
+	if bestLiterals < 0 {
+		return "", nil
+	}
+	return best, d.Paths[best][strings.ToLower(method)]
+}
+
+// Validate checks a value decoded from JSON against schema. Errors name the JSON path of the
+// first value that doesn't match.
+func (d *Document) Validate(schema *Schema, value interface{}) error {
+	return d.validate(schema, value, "$")
+}
+
+func (d *Document) validate(schema *Schema, value interface{}, path string) error {
+	if schema.Ref != "" {
+		name := strings.TrimPrefix(schema.Ref, schemaRef)
+		component, found := d.Components.Schemas[name]
+		if !found {
+			return fmt.Errorf("%s: unknown schema %s", path, schema.Ref)
+		}
+		return d.validate(component, value, path)
+	}
+	if value == nil {
+		if schema.Nullable || (schema.Type == "" && len(schema.AllOf) == 0) {
+			return nil
+		}
+		return fmt.Errorf("%s: null is not allowed", path)
+	}
+	for _, part := range schema.AllOf {
+		if err := d.validate(part, value, path); err != nil {
+			return err
+		}
+	}
+
+	switch schema.Type {
+	case "":
+		return nil
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return mismatch(path, schema.Type, value)
+		}
+		for _, name := range schema.Required {
+			if _, found := object[name]; !found {
+				return fmt.Errorf("%s: missing required property %s", path, name)
+			}
+		}
+		names := make([]string, 0, len(object))
+		for name := range object {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			property, found := schema.Properties[name]
+			if !found {
+				switch additional := schema.AdditionalProperties.(type) {
+				case *Schema:
+					property = additional
+				case bool:
+					if !additional {
+						return fmt.Errorf("%s: property %s is not in the schema", path, name)
+					}
+				}
+			}
+			if property == nil {
+				continue
+			}
+			if err := d.validate(property, object[name], path+"."+name); err != nil {
+				return err
+			}
+		}
+	case "array":
+		array, ok := value.([]interface{})
+		if !ok {
+			return mismatch(path, schema.Type, value)
+		}
+		for i, item := range array {
+			if err := d.validate(schema.Items, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			return mismatch(path, schema.Type, value)
+		}
+		if len(schema.Enum) > 0 && !contains(schema.Enum, s) {
+			return fmt.Errorf("%s: %q is not one of %s", path, s, strings.Join(schema.Enum, ", "))
+		}
+	case "integer":
+		n, ok := value.(float64)
+		if !ok || n != math.Trunc(n) {
+			return mismatch(path, schema.Type, value)
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			return mismatch(path, schema.Type, value)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return mismatch(path, schema.Type, value)
+		}
+	default:
+		return fmt.Errorf("%s: unknown schema type %s", path, schema.Type)
+	}
+	return nil
+}
+
+func mismatch(path, expected string, value interface{}) error {
+	return fmt.Errorf("%s: expected %s, got %T", path, expected, value)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
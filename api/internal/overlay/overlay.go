@@ -0,0 +1,330 @@
+// Package overlay applies local patches on top of the loaded data, for errata and dataslates
+// that haven't reached the data repository yet
+package overlay
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"grimoire-api/internal/models"
+	"grimoire-api/internal/parser"
+)
+
+// Overlay is one patch file. Its name is the file name without the .json extension.
+type Overlay struct {
+	Name        string  `json:"-"`
+	File        string  `json:"-"`
+	Description string  `json:"description"`
+	Patches     []Patch `json:"patches"`
+}
+
+// Patch changes every selectionEntry, entryLink or profile with the given ID.
+// Costs apply to entries and links, characteristics to profiles.
+type Patch struct {
+	ID              string                 `json:"id"`
+	Name            string                 `json:"name,omitempty"`
+	Costs           map[string]json.Number `json:"costs,omitempty"`           // Cost name -> value
+	Characteristics map[string]string      `json:"characteristics,omitempty"` // Characteristic name -> value
+}
+
+// LoadDir reads every .json overlay in dir, ordered by file name. A missing directory has no overlays.
+func LoadDir(dir string) ([]*Overlay, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read overlay directory: %w", err)
+	}
+
+	var overlays []*Overlay
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(strings.ToLower(entry.Name()), ".json") {
+			continue
+		}
+		overlay, err := Load(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		overlays = append(overlays, overlay)
+	}
+	return overlays, nil
+}
+
+// Load reads and validates one overlay file
+func Load(path string) (*Overlay, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read overlay %s: %w", path, err)
+	}
+
+	var overlay Overlay
+	if err := json.Unmarshal(data, &overlay); err != nil {
+		return nil, fmt.Errorf("failed to parse overlay %s: %w", path, err)
+	}
+	for i, patch := range overlay.Patches {
+		if patch.ID == "" {
+			return nil, fmt.Errorf("overlay %s: patch %d has no id", path, i+1)
+		}
+		if patch.Name == "" && len(patch.Costs) == 0 && len(patch.Characteristics) == 0 {
+			return nil, fmt.Errorf("overlay %s: patch %d (%s) changes nothing", path, i+1, patch.ID)
+		}
+	}
+
+	overlay.File = filepath.Base(path)
+	overlay.Name = strings.TrimSuffix(overlay.File, filepath.Ext(overlay.File))
+	return &overlay, nil
+}
+
+// element is a patchable element, pointing into the parsed data
+type element struct {
+	name            *string
+	costs           *[]models.Cost          // nil for profiles
+	characteristics []models.Characteristic // nil for entries and links
+	overlays        *[]models.OverlayMark
+}
+
+// applier applies overlays to one dataset
+type applier struct {
+	costTypes map[string]string // Cost type name -> ID, from the game system
+	elements  map[string][]element
+	report    *models.OverlayReport
+}
+
+// Apply patches the parser's game system, catalogues and libraries in overlay order and marks every
+// changed element. It mutates the parsed data, so it must run before the parser is shared.
+func Apply(p *parser.Parser, overlays []*Overlay) *models.OverlayReport {
+	a := &applier{
+		costTypes: make(map[string]string),
+		elements:  make(map[string][]element),
+		report: &models.OverlayReport{
+			Overlays:  make([]models.OverlaySummary, 0, len(overlays)),
+			Conflicts: make([]models.OverlayConflict, 0),
+		},
+	}
+	if len(overlays) == 0 {
+		return a.report
+	}
+
+	a.index(p)
+
+	applied := 0
+	for _, overlay := range overlays {
+		summary := models.OverlaySummary{
+			Name:        overlay.Name,
+			File:        overlay.File,
+			Description: overlay.Description,
+			Patches:     len(overlay.Patches),
+		}
+		for _, patch := range overlay.Patches {
+			summary.Applied += a.apply(overlay, patch)
+		}
+		applied += summary.Applied
+		a.report.Overlays = append(a.report.Overlays, summary)
+	}
+
+	if applied > 0 {
+		p.MarkOverlaid()
+	}
+	return a.report
+}
+
+// index records every selectionEntry, entryLink and profile by ID
+func (a *applier) index(p *parser.Parser) {
+	if gs := p.GetGameSystem(); gs != nil {
+		for _, costType := range gs.CostTypes {
+			a.costTypes[costType.Name] = costType.ID
+		}
+		for i := range gs.SharedProfiles {
+			a.addProfile(&gs.SharedProfiles[i])
+		}
+	}
+
+	var catalogues []*models.Catalogue
+	for _, cat := range p.GetAllCatalogues() {
+		catalogues = append(catalogues, cat)
+	}
+	for _, lib := range p.GetAllLibraries() {
+		catalogues = append(catalogues, lib)
+	}
+	for _, cat := range catalogues {
+		for i := range cat.SharedProfiles {
+			a.addProfile(&cat.SharedProfiles[i])
+		}
+		for i := range cat.SharedSelectionEntries {
+			a.addEntry(&cat.SharedSelectionEntries[i])
+		}
+		for i := range cat.SharedSelectionEntryGroups {
+			a.addGroup(&cat.SharedSelectionEntryGroups[i])
+		}
+		for i := range cat.EntryLinks {
+			a.addLink(&cat.EntryLinks[i])
+		}
+	}
+}
+
+func (a *applier) addProfile(profile *models.Profile) {
+	a.elements[profile.ID] = append(a.elements[profile.ID], element{
+		name:            &profile.Name,
+		characteristics: profile.Characteristics,
+		overlays:        &profile.Overlays,
+	})
+}
+
+func (a *applier) addEntry(entry *models.SelectionEntry) {
+	a.elements[entry.ID] = append(a.elements[entry.ID], element{
+		name:     &entry.Name,
+		costs:    &entry.Costs,
+		overlays: &entry.Overlays,
+	})
+	for i := range entry.Profiles {
+		a.addProfile(&entry.Profiles[i])
+	}
+	for i := range entry.SelectionEntries {
+		a.addEntry(&entry.SelectionEntries[i])
+	}
+	for i := range entry.SelectionEntryGroups {
+		a.addGroup(&entry.SelectionEntryGroups[i])
+	}
+	for i := range entry.EntryLinks {
+		a.addLink(&entry.EntryLinks[i])
+	}
+}
+
+func (a *applier) addGroup(group *models.SelectionEntryGroup) {
+	for i := range group.SelectionEntries {
+		a.addEntry(&group.SelectionEntries[i])
+	}
+	for i := range group.SelectionEntryGroups {
+		a.addGroup(&group.SelectionEntryGroups[i])
+	}
+	for i := range group.EntryLinks {
+		a.addLink(&group.EntryLinks[i])
+	}
+}
+
+func (a *applier) addLink(link *models.EntryLink) {
+	a.elements[link.ID] = append(a.elements[link.ID], element{
+		name:     &link.Name,
+		costs:    &link.Costs,
+		overlays: &link.Overlays,
+	})
+	for i := range link.EntryLinks {
+		a.addLink(&link.EntryLinks[i])
+	}
+}
+
+func (a *applier) conflict(overlay *Overlay, targetID, field, reason string) {
+	a.report.Conflicts = append(a.report.Conflicts, models.OverlayConflict{
+		Overlay:  overlay.Name,
+		File:     overlay.File,
+		TargetID: targetID,
+		Field:    field,
+		Reason:   reason,
+	})
+}
+
+// apply applies one patch to every element with its ID and returns the number of values changed
+func (a *applier) apply(overlay *Overlay, patch Patch) int {
+	elements := a.elements[patch.ID]
+	if len(elements) == 0 {
+		a.conflict(overlay, patch.ID, "", "no selectionEntry, entryLink or profile has this ID")
+		return 0
+	}
+
+	changed := 0
+	mark := func(el element, field, original, value string) {
+		*el.overlays = append(*el.overlays, models.OverlayMark{
+			Overlay:  overlay.Name,
+			TargetID: patch.ID,
+			Field:    field,
+			Original: original,
+			Value:    value,
+		})
+		changed++
+	}
+
+	if patch.Name != "" {
+		for _, el := range elements {
+			mark(el, "name", *el.name, patch.Name)
+			*el.name = patch.Name
+		}
+	}
+
+	for _, name := range sortedKeys(patch.Costs) {
+		field := "costs." + name
+		value := patch.Costs[name].String()
+		if _, err := patch.Costs[name].Int64(); err != nil {
+			a.conflict(overlay, patch.ID, field, fmt.Sprintf("cost %q is not a whole number", value))
+			continue
+		}
+		matched := false
+		for _, el := range elements {
+			if el.costs == nil {
+				continue
+			}
+			matched = true
+			original, found := setCost(el.costs, name, value)
+			if !found {
+				typeID, known := a.costTypes[name]
+				if !known {
+					a.conflict(overlay, patch.ID, field, fmt.Sprintf("the game system has no cost type named %q", name))
+					break
+				}
+				*el.costs = append(*el.costs, models.Cost{Name: name, TypeID: typeID, Value: value})
+			}
+			mark(el, field, original, value)
+		}
+		if !matched {
+			a.conflict(overlay, patch.ID, field, "costs only apply to selectionEntries and entryLinks")
+		}
+	}
+
+	for _, name := range sortedKeys(patch.Characteristics) {
+		field := "characteristics." + name
+		value := patch.Characteristics[name]
+		matched := false
+		for _, el := range elements {
+			if el.characteristics == nil {
+				continue
+			}
+			for i := range el.characteristics {
+				if el.characteristics[i].Name == name {
+					mark(el, field, el.characteristics[i].Value, value)
+					el.characteristics[i].Value = value
+					matched = true
+				}
+			}
+		}
+		if !matched {
+			a.conflict(overlay, patch.ID, field, fmt.Sprintf("no profile with this ID has a %q characteristic", name))
+		}
+	}
+
+	return changed
+}
+
+// setCost sets the value of the named cost, returning its previous value and whether it existed
+func setCost(costs *[]models.Cost, name, value string) (string, bool) {
+	for i := range *costs {
+		if (*costs)[i].Name == name {
+			original := (*costs)[i].Value
+			(*costs)[i].Value = value
+			return original, true
+		}
+	}
+	return "", false
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,96 @@
+package overlay
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"grimoire-api/internal/models"
+	"grimoire-api/internal/parser"
+)
+
+const fixtureDir = "../../testdata/wh40k-fixture"
+
+const errataOverlay = `{
+  "description": "Fixture errata",
+  "patches": [
+    {"id": "el-fixture-captain", "costs": {"pts": 90}},
+    {"id": "prof-cap-unit", "characteristics": {"T": "5"}},
+    {"id": "prof-cap-unit", "characteristics": {"Invuln": "4+"}},
+    {"id": "se-fixture-removed", "name": "Removed Unit"}
+  ]
+}`
+
+func writeOverlay(t *testing.T, dir, name, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+}
+
+func TestLoadDir(t *testing.T) {
+	dir := t.TempDir()
+	writeOverlay(t, dir, "b-dataslate.json", `{"patches": [{"id": "x", "name": "X"}]}`)
+	writeOverlay(t, dir, "a-errata.json", errataOverlay)
+	writeOverlay(t, dir, "notes.txt", "not an overlay")
+
+	overlays, err := LoadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, overlays, 2)
+	assert.Equal(t, "a-errata", overlays[0].Name)
+	assert.Equal(t, "b-dataslate", overlays[1].Name)
+	assert.Equal(t, "90", overlays[0].Patches[0].Costs["pts"].String())
+
+	missing, err := LoadDir(filepath.Join(dir, "missing"))
+	assert.NoError(t, err)
+	assert.Empty(t, missing)
+
+	writeOverlay(t, dir, "c-empty.json", `{"patches": [{"id": "x"}]}`)
+	_, err = LoadDir(dir)
+	assert.ErrorContains(t, err, "changes nothing")
+}
+
+func TestApply(t *testing.T) {
+	dir := t.TempDir()
+	writeOverlay(t, dir, "errata.json", errataOverlay)
+	overlays, err := LoadDir(dir)
+	require.NoError(t, err)
+
+	p, err := parser.LoadDataDir(fixtureDir)
+	require.NoError(t, err)
+	report := Apply(p, overlays)
+
+	require.Len(t, report.Overlays, 1)
+	assert.Equal(t, 4, report.Overlays[0].Patches)
+	assert.Equal(t, 2, report.Overlays[0].Applied)
+	require.Len(t, report.Conflicts, 2)
+	assert.Equal(t, "characteristics.Invuln", report.Conflicts[0].Field)
+	assert.Equal(t, "se-fixture-removed", report.Conflicts[1].TargetID)
+	assert.True(t, p.Overlaid())
+
+	// The captain's link gained a pts cost, which overrides the selectionEntry's 80
+	resolver := parser.NewLinkResolver(p)
+	transformer := parser.NewTransformer(resolver)
+	link, catalogueID, found := p.FindEntryLinkByID("el-fixture-captain")
+	require.True(t, found)
+	entry, err := resolver.ResolveEntryLink(link, catalogueID)
+	require.NoError(t, err)
+	unit := transformer.TransformUnit(resolver.MergeEntryLinkWithSelectionEntry(link, entry), catalogueID)
+
+	assert.Equal(t, 90, unit.Costs["pts"])
+	require.NotNil(t, unit.Profiles.Unit)
+	assert.Equal(t, 5, unit.Profiles.Unit.Toughness)
+	assert.Contains(t, unit.Overlays, models.OverlayMark{Overlay: "errata", TargetID: "el-fixture-captain", Field: "costs.pts", Value: "90"})
+	assert.Contains(t, unit.Overlays, models.OverlayMark{Overlay: "errata", TargetID: "prof-cap-unit", Field: "characteristics.T", Original: "4", Value: "5"})
+}
+
+func TestApplyWithoutOverlays(t *testing.T) {
+	p, err := parser.LoadDataDir(fixtureDir)
+	require.NoError(t, err)
+
+	report := Apply(p, nil)
+	assert.Empty(t, report.Overlays)
+	assert.Empty(t, report.Conflicts)
+	assert.False(t, p.Overlaid())
+}
@@ -0,0 +1,202 @@
+package parser
+
+import (
+	"sort"
+
+	"grimoire-api/internal/models"
+)
+
+// CatalogueGraph is the import graph formed by catalogueLinks between catalogues and libraries
+type CatalogueGraph struct {
+	Nodes []*CatalogueNode
+	Edges []CatalogueEdge
+	// Cycles lists each strongly connected group of catalogues that import each other
+	Cycles [][]string
+
+	byID map[string]*CatalogueNode
+}
+
+// CatalogueNode is a catalogue or library with its direct and transitive imports
+type CatalogueNode struct {
+	Catalogue *models.Catalogue
+	// Imports and ImportedBy are direct catalogueLinks, sorted by ID
+	Imports    []string
+	ImportedBy []string
+	// TransitiveImports is everything this catalogue loads, directly or indirectly
+	TransitiveImports []string
+	// Dependents is every catalogue affected by a change to this one
+	Dependents []string
+}
+
+// CatalogueEdge is a single catalogueLink
+type CatalogueEdge struct {
+	From              string
+	To                string
+	Name              string
+	ImportRootEntries bool
+	Resolved          bool // False when the target catalogue isn't loaded
+}
+
+// Node returns the node for a catalogue ID
+func (g *CatalogueGraph) Node(id string) (*CatalogueNode, bool) {
+	node, exists := g.byID[id]
+	return node, exists
+}
+
+// ResolveCatalogueGraph returns the import graph for all loaded catalogues and libraries. It is
+// built once per resolver and shared, so callers must not change it.
+func (lr *LinkResolver) ResolveCatalogueGraph() *CatalogueGraph {
+	lr.graphOnce.Do(func() {
+		lr.graph = lr.buildCatalogueGraph()
+	})
+	return lr.graph
+}
+
+// buildCatalogueGraph builds the import graph from the catalogueLinks
+func (lr *LinkResolver) buildCatalogueGraph() *CatalogueGraph {
+	graph := &CatalogueGraph{byID: make(map[string]*CatalogueNode)}
+
+	all := make([]*models.Catalogue, 0)
+	for _, cat := range lr.parser.GetAllCatalogues() {
+		all = append(all, cat)
+	}
+	for _, lib := range lr.parser.GetAllLibraries() {
+		all = append(all, lib)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].ID < all[j].ID })
+
+	for _, cat := range all {
+		node := &CatalogueNode{Catalogue: cat}
+		graph.Nodes = append(graph.Nodes, node)
+		graph.byID[cat.ID] = node
+	}
+
+	for _, node := range graph.Nodes {
+		seen := make(map[string]bool)
+		for _, catLink := range node.Catalogue.CatalogueLinks {
+			target, resolved := graph.byID[catLink.TargetID]
+			graph.Edges = append(graph.Edges, CatalogueEdge{
+				From:              node.Catalogue.ID,
+				To:                catLink.TargetID,
+				Name:              catLink.Name,
+				ImportRootEntries: catLink.ImportRootEntries,
+				Resolved:          resolved,
+			})
+			if !resolved || seen[catLink.TargetID] {
+				continue
+			}
+			seen[catLink.TargetID] = true
+			node.Imports = append(node.Imports, catLink.TargetID)
+			target.ImportedBy = append(target.ImportedBy, node.Catalogue.ID)
+		}
+	}
+
+	for _, node := range graph.Nodes {
+		sort.Strings(node.Imports)
+		sort.Strings(node.ImportedBy)
+	}
+
+	for _, node := range graph.Nodes {
+		node.TransitiveImports = graph.reachable(node.Catalogue.ID, func(n *CatalogueNode) []string { return n.Imports })
+		node.Dependents = graph.reachable(node.Catalogue.ID, func(n *CatalogueNode) []string { return n.ImportedBy })
+	}
+
+	graph.Cycles = graph.findCycles()
+
+	return graph
+}
+
+// reachable returns the sorted IDs reachable from start by following next, excluding start
+// unless it is part of a cycle
+func (g *CatalogueGraph) reachable(start string, next func(*CatalogueNode) []string) []string {
+	visited := make(map[string]bool)
+	stack := append([]string(nil), next(g.byID[start])...)
+	for len(stack) > 0 {
+		id := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if visited[id] {
+			continue
+		}
+		visited[id] = true
+		stack = append(stack, next(g.byID[id])...)
+	}
+
+	result := make([]string, 0, len(visited))
+	for id := range visited {
+		result = append(result, id)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// findCycles returns the groups of catalogues that import each other
+func (g *CatalogueGraph) findCycles() [][]string {
+	nodes := make([]string, 0, len(g.Nodes))
+	edges := make(map[string][]string, len(g.Nodes))
+	for _, node := range g.Nodes {
+		nodes = append(nodes, node.Catalogue.ID)
+		edges[node.Catalogue.ID] = node.Imports
+	}
+	return FindCycles(nodes, edges)
+}
+
+// FindCycles returns the strongly connected components of a directed graph that contain a
+// cycle (Tarjan's algorithm), each sorted and ordered by their first ID
+func FindCycles(nodes []string, edges map[string][]string) [][]string {
+	index := 0
+	indices := make(map[string]int)
+	lowlink := make(map[string]int)
+	onStack := make(map[string]bool)
+	var stack []string
+	var cycles [][]string
+
+	var strongConnect func(id string)
+	strongConnect = func(id string) {
+		indices[id] = index
+		lowlink[id] = index
+		index++
+		stack = append(stack, id)
+		onStack[id] = true
+
+		selfLoop := false
+		for _, target := range edges[id] {
+			if target == id {
+				selfLoop = true
+			}
+			if _, visited := indices[target]; !visited {
+				strongConnect(target)
+				lowlink[id] = min(lowlink[id], lowlink[target])
+			} else if onStack[target] {
+				lowlink[id] = min(lowlink[id], indices[target])
+			}
+		}
+
+		if lowlink[id] != indices[id] {
+			return
+		}
+
+		var component []string
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[top] = false
+			component = append(component, top)
+			if top == id {
+				break
+			}
+		}
+		if len(component) > 1 || selfLoop {
+			sort.Strings(component)
+			cycles = append(cycles, component)
+		}
+	}
+
+	for _, id := range nodes {
+		if _, visited := indices[id]; !visited {
+			strongConnect(id)
+		}
+	}
+
+	sort.Slice(cycles, func(i, j int) bool { return cycles[i][0] < cycles[j][0] })
+	return cycles
+}
@@ -0,0 +1,75 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+
+	"grimoire-api/internal/models"
+)
+
+func TestResolveCatalogueGraph(t *testing.T) {
+	p := NewParser(getFixtureDataDir(t))
+	if err := p.LoadAllCatalogues(); err != nil {
+		t.Fatalf("Failed to load catalogues: %v", err)
+	}
+
+	resolver := NewLinkResolver(p)
+	graph := resolver.ResolveCatalogueGraph()
+	if resolver.ResolveCatalogueGraph() != graph {
+		t.Error("Expected the graph to be built once per resolver")
+	}
+
+	if len(graph.Nodes) != 4 {
+		t.Fatalf("Expected 4 nodes, got %d", len(graph.Nodes))
+	}
+	if len(graph.Cycles) != 0 {
+		t.Errorf("Expected no cycles, got %v", graph.Cycles)
+	}
+
+	library, exists := graph.Node("lib-fixture-astartes")
+	if !exists {
+		t.Fatal("Library node not found")
+	}
+	if !reflect.DeepEqual(library.ImportedBy, []string{"cat-fixture-marines"}) {
+		t.Errorf("Unexpected direct importers: %v", library.ImportedBy)
+	}
+	if !reflect.DeepEqual(library.Dependents, []string{"cat-fixture-blood-angels", "cat-fixture-marines"}) {
+		t.Errorf("Unexpected dependents: %v", library.Dependents)
+	}
+
+	bloodAngels, _ := graph.Node("cat-fixture-blood-angels")
+	if !reflect.DeepEqual(bloodAngels.TransitiveImports, []string{"cat-fixture-marines", "lib-fixture-astartes"}) {
+		t.Errorf("Unexpected transitive imports: %v", bloodAngels.TransitiveImports)
+	}
+}
+
+func TestResolveCatalogueGraphCycles(t *testing.T) {
+	p := NewParser("")
+	link := func(id, target string) models.CatalogueLink {
+		return models.CatalogueLink{ID: id, TargetID: target, ImportRootEntries: true}
+	}
+	p.libraries["a"] = &models.Catalogue{ID: "a", Library: true, CatalogueLinks: []models.CatalogueLink{link("ab", "b")}}
+	p.libraries["b"] = &models.Catalogue{ID: "b", Library: true, CatalogueLinks: []models.CatalogueLink{link("ba", "a")}}
+	p.catalogues["c"] = &models.Catalogue{ID: "c", CatalogueLinks: []models.CatalogueLink{link("ca", "a"), link("cx", "missing")}}
+
+	graph := NewLinkResolver(p).ResolveCatalogueGraph()
+
+	if !reflect.DeepEqual(graph.Cycles, [][]string{{"a", "b"}}) {
+		t.Errorf("Expected cycle [a b], got %v", graph.Cycles)
+	}
+
+	c, _ := graph.Node("c")
+	if !reflect.DeepEqual(c.TransitiveImports, []string{"a", "b"}) {
+		t.Errorf("Unexpected transitive imports: %v", c.TransitiveImports)
+	}
+
+	unresolved := 0
+	for _, edge := range graph.Edges {
+		if !edge.Resolved {
+			unresolved++
+		}
+	}
+	if unresolved != 1 {
+		t.Errorf("Expected 1 unresolved edge, got %d", unresolved)
+	}
+}
@@ -0,0 +1,65 @@
+package parser
+
+import "grimoire-api/internal/models"
+
+// Dataset is everything a Parser has loaded, so it can be saved and restored without the XML
+type Dataset struct {
+	GameSystem *models.GameSystem
+	Catalogues map[string]*models.Catalogue
+	Libraries  map[string]*models.Catalogue
+	Files      map[string]string // Catalogue ID -> file path relative to the data directory
+	Homebrew   map[string]bool
+	Overlaid   bool
+	LoadReport *models.LoadReport
+}
+
+// Dataset returns the loaded data. The maps are copies but the models are shared with the parser.
+func (p *Parser) Dataset() *Dataset {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	d := &Dataset{
+		GameSystem: p.gameSystem,
+		Catalogues: make(map[string]*models.Catalogue, len(p.catalogues)),
+		Libraries:  make(map[string]*models.Catalogue, len(p.libraries)),
+		Files:      make(map[string]string, len(p.files)),
+		Homebrew:   make(map[string]bool, len(p.homebrew)),
+		Overlaid:   p.overlaid,
+		LoadReport: p.loadReport,
+	}
+	for id, cat := range p.catalogues {
+		d.Catalogues[id] = cat
+	}
+	for id, lib := range p.libraries {
+		d.Libraries[id] = lib
+	}
+	for id, file := range p.files {
+		d.Files[id] = file
+	}
+	for id := range p.homebrew {
+		d.Homebrew[id] = true
+	}
+	return d
+}
+
+// NewParserFromDataset creates a parser holding a dataset saved with Dataset, as if it had
+// been loaded from dataDir
+func NewParserFromDataset(dataDir string, d *Dataset) *Parser {
+	p := NewParser(dataDir)
+	p.gameSystem = d.GameSystem
+	p.overlaid = d.Overlaid
+	p.loadReport = d.LoadReport
+	for id, cat := range d.Catalogues {
+		p.catalogues[id] = cat
+	}
+	for id, lib := range d.Libraries {
+		p.libraries[id] = lib
+	}
+	for id, file := range d.Files {
+		p.files[id] = file
+	}
+	for id := range d.Homebrew {
+		p.homebrew[id] = true
+	}
+	return p
+}
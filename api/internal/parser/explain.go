@@ -0,0 +1,277 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"grimoire-api/internal/models"
+)
+
+// unitTrace records which profiles and modifiers a transformation used, for explain mode.
+// Its methods do nothing on a nil trace, so normal transformations only pay a nil check.
+type unitTrace struct {
+	profiles  []models.Profile
+	modifiers map[int]string // Index into the unit's modifiers then its modifierGroups' -> effect
+}
+
+func (tr *unitTrace) useProfile(profile models.Profile) {
+	if tr == nil {
+		return
+	}
+	tr.profiles = append(tr.profiles, profile)
+}
+
+func (tr *unitTrace) applyModifier(index int, effect string) {
+	if tr == nil {
+		return
+	}
+	tr.modifiers[index] = effect
+}
+
+// ExplainEntryLink transforms the unit a root entryLink resolves to, recording where each value came from
+func (t *Transformer) ExplainEntryLink(entryLink *models.EntryLink, catalogueID string) (*models.UnitExplanation, error) {
+	resolved, sourceID, err := t.resolver.ResolveEntryLinkSource(entryLink, catalogueID)
+	if err != nil {
+		return nil, err
+	}
+	merged := t.resolver.MergeEntryLinkWithSelectionEntry(entryLink, resolved)
+
+	linkRef := t.sourceRef("entryLink", entryLink.ID, entryLink.Name, catalogueID, entryLink.Pos)
+	entryRef := t.sourceRef("selectionEntry", resolved.ID, resolved.Name, sourceID, resolved.Pos)
+	explanation := t.explain(merged, catalogueID, entryRef, entryLink, &linkRef, len(resolved.Modifiers))
+
+	// GetUnit keeps the entryLink ID for units found through one
+	explanation.ID = entryLink.ID
+	explanation.Unit.ID = entryLink.ID
+	explanation.Overrides = explainOverrides(entryLink, resolved)
+
+	return explanation, nil
+}
+
+// ExplainEntry transforms a selectionEntry defined in catalogueID, recording where each value came from
+func (t *Transformer) ExplainEntry(entry *models.SelectionEntry, catalogueID string) *models.UnitExplanation {
+	entryRef := t.sourceRef("selectionEntry", entry.ID, entry.Name, catalogueID, entry.Pos)
+	return t.explain(entry, catalogueID, entryRef, nil, nil, len(entry.Modifiers))
+}
+
+// explain transforms entry with a trace and attributes each traced value to its source.
+// entryModifiers is how many of entry's modifiers came from the selectionEntry rather than the entryLink.
+func (t *Transformer) explain(entry *models.SelectionEntry, catalogueID string, entryRef models.SourceRef, entryLink *models.EntryLink, linkRef *models.SourceRef, entryModifiers int) *models.UnitExplanation {
+	rootChain := []models.SourceRef{entryRef}
+	if linkRef != nil {
+		rootChain = []models.SourceRef{*linkRef, entryRef}
+	}
+
+	// The transformer's walk of the unit records where it found each profile
+	tracer := &Transformer{resolver: t.resolver, trace: &unitTrace{modifiers: make(map[int]string)}}
+	tracer.walk = newUnitWalk(tracer, entry, catalogueID)
+	tracer.walk.warnings = true
+	tracer.walk.overlays = t.resolver.parser.Overlaid()
+	tracer.walk.definedIn = entryRef.CatalogueID
+	tracer.walk.chain = rootChain
+	tracer.walk.sources = make(map[string]profileSource)
+	unit := tracer.TransformUnitWithWarnings(entry, catalogueID)
+
+	explanation := &models.UnitExplanation{
+		ID:             entry.ID,
+		Name:           entry.Name,
+		EntryLink:      linkRef,
+		SelectionEntry: entryRef,
+		Overrides:      make([]models.EntryLinkOverride, 0),
+		Profiles:       make([]models.ExplainedValue, 0),
+		Weapons:        make([]models.ExplainedValue, 0),
+		Costs:          make([]models.ExplainedValue, 0),
+		Categories:     make([]models.ExplainedValue, 0),
+		Modifiers:      make([]models.ExplainedModifier, 0),
+		Unit:           unit,
+	}
+
+	// Profiles and weapons, in the order the transformer used them
+	sources := tracer.walk.sources
+	for _, profile := range tracer.trace.profiles {
+		value := models.ExplainedValue{
+			ID:    profile.ID,
+			Name:  profile.Name,
+			Type:  profile.TypeName,
+			Chain: make([]models.SourceRef, 0),
+		}
+		if source, found := sources[profile.ID]; found {
+			value.Source = source.ref
+			value.Chain = source.chain
+		}
+		if profile.TypeName == "Ranged Weapons" || profile.TypeName == "Melee Weapons" {
+			explanation.Weapons = append(explanation.Weapons, value)
+		} else {
+			explanation.Profiles = append(explanation.Profiles, value)
+		}
+	}
+
+	// Costs and categories come from the selectionEntry unless the entryLink overrode them
+	linkCosts := make(map[string]bool)
+	linkCategories := make(map[string]bool)
+	if entryLink != nil {
+		for _, cost := range entryLink.Costs {
+			linkCosts[cost.TypeID] = true
+		}
+		for _, catLink := range entryLink.CategoryLinks {
+			linkCategories[catLink.TargetID] = true
+		}
+	}
+	sourceOf := func(fromLink bool) (models.SourceRef, []models.SourceRef) {
+		if fromLink {
+			return *linkRef, []models.SourceRef{*linkRef}
+		}
+		return entryRef, rootChain
+	}
+
+	for _, cost := range entry.Costs {
+		if _, err := strconv.Atoi(cost.Value); err != nil {
+			continue // TransformCosts leaves these out
+		}
+		owner, chain := sourceOf(linkCosts[cost.TypeID])
+		explanation.Costs = append(explanation.Costs, models.ExplainedValue{
+			ID:     cost.TypeID,
+			Name:   cost.Name,
+			Type:   "cost",
+			Value:  cost.Value,
+			Source: t.sourceRef("cost", cost.TypeID, cost.Name, owner.CatalogueID, cost.Pos),
+			Chain:  chain,
+		})
+	}
+	sort.Slice(explanation.Costs, func(i, j int) bool {
+		return explanation.Costs[i].Name < explanation.Costs[j].Name
+	})
+
+	for _, catLink := range entry.CategoryLinks {
+		source, chain := sourceOf(linkCategories[catLink.TargetID])
+		explanation.Categories = append(explanation.Categories, models.ExplainedValue{
+			ID:     catLink.TargetID,
+			Name:   catLink.Name,
+			Type:   "category",
+			Source: source,
+			Chain:  chain,
+		})
+	}
+	sort.Slice(explanation.Categories, func(i, j int) bool {
+		return explanation.Categories[i].Name < explanation.Categories[j].Name
+	})
+
+	// Modifiers are numbered the way transformTieredCosts collects them
+	index := 0
+	addModifier := func(modifier models.Modifier, source models.SourceRef) {
+		effect, applied := tracer.trace.modifiers[index]
+		explanation.Modifiers = append(explanation.Modifiers, models.ExplainedModifier{
+			Type:    modifier.Type,
+			Field:   modifier.Field,
+			Value:   modifier.Value,
+			Applied: applied,
+			Effect:  effect,
+			Source:  source,
+		})
+		index++
+	}
+	for i, modifier := range entry.Modifiers {
+		source := entryRef
+		if linkRef != nil && i >= entryModifiers {
+			source = *linkRef
+		}
+		addModifier(modifier, source)
+	}
+	for _, group := range entry.ModifierGroups {
+		groupRef := t.sourceRef("modifierGroup", group.ID, group.Comment, entryRef.CatalogueID, models.SourcePos{})
+		for _, modifier := range group.Modifiers {
+			addModifier(modifier, groupRef)
+		}
+	}
+
+	return explanation
+}
+
+// explainOverrides lists what MergeEntryLinkWithSelectionEntry takes from entryLink
+func explainOverrides(entryLink *models.EntryLink, entry *models.SelectionEntry) []models.EntryLinkOverride {
+	overrides := make([]models.EntryLinkOverride, 0)
+
+	if entryLink.Name != "" && entryLink.Name != entry.Name {
+		overrides = append(overrides, models.EntryLinkOverride{
+			Field:    "name",
+			Action:   "replaced",
+			Original: entry.Name,
+			Value:    entryLink.Name,
+		})
+	}
+
+	costs := make(map[string]string)
+	for _, cost := range entry.Costs {
+		costs[cost.TypeID] = cost.Value
+	}
+	for _, cost := range entryLink.Costs {
+		override := models.EntryLinkOverride{Field: "costs", Action: "added", ID: cost.TypeID, Value: cost.Value}
+		if original, exists := costs[cost.TypeID]; exists {
+			override.Action = "replaced"
+			override.Original = original
+		}
+		overrides = append(overrides, override)
+	}
+
+	categories := make(map[string]string)
+	for _, catLink := range entry.CategoryLinks {
+		categories[catLink.TargetID] = catLink.Name
+	}
+	for _, catLink := range entryLink.CategoryLinks {
+		override := models.EntryLinkOverride{Field: "categoryLinks", Action: "added", ID: catLink.TargetID, Value: catLink.Name}
+		if original, exists := categories[catLink.TargetID]; exists {
+			override.Action = "replaced"
+			override.Original = original
+		}
+		overrides = append(overrides, override)
+	}
+
+	for _, constraint := range entryLink.Constraints {
+		overrides = append(overrides, models.EntryLinkOverride{
+			Field:  "constraints",
+			Action: "added",
+			ID:     constraint.ID,
+			Value:  fmt.Sprintf("%s %s %s", constraint.Type, constraint.Value, constraint.Scope),
+		})
+	}
+
+	for _, modifier := range entryLink.Modifiers {
+		overrides = append(overrides, models.EntryLinkOverride{
+			Field:  "modifiers",
+			Action: "added",
+			ID:     modifier.ID,
+			Value:  fmt.Sprintf("%s %s %s", modifier.Type, modifier.Field, modifier.Value),
+		})
+	}
+
+	return overrides
+}
+
+// sourceRef describes an element defined in the catalogue or library catalogueID at pos.
+// Elements whose position isn't recorded pass a zero pos.
+func (t *Transformer) sourceRef(kind, id, name, catalogueID string, pos models.SourcePos) models.SourceRef {
+	file, _ := t.resolver.parser.GetCatalogueFile(catalogueID)
+	return models.SourceRef{
+		Kind:        kind,
+		ID:          id,
+		Name:        name,
+		CatalogueID: catalogueID,
+		File:        file,
+		Line:        pos.Line,
+		Offset:      pos.Offset,
+	}
+}
+
+// profileSource is where a profile is defined and how it was reached from the unit
+type profileSource struct {
+	ref   models.SourceRef
+	chain []models.SourceRef
+}
+
+// extend returns a copy of chain with ref appended
+func extend(chain []models.SourceRef, ref models.SourceRef) []models.SourceRef {
+	extended := make([]models.SourceRef, 0, len(chain)+1)
+	extended = append(extended, chain...)
+	return append(extended, ref)
+}
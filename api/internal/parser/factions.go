@@ -0,0 +1,358 @@
+package parser
+
+import (
+	"sort"
+	"strings"
+
+	"grimoire-api/internal/models"
+)
+
+// factionKeywordPrefix marks the category that carries a unit's faction keyword
+const factionKeywordPrefix = "Faction: "
+
+// Faction is an army derived from the data: units sharing a faction keyword category,
+// the catalogue that defines them, child catalogues importing it and the libraries they use
+type Faction struct {
+	ID           string               // Faction keyword category ID, or the catalogue ID when there is no keyword
+	Name         string               // Keyword without the "Faction: " prefix
+	SuperFaction string               // Grand alliance, from the faction keyword its units share with other factions
+	Keyword      *models.CategoryLink // Faction keyword category, nil if none was found
+	Catalogue    *models.Catalogue    // Root catalogue for the faction
+	SubFactions  []*models.Catalogue  // Other catalogues carrying the same keyword (e.g. Blood Angels)
+	Libraries    []*models.Catalogue  // Libraries imported by any of the faction's catalogues
+	ParentID     string               // Faction whose catalogue this faction's catalogue imports
+	UnitCount    int                  // Root units in the faction's catalogues
+}
+
+// Catalogues returns the faction's root catalogue followed by its sub-faction catalogues
+func (f *Faction) Catalogues() []*models.Catalogue {
+	return append([]*models.Catalogue{f.Catalogue}, f.SubFactions...)
+}
+
+// ResolveFactions derives factions from faction keyword categories, catalogue links and
+// library imports. The result is computed once per resolver and sorted by name.
+func (lr *LinkResolver) ResolveFactions() []*Faction {
+	lr.factionsOnce.Do(func() {
+		lr.factions = lr.buildFactions()
+	})
+	return lr.factions
+}
+
+// FindFactions returns factions matching a faction ID, name, keyword or catalogue.
+// When nothing matches directly, factions belonging to a super-faction of that name are returned.
+func (lr *LinkResolver) FindFactions(query string) []*Faction {
+	var matched []*Faction
+	for _, faction := range lr.ResolveFactions() {
+		if faction.Matches(query) {
+			matched = append(matched, faction)
+		}
+	}
+	if len(matched) > 0 {
+		return matched
+	}
+
+	for _, faction := range lr.ResolveFactions() {
+		if faction.SuperFaction != "" && strings.EqualFold(faction.SuperFaction, query) {
+			matched = append(matched, faction)
+		}
+	}
+	return matched
+}
+
+// Matches reports whether query identifies this faction directly
+func (f *Faction) Matches(query string) bool {
+	if f.ID == query || strings.EqualFold(f.Name, query) {
+		return true
+	}
+	if f.Keyword != nil && strings.EqualFold(f.Keyword.Name, query) {
+		return true
+	}
+	for _, cat := range f.Catalogues() {
+		if cat.ID == query || strings.EqualFold(cat.Name, query) {
+			return true
+		}
+	}
+	return false
+}
+
+// catalogueFaction holds the faction facts derived for a single catalogue
+type catalogueFaction struct {
+	catalogue    *models.Catalogue
+	keyword      *models.CategoryLink
+	superKeyword *models.CategoryLink // Faction keyword defined outside the catalogue, such as "Faction: Imperium"
+	parent       *models.Catalogue
+	units        int
+}
+
+// buildFactions groups catalogues into factions by their faction keyword
+func (lr *LinkResolver) buildFactions() []*Faction {
+	catalogues := lr.parser.GetAllCatalogues()
+
+	ids := make([]string, 0, len(catalogues))
+	for id := range catalogues {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	derived := make(map[string]*catalogueFaction, len(ids))
+	for _, id := range ids {
+		catalogue := catalogues[id]
+		keyword, superKeyword, units := lr.factionKeywords(catalogue)
+		derived[id] = &catalogueFaction{
+			catalogue:    catalogue,
+			keyword:      keyword,
+			superKeyword: superKeyword,
+			parent:       lr.parentCatalogue(catalogue),
+			units:        units,
+		}
+	}
+
+	// Group catalogues sharing a faction keyword
+	groups := make(map[string][]*catalogueFaction)
+	var groupIDs []string
+	for _, id := range ids {
+		key := factionKey(derived[id])
+		if _, exists := groups[key]; !exists {
+			groupIDs = append(groupIDs, key)
+		}
+		groups[key] = append(groups[key], derived[id])
+	}
+
+	factions := make([]*Faction, 0, len(groupIDs))
+	byCatalogue := make(map[string]*Faction, len(ids))
+	for _, key := range groupIDs {
+		faction := lr.newFaction(key, groups[key])
+		factions = append(factions, faction)
+		for _, cat := range faction.Catalogues() {
+			byCatalogue[cat.ID] = faction
+		}
+	}
+
+	// Link factions to the faction of the catalogue their root catalogue imports
+	for _, faction := range factions {
+		if parent := derived[faction.Catalogue.ID].parent; parent != nil {
+			if parentFaction, exists := byCatalogue[parent.ID]; exists && parentFaction != faction {
+				faction.ParentID = parentFaction.ID
+			}
+		}
+		if keyword := superFactionKeyword(faction, derived); keyword != nil {
+			faction.SuperFaction = strings.TrimPrefix(keyword.Name, factionKeywordPrefix)
+		}
+	}
+
+	sort.Slice(factions, func(i, j int) bool {
+		if factions[i].Name != factions[j].Name {
+			return factions[i].Name < factions[j].Name
+		}
+		return factions[i].ID < factions[j].ID
+	})
+
+	return factions
+}
+
+// factionKey returns the grouping key for a catalogue
+func factionKey(cf *catalogueFaction) string {
+	if cf.keyword != nil {
+		return cf.keyword.TargetID
+	}
+	return cf.catalogue.ID
+}
+
+// newFaction builds a faction from the catalogues sharing its key
+func (lr *LinkResolver) newFaction(key string, members []*catalogueFaction) *Faction {
+	inGroup := make(map[string]bool, len(members))
+	for _, member := range members {
+		inGroup[member.catalogue.ID] = true
+	}
+
+	// The root catalogue is one that doesn't import another member; prefer the largest
+	root := members[0]
+	for _, member := range members {
+		isRoot := member.parent == nil || !inGroup[member.parent.ID]
+		rootIsRoot := root.parent == nil || !inGroup[root.parent.ID]
+		switch {
+		case isRoot && !rootIsRoot:
+			root = member
+		case isRoot == rootIsRoot && member.units > root.units:
+			root = member
+		}
+	}
+
+	faction := &Faction{
+		ID:          key,
+		Keyword:     root.keyword,
+		Catalogue:   root.catalogue,
+		SubFactions: make([]*models.Catalogue, 0, len(members)-1),
+	}
+	if root.keyword != nil {
+		faction.Name = strings.TrimPrefix(root.keyword.Name, factionKeywordPrefix)
+	} else {
+		faction.Name = root.catalogue.Name
+	}
+
+	for _, member := range members {
+		faction.UnitCount += member.units
+		if member != root {
+			faction.SubFactions = append(faction.SubFactions, member.catalogue)
+		}
+	}
+	sort.Slice(faction.SubFactions, func(i, j int) bool {
+		return faction.SubFactions[i].Name < faction.SubFactions[j].Name
+	})
+
+	faction.Libraries = lr.importedLibraries(faction.Catalogues())
+
+	return faction
+}
+
+// factionKeywords finds the faction keyword carried by most of a catalogue's root units, and the
+// super-faction keyword most of them carry beside it: a faction keyword defined outside the
+// catalogue and the catalogues it imports, such as the game system's "Faction: Imperium". Without
+// units, it falls back to faction categories the catalogue declares itself.
+func (lr *LinkResolver) factionKeywords(catalogue *models.Catalogue) (keyword, superKeyword *models.CategoryLink, units int) {
+	counts := make(map[string]int)
+	keywords := make(map[string]models.CategoryLink)
+
+	for i := range catalogue.EntryLinks {
+		entryLink := &catalogue.EntryLinks[i]
+		if entryLink.Type != "selectionEntry" {
+			continue
+		}
+		resolved, err := lr.ResolveEntryLink(entryLink, catalogue.ID)
+		if err != nil {
+			continue
+		}
+		units++
+
+		entry := lr.MergeEntryLinkWithSelectionEntry(entryLink, resolved)
+		for _, catLink := range entry.CategoryLinks {
+			if strings.HasPrefix(catLink.Name, factionKeywordPrefix) {
+				counts[catLink.TargetID]++
+				keywords[catLink.TargetID] = catLink
+			}
+		}
+	}
+
+	// The most common keyword names the faction; on a tie, one the catalogue defines itself
+	own := lr.definedCategories(catalogue)
+	var best string
+	for id, count := range counts {
+		if best == "" || count > counts[best] ||
+			(count == counts[best] && own[id] && !own[best]) ||
+			(count == counts[best] && own[id] == own[best] && keywords[id].Name < keywords[best].Name) {
+			best = id
+		}
+	}
+	if best == "" {
+		for _, category := range catalogue.CategoryEntries {
+			if strings.HasPrefix(category.Name, factionKeywordPrefix) {
+				return &models.CategoryLink{ID: category.ID, Name: category.Name, TargetID: category.ID}, nil, units
+			}
+		}
+		return nil, nil, units
+	}
+
+	var super string
+	for id, count := range counts {
+		if id == best || own[id] {
+			continue
+		}
+		if super == "" || count > counts[super] || (count == counts[super] && keywords[id].Name < keywords[super].Name) {
+			super = id
+		}
+	}
+
+	primary := keywords[best]
+	keyword = &primary
+	if super != "" {
+		shared := keywords[super]
+		superKeyword = &shared
+	}
+	return keyword, superKeyword, units
+}
+
+// definedCategories returns the IDs of the categories a catalogue and the catalogues and libraries
+// it links define
+func (lr *LinkResolver) definedCategories(catalogue *models.Catalogue) map[string]bool {
+	defined := make(map[string]bool)
+	seen := make(map[string]bool)
+	var visit func(catalogue *models.Catalogue)
+	visit = func(catalogue *models.Catalogue) {
+		if seen[catalogue.ID] {
+			return
+		}
+		seen[catalogue.ID] = true
+		for _, category := range catalogue.CategoryEntries {
+			defined[category.ID] = true
+		}
+		for _, catLink := range catalogue.CatalogueLinks {
+			if linked, exists := lr.parser.GetCatalogue(catLink.TargetID); exists {
+				visit(linked)
+			} else if library, exists := lr.parser.GetLibrary(catLink.TargetID); exists {
+				visit(library)
+			}
+		}
+	}
+	visit(catalogue)
+	return defined
+}
+
+// parentCatalogue returns the non-library catalogue whose root entries this catalogue imports
+func (lr *LinkResolver) parentCatalogue(catalogue *models.Catalogue) *models.Catalogue {
+	for _, catLink := range catalogue.CatalogueLinks {
+		if !catLink.ImportRootEntries || catLink.TargetID == catalogue.ID {
+			continue
+		}
+		if parent, exists := lr.parser.GetCatalogue(catLink.TargetID); exists {
+			return parent
+		}
+	}
+	return nil
+}
+
+// superFactionKeyword returns the super-faction keyword of a faction's root catalogue, else of its
+// other catalogues, else of the catalogues its root catalogue imports
+func superFactionKeyword(faction *Faction, derived map[string]*catalogueFaction) *models.CategoryLink {
+	for _, catalogue := range faction.Catalogues() {
+		if cf, exists := derived[catalogue.ID]; exists && cf.superKeyword != nil {
+			return cf.superKeyword
+		}
+	}
+	seen := map[string]bool{faction.Catalogue.ID: true}
+	for cf := derived[faction.Catalogue.ID]; cf != nil && cf.parent != nil && !seen[cf.parent.ID]; {
+		seen[cf.parent.ID] = true
+		cf = derived[cf.parent.ID]
+		if cf != nil && cf.superKeyword != nil {
+			return cf.superKeyword
+		}
+	}
+	return nil
+}
+
+// importedLibraries returns every library reachable through catalogueLinks, sorted by name
+func (lr *LinkResolver) importedLibraries(catalogues []*models.Catalogue) []*models.Catalogue {
+	seen := make(map[string]bool)
+	var libraries []*models.Catalogue
+
+	var visit func(catalogue *models.Catalogue)
+	visit = func(catalogue *models.Catalogue) {
+		for _, catLink := range catalogue.CatalogueLinks {
+			if seen[catLink.TargetID] {
+				continue
+			}
+			seen[catLink.TargetID] = true
+			if library, exists := lr.parser.GetLibrary(catLink.TargetID); exists {
+				libraries = append(libraries, library)
+				visit(library)
+			}
+		}
+	}
+	for _, catalogue := range catalogues {
+		visit(catalogue)
+	}
+
+	sort.Slice(libraries, func(i, j int) bool {
+		return libraries[i].Name < libraries[j].Name
+	})
+	return libraries
+}
@@ -0,0 +1,103 @@
+package parser
+
+import (
+	"path/filepath"
+	"testing"
+
+	"grimoire-api/internal/datatest"
+)
+
+func TestResolveFactions(t *testing.T) {
+	p := NewParser(getFixtureDataDir(t))
+	if err := p.LoadGameSystem(); err != nil {
+		t.Fatalf("Failed to load game system: %v", err)
+	}
+	if err := p.LoadAllCatalogues(); err != nil {
+		t.Fatalf("Failed to load catalogues: %v", err)
+	}
+
+	resolver := NewLinkResolver(p)
+	factions := resolver.ResolveFactions()
+
+	if len(factions) != 2 {
+		t.Fatalf("Expected 2 factions, got %d", len(factions))
+	}
+
+	astartes := factions[0]
+	if astartes.ID != "fac-fixture-astartes" || astartes.Name != "Adeptus Astartes" {
+		t.Errorf("Unexpected first faction: %s (%s)", astartes.Name, astartes.ID)
+	}
+	if astartes.SuperFaction != "Imperium" {
+		t.Errorf("Expected super-faction Imperium, got %q", astartes.SuperFaction)
+	}
+	if astartes.Catalogue.ID != "cat-fixture-marines" {
+		t.Errorf("Expected root catalogue cat-fixture-marines, got %s", astartes.Catalogue.ID)
+	}
+	if len(astartes.SubFactions) != 1 || astartes.SubFactions[0].ID != "cat-fixture-blood-angels" {
+		t.Errorf("Expected Blood Angels as the only sub-faction, got %d", len(astartes.SubFactions))
+	}
+	if len(astartes.Libraries) != 1 || astartes.Libraries[0].ID != "lib-fixture-astartes" {
+		t.Errorf("Expected the Astartes library to be imported, got %d libraries", len(astartes.Libraries))
+	}
+	if astartes.UnitCount != 5 {
+		t.Errorf("Expected 5 resolvable units, got %d", astartes.UnitCount)
+	}
+
+	daemons := factions[1]
+	if daemons.Name != "Legiones Daemonica" || daemons.SuperFaction != "Chaos" {
+		t.Errorf("Unexpected second faction: %s (%s)", daemons.Name, daemons.SuperFaction)
+	}
+}
+
+func TestSuperFactionsFromKeywords(t *testing.T) {
+	// Super-factions come from the faction keywords units share, not from the catalogue names
+	dataDir := datatest.CopyFixture(t)
+	datatest.EditFile(t, filepath.Join(dataDir, "Chaos - Fixture Daemons.cat"), `name="Chaos - Fixture Daemons"`, `name="Fixture Daemons"`)
+	datatest.EditFile(t, filepath.Join(dataDir, "Imperium - Fixture Marines.cat"), `name="Imperium - Fixture Marines"`, `name="Fixture Marines"`)
+
+	p := NewParser(dataDir)
+	p.SetQuiet(true)
+	if err := p.LoadAllCatalogues(); err != nil {
+		t.Fatalf("Failed to load catalogues: %v", err)
+	}
+	resolver := NewLinkResolver(p)
+
+	for _, faction := range resolver.ResolveFactions() {
+		switch faction.ID {
+		case "fac-fixture-astartes":
+			if faction.SuperFaction != "Imperium" {
+				t.Errorf("Expected super-faction Imperium for %s, got %q", faction.Name, faction.SuperFaction)
+			}
+		case "fac-fixture-daemons":
+			if faction.SuperFaction != "Chaos" {
+				t.Errorf("Expected super-faction Chaos for %s, got %q", faction.Name, faction.SuperFaction)
+			}
+		default:
+			t.Errorf("Unexpected faction %s (%s)", faction.Name, faction.ID)
+		}
+	}
+
+	found := resolver.FindFactions("Chaos")
+	if len(found) != 1 || found[0].ID != "fac-fixture-daemons" {
+		t.Errorf("Expected Chaos to find Legiones Daemonica, got %d factions", len(found))
+	}
+}
+
+func TestFindFactions(t *testing.T) {
+	p := NewParser(getFixtureDataDir(t))
+	if err := p.LoadAllCatalogues(); err != nil {
+		t.Fatalf("Failed to load catalogues: %v", err)
+	}
+	resolver := NewLinkResolver(p)
+
+	for _, query := range []string{"fac-fixture-astartes", "adeptus astartes", "Faction: Adeptus Astartes", "Imperium - Fixture Blood Angels", "Imperium"} {
+		found := resolver.FindFactions(query)
+		if len(found) != 1 || found[0].ID != "fac-fixture-astartes" {
+			t.Errorf("Expected %q to find Adeptus Astartes, got %d factions", query, len(found))
+		}
+	}
+
+	if found := resolver.FindFactions("Chaos Space Marines"); len(found) != 0 {
+		t.Errorf("Expected no factions for unknown name, got %d", len(found))
+	}
+}
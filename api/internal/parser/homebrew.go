@@ -0,0 +1,20 @@
+package parser
+
+import "grimoire-api/internal/models"
+
+// AddHomebrewCatalogue registers an uploaded catalogue alongside the loaded data, replacing any
+// upload with the same ID. file is reported as the catalogue's file.
+func (p *Parser) AddHomebrewCatalogue(catalogue *models.Catalogue, file string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.catalogues[catalogue.ID] = catalogue
+	p.files[catalogue.ID] = file
+	p.homebrew[catalogue.ID] = true
+}
+
+// IsHomebrew reports whether a catalogue was uploaded rather than loaded from the data directory
+func (p *Parser) IsHomebrew(id string) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.homebrew[id]
+}
@@ -0,0 +1,95 @@
+package parser
+
+import (
+	"encoding/xml"
+	"reflect"
+	"strings"
+	"sync"
+	"unique"
+)
+
+// The same few strings repeat across every catalogue: type names, typeIds, targetIds,
+// characteristic names and short values like "3+" or "D6". Decoding allocates each occurrence
+// separately, so after a file is decoded every attribute and short text value is swapped for a
+// canonical copy shared by all catalogues. Long text, such as rule descriptions, is left alone.
+
+// maxInternedText is the longest element text that is interned
+const maxInternedText = 32
+
+// internField is a string field to intern, by its index path from the struct
+type internField struct {
+	index []int
+	text  bool // Element text rather than an attribute, interned only when short
+}
+
+// internPlan says which fields of a struct type hold strings to intern and which to descend into
+type internPlan struct {
+	strings  []internField
+	children [][]int
+}
+
+var internPlans sync.Map // reflect.Type -> *internPlan
+
+// intern replaces the decoded strings in v, a pointer to a decoded model, with canonical copies
+func intern(v interface{}) {
+	internValue(reflect.ValueOf(v))
+}
+
+func internValue(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			internValue(v.Elem())
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			internValue(v.Index(i))
+		}
+	case reflect.Struct:
+		plan := planFor(v.Type())
+		for _, field := range plan.strings {
+			s := v.FieldByIndex(field.index)
+			if s.Len() == 0 || (field.text && s.Len() > maxInternedText) {
+				continue
+			}
+			s.SetString(unique.Make(s.String()).Value())
+		}
+		for _, index := range plan.children {
+			internValue(v.FieldByIndex(index))
+		}
+	}
+}
+
+var xmlNameType = reflect.TypeOf(xml.Name{})
+
+// planFor returns the intern plan of a struct type, working it out on first use
+func planFor(t reflect.Type) *internPlan {
+	if plan, ok := internPlans.Load(t); ok {
+		return plan.(*internPlan)
+	}
+
+	plan := &internPlan{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("xml")
+		if !field.IsExported() || tag == "-" {
+			continue
+		}
+		switch {
+		case field.Type == xmlNameType:
+			// The namespace and name of every decoded element
+			plan.strings = append(plan.strings, internField{index: []int{i, 0}}, internField{index: []int{i, 1}})
+		case field.Type.Kind() == reflect.String:
+			if strings.HasSuffix(tag, ",attr") {
+				plan.strings = append(plan.strings, internField{index: []int{i}})
+			} else if strings.HasSuffix(tag, ",chardata") || !strings.Contains(tag, ",") {
+				plan.strings = append(plan.strings, internField{index: []int{i}, text: true})
+			}
+		case field.Type.Kind() == reflect.Ptr || field.Type.Kind() == reflect.Slice || field.Type.Kind() == reflect.Struct:
+			plan.children = append(plan.children, []int{i})
+		}
+	}
+
+	actual, _ := internPlans.LoadOrStore(t, plan)
+	return actual.(*internPlan)
+}
@@ -0,0 +1,57 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+	"unsafe"
+
+	"grimoire-api/internal/models"
+)
+
+func TestDecodeInternsRepeatedStrings(t *testing.T) {
+	const doc = `<catalogue id="cat-a" name="A" library="true">
+  <sharedProfiles>
+    <profile id="p1" name="One" hidden="false" typeId="c547-1836-d8a-ff4f" typeName="Unit">
+      <characteristics><characteristic name="SV" typeId="450-a17e-9d5e-29da">3+</characteristic></characteristics>
+    </profile>
+    <profile id="p2" name="Two" hidden="true" typeId="c547-1836-d8a-ff4f" typeName="Unit">
+      <characteristics><characteristic name="SV" typeId="450-a17e-9d5e-29da">3+</characteristic></characteristics>
+    </profile>
+  </sharedProfiles>
+</catalogue>`
+
+	catalogue, err := DecodeCatalogue(strings.NewReader(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !catalogue.Library {
+		t.Error("library=\"true\" should decode to true")
+	}
+	profiles := catalogue.SharedProfiles
+	if len(profiles) != 2 {
+		t.Fatalf("Expected 2 profiles, got %d", len(profiles))
+	}
+	if profiles[0].Hidden || !profiles[1].Hidden {
+		t.Error("hidden should decode to a bool")
+	}
+
+	same := func(what, a, b string) {
+		t.Helper()
+		if a != b || unsafe.StringData(a) != unsafe.StringData(b) {
+			t.Errorf("%s should share one copy of %q", what, a)
+		}
+	}
+	same("typeId", profiles[0].TypeID, profiles[1].TypeID)
+	same("typeName", profiles[0].TypeName, profiles[1].TypeName)
+	first, second := profiles[0].Characteristics[0], profiles[1].Characteristics[0]
+	same("characteristic name", first.Name, second.Name)
+	same("characteristic value", first.Value, second.Value)
+
+	// Long text isn't interned
+	long := models.Characteristic{Value: strings.Repeat("x", maxInternedText+1)}
+	value := long.Value
+	intern(&long)
+	if unsafe.StringData(long.Value) != unsafe.StringData(value) {
+		t.Error("Text longer than maxInternedText should be left alone")
+	}
+}
@@ -1,7 +1,10 @@
 package parser
 
 import (
+	"context"
 	"fmt"
+	"sort"
+	"sync"
 
 	"grimoire-api/internal/models"
 )
@@ -9,6 +12,11 @@ import (
 // LinkResolver resolves entryLinks to their actual selectionEntries
 type LinkResolver struct {
 	parser *Parser
+
+	factionsOnce sync.Once
+	factions     []*Faction
+	graphOnce    sync.Once
+	graph        *CatalogueGraph
 }
 
 // NewLinkResolver creates a new link resolver
@@ -18,15 +26,22 @@ func NewLinkResolver(parser *Parser) *LinkResolver {
 
 // ResolveEntryLink resolves an entryLink to its selectionEntry
 func (lr *LinkResolver) ResolveEntryLink(entryLink *models.EntryLink, catalogueID string) (*models.SelectionEntry, error) {
+	entry, _, err := lr.ResolveEntryLinkSource(entryLink, catalogueID)
+	return entry, err
+}
+
+// ResolveEntryLinkSource resolves an entryLink like ResolveEntryLink, also returning the ID of the
+// catalogue or library the selectionEntry was found in
+func (lr *LinkResolver) ResolveEntryLinkSource(entryLink *models.EntryLink, catalogueID string) (*models.SelectionEntry, string, error) {
 	targetID := entryLink.TargetID
 	if targetID == "" {
-		return nil, fmt.Errorf("entryLink has no targetId")
+		return nil, "", fmt.Errorf("entryLink has no targetId")
 	}
 
 	// First, check if catalogueID is a library and search there first
 	if library, exists := lr.parser.GetLibrary(catalogueID); exists {
 		if entry := findEntryInCatalogue(library, targetID); entry != nil {
-			return entry, nil
+			return entry, library.ID, nil
 		}
 	}
 
@@ -34,11 +49,11 @@ func (lr *LinkResolver) ResolveEntryLink(entryLink *models.EntryLink, catalogueI
 	if catalogue, exists := lr.parser.GetCatalogue(catalogueID); exists {
 		// Check catalogueLinks for libraries
 		for _, catLink := range catalogue.CatalogueLinks {
-			if catLink.ImportRootEntries == "true" {
+			if catLink.ImportRootEntries {
 				library, libExists := lr.parser.GetLibrary(catLink.TargetID)
 				if libExists {
 					if entry := findEntryInCatalogue(library, targetID); entry != nil {
-						return entry, nil
+						return entry, library.ID, nil
 					}
 				}
 			}
@@ -48,18 +63,69 @@ func (lr *LinkResolver) ResolveEntryLink(entryLink *models.EntryLink, catalogueI
 	// Search all libraries
 	for _, library := range lr.parser.GetAllLibraries() {
 		if entry := findEntryInCatalogue(library, targetID); entry != nil {
-			return entry, nil
+			return entry, library.ID, nil
 		}
 	}
 
 	// Search all catalogues
 	for _, cat := range lr.parser.GetAllCatalogues() {
 		if entry := findEntryInCatalogue(cat, targetID); entry != nil {
-			return entry, nil
+			return entry, cat.ID, nil
 		}
 	}
 
-	return nil, fmt.Errorf("selectionEntry with id %s not found", targetID)
+	return nil, "", fmt.Errorf("selectionEntry with id %s not found", targetID)
+}
+
+// RootUnit is a unit entryLink at the root of a catalogue, resolved and merged with its selectionEntry
+type RootUnit struct {
+	Catalogue *models.Catalogue
+	EntryLink *models.EntryLink
+	Entry     *models.SelectionEntry
+}
+
+// ResolveRootUnits resolves every selectionEntry entryLink at the root of each catalogue,
+// ordered by catalogue name and then by link order. Links that fail to resolve are skipped.
+func (lr *LinkResolver) ResolveRootUnits() []RootUnit {
+	units, _ := lr.ResolveRootUnitsContext(context.Background())
+	return units
+}
+
+// ResolveRootUnitsContext is ResolveRootUnits, stopping with the context's error once the context is done
+func (lr *LinkResolver) ResolveRootUnitsContext(ctx context.Context) ([]RootUnit, error) {
+	catalogues := make([]*models.Catalogue, 0)
+	for _, cat := range lr.parser.GetAllCatalogues() {
+		catalogues = append(catalogues, cat)
+	}
+	sort.Slice(catalogues, func(i, j int) bool {
+		if catalogues[i].Name != catalogues[j].Name {
+			return catalogues[i].Name < catalogues[j].Name
+		}
+		return catalogues[i].ID < catalogues[j].ID
+	})
+
+	var units []RootUnit
+	for _, catalogue := range catalogues {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		for i := range catalogue.EntryLinks {
+			entryLink := &catalogue.EntryLinks[i]
+			if entryLink.Type != "selectionEntry" {
+				continue
+			}
+			resolved, err := lr.ResolveEntryLink(entryLink, catalogue.ID)
+			if err != nil {
+				continue
+			}
+			units = append(units, RootUnit{
+				Catalogue: catalogue,
+				EntryLink: entryLink,
+				Entry:     lr.MergeEntryLinkWithSelectionEntry(entryLink, resolved),
+			})
+		}
+	}
+	return units, nil
 }
 
 // ResolveCatalogueLinks resolves all catalogueLinks for a catalogue
@@ -86,33 +152,40 @@ func (lr *LinkResolver) MergeEntryLinkWithSelectionEntry(entryLink *models.Entry
 	}
 
 	// Merge costs (entryLink costs override selectionEntry costs)
+	// The selectionEntry's order is kept and new costs are appended, so every load merges the same way
 	if len(entryLink.Costs) > 0 {
-		costMap := make(map[string]*models.Cost)
-		for i := range merged.Costs {
-			costMap[merged.Costs[i].TypeID] = &merged.Costs[i]
-		}
+		costs := append([]models.Cost(nil), merged.Costs...)
 		for _, cost := range entryLink.Costs {
-			costMap[cost.TypeID] = &cost
-		}
-		merged.Costs = make([]models.Cost, 0, len(costMap))
-		for _, cost := range costMap {
-			merged.Costs = append(merged.Costs, *cost)
+			replaced := false
+			for i := range costs {
+				if costs[i].TypeID == cost.TypeID {
+					costs[i] = cost
+					replaced = true
+				}
+			}
+			if !replaced {
+				costs = append(costs, cost)
+			}
 		}
+		merged.Costs = costs
 	}
 
 	// Merge categoryLinks
 	if len(entryLink.CategoryLinks) > 0 {
-		catMap := make(map[string]*models.CategoryLink)
-		for i := range merged.CategoryLinks {
-			catMap[merged.CategoryLinks[i].TargetID] = &merged.CategoryLinks[i]
-		}
+		categoryLinks := append([]models.CategoryLink(nil), merged.CategoryLinks...)
 		for _, catLink := range entryLink.CategoryLinks {
-			catMap[catLink.TargetID] = &catLink
-		}
-		merged.CategoryLinks = make([]models.CategoryLink, 0, len(catMap))
-		for _, catLink := range catMap {
-			merged.CategoryLinks = append(merged.CategoryLinks, *catLink)
+			replaced := false
+			for i := range categoryLinks {
+				if categoryLinks[i].TargetID == catLink.TargetID {
+					categoryLinks[i] = catLink
+					replaced = true
+				}
+			}
+			if !replaced {
+				categoryLinks = append(categoryLinks, catLink)
+			}
 		}
+		merged.CategoryLinks = categoryLinks
 	}
 
 	// Merge constraints
@@ -127,6 +200,10 @@ func (lr *LinkResolver) MergeEntryLinkWithSelectionEntry(entryLink *models.Entry
 		merged.Modifiers = append(merged.Modifiers, entryLink.Modifiers...)
 	}
 
+	// Keep the overlay marks of both the entryLink and the selectionEntry
+	if len(entryLink.Overlays) > 0 {
+		merged.Overlays = append(append([]models.OverlayMark(nil), merged.Overlays...), entryLink.Overlays...)
+	}
+
 	return &merged
 }
-
@@ -0,0 +1,15 @@
+package parser
+
+// MarkOverlaid records that local overlays changed the loaded data, so transformed units collect their marks
+func (p *Parser) MarkOverlaid() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.overlaid = true
+}
+
+// Overlaid reports whether local overlays changed the loaded data
+func (p *Parser) Overlaid() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.overlaid
+}
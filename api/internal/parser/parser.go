@@ -1,25 +1,35 @@
 package parser
 
 import (
+	"bufio"
+	"context"
 	"encoding/xml"
 	"fmt"
+	"io"
 	"io/fs"
 	"log"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"sync"
+	"time"
 
 	"grimoire-api/internal/models"
 )
 
 // Parser handles parsing of BattleScribe XML files
 type Parser struct {
-	dataDir      string
-	gameSystem   *models.GameSystem
-	catalogues   map[string]*models.Catalogue
-	libraries    map[string]*models.Catalogue
-	mu           sync.RWMutex
+	dataDir    string
+	gameSystem *models.GameSystem
+	catalogues map[string]*models.Catalogue
+	libraries  map[string]*models.Catalogue
+	files      map[string]string // Catalogue ID -> file path relative to dataDir
+	quiet      bool
+	overlaid   bool            // Set once local overlays have changed the data
+	homebrew   map[string]bool // IDs of uploaded catalogues
+	loadReport *models.LoadReport
+	mu         sync.RWMutex
 }
 
 // NewParser creates a new parser instance
@@ -28,34 +38,80 @@ func NewParser(dataDir string) *Parser {
 		dataDir:    dataDir,
 		catalogues: make(map[string]*models.Catalogue),
 		libraries:  make(map[string]*models.Catalogue),
+		files:      make(map[string]string),
+		homebrew:   make(map[string]bool),
+	}
+}
+
+// LoadDataDir creates a parser and loads the game system and all catalogues from dataDir
+func LoadDataDir(dataDir string) (*Parser, error) {
+	p := NewParser(dataDir)
+	if err := p.LoadGameSystem(); err != nil {
+		return nil, err
+	}
+	if err := p.LoadAllCatalogues(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// SetQuiet turns off the per-file load logging, for example when loading old revisions in the background
+func (p *Parser) SetQuiet(quiet bool) {
+	p.quiet = quiet
+}
+
+// logf logs load progress unless the parser is quiet
+func (p *Parser) logf(format string, args ...interface{}) {
+	if !p.quiet {
+		log.Printf(format, args...)
 	}
 }
 
 // LoadGameSystem loads and parses the game system file
 func (p *Parser) LoadGameSystem() error {
-	gstFile := filepath.Join(p.dataDir, "Warhammer 40,000.gst")
-	
-	data, err := os.ReadFile(gstFile)
-	if err != nil {
-		return fmt.Errorf("failed to read game system file: %w", err)
-	}
+	gstFile := filepath.Join(p.dataDir, GameSystemFile)
 
 	var gameSystem models.GameSystem
-	if err := xml.Unmarshal(data, &gameSystem); err != nil {
-		return fmt.Errorf("failed to parse game system file: %w", err)
+	if err := decodeFile(gstFile, &gameSystem); err != nil {
+		return fmt.Errorf("failed to load game system file: %w", err)
 	}
 
 	p.mu.Lock()
 	p.gameSystem = &gameSystem
 	p.mu.Unlock()
 
-	log.Printf("Loaded game system: %s (revision %s)", gameSystem.Name, gameSystem.Revision)
+	p.logf("Loaded game system: %s (revision %s)", gameSystem.Name, gameSystem.Revision)
 	return nil
 }
 
-// LoadAllCatalogues loads all catalogue files from the data directory
+// LoadErrors is returned by LoadAllCatalogues when some files failed to load. The files that
+// parsed are loaded all the same.
+type LoadErrors struct {
+	Report *models.LoadReport
+}
+
+func (e *LoadErrors) Error() string {
+	failed := make([]string, 0, len(e.Report.Errors))
+	for _, loadErr := range e.Report.Errors {
+		failed = append(failed, loadErr.File+": "+loadErr.Error)
+	}
+	return fmt.Sprintf("%d of %d catalogue files failed to load: %s", len(e.Report.Errors), e.Report.Files, strings.Join(failed, "; "))
+}
+
+// LoadAllCatalogues loads all catalogue files from the data directory. Files are decoded in
+// parallel and a bad file doesn't stop the others: every file that parses is loaded, and if any
+// failed a *LoadErrors listing them is returned. Errors reading the directory itself fail the load.
 func (p *Parser) LoadAllCatalogues() error {
-	return filepath.WalkDir(p.dataDir, func(path string, d fs.DirEntry, err error) error {
+	return p.LoadAllCataloguesContext(context.Background())
+}
+
+// LoadAllCataloguesContext is LoadAllCatalogues, stopping with the context's error, without adding
+// any catalogue, once the context is done
+func (p *Parser) LoadAllCataloguesContext(ctx context.Context) error {
+	start := time.Now()
+
+	var paths []string
+	err := filepath.WalkDir(p.dataDir, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
@@ -68,35 +124,156 @@ func (p *Parser) LoadAllCatalogues() error {
 			return nil
 		}
 
-		return p.LoadCatalogue(path)
+		paths = append(paths, path)
+		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	// Decode with a bounded pool of workers, then add the results in walk order so that
+	// the outcome (including which of two files with the same ID wins) doesn't depend on timing
+	type result struct {
+		catalogue *models.Catalogue
+		err       error
+	}
+	results := make([]result, len(paths))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(runtime.GOMAXPROCS(0), len(paths)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if ctx.Err() != nil {
+					continue
+				}
+				var catalogue models.Catalogue
+				if err := decodeFile(paths[i], &catalogue); err != nil {
+					results[i].err = err
+					continue
+				}
+				results[i].catalogue = &catalogue
+			}
+		}()
+	}
+	for i := range paths {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	report := &models.LoadReport{
+		Files:  len(paths),
+		Errors: make([]models.LoadError, 0),
+	}
+	for i, path := range paths {
+		if results[i].err != nil {
+			report.Errors = append(report.Errors, models.LoadError{
+				File:  p.relativePath(path),
+				Error: results[i].err.Error(),
+			})
+			continue
+		}
+		p.addCatalogue(results[i].catalogue, path)
+		report.Loaded++
+	}
+	report.Duration = time.Since(start)
+
+	p.mu.Lock()
+	p.loadReport = report
+	p.mu.Unlock()
+
+	if len(report.Errors) > 0 {
+		return &LoadErrors{Report: report}
+	}
+	return nil
+}
+
+// LoadReport returns the report of the last LoadAllCatalogues, or nil if it hasn't run
+func (p *Parser) LoadReport() *models.LoadReport {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.loadReport
 }
 
 // LoadCatalogue loads and parses a single catalogue file
 func (p *Parser) LoadCatalogue(filePath string) error {
-	data, err := os.ReadFile(filePath)
-	if err != nil {
-		return fmt.Errorf("failed to read catalogue file %s: %w", filePath, err)
-	}
-
 	var catalogue models.Catalogue
-	if err := xml.Unmarshal(data, &catalogue); err != nil {
-		return fmt.Errorf("failed to parse catalogue file %s: %w", filePath, err)
+	if err := decodeFile(filePath, &catalogue); err != nil {
+		return fmt.Errorf("failed to load catalogue file %s: %w", filePath, err)
 	}
 
+	p.addCatalogue(&catalogue, filePath)
+	return nil
+}
+
+// addCatalogue adds a decoded catalogue or library loaded from filePath
+func (p *Parser) addCatalogue(catalogue *models.Catalogue, filePath string) {
 	p.mu.Lock()
-	if catalogue.Library == "true" {
-		p.libraries[catalogue.ID] = &catalogue
-		log.Printf("Loaded library: %s (revision %s)", catalogue.Name, catalogue.Revision)
+	p.files[catalogue.ID] = p.relativePath(filePath)
+	if catalogue.Library {
+		p.libraries[catalogue.ID] = catalogue
+		p.logf("Loaded library: %s (revision %s)", catalogue.Name, catalogue.Revision)
 	} else {
-		p.catalogues[catalogue.ID] = &catalogue
-		log.Printf("Loaded catalogue: %s (revision %s)", catalogue.Name, catalogue.Revision)
+		p.catalogues[catalogue.ID] = catalogue
+		p.logf("Loaded catalogue: %s (revision %s)", catalogue.Name, catalogue.Revision)
 	}
 	p.mu.Unlock()
+}
 
+// relativePath returns filePath relative to the data directory, with forward slashes
+func (p *Parser) relativePath(filePath string) string {
+	relPath, err := filepath.Rel(p.dataDir, filePath)
+	if err != nil {
+		relPath = filePath
+	}
+	return filepath.ToSlash(relPath)
+}
+
+// decodeFile streams an XML file into v. Decoding from the file rather than a byte slice lets
+// the models record the line and offset of each element as it is read.
+func decodeFile(filePath string, v interface{}) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return decode(file, v)
+}
+
+// DecodeCatalogue reads a catalogue from r, recording element positions as LoadCatalogue does
+func DecodeCatalogue(r io.Reader) (*models.Catalogue, error) {
+	var catalogue models.Catalogue
+	if err := decode(r, &catalogue); err != nil {
+		return nil, err
+	}
+	return &catalogue, nil
+}
+
+// decode streams an XML document into v, interning its repeated strings entry by entry
+func decode(r io.Reader, v interface{}) error {
+	if err := decodeStream(xml.NewDecoder(bufio.NewReader(r)), v); err != nil {
+		return fmt.Errorf("invalid XML: %w", err)
+	}
 	return nil
 }
 
+// GameSystemFile is the name of the game system file in the data directory
+const GameSystemFile = "Warhammer 40,000.gst"
+
+// GetCatalogueFile returns the file a catalogue or library was loaded from, relative to the data directory
+func (p *Parser) GetCatalogueFile(id string) (string, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	file, exists := p.files[id]
+	return file, exists
+}
+
 // GetGameSystem returns the loaded game system
 func (p *Parser) GetGameSystem() *models.GameSystem {
 	p.mu.RLock()
@@ -146,7 +323,7 @@ func (p *Parser) GetAllLibraries() map[string]*models.Catalogue {
 func (p *Parser) GetProfile(profileID string, catalogueID string) (*models.Profile, bool) {
 	p.mu.RLock()
 	defer p.mu.RUnlock()
-	
+
 	// Try regular catalogue first
 	if cat, exists := p.catalogues[catalogueID]; exists {
 		for i := range cat.SharedProfiles {
@@ -155,7 +332,7 @@ func (p *Parser) GetProfile(profileID string, catalogueID string) (*models.Profi
 			}
 		}
 	}
-	
+
 	// Try library
 	if lib, exists := p.libraries[catalogueID]; exists {
 		for i := range lib.SharedProfiles {
@@ -164,7 +341,7 @@ func (p *Parser) GetProfile(profileID string, catalogueID string) (*models.Profi
 			}
 		}
 	}
-	
+
 	return nil, false
 }
 
@@ -260,4 +437,3 @@ func findEntryInGroup(group *models.SelectionEntryGroup, id string) *models.Sele
 
 	return nil
 }
-
@@ -0,0 +1,63 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// The load benchmarks report the heap the loaded data keeps alive as retained-KB, alongside the
+// usual time and allocations of a load. Run them on the full data set with:
+//
+//	TEST_DATA_DIR=../wh40k-10e go test ./internal/parser -run '^$' -bench Load -benchmem
+
+func BenchmarkLoadDataDir(b *testing.B) {
+	dataDir := getTestDataDir(b)
+	if _, err := os.Stat(filepath.Join(dataDir, GameSystemFile)); err != nil {
+		b.Skipf("No game system in %s", dataDir)
+	}
+	benchmarkLoad(b, dataDir)
+}
+
+func BenchmarkLoadFixture(b *testing.B) {
+	benchmarkLoad(b, getFixtureDataDir(b))
+}
+
+func benchmarkLoad(b *testing.B, dataDir string) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		p := NewParser(dataDir)
+		p.SetQuiet(true)
+		if err := p.LoadGameSystem(); err != nil {
+			b.Fatal(err)
+		}
+		if err := p.LoadAllCatalogues(); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.StopTimer()
+	b.ReportMetric(retainedKB(b, dataDir), "retained-KB")
+}
+
+// retainedKB loads dataDir once more and measures how much live heap the parser holds. The heap can
+// shrink while loading when earlier garbage is collected, so the difference is taken signed.
+func retainedKB(b *testing.B, dataDir string) float64 {
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+
+	p := NewParser(dataDir)
+	p.SetQuiet(true)
+	if err := p.LoadGameSystem(); err != nil {
+		b.Fatal(err)
+	}
+	if err := p.LoadAllCatalogues(); err != nil {
+		b.Fatal(err)
+	}
+
+	runtime.GC()
+	runtime.ReadMemStats(&after)
+	runtime.KeepAlive(p)
+	return float64(int64(after.HeapAlloc)-int64(before.HeapAlloc)) / (1 << 10)
+}
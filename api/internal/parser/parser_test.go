@@ -3,7 +3,10 @@ package parser
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+
+	"grimoire-api/internal/datatest"
 )
 
 func TestNewParser(t *testing.T) {
@@ -75,7 +78,7 @@ func TestLoadCatalogue(t *testing.T) {
 	}
 
 	p := NewParser(dataDir)
-	
+
 	// Try to load a known catalogue file
 	catalogueFile := filepath.Join(dataDir, "Aeldari - Craftworlds.cat")
 	if _, err := os.Stat(catalogueFile); os.IsNotExist(err) {
@@ -104,7 +107,7 @@ func TestFindSelectionEntryByID(t *testing.T) {
 	}
 
 	p := NewParser(dataDir)
-	
+
 	// Load game system and catalogues
 	if err := p.LoadGameSystem(); err != nil {
 		t.Fatalf("Failed to load game system: %v", err)
@@ -116,7 +119,7 @@ func TestFindSelectionEntryByID(t *testing.T) {
 	// Test with known unit ID (Asurmen from Aeldari Library)
 	unitID := "828d-840a-9a67-9074"
 	entry, catalogueID, found := p.FindSelectionEntryByID(unitID)
-	
+
 	if !found {
 		t.Fatalf("Unit %s not found", unitID)
 	}
@@ -159,7 +162,7 @@ func TestFindEntryLinkByID(t *testing.T) {
 	}
 
 	p := NewParser(dataDir)
-	
+
 	if err := p.LoadGameSystem(); err != nil {
 		t.Fatalf("Failed to load game system: %v", err)
 	}
@@ -170,7 +173,7 @@ func TestFindEntryLinkByID(t *testing.T) {
 	// Test with known entryLink ID (Warlock from Craftworlds)
 	entryLinkID := "a502-4dbe-d0c6-69fd"
 	entryLink, catalogueID, found := p.FindEntryLinkByID(entryLinkID)
-	
+
 	if !found {
 		t.Fatalf("EntryLink %s not found", entryLinkID)
 	}
@@ -212,7 +215,7 @@ func TestGetAllCatalogues(t *testing.T) {
 	}
 
 	p := NewParser(dataDir)
-	
+
 	if err := p.LoadGameSystem(); err != nil {
 		t.Fatalf("Failed to load game system: %v", err)
 	}
@@ -256,7 +259,7 @@ func TestGetAllLibraries(t *testing.T) {
 	}
 
 	p := NewParser(dataDir)
-	
+
 	if err := p.LoadGameSystem(); err != nil {
 		t.Fatalf("Failed to load game system: %v", err)
 	}
@@ -272,7 +275,7 @@ func TestGetAllLibraries(t *testing.T) {
 	// Verify we have library files
 	hasLibrary := false
 	for _, lib := range libraries {
-		if lib.Library == "true" {
+		if lib.Library {
 			hasLibrary = true
 			break
 		}
@@ -283,4 +286,84 @@ func TestGetAllLibraries(t *testing.T) {
 	}
 }
 
+func TestSourcePositions(t *testing.T) {
+	dataDir := getFixtureDataDir(t)
+	p := NewParser(dataDir)
+	if err := p.LoadGameSystem(); err != nil {
+		t.Fatalf("Failed to load game system: %v", err)
+	}
+	if err := p.LoadAllCatalogues(); err != nil {
+		t.Fatalf("Failed to load catalogues: %v", err)
+	}
+
+	entryLink, catalogueID, found := p.FindEntryLinkByID("el-fixture-captain")
+	if !found {
+		t.Fatal("el-fixture-captain not found")
+	}
+	file, _ := p.GetCatalogueFile(catalogueID)
+	data, err := os.ReadFile(filepath.Join(dataDir, file))
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", file, err)
+	}
 
+	if entryLink.Pos.Line != 14 {
+		t.Errorf("Expected el-fixture-captain on line 14, got %d", entryLink.Pos.Line)
+	}
+	// The offset points just past the start tag
+	start := strings.LastIndex(string(data[:entryLink.Pos.Offset]), "<entryLink ")
+	if start < 0 || !strings.HasPrefix(string(data[start:]), `<entryLink id="el-fixture-captain"`) || data[entryLink.Pos.Offset-1] != '>' {
+		t.Errorf("Offset %d does not follow the el-fixture-captain start tag", entryLink.Pos.Offset)
+	}
+
+	entry, _, found := p.FindSelectionEntryByID("se-fixture-captain")
+	if !found {
+		t.Fatal("se-fixture-captain not found")
+	}
+	if entry.Pos.Line != 88 {
+		t.Errorf("Expected se-fixture-captain on line 88, got %d", entry.Pos.Line)
+	}
+	if len(entry.Profiles) == 0 || entry.Profiles[0].Pos.Line <= entry.Pos.Line {
+		t.Errorf("Expected the captain's profile to be recorded after line %d", entry.Pos.Line)
+	}
+	if len(entry.Costs) == 0 || entry.Costs[0].Pos.Line <= entry.Pos.Line {
+		t.Errorf("Expected the captain's cost to be recorded after line %d", entry.Pos.Line)
+	}
+}
+
+func TestLoadAllCataloguesReportsBadFiles(t *testing.T) {
+	dataDir := datatest.CopyFixture(t)
+	broken := `<?xml version="1.0"?><catalogue id="cat-broken" name="Broken"><sharedSelectionEntries>`
+	if err := os.WriteFile(filepath.Join(dataDir, "Broken.cat"), []byte(broken), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	p := NewParser(dataDir)
+	p.SetQuiet(true)
+	err := p.LoadAllCatalogues()
+
+	loadErrs, ok := err.(*LoadErrors)
+	if !ok {
+		t.Fatalf("Expected *LoadErrors, got %v", err)
+	}
+	report := p.LoadReport()
+	if loadErrs.Report != report {
+		t.Error("LoadErrors should carry the parser's load report")
+	}
+	if report.Files != 5 || report.Loaded != 4 {
+		t.Errorf("Expected 4 of 5 files loaded, got %d of %d", report.Loaded, report.Files)
+	}
+	if len(report.Errors) != 1 || report.Errors[0].File != "Broken.cat" {
+		t.Fatalf("Expected one error for Broken.cat, got %+v", report.Errors)
+	}
+	if !strings.Contains(err.Error(), "Broken.cat") {
+		t.Errorf("Error should name the bad file: %v", err)
+	}
+
+	// Every other file is loaded all the same
+	if len(p.GetAllCatalogues()) != 3 || len(p.GetAllLibraries()) != 1 {
+		t.Errorf("Expected 3 catalogues and 1 library, got %d and %d", len(p.GetAllCatalogues()), len(p.GetAllLibraries()))
+	}
+	if _, found := p.GetCatalogueFile("cat-broken"); found {
+		t.Error("The bad file should not be loaded")
+	}
+}
@@ -0,0 +1,149 @@
+package parser
+
+import (
+	"encoding/xml"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Catalogues and game systems are decoded a token at a time rather than with one Decode call over
+// the whole document: the root's attributes are decoded first, then each entry of a section such as
+// sharedSelectionEntries is decoded and interned on its own and appended to the model. The strings
+// of an entry are swapped for their interned copies while the rest of the file is still being read,
+// so the duplicates don't all stay live until the end of the file.
+
+// streamPlan maps the sections of a root model to the slice fields their entries are decoded into
+type streamPlan struct {
+	sections map[string]map[string][]int // Section element -> entry element -> field index
+}
+
+var streamPlans sync.Map // reflect.Type -> *streamPlan
+
+// streamPlanFor builds the plan of a root model from its "section>entry" field tags
+func streamPlanFor(t reflect.Type) *streamPlan {
+	if plan, ok := streamPlans.Load(t); ok {
+		return plan.(*streamPlan)
+	}
+
+	plan := &streamPlan{sections: make(map[string]map[string][]int)}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("xml"), ",")
+		section, entry, nested := strings.Cut(name, ">")
+		if !nested || field.Type.Kind() != reflect.Slice {
+			continue
+		}
+		if plan.sections[section] == nil {
+			plan.sections[section] = make(map[string][]int)
+		}
+		plan.sections[section][entry] = field.Index
+	}
+
+	actual, _ := streamPlans.LoadOrStore(t, plan)
+	return actual.(*streamPlan)
+}
+
+// decodeStream decodes the document read by d into v, a pointer to a root model whose elements
+// are all in "section>entry" fields, interning each entry as it is decoded
+func decodeStream(d *xml.Decoder, v interface{}) error {
+	start, err := rootElement(d)
+	if err != nil {
+		return err
+	}
+
+	// The root's attributes, decoded from the start element alone; this also checks its name
+	root := tokenList{start, start.End()}
+	if err := xml.NewTokenDecoder(&root).Decode(v); err != nil {
+		return err
+	}
+	intern(v)
+
+	value := reflect.ValueOf(v).Elem()
+	plan := streamPlanFor(value.Type())
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return unexpectedEOF(err)
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			entries, known := plan.sections[tok.Name.Local]
+			if !known {
+				if err := d.Skip(); err != nil {
+					return unexpectedEOF(err)
+				}
+				continue
+			}
+			if err := decodeSection(d, value, entries); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// decodeSection decodes the entries of a section one at a time into their slice fields of root,
+// up to the section's end element
+func decodeSection(d *xml.Decoder, root reflect.Value, entries map[string][]int) error {
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return unexpectedEOF(err)
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			index, known := entries[tok.Name.Local]
+			if !known {
+				if err := d.Skip(); err != nil {
+					return unexpectedEOF(err)
+				}
+				continue
+			}
+			slice := root.FieldByIndex(index)
+			entry := reflect.New(slice.Type().Elem())
+			if err := d.DecodeElement(entry.Interface(), &tok); err != nil {
+				return err
+			}
+			internValue(entry)
+			slice.Set(reflect.Append(slice, entry.Elem()))
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// rootElement reads up to the document's root element, skipping the declaration and comments
+func rootElement(d *xml.Decoder) (xml.StartElement, error) {
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return xml.StartElement{}, err
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			return start, nil
+		}
+	}
+}
+
+// unexpectedEOF reports a document that ends before its root element is closed
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// tokenList is a TokenReader over a fixed list of tokens
+type tokenList []xml.Token
+
+func (l *tokenList) Token() (xml.Token, error) {
+	if len(*l) == 0 {
+		return nil, io.EOF
+	}
+	tok := (*l)[0]
+	*l = (*l)[1:]
+	return tok, nil
+}
@@ -0,0 +1,61 @@
+package parser
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"grimoire-api/internal/models"
+)
+
+func TestDecodeStreamMatchesUnmarshal(t *testing.T) {
+	dataDir := getFixtureDataDir(t)
+	files, err := filepath.Glob(filepath.Join(dataDir, "*.*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var streamed, unmarshaled interface{}
+		switch {
+		case strings.HasSuffix(file, ".gst"):
+			streamed, unmarshaled = &models.GameSystem{}, &models.GameSystem{}
+		case strings.HasSuffix(file, ".cat"):
+			streamed, unmarshaled = &models.Catalogue{}, &models.Catalogue{}
+		default:
+			continue
+		}
+		if err := decodeFile(file, streamed); err != nil {
+			t.Fatalf("Failed to decode %s: %v", filepath.Base(file), err)
+		}
+		if err := xml.Unmarshal(data, unmarshaled); err != nil {
+			t.Fatalf("Failed to unmarshal %s: %v", filepath.Base(file), err)
+		}
+		if !reflect.DeepEqual(streamed, unmarshaled) {
+			t.Errorf("Streaming %s decoded differently from xml.Unmarshal", filepath.Base(file))
+		}
+	}
+}
+
+func TestDecodeStreamErrors(t *testing.T) {
+	tests := map[string]string{
+		"empty":      "",
+		"unclosed":   `<catalogue id="c"><entryLinks><entryLink id="e"/>`,
+		"wrong root": `<gameSystem id="g"></gameSystem>`,
+		"bad entry":  `<catalogue id="c"><entryLinks><entryLink id="e" hidden="maybe"/></entryLinks></catalogue>`,
+	}
+	for name, doc := range tests {
+		var catalogue models.Catalogue
+		if err := decode(strings.NewReader(doc), &catalogue); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -6,7 +6,7 @@ import (
 )
 
 // getTestDataDir returns the test data directory path
-func getTestDataDir(t *testing.T) string {
+func getTestDataDir(t testing.TB) string {
 	dataDir := os.Getenv("TEST_DATA_DIR")
 	if dataDir == "" {
 		dataDir = "../../../wh40k-10e"
@@ -19,4 +19,11 @@ func getTestDataDir(t *testing.T) string {
 	return dataDir
 }
 
-
+// getFixtureDataDir returns the path of the small checked-in fixture dataset
+func getFixtureDataDir(t testing.TB) string {
+	dataDir := "../../testdata/wh40k-fixture"
+	if _, err := os.Stat(dataDir); os.IsNotExist(err) {
+		t.Fatalf("Fixture directory not found: %s", dataDir)
+	}
+	return dataDir
+}
@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"context"
 	"strconv"
 	"strings"
 
@@ -10,6 +11,8 @@ import (
 // Transformer converts XML models to JSON-friendly response models
 type Transformer struct {
 	resolver *LinkResolver
+	trace    *unitTrace // Only set while explaining a unit
+	walk     *unitWalk  // Only set while transforming a unit that records what it passes
 }
 
 // NewTransformer creates a new transformer
@@ -17,8 +20,26 @@ func NewTransformer(resolver *LinkResolver) *Transformer {
 	return &Transformer{resolver: resolver}
 }
 
-// TransformUnit transforms a SelectionEntry to a UnitResponse
+// TransformUnit transforms a SelectionEntry to a UnitResponse, without resolution warnings
 func (t *Transformer) TransformUnit(entry *models.SelectionEntry, catalogueID string) *models.UnitResponse {
+	return t.transformUnit(entry, catalogueID, false)
+}
+
+// TransformUnitWithWarnings is TransformUnit, also reporting the links and values that had to be skipped
+func (t *Transformer) TransformUnitWithWarnings(entry *models.SelectionEntry, catalogueID string) *models.UnitResponse {
+	return t.transformUnit(entry, catalogueID, true)
+}
+
+func (t *Transformer) transformUnit(entry *models.SelectionEntry, catalogueID string, warnings bool) *models.UnitResponse {
+	// The walk for weapons records warnings and overlay marks, only when there are any to record
+	overlays := t.resolver.parser.Overlaid()
+	if t.walk == nil && (warnings || overlays) {
+		walk := newUnitWalk(t, entry, catalogueID)
+		walk.warnings = warnings
+		walk.overlays = overlays
+		t = &Transformer{resolver: t.resolver, trace: t.trace, walk: walk}
+	}
+
 	response := &models.UnitResponse{
 		ID:   entry.ID,
 		Name: entry.Name,
@@ -31,7 +52,7 @@ func (t *Transformer) TransformUnit(entry *models.SelectionEntry, catalogueID st
 			ID:   entry.PublicationID,
 			Page: entry.Page,
 		}
-		
+
 		// Try to resolve full publication details from catalogue
 		if catalogueID != "" {
 			if cat, exists := t.resolver.parser.GetCatalogue(catalogueID); exists {
@@ -54,7 +75,7 @@ func (t *Transformer) TransformUnit(entry *models.SelectionEntry, catalogueID st
 				}
 			}
 		}
-		
+
 		response.Publication = pubInfo
 	}
 
@@ -87,7 +108,7 @@ func (t *Transformer) TransformUnit(entry *models.SelectionEntry, catalogueID st
 				ID:       cat.ID,
 				Name:     cat.Name,
 				Revision: cat.Revision,
-				Library:  cat.Library == "true",
+				Library:  cat.Library,
 			}
 		} else if lib, exists := t.resolver.parser.GetLibrary(catalogueID); exists {
 			// If not found, try library
@@ -111,6 +132,14 @@ func (t *Transformer) TransformUnit(entry *models.SelectionEntry, catalogueID st
 		}
 	}
 
+	if t.walk != nil {
+		// Report the links and values that had to be skipped, and the values local overlays changed
+		if t.walk.warnings {
+			response.Warnings = t.walk.unitWarnings(response)
+		}
+		response.Overlays = t.walk.marks
+	}
+
 	return response
 }
 
@@ -126,11 +155,13 @@ func (t *Transformer) transformProfiles(profiles []models.Profile, entry *models
 		switch profile.TypeName {
 		case "Unit":
 			if result.Unit == nil { // Only set if not already found in nested entries
-				result.Unit = t.transformUnitProfile(profile)
+				result.Unit = t.unitProfileAt(&profile, "", nil)
 			}
 		case "Abilities":
+			t.walk.foundProfile(&profile, "", nil)
 			result.Abilities = append(result.Abilities, t.transformAbilityProfile(profile))
 		case "Transport":
+			t.walk.foundProfile(&profile, "", nil)
 			result.Transport = t.transformTransportProfile(profile)
 		}
 	}
@@ -139,9 +170,10 @@ func (t *Transformer) transformProfiles(profiles []models.Profile, entry *models
 	if result.Unit == nil {
 		for i := range entry.SelectionEntries {
 			subEntry := &entry.SelectionEntries[i]
+			subPath := t.walk.via(nil, "selectionEntry", subEntry.ID, subEntry.Name, "", subEntry.Pos)
 			for _, profile := range subEntry.Profiles {
 				if profile.TypeName == "Unit" {
-					result.Unit = t.transformUnitProfile(profile)
+					result.Unit = t.unitProfileAt(&profile, "", subPath)
 					break // Found it, no need to continue
 				}
 			}
@@ -149,9 +181,10 @@ func (t *Transformer) transformProfiles(profiles []models.Profile, entry *models
 			if result.Unit == nil {
 				for j := range subEntry.SelectionEntries {
 					deepEntry := &subEntry.SelectionEntries[j]
+					deepPath := t.walk.via(subPath, "selectionEntry", deepEntry.ID, deepEntry.Name, "", deepEntry.Pos)
 					for _, profile := range deepEntry.Profiles {
 						if profile.TypeName == "Unit" {
-							result.Unit = t.transformUnitProfile(profile)
+							result.Unit = t.unitProfileAt(&profile, "", deepPath)
 							break
 						}
 					}
@@ -169,269 +202,36 @@ func (t *Transformer) transformProfiles(profiles []models.Profile, entry *models
 	// Also check selectionEntryGroups for Unit profiles (e.g., Boyz, Gretchin, Grot Tanks)
 	// Use a recursive helper to search all possible nested structures
 	if result.Unit == nil {
-		result.Unit = t.findUnitProfileInGroups(entry.SelectionEntryGroups, catalogueID)
-	}
-	
-	// Also check selectionEntryGroups for Unit profiles (e.g., Boyz, Gretchin) - keep original code as fallback
-	if result.Unit == nil {
-		for i := range entry.SelectionEntryGroups {
-			group := &entry.SelectionEntryGroups[i]
-			// Check SelectionEntries in the group
-			for j := range group.SelectionEntries {
-				subEntry := &group.SelectionEntries[j]
-				for _, profile := range subEntry.Profiles {
-					if profile.TypeName == "Unit" {
-						result.Unit = t.transformUnitProfile(profile)
-						break
-					}
-				}
-				// Also check entryLinks within selectionEntries (e.g., Gretchin unit profile)
-				if result.Unit == nil {
-					for k := range subEntry.EntryLinks {
-						entryLink := &subEntry.EntryLinks[k]
-						if entryLink.Type == "selectionEntry" || entryLink.Type == "upgrade" {
-							resolvedEntry, err := t.resolver.ResolveEntryLink(entryLink, catalogueID)
-							if err == nil {
-								// Check if resolved entry has unit profile
-								for _, profile := range resolvedEntry.Profiles {
-									if profile.TypeName == "Unit" {
-										result.Unit = t.transformUnitProfile(profile)
-										break
-									}
-								}
-								// Also check nested selectionEntries in resolved entry
-								if result.Unit == nil {
-									for l := range resolvedEntry.SelectionEntries {
-										deepEntry := &resolvedEntry.SelectionEntries[l]
-										for _, profile := range deepEntry.Profiles {
-											if profile.TypeName == "Unit" {
-												result.Unit = t.transformUnitProfile(profile)
-												break
-											}
-										}
-										if result.Unit != nil {
-											break
-										}
-									}
-								}
-							}
-						}
-						if result.Unit != nil {
-							break
-						}
-					}
-				}
-				// Check nested selectionEntryGroups within selectionEntries (e.g., Grot Tanks - "Wargear" group)
-				if result.Unit == nil {
-					for k := range subEntry.SelectionEntryGroups {
-						nestedGroupInEntry := &subEntry.SelectionEntryGroups[k]
-						// Check entryLinks in nested groups within selectionEntries
-						for l := range nestedGroupInEntry.EntryLinks {
-							entryLink := &nestedGroupInEntry.EntryLinks[l]
-							if entryLink.Type == "selectionEntry" || entryLink.Type == "upgrade" {
-								resolvedEntry, err := t.resolver.ResolveEntryLink(entryLink, catalogueID)
-								if err == nil {
-									for _, profile := range resolvedEntry.Profiles {
-										if profile.TypeName == "Unit" {
-											result.Unit = t.transformUnitProfile(profile)
-											break
-										}
-									}
-									// Also check nested selectionEntries in resolved entry
-									if result.Unit == nil {
-										for m := range resolvedEntry.SelectionEntries {
-											deepEntry := &resolvedEntry.SelectionEntries[m]
-											for _, profile := range deepEntry.Profiles {
-												if profile.TypeName == "Unit" {
-													result.Unit = t.transformUnitProfile(profile)
-													break
-												}
-											}
-											if result.Unit != nil {
-												break
-											}
-										}
-									}
-								}
-							}
-							if result.Unit != nil {
-								break
-							}
-						}
-						if result.Unit != nil {
-							break
-						}
-					}
-				}
-				if result.Unit != nil {
-					break
-				}
-			}
-			if result.Unit != nil {
-				break
-			}
-			// Check EntryLinks in the group (e.g., Gretchin unit profile is in an entryLink)
-			for j := range group.EntryLinks {
-				entryLink := &group.EntryLinks[j]
-				if entryLink.Type == "selectionEntry" || entryLink.Type == "upgrade" {
-					resolvedEntry, err := t.resolver.ResolveEntryLink(entryLink, catalogueID)
-					if err == nil {
-						// Check if resolved entry has unit profile
-						for _, profile := range resolvedEntry.Profiles {
-							if profile.TypeName == "Unit" {
-								result.Unit = t.transformUnitProfile(profile)
-								break
-							}
-						}
-						// Also check nested selectionEntries in resolved entry
-						if result.Unit == nil {
-							for k := range resolvedEntry.SelectionEntries {
-								subEntry := &resolvedEntry.SelectionEntries[k]
-								for _, profile := range subEntry.Profiles {
-									if profile.TypeName == "Unit" {
-										result.Unit = t.transformUnitProfile(profile)
-										break
-									}
-								}
-								if result.Unit != nil {
-									break
-								}
-							}
-						}
-					}
-				}
-				if result.Unit != nil {
-					break
-				}
-			}
-			if result.Unit != nil {
-				break
-			}
-			// Also check nested groups
-			for j := range group.SelectionEntryGroups {
-				nestedGroup := &group.SelectionEntryGroups[j]
-				for k := range nestedGroup.SelectionEntries {
-					subEntry := &nestedGroup.SelectionEntries[k]
-					for _, profile := range subEntry.Profiles {
-						if profile.TypeName == "Unit" {
-							result.Unit = t.transformUnitProfile(profile)
-							break
-						}
-					}
-					// Check entryLinks within nested group selectionEntries (e.g., Grot Tanks)
-					if result.Unit == nil {
-						for l := range subEntry.EntryLinks {
-							entryLink := &subEntry.EntryLinks[l]
-							if entryLink.Type == "selectionEntry" || entryLink.Type == "upgrade" {
-								resolvedEntry, err := t.resolver.ResolveEntryLink(entryLink, catalogueID)
-								if err == nil {
-									for _, profile := range resolvedEntry.Profiles {
-										if profile.TypeName == "Unit" {
-											result.Unit = t.transformUnitProfile(profile)
-											break
-										}
-									}
-								}
-							}
-							if result.Unit != nil {
-								break
-							}
-						}
-					}
-					if result.Unit != nil {
-						break
-					}
-				}
-				// Check entryLinks directly in nested groups (e.g., Grot Tanks - "Ramshackle hull" is here)
-				if result.Unit == nil {
-					for k := range nestedGroup.EntryLinks {
-						entryLink := &nestedGroup.EntryLinks[k]
-						if entryLink.Type == "selectionEntry" || entryLink.Type == "upgrade" {
-							resolvedEntry, err := t.resolver.ResolveEntryLink(entryLink, catalogueID)
-							if err == nil {
-								for _, profile := range resolvedEntry.Profiles {
-									if profile.TypeName == "Unit" {
-										result.Unit = t.transformUnitProfile(profile)
-										break
-									}
-								}
-								// Also check nested selectionEntries in resolved entry
-								if result.Unit == nil {
-									for l := range resolvedEntry.SelectionEntries {
-										deepEntry := &resolvedEntry.SelectionEntries[l]
-										for _, profile := range deepEntry.Profiles {
-											if profile.TypeName == "Unit" {
-												result.Unit = t.transformUnitProfile(profile)
-												break
-											}
-										}
-										if result.Unit != nil {
-											break
-										}
-									}
-								}
-							}
-						}
-						if result.Unit != nil {
-							break
-						}
-					}
-				}
-				// Also recurse into deeper nested groups within nested groups
-				if result.Unit == nil {
-					for k := range nestedGroup.SelectionEntryGroups {
-						deepNestedGroup := &nestedGroup.SelectionEntryGroups[k]
-						for l := range deepNestedGroup.EntryLinks {
-							entryLink := &deepNestedGroup.EntryLinks[l]
-							if entryLink.Type == "selectionEntry" || entryLink.Type == "upgrade" {
-								resolvedEntry, err := t.resolver.ResolveEntryLink(entryLink, catalogueID)
-								if err == nil {
-									for _, profile := range resolvedEntry.Profiles {
-										if profile.TypeName == "Unit" {
-											result.Unit = t.transformUnitProfile(profile)
-											break
-										}
-									}
-								}
-							}
-							if result.Unit != nil {
-								break
-							}
-						}
-						if result.Unit != nil {
-							break
-						}
-					}
-				}
-				if result.Unit != nil {
-					break
-				}
-			}
-			if result.Unit != nil {
-				break
-			}
-		}
+		result.Unit = t.findUnitProfileInGroups(entry.SelectionEntryGroups, catalogueID, nil)
 	}
 
 	// Also check infoLinks for Unit profiles (e.g., Grot Tanks [Legends])
 	// Some units reference Unit profiles via infoLinks with type="profile"
 	// Check infoLinks in the entry itself and recursively in nested entries
 	if result.Unit == nil && catalogueID != "" {
-		result.Unit = t.findUnitProfileInInfoLinks(entry, catalogueID)
+		result.Unit = t.findUnitProfileInInfoLinks(entry, catalogueID, nil)
 	}
 
 	return result
 }
 
+// unitProfileAt transforms a Unit profile found in the catalogue definedIn (empty for the one the
+// unit is defined in), reached from the unit through path
+func (t *Transformer) unitProfileAt(profile *models.Profile, definedIn string, path []models.SourceRef) *models.UnitProfile {
+	t.walk.foundProfile(profile, definedIn, path)
+	return t.transformUnitProfile(*profile)
+}
+
 // findUnitProfileInInfoLinks recursively searches infoLinks for Unit profiles
-func (t *Transformer) findUnitProfileInInfoLinks(entry *models.SelectionEntry, catalogueID string) *models.UnitProfile {
+func (t *Transformer) findUnitProfileInInfoLinks(entry *models.SelectionEntry, catalogueID string, path []models.SourceRef) *models.UnitProfile {
 	// Check infoLinks in this entry
 	for _, infoLink := range entry.InfoLinks {
 		if infoLink.Type == "profile" && infoLink.TargetID != "" {
 			// Try to resolve the profile from sharedProfiles
 			if profile, found := t.resolver.parser.GetProfile(infoLink.TargetID, catalogueID); found {
 				if profile.TypeName == "Unit" {
-					return t.transformUnitProfile(*profile)
+					linkPath := t.walk.via(path, "infoLink", infoLink.ID, infoLink.Name, "", models.SourcePos{})
+					return t.unitProfileAt(profile, catalogueID, linkPath)
 				}
 			}
 		}
@@ -439,14 +239,16 @@ func (t *Transformer) findUnitProfileInInfoLinks(entry *models.SelectionEntry, c
 
 	// Check nested selectionEntries
 	for i := range entry.SelectionEntries {
-		if unitProfile := t.findUnitProfileInInfoLinks(&entry.SelectionEntries[i], catalogueID); unitProfile != nil {
+		subEntry := &entry.SelectionEntries[i]
+		subPath := t.walk.via(path, "selectionEntry", subEntry.ID, subEntry.Name, "", subEntry.Pos)
+		if unitProfile := t.findUnitProfileInInfoLinks(subEntry, catalogueID, subPath); unitProfile != nil {
 			return unitProfile
 		}
 	}
 
 	// Check selectionEntryGroups
 	for i := range entry.SelectionEntryGroups {
-		if unitProfile := t.findUnitProfileInInfoLinksInGroup(&entry.SelectionEntryGroups[i], catalogueID); unitProfile != nil {
+		if unitProfile := t.findUnitProfileInInfoLinksInGroup(&entry.SelectionEntryGroups[i], catalogueID, path); unitProfile != nil {
 			return unitProfile
 		}
 	}
@@ -455,17 +257,21 @@ func (t *Transformer) findUnitProfileInInfoLinks(entry *models.SelectionEntry, c
 }
 
 // findUnitProfileInInfoLinksInGroup recursively searches infoLinks in a group for Unit profiles
-func (t *Transformer) findUnitProfileInInfoLinksInGroup(group *models.SelectionEntryGroup, catalogueID string) *models.UnitProfile {
+func (t *Transformer) findUnitProfileInInfoLinksInGroup(group *models.SelectionEntryGroup, catalogueID string, path []models.SourceRef) *models.UnitProfile {
+	path = t.walk.via(path, "selectionEntryGroup", group.ID, group.Name, "", models.SourcePos{})
+
 	// Check SelectionEntries in the group
 	for i := range group.SelectionEntries {
-		if unitProfile := t.findUnitProfileInInfoLinks(&group.SelectionEntries[i], catalogueID); unitProfile != nil {
+		subEntry := &group.SelectionEntries[i]
+		subPath := t.walk.via(path, "selectionEntry", subEntry.ID, subEntry.Name, "", subEntry.Pos)
+		if unitProfile := t.findUnitProfileInInfoLinks(subEntry, catalogueID, subPath); unitProfile != nil {
 			return unitProfile
 		}
 	}
 
 	// Recursively check nested groups
 	for i := range group.SelectionEntryGroups {
-		if unitProfile := t.findUnitProfileInInfoLinksInGroup(&group.SelectionEntryGroups[i], catalogueID); unitProfile != nil {
+		if unitProfile := t.findUnitProfileInInfoLinksInGroup(&group.SelectionEntryGroups[i], catalogueID, path); unitProfile != nil {
 			return unitProfile
 		}
 	}
@@ -474,80 +280,80 @@ func (t *Transformer) findUnitProfileInInfoLinksInGroup(group *models.SelectionE
 }
 
 // findUnitProfileInGroups recursively searches selectionEntryGroups for Unit profiles
-func (t *Transformer) findUnitProfileInGroups(groups []models.SelectionEntryGroup, catalogueID string) *models.UnitProfile {
+func (t *Transformer) findUnitProfileInGroups(groups []models.SelectionEntryGroup, catalogueID string, path []models.SourceRef) *models.UnitProfile {
 	for i := range groups {
 		group := &groups[i]
-		
+		groupPath := t.walk.via(path, "selectionEntryGroup", group.ID, group.Name, "", models.SourcePos{})
+
 		// Check SelectionEntries in the group
 		for j := range group.SelectionEntries {
 			subEntry := &group.SelectionEntries[j]
+			subPath := t.walk.via(groupPath, "selectionEntry", subEntry.ID, subEntry.Name, "", subEntry.Pos)
 			// Check profiles directly in selectionEntry
 			for _, profile := range subEntry.Profiles {
 				if profile.TypeName == "Unit" {
-					return t.transformUnitProfile(profile)
+					return t.unitProfileAt(&profile, "", subPath)
 				}
 			}
 			// Check entryLinks in selectionEntry
 			for k := range subEntry.EntryLinks {
-				entryLink := &subEntry.EntryLinks[k]
-				if entryLink.Type == "selectionEntry" || entryLink.Type == "upgrade" {
-					if resolvedEntry, err := t.resolver.ResolveEntryLink(entryLink, catalogueID); err == nil {
-						for _, profile := range resolvedEntry.Profiles {
-							if profile.TypeName == "Unit" {
-								return t.transformUnitProfile(profile)
-							}
-						}
-						// Check nested selectionEntries in resolved entry
-						for l := range resolvedEntry.SelectionEntries {
-							deepEntry := &resolvedEntry.SelectionEntries[l]
-							for _, profile := range deepEntry.Profiles {
-								if profile.TypeName == "Unit" {
-									return t.transformUnitProfile(profile)
-								}
-							}
-						}
-					}
+				if unitProfile := t.findUnitProfileInLink(&subEntry.EntryLinks[k], catalogueID, subPath); unitProfile != nil {
+					return unitProfile
 				}
 			}
 			// Recursively check nested groups in selectionEntry
-			if unitProfile := t.findUnitProfileInGroups(subEntry.SelectionEntryGroups, catalogueID); unitProfile != nil {
+			if unitProfile := t.findUnitProfileInGroups(subEntry.SelectionEntryGroups, catalogueID, subPath); unitProfile != nil {
 				return unitProfile
 			}
 		}
-		
+
 		// Check EntryLinks directly in the group
 		for j := range group.EntryLinks {
-			entryLink := &group.EntryLinks[j]
-			if entryLink.Type == "selectionEntry" || entryLink.Type == "upgrade" {
-				if resolvedEntry, err := t.resolver.ResolveEntryLink(entryLink, catalogueID); err == nil {
-					for _, profile := range resolvedEntry.Profiles {
-						if profile.TypeName == "Unit" {
-							return t.transformUnitProfile(profile)
-						}
-					}
-					// Check nested selectionEntries in resolved entry
-					for k := range resolvedEntry.SelectionEntries {
-						subEntry := &resolvedEntry.SelectionEntries[k]
-						for _, profile := range subEntry.Profiles {
-							if profile.TypeName == "Unit" {
-								return t.transformUnitProfile(profile)
-							}
-						}
-					}
-				}
+			if unitProfile := t.findUnitProfileInLink(&group.EntryLinks[j], catalogueID, groupPath); unitProfile != nil {
+				return unitProfile
 			}
 		}
-		
+
 		// Recursively check nested groups
-		if unitProfile := t.findUnitProfileInGroups(group.SelectionEntryGroups, catalogueID); unitProfile != nil {
+		if unitProfile := t.findUnitProfileInGroups(group.SelectionEntryGroups, catalogueID, groupPath); unitProfile != nil {
 			return unitProfile
 		}
 	}
 	return nil
 }
 
+// findUnitProfileInLink searches the entry an entryLink resolves to, and its selectionEntries, for a Unit profile
+func (t *Transformer) findUnitProfileInLink(entryLink *models.EntryLink, catalogueID string, path []models.SourceRef) *models.UnitProfile {
+	if entryLink.Type != "selectionEntry" && entryLink.Type != "upgrade" {
+		return nil
+	}
+	resolvedEntry, sourceID, err := t.resolver.ResolveEntryLinkSource(entryLink, catalogueID)
+	if err != nil {
+		return nil
+	}
+	linkPath := t.walk.via(path, "entryLink", entryLink.ID, entryLink.Name, "", entryLink.Pos)
+	entryPath := t.walk.via(linkPath, "selectionEntry", resolvedEntry.ID, resolvedEntry.Name, sourceID, resolvedEntry.Pos)
+	for _, profile := range resolvedEntry.Profiles {
+		if profile.TypeName == "Unit" {
+			return t.unitProfileAt(&profile, sourceID, entryPath)
+		}
+	}
+	// Check nested selectionEntries in resolved entry
+	for i := range resolvedEntry.SelectionEntries {
+		subEntry := &resolvedEntry.SelectionEntries[i]
+		subPath := t.walk.via(entryPath, "selectionEntry", subEntry.ID, subEntry.Name, sourceID, subEntry.Pos)
+		for _, profile := range subEntry.Profiles {
+			if profile.TypeName == "Unit" {
+				return t.unitProfileAt(&profile, sourceID, subPath)
+			}
+		}
+	}
+	return nil
+}
+
 // transformUnitProfile transforms a Unit profile
 func (t *Transformer) transformUnitProfile(profile models.Profile) *models.UnitProfile {
+	t.trace.useProfile(profile)
 	unit := &models.UnitProfile{}
 	charMap := make(map[string]string)
 
@@ -567,6 +373,7 @@ func (t *Transformer) transformUnitProfile(profile models.Profile) *models.UnitP
 
 // transformAbilityProfile transforms an Ability profile
 func (t *Transformer) transformAbilityProfile(profile models.Profile) models.AbilityProfile {
+	t.trace.useProfile(profile)
 	ability := models.AbilityProfile{
 		Name: profile.Name,
 	}
@@ -582,6 +389,7 @@ func (t *Transformer) transformAbilityProfile(profile models.Profile) models.Abi
 
 // transformTransportProfile transforms a Transport profile
 func (t *Transformer) transformTransportProfile(profile models.Profile) *models.TransportProfile {
+	t.trace.useProfile(profile)
 	transport := &models.TransportProfile{}
 
 	for _, char := range profile.Characteristics {
@@ -606,79 +414,33 @@ func (t *Transformer) transformWeaponsWithCatalogue(entry *models.SelectionEntry
 	}
 
 	// Search through selectionEntries for weapons
+	t.walk.entry(entry)
 	t.extractWeaponsRecursive(entry, weapons, catalogueID)
 
 	return weapons
 }
 
-// extractWeaponsRecursive recursively extracts weapons from selectionEntries
+// extractWeaponsRecursive recursively extracts weapons from the entries, links and groups of an entry
 func (t *Transformer) extractWeaponsRecursive(entry *models.SelectionEntry, weapons *models.WeaponSet, catalogueID string) {
 	// Check direct selectionEntries
 	for i := range entry.SelectionEntries {
-		subEntry := &entry.SelectionEntries[i]
-		
-		// Check if this entry has weapon profiles
-		for _, profile := range subEntry.Profiles {
-			if profile.TypeName == "Ranged Weapons" {
-				weapons.Ranged = append(weapons.Ranged, t.transformRangedWeapon(profile))
-			} else if profile.TypeName == "Melee Weapons" {
-				weapons.Melee = append(weapons.Melee, t.transformMeleeWeapon(profile))
-			}
-		}
-
-		// Recurse into nested entries
-		t.extractWeaponsRecursive(subEntry, weapons, catalogueID)
+		leave := t.walk.enterEntry(&entry.SelectionEntries[i])
+		t.extractWeaponsFromEntry(&entry.SelectionEntries[i], weapons, catalogueID)
+		leave()
 	}
 
 	// Check EntryLinks directly in the entry
 	for i := range entry.EntryLinks {
-		entryLink := &entry.EntryLinks[i]
-		if entryLink.Type == "selectionEntry" || entryLink.Type == "upgrade" {
-			resolvedEntry, err := t.resolver.ResolveEntryLink(entryLink, catalogueID)
-			if err == nil {
-				// Extract weapons from resolved entry
-				t.extractWeaponsFromEntry(resolvedEntry, weapons, catalogueID)
-			}
-		}
+		t.extractWeaponsFromLink(entry.ID, &entry.EntryLinks[i], weapons, catalogueID)
 	}
 
 	// Check selectionEntryGroups
 	for i := range entry.SelectionEntryGroups {
-		group := &entry.SelectionEntryGroups[i]
-		
-		// Check SelectionEntries in the group
-		for j := range group.SelectionEntries {
-			subEntry := &group.SelectionEntries[j]
-			for _, profile := range subEntry.Profiles {
-				if profile.TypeName == "Ranged Weapons" {
-					weapons.Ranged = append(weapons.Ranged, t.transformRangedWeapon(profile))
-				} else if profile.TypeName == "Melee Weapons" {
-					weapons.Melee = append(weapons.Melee, t.transformMeleeWeapon(profile))
-				}
-			}
-			t.extractWeaponsRecursive(subEntry, weapons, catalogueID)
-		}
-		
-		// Check EntryLinks in the group (this is the missing piece!)
-		for j := range group.EntryLinks {
-			entryLink := &group.EntryLinks[j]
-			if entryLink.Type == "selectionEntry" || entryLink.Type == "upgrade" {
-				resolvedEntry, err := t.resolver.ResolveEntryLink(entryLink, catalogueID)
-				if err == nil {
-					// Extract weapons from resolved entry
-					t.extractWeaponsFromEntry(resolvedEntry, weapons, catalogueID)
-				}
-			}
-		}
-		
-		// Recurse into nested selectionEntryGroups
-		for j := range group.SelectionEntryGroups {
-			t.extractWeaponsFromGroup(&group.SelectionEntryGroups[j], weapons, catalogueID)
-		}
+		t.extractWeaponsFromGroup(&entry.SelectionEntryGroups[i], weapons, catalogueID)
 	}
 }
 
-// extractWeaponsFromEntry extracts weapons from a selectionEntry (used for resolved entryLinks)
+// extractWeaponsFromEntry extracts weapons from a selectionEntry and everything below it
 func (t *Transformer) extractWeaponsFromEntry(entry *models.SelectionEntry, weapons *models.WeaponSet, catalogueID string) {
 	// Check if this entry has weapon profiles
 	for _, profile := range entry.Profiles {
@@ -688,37 +450,43 @@ func (t *Transformer) extractWeaponsFromEntry(entry *models.SelectionEntry, weap
 			weapons.Melee = append(weapons.Melee, t.transformMeleeWeapon(profile))
 		}
 	}
-	
+
 	// Recurse into nested structures
 	t.extractWeaponsRecursive(entry, weapons, catalogueID)
 }
 
+// extractWeaponsFromLink extracts weapons from the entry an entryLink of parentID resolves to
+func (t *Transformer) extractWeaponsFromLink(parentID string, entryLink *models.EntryLink, weapons *models.WeaponSet, catalogueID string) {
+	if entryLink.Type != "selectionEntry" && entryLink.Type != "upgrade" {
+		return
+	}
+	resolvedEntry, sourceID, err := t.resolver.ResolveEntryLinkSource(entryLink, catalogueID)
+	leave := t.walk.followLink(parentID, entryLink, sourceID, err)
+	defer leave()
+	if err == nil {
+		leave := t.walk.enterEntry(resolvedEntry)
+		t.extractWeaponsFromEntry(resolvedEntry, weapons, catalogueID)
+		leave()
+	}
+}
+
 // extractWeaponsFromGroup extracts weapons from a selectionEntryGroup
 func (t *Transformer) extractWeaponsFromGroup(group *models.SelectionEntryGroup, weapons *models.WeaponSet, catalogueID string) {
+	leave := t.walk.enterGroup(group)
+	defer leave()
+
 	// Check SelectionEntries in the group
 	for i := range group.SelectionEntries {
-		subEntry := &group.SelectionEntries[i]
-		for _, profile := range subEntry.Profiles {
-			if profile.TypeName == "Ranged Weapons" {
-				weapons.Ranged = append(weapons.Ranged, t.transformRangedWeapon(profile))
-			} else if profile.TypeName == "Melee Weapons" {
-				weapons.Melee = append(weapons.Melee, t.transformMeleeWeapon(profile))
-			}
-		}
-		t.extractWeaponsRecursive(subEntry, weapons, catalogueID)
+		leave := t.walk.enterEntry(&group.SelectionEntries[i])
+		t.extractWeaponsFromEntry(&group.SelectionEntries[i], weapons, catalogueID)
+		leave()
 	}
-	
+
 	// Check EntryLinks in the group
 	for i := range group.EntryLinks {
-		entryLink := &group.EntryLinks[i]
-		if entryLink.Type == "selectionEntry" || entryLink.Type == "upgrade" {
-			resolvedEntry, err := t.resolver.ResolveEntryLink(entryLink, catalogueID)
-			if err == nil {
-				t.extractWeaponsFromEntry(resolvedEntry, weapons, catalogueID)
-			}
-		}
+		t.extractWeaponsFromLink(group.ID, &group.EntryLinks[i], weapons, catalogueID)
 	}
-	
+
 	// Recurse into nested groups
 	for i := range group.SelectionEntryGroups {
 		t.extractWeaponsFromGroup(&group.SelectionEntryGroups[i], weapons, catalogueID)
@@ -727,6 +495,7 @@ func (t *Transformer) extractWeaponsFromGroup(group *models.SelectionEntryGroup,
 
 // transformRangedWeapon transforms a Ranged Weapons profile
 func (t *Transformer) transformRangedWeapon(profile models.Profile) models.RangedWeapon {
+	t.trace.useProfile(profile)
 	weapon := models.RangedWeapon{
 		Name: profile.Name,
 	}
@@ -742,7 +511,7 @@ func (t *Transformer) transformRangedWeapon(profile models.Profile) models.Range
 	weapon.Strength = charMap["S"]
 	weapon.ArmorPenetration = charMap["AP"]
 	weapon.Damage = charMap["D"]
-	
+
 	if keywords := charMap["Keywords"]; keywords != "" {
 		weapon.Keywords = parseKeywords(keywords)
 	}
@@ -752,6 +521,7 @@ func (t *Transformer) transformRangedWeapon(profile models.Profile) models.Range
 
 // transformMeleeWeapon transforms a Melee Weapons profile
 func (t *Transformer) transformMeleeWeapon(profile models.Profile) models.MeleeWeapon {
+	t.trace.useProfile(profile)
 	weapon := models.MeleeWeapon{
 		Name: profile.Name,
 	}
@@ -767,7 +537,7 @@ func (t *Transformer) transformMeleeWeapon(profile models.Profile) models.MeleeW
 	weapon.Strength = charMap["S"]
 	weapon.ArmorPenetration = charMap["AP"]
 	weapon.Damage = charMap["D"]
-	
+
 	if keywords := charMap["Keywords"]; keywords != "" {
 		weapon.Keywords = parseKeywords(keywords)
 	}
@@ -782,7 +552,7 @@ func (t *Transformer) transformCategories(categoryLinks []models.CategoryLink) [
 		categories = append(categories, models.CategoryInfo{
 			ID:      catLink.TargetID,
 			Name:    catLink.Name,
-			Primary: catLink.Primary == "true",
+			Primary: catLink.Primary,
 		})
 	}
 	return categories
@@ -819,7 +589,7 @@ func (t *Transformer) TransformCosts(costs []models.Cost) map[string]int {
 // The pts cost typeId is "51b2-306e-1021-d207"
 func (t *Transformer) transformTieredCosts(costs []models.Cost, modifiers []models.Modifier, modifierGroups []models.ModifierGroup, entryID string) *models.TieredCosts {
 	const ptsTypeID = "51b2-306e-1021-d207"
-	
+
 	// Find base pts cost
 	var baseCost int
 	for _, cost := range costs {
@@ -830,33 +600,33 @@ func (t *Transformer) transformTieredCosts(costs []models.Cost, modifiers []mode
 			}
 		}
 	}
-	
+
 	if baseCost == 0 {
 		return nil // No base cost found
 	}
-	
+
 	// Collect all modifiers (from both direct modifiers and modifier groups)
 	allModifiers := make([]models.Modifier, 0)
 	allModifiers = append(allModifiers, modifiers...)
 	for _, group := range modifierGroups {
 		allModifiers = append(allModifiers, group.Modifiers...)
 	}
-	
+
 	// Parse modifiers that affect pts cost based on model count
 	tiers := make([]models.CostTier, 0)
-	
-	for _, modifier := range allModifiers {
+
+	for i, modifier := range allModifiers {
 		// Only process modifiers that set the pts cost field
 		if modifier.Type != "set" || modifier.Field != ptsTypeID {
 			continue
 		}
-		
+
 		// Parse the cost value
 		costValue, err := strconv.Atoi(modifier.Value)
 		if err != nil {
 			continue
 		}
-		
+
 		// Check conditions for model count
 		// Conditions can check selections with childId="model" or a specific entry ID
 		// We're looking for conditions that check the number of models/units selected
@@ -875,20 +645,21 @@ func (t *Transformer) transformTieredCosts(costs []models.Cost, modifiers []mode
 					if condition.Type == "greaterThan" {
 						minModels = threshold + 1
 					}
-					
+
 					tiers = append(tiers, models.CostTier{
 						MinModels: minModels,
 						Cost:      costValue,
 					})
+					t.trace.applyModifier(i, "tieredCosts")
 				}
 			}
 		}
-		
+
 		// Process direct conditions
 		for _, condition := range modifier.Conditions {
 			processCondition(condition, entryID)
 		}
-		
+
 		// Also check condition groups
 		for _, condGroup := range modifier.ConditionGroups {
 			for _, condition := range condGroup.Conditions {
@@ -896,12 +667,12 @@ func (t *Transformer) transformTieredCosts(costs []models.Cost, modifiers []mode
 			}
 		}
 	}
-	
+
 	// If no tiers found, return nil (unit has flat cost)
 	if len(tiers) == 0 {
 		return nil
 	}
-	
+
 	// Sort tiers by minModels (ascending)
 	for i := 0; i < len(tiers)-1; i++ {
 		for j := i + 1; j < len(tiers); j++ {
@@ -910,7 +681,7 @@ func (t *Transformer) transformTieredCosts(costs []models.Cost, modifiers []mode
 			}
 		}
 	}
-	
+
 	return &models.TieredCosts{
 		BaseCost: baseCost,
 		Tiers:    tiers,
@@ -920,7 +691,7 @@ func (t *Transformer) transformTieredCosts(costs []models.Cost, modifiers []mode
 // transformConstraints extracts constraint information
 func (t *Transformer) transformConstraints(constraints []models.Constraint) *models.UnitConstraints {
 	result := &models.UnitConstraints{}
-	
+
 	for _, constraint := range constraints {
 		value := parseInt(constraint.Value)
 		if value < 0 {
@@ -939,8 +710,8 @@ func (t *Transformer) transformConstraints(constraints []models.Constraint) *mod
 		}
 	}
 
-	if result.MaxPerRoster == 0 && result.MinPerRoster == 0 && 
-	   result.MaxPerForce == 0 && result.MinPerForce == 0 {
+	if result.MaxPerRoster == 0 && result.MinPerRoster == 0 &&
+		result.MaxPerForce == 0 && result.MinPerForce == 0 {
 		return nil
 	}
 
@@ -948,12 +719,12 @@ func (t *Transformer) transformConstraints(constraints []models.Constraint) *mod
 }
 
 // TransformCatalogue transforms a Catalogue to CatalogueResponse
-func (t *Transformer) TransformCatalogue(catalogue *models.Catalogue) *models.CatalogueResponse {
+func (t *Transformer) TransformCatalogue(ctx context.Context, catalogue *models.Catalogue) (*models.CatalogueResponse, error) {
 	response := &models.CatalogueResponse{
 		ID:           catalogue.ID,
 		Name:         catalogue.Name,
 		Revision:     catalogue.Revision,
-		Library:      catalogue.Library == "true",
+		Library:      catalogue.Library,
 		GameSystemID: catalogue.GameSystemID,
 	}
 
@@ -965,7 +736,7 @@ func (t *Transformer) TransformCatalogue(catalogue *models.Catalogue) *models.Ca
 			ID:       cat.ID,
 			Name:     cat.Name,
 			Revision: cat.Revision,
-			Library:  cat.Library == "true",
+			Library:  cat.Library,
 		})
 	}
 
@@ -983,17 +754,23 @@ func (t *Transformer) TransformCatalogue(catalogue *models.Catalogue) *models.Ca
 	// Transform entryLinks to unit summaries
 	response.Units = make([]models.UnitSummary, 0, len(catalogue.EntryLinks))
 	for _, entryLink := range catalogue.EntryLinks {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		summary := models.UnitSummary{
 			ID:       entryLink.ID,
 			Name:     entryLink.Name,
 			TargetID: entryLink.TargetID,
 			Type:     entryLink.Type,
 			Costs:    t.TransformCosts(entryLink.Costs),
+			Overlays: entryLink.Overlays,
 		}
 		response.Units = append(response.Units, summary)
 	}
 
-	return response
+	response.Warnings = t.catalogueWarnings(catalogue)
+
+	return response, nil
 }
 
 // Helper functions
@@ -1025,4 +802,3 @@ func parseKeywords(keywords string) []string {
 	}
 	return result
 }
-
@@ -1,15 +1,20 @@
 package parser
 
 import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"grimoire-api/internal/datatest"
 	"grimoire-api/internal/models"
 )
 
 func TestTransformUnit(t *testing.T) {
 	dataDir := getTestDataDir(t)
 	p := NewParser(dataDir)
-	
+
 	if err := p.LoadGameSystem(); err != nil {
 		t.Fatalf("Failed to load game system: %v", err)
 	}
@@ -110,7 +115,7 @@ func TestTransformUnit(t *testing.T) {
 func TestTransformUnitProfile(t *testing.T) {
 	dataDir := getTestDataDir(t)
 	p := NewParser(dataDir)
-	
+
 	if err := p.LoadGameSystem(); err != nil {
 		t.Fatalf("Failed to load game system: %v", err)
 	}
@@ -171,7 +176,7 @@ func TestTransformUnitProfile(t *testing.T) {
 func TestTransformWeapons(t *testing.T) {
 	dataDir := getTestDataDir(t)
 	p := NewParser(dataDir)
-	
+
 	if err := p.LoadGameSystem(); err != nil {
 		t.Fatalf("Failed to load game system: %v", err)
 	}
@@ -237,7 +242,7 @@ func TestTransformWeapons(t *testing.T) {
 func TestTransformWeaponsWithEntryLinks(t *testing.T) {
 	dataDir := getTestDataDir(t)
 	p := NewParser(dataDir)
-	
+
 	if err := p.LoadGameSystem(); err != nil {
 		t.Fatalf("Failed to load game system: %v", err)
 	}
@@ -344,7 +349,7 @@ func TestTransformCosts(t *testing.T) {
 func TestTransformCatalogue(t *testing.T) {
 	dataDir := getTestDataDir(t)
 	p := NewParser(dataDir)
-	
+
 	if err := p.LoadGameSystem(); err != nil {
 		t.Fatalf("Failed to load game system: %v", err)
 	}
@@ -373,7 +378,10 @@ func TestTransformCatalogue(t *testing.T) {
 		t.Skip("No catalogue with entryLinks found")
 	}
 
-	response := transformer.TransformCatalogue(testCatalogue)
+	response, err := transformer.TransformCatalogue(context.Background(), testCatalogue)
+	if err != nil {
+		t.Fatalf("Failed to transform catalogue: %v", err)
+	}
 
 	if response == nil {
 		t.Fatal("Transformed catalogue is nil")
@@ -403,4 +411,221 @@ func TestTransformCatalogue(t *testing.T) {
 	}
 }
 
+func TestTransformUnitWarnings(t *testing.T) {
+	p := NewParser(getFixtureDataDir(t))
+	if err := p.LoadGameSystem(); err != nil {
+		t.Fatalf("Failed to load game system: %v", err)
+	}
+	if err := p.LoadAllCatalogues(); err != nil {
+		t.Fatalf("Failed to load catalogues: %v", err)
+	}
+
+	transformer := NewTransformer(NewLinkResolver(p))
+
+	// A unit with no profile, a cost that isn't a number and a wargear link that doesn't resolve
+	entry := &models.SelectionEntry{
+		ID:    "se-warnings",
+		Name:  "Warning Unit",
+		Type:  "unit",
+		Costs: []models.Cost{{Name: "pts", Value: "lots"}},
+		InfoLinks: []models.InfoLink{
+			{ID: "il-warnings", Name: "Missing", TargetID: "prof-missing", Type: "profile"},
+		},
+		EntryLinks: []models.EntryLink{
+			{ID: "el-warnings", Name: "Missing Wargear", TargetID: "se-missing", Type: "selectionEntry"},
+		},
+	}
+
+	if unit := transformer.TransformUnit(entry, "cat-fixture-marines"); len(unit.Warnings) != 0 {
+		t.Errorf("Expected no warnings unless asked for, got %+v", unit.Warnings)
+	}
+	unit := transformer.TransformUnitWithWarnings(entry, "cat-fixture-marines")
+
+	codes := make(map[string]models.ResolutionWarning)
+	for _, warning := range unit.Warnings {
+		codes[warning.Code] = warning
+	}
+	if len(codes) != 4 {
+		t.Fatalf("Expected 4 kinds of warning, got %+v", unit.Warnings)
+	}
+	if w := codes[models.RuleUnresolvedLink]; w.LinkID != "el-warnings" || w.TargetID != "se-missing" || w.EntryID != "se-warnings" {
+		t.Errorf("Unexpected unresolved-link warning: %+v", w)
+	}
+	if _, ok := codes[models.RuleUnparseableCost]; !ok {
+		t.Error("Expected an unparseable-cost warning")
+	}
+	if _, ok := codes[models.RuleMissingUnitProfile]; !ok {
+		t.Error("Expected a missing-unit-profile warning")
+	}
+	if w := codes[models.RuleMissingInfoTarget]; w.TargetID != "prof-missing" {
+		t.Errorf("Unexpected missing-info-link-target warning: %+v", w)
+	}
+
+	catalogue, _ := p.GetCatalogue("cat-fixture-marines")
+	response, err := transformer.TransformCatalogue(context.Background(), catalogue)
+	if err != nil {
+		t.Fatalf("Failed to transform catalogue: %v", err)
+	}
+	if len(response.Warnings) != 1 || response.Warnings[0].LinkID != "el-fixture-broken" {
+		t.Fatalf("Expected one warning for el-fixture-broken, got %+v", response.Warnings)
+	}
+	if response.Warnings[0].File != "Imperium - Fixture Marines.cat" || response.Warnings[0].Line != 21 {
+		t.Errorf("Expected the warning at Imperium - Fixture Marines.cat:21, got %s:%d", response.Warnings[0].File, response.Warnings[0].Line)
+	}
+}
+
+func TestExplainEntryLink(t *testing.T) {
+	p := NewParser(getFixtureDataDir(t))
+	if err := p.LoadGameSystem(); err != nil {
+		t.Fatalf("Failed to load game system: %v", err)
+	}
+	if err := p.LoadAllCatalogues(); err != nil {
+		t.Fatalf("Failed to load catalogues: %v", err)
+	}
 
+	transformer := NewTransformer(NewLinkResolver(p))
+
+	entryLink, catalogueID, found := p.FindEntryLinkByID("el-fixture-captain")
+	if !found {
+		t.Fatal("el-fixture-captain not found")
+	}
+	explanation, err := transformer.ExplainEntryLink(entryLink, catalogueID)
+	if err != nil {
+		t.Fatalf("Failed to explain: %v", err)
+	}
+
+	if explanation.SelectionEntry.File != "Library - Fixture Astartes.cat" || explanation.SelectionEntry.Line != 88 {
+		t.Errorf("Expected the selectionEntry at Library - Fixture Astartes.cat:88, got %+v", explanation.SelectionEntry)
+	}
+	if explanation.EntryLink == nil || explanation.EntryLink.Line != 14 {
+		t.Errorf("Expected the entryLink on line 14, got %+v", explanation.EntryLink)
+	}
+	if len(explanation.Costs) != 1 || explanation.Costs[0].Source.Kind != "cost" || explanation.Costs[0].Source.Line <= 88 {
+		t.Errorf("Expected the pts cost inside the library entry, got %+v", explanation.Costs)
+	}
+	if len(explanation.Overrides) != 1 || explanation.Overrides[0].Field != "categoryLinks" || explanation.Overrides[0].ID != "fac-fixture-imperium" {
+		t.Errorf("Expected the Imperium categoryLink override, got %+v", explanation.Overrides)
+	}
+
+	// The bolter is reached through an entryLink inside the library entry
+	if len(explanation.Weapons) != 1 {
+		t.Fatalf("Expected 1 weapon, got %+v", explanation.Weapons)
+	}
+	chain := explanation.Weapons[0].Chain
+	if len(chain) != 4 || chain[2].ID != "el-cap-bolter" || chain[3].ID != "se-fixture-mc-bolter" {
+		t.Errorf("Unexpected weapon chain: %+v", chain)
+	}
+
+	for _, category := range explanation.Categories {
+		wantKind := "selectionEntry"
+		if category.ID == "fac-fixture-imperium" {
+			wantKind = "entryLink"
+		}
+		if category.Source.Kind != wantKind {
+			t.Errorf("Expected category %s to come from the %s, got %+v", category.Name, wantKind, category.Source)
+		}
+	}
+
+	// The Intercessors' pts modifier sets the cost of the larger squad
+	entryLink, catalogueID, _ = p.FindEntryLinkByID("el-fixture-intercessors")
+	explanation, err = transformer.ExplainEntryLink(entryLink, catalogueID)
+	if err != nil {
+		t.Fatalf("Failed to explain: %v", err)
+	}
+	if len(explanation.Modifiers) != 1 || !explanation.Modifiers[0].Applied || explanation.Modifiers[0].Effect != "tieredCosts" {
+		t.Errorf("Expected the tier modifier to be applied, got %+v", explanation.Modifiers)
+	}
+}
+
+func TestExplainSharedAndLinkedProfiles(t *testing.T) {
+	dir := datatest.CopyFixture(t)
+	path := filepath.Join(dir, "Chaos - Fixture Daemons.cat")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	daemons := string(data)
+
+	// cutProfiles removes the profiles block that follows after and returns its profile
+	cutProfiles := func(after string) string {
+		start := strings.Index(daemons, after) + len(after)
+		open := start + strings.Index(daemons[start:], "<profiles>")
+		end := open + strings.Index(daemons[open:], "</profiles>")
+		profile := daemons[open+len("<profiles>") : end]
+		daemons = daemons[:open] + "<!--profiles-->" + daemons[end+len("</profiles>"):]
+		return profile
+	}
+
+	// The Bloodletter profile becomes a shared profile the model links to
+	bloodletter := cutProfiles(`id="se-fixture-bloodletter-model"`)
+	daemons = strings.Replace(daemons, "<!--profiles-->",
+		`<infoLinks><infoLink id="il-bl-unit" name="Bloodletter" hidden="false" targetId="prof-bl-unit" type="profile"/></infoLinks>`, 1)
+
+	// The Bloodthirster profile moves to a shared entry linked from a group of the unit
+	bloodthirster := cutProfiles(`id="se-fixture-bloodthirster"`)
+	daemons = strings.Replace(daemons, "<!--profiles-->",
+		`<selectionEntryGroups><selectionEntryGroup id="seg-bt-body" name="Body" hidden="false" collective="false" import="true">`+
+			`<entryLinks><entryLink id="el-bt-body" name="Body" hidden="false" collective="false" import="true" targetId="se-bt-body" type="selectionEntry"/></entryLinks>`+
+			`</selectionEntryGroup></selectionEntryGroups>`, 1)
+	daemons = strings.Replace(daemons, "</sharedSelectionEntries>",
+		`<selectionEntry id="se-bt-body" name="Body" hidden="false" collective="false" import="true" type="upgrade"><profiles>`+bloodthirster+`</profiles></selectionEntry></sharedSelectionEntries>`, 1)
+	daemons = strings.Replace(daemons, "</catalogue>", "<sharedProfiles>"+bloodletter+"</sharedProfiles></catalogue>", 1)
+	if err := os.WriteFile(path, []byte(daemons), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	p := NewParser(dir)
+	p.SetQuiet(true)
+	if err := p.LoadGameSystem(); err != nil {
+		t.Fatalf("Failed to load game system: %v", err)
+	}
+	if err := p.LoadAllCatalogues(); err != nil {
+		t.Fatalf("Failed to load catalogues: %v", err)
+	}
+	transformer := NewTransformer(NewLinkResolver(p))
+
+	tests := []struct {
+		entryLinkID string
+		profileID   string
+		toughness   int
+		chain       []string
+	}{
+		{"el-fixture-bloodletters", "prof-bl-unit", 5,
+			[]string{"el-fixture-bloodletters", "se-fixture-bloodletters", "se-fixture-bloodletter-model", "il-bl-unit"}},
+		{"el-fixture-bloodthirster", "prof-bt-unit", 13,
+			[]string{"el-fixture-bloodthirster", "se-fixture-bloodthirster", "seg-bt-body", "el-bt-body", "se-bt-body"}},
+	}
+	for _, tt := range tests {
+		entryLink, catalogueID, found := p.FindEntryLinkByID(tt.entryLinkID)
+		if !found {
+			t.Fatalf("%s not found", tt.entryLinkID)
+		}
+		explanation, err := transformer.ExplainEntryLink(entryLink, catalogueID)
+		if err != nil {
+			t.Fatalf("Failed to explain %s: %v", tt.entryLinkID, err)
+		}
+		if explanation.Unit.Profiles.Unit == nil || explanation.Unit.Profiles.Unit.Toughness != tt.toughness {
+			t.Errorf("Expected %s to have toughness %d, got %+v", tt.entryLinkID, tt.toughness, explanation.Unit.Profiles.Unit)
+		}
+
+		var profile *models.ExplainedValue
+		for i := range explanation.Profiles {
+			if explanation.Profiles[i].ID == tt.profileID {
+				profile = &explanation.Profiles[i]
+			}
+		}
+		if profile == nil {
+			t.Fatalf("Expected %s among the profiles of %s, got %+v", tt.profileID, tt.entryLinkID, explanation.Profiles)
+		}
+		if profile.Source.Kind != "profile" || profile.Source.File != "Chaos - Fixture Daemons.cat" || profile.Source.Line == 0 {
+			t.Errorf("Expected %s to be sourced in the daemons catalogue, got %+v", tt.profileID, profile.Source)
+		}
+		var chain []string
+		for _, ref := range profile.Chain {
+			chain = append(chain, ref.ID)
+		}
+		if strings.Join(chain, " > ") != strings.Join(tt.chain, " > ") {
+			t.Errorf("Expected %s to be reached through %v, got %v", tt.profileID, tt.chain, chain)
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+
+	"grimoire-api/internal/models"
+)
+
+// unitWalk records what the transformer passes while it walks a unit's entries, groups and links
+// for weapons: the warnings, overlay marks and profile sources that were asked for. Its methods do
+// nothing on a nil walk, so transformations that record nothing only pay a nil check.
+type unitWalk struct {
+	transformer *Transformer
+	catalogueID string // The unit's catalogue, which the transformer resolves every link against
+	root        *models.SelectionEntry
+	definedIn   string             // Catalogue the entries being walked are defined in, empty until looked up for the root's
+	chain       []models.SourceRef // How the entries being walked were reached, when recording sources
+	visited     map[string]bool
+
+	warnings     bool
+	overlays     bool
+	found        []models.ResolutionWarning
+	profileLinks []models.ResolutionWarning // Only relevant when no Unit profile was found
+	seen         map[models.ResolutionWarning]bool
+	marks        []models.OverlayMark
+	seenMarks    map[models.OverlayMark]bool
+	sources      map[string]profileSource // Set when explaining
+}
+
+func newUnitWalk(t *Transformer, entry *models.SelectionEntry, catalogueID string) *unitWalk {
+	return &unitWalk{
+		transformer: t,
+		catalogueID: catalogueID,
+		root:        entry,
+		visited:     make(map[string]bool),
+		seen:        make(map[models.ResolutionWarning]bool),
+		seenMarks:   make(map[models.OverlayMark]bool),
+	}
+}
+
+// source returns the catalogue the entries being walked are defined in
+func (w *unitWalk) source() string {
+	if w.definedIn == "" {
+		_, w.definedIn, _ = w.transformer.resolver.parser.FindSelectionEntryByID(w.root.ID)
+	}
+	return w.definedIn
+}
+
+// ref describes an element of the entries being walked
+func (w *unitWalk) ref(kind, id, name string, pos models.SourcePos) models.SourceRef {
+	return w.transformer.sourceRef(kind, id, name, w.source(), pos)
+}
+
+// push extends the chain with ref while recording sources, returning a func that restores it
+func (w *unitWalk) push(ref func() models.SourceRef) func() {
+	if w == nil || w.sources == nil {
+		return func() {}
+	}
+	chain := w.chain
+	w.chain = extend(chain, ref())
+	return func() { w.chain = chain }
+}
+
+// warn records a warning about an element of the entries being walked, once
+func (w *unitWalk) warn(warning models.ResolutionWarning, pos models.SourcePos) {
+	warning.File = w.file()
+	warning.Line = pos.Line
+	if !w.seen[warning] {
+		w.seen[warning] = true
+		w.found = append(w.found, warning)
+	}
+}
+
+func (w *unitWalk) mark(marks []models.OverlayMark) {
+	for _, mark := range marks {
+		if !w.seenMarks[mark] {
+			w.seenMarks[mark] = true
+			w.marks = append(w.marks, mark)
+		}
+	}
+}
+
+func (w *unitWalk) addSource(profile *models.Profile, definedIn string, chain []models.SourceRef) {
+	if profile.ID == "" {
+		return
+	}
+	if _, exists := w.sources[profile.ID]; exists {
+		return
+	}
+	w.sources[profile.ID] = profileSource{
+		ref:   w.transformer.sourceRef("profile", profile.ID, profile.Name, definedIn, profile.Pos),
+		chain: chain,
+	}
+}
+
+// foundProfile records where the transformer found a profile while recording sources. definedIn
+// is the catalogue it is defined in, empty for the one being walked, and path is how it was reached
+// from the entries being walked.
+func (w *unitWalk) foundProfile(profile *models.Profile, definedIn string, path []models.SourceRef) {
+	if w == nil || w.sources == nil {
+		return
+	}
+	if definedIn == "" {
+		definedIn = w.source()
+	}
+	chain := append(append(make([]models.SourceRef, 0, len(w.chain)+len(path)), w.chain...), path...)
+	w.addSource(profile, definedIn, chain)
+}
+
+// via extends path with an element defined in the catalogue definedIn (empty for the one being
+// walked) while recording sources, for searches that look below the entries being walked
+func (w *unitWalk) via(path []models.SourceRef, kind, id, name, definedIn string, pos models.SourcePos) []models.SourceRef {
+	if w == nil || w.sources == nil {
+		return nil
+	}
+	if definedIn == "" {
+		definedIn = w.source()
+	}
+	return extend(path, w.transformer.sourceRef(kind, id, name, definedIn, pos))
+}
+
+// entry records an entry the first time the walk reaches it
+func (w *unitWalk) entry(entry *models.SelectionEntry) {
+	if w == nil || w.visited[entry.ID] {
+		return
+	}
+	w.visited[entry.ID] = true
+
+	if w.overlays {
+		w.mark(entry.Overlays)
+		for i := range entry.Profiles {
+			w.mark(entry.Profiles[i].Overlays)
+		}
+	}
+	if w.sources != nil {
+		for i := range entry.Profiles {
+			w.addSource(&entry.Profiles[i], w.source(), w.chain)
+		}
+	}
+
+	if w.warnings {
+		for _, cost := range entry.Costs {
+			if cost.Value == "" {
+				continue
+			}
+			if _, err := strconv.Atoi(cost.Value); err != nil {
+				w.warn(models.ResolutionWarning{
+					Code:        models.RuleUnparseableCost,
+					Message:     fmt.Sprintf("%s cost %q on %q is not a number", cost.Name, cost.Value, entry.Name),
+					CatalogueID: w.catalogueID,
+					EntryID:     entry.ID,
+				}, cost.Pos)
+			}
+		}
+	}
+
+	for _, infoLink := range entry.InfoLinks {
+		if infoLink.Type != "profile" || infoLink.TargetID == "" {
+			continue
+		}
+		profile, found := w.transformer.resolver.parser.GetProfile(infoLink.TargetID, w.catalogueID)
+		switch {
+		case found && w.overlays:
+			w.mark(profile.Overlays)
+		case !found && w.warnings:
+			w.profileLinks = append(w.profileLinks, models.ResolutionWarning{
+				Code:        models.RuleMissingInfoTarget,
+				Message:     fmt.Sprintf("infoLink %q targets profile %s, which is not a shared profile of catalogue %s", infoLink.Name, infoLink.TargetID, w.catalogueID),
+				CatalogueID: w.catalogueID,
+				EntryID:     entry.ID,
+				LinkID:      infoLink.ID,
+				TargetID:    infoLink.TargetID,
+				File:        w.file(),
+				Line:        entry.Pos.Line,
+			})
+		}
+		if found && w.sources != nil {
+			linkRef := w.ref("infoLink", infoLink.ID, infoLink.Name, models.SourcePos{})
+			w.addSource(profile, w.catalogueID, extend(w.chain, linkRef))
+		}
+	}
+}
+
+// file returns the file of the catalogue the entries being walked are defined in
+func (w *unitWalk) file() string {
+	file, _ := w.transformer.resolver.parser.GetCatalogueFile(w.source())
+	return file
+}
+
+// enterEntry records a child entry and extends the chain with it, returning a func to leave it
+func (w *unitWalk) enterEntry(entry *models.SelectionEntry) func() {
+	leave := w.push(func() models.SourceRef {
+		return w.ref("selectionEntry", entry.ID, entry.Name, entry.Pos)
+	})
+	w.entry(entry)
+	return leave
+}
+
+// enterGroup extends the chain with a group, returning a func to leave it
+func (w *unitWalk) enterGroup(group *models.SelectionEntryGroup) func() {
+	return w.push(func() models.SourceRef {
+		return w.ref("selectionEntryGroup", group.ID, group.Name, models.SourcePos{})
+	})
+}
+
+// followLink records an entryLink of parentID that resolved to an entry defined in sourceID, or
+// failed to resolve with err. It returns a func to leave the link once its entry has been walked.
+func (w *unitWalk) followLink(parentID string, entryLink *models.EntryLink, sourceID string, err error) func() {
+	if w == nil {
+		return func() {}
+	}
+	if w.overlays {
+		w.mark(entryLink.Overlays)
+	}
+	if err != nil {
+		if w.warnings {
+			w.warn(w.transformer.resolver.UnresolvedLinkWarning(entryLink, w.catalogueID, parentID, err), entryLink.Pos)
+		}
+		return func() {}
+	}
+
+	definedIn := w.source()
+	leave := w.push(func() models.SourceRef {
+		return w.ref("entryLink", entryLink.ID, entryLink.Name, entryLink.Pos)
+	})
+	w.definedIn = sourceID
+	return func() {
+		w.definedIn = definedIn
+		leave()
+	}
+}
@@ -0,0 +1,72 @@
+package parser
+
+import (
+	"fmt"
+
+	"grimoire-api/internal/models"
+)
+
+// UnresolvedLinkWarning describes an entryLink defined in catalogueID that was skipped because it didn't resolve
+func (lr *LinkResolver) UnresolvedLinkWarning(entryLink *models.EntryLink, catalogueID, entryID string, err error) models.ResolutionWarning {
+	file, _ := lr.parser.GetCatalogueFile(catalogueID)
+	return models.ResolutionWarning{
+		Code:        models.RuleUnresolvedLink,
+		Message:     fmt.Sprintf("entryLink %q (%s) skipped: %v", entryLink.Name, entryLink.ID, err),
+		CatalogueID: catalogueID,
+		EntryID:     entryID,
+		LinkID:      entryLink.ID,
+		TargetID:    entryLink.TargetID,
+		File:        file,
+		Line:        entryLink.Pos.Line,
+	}
+}
+
+// unitWarnings reports the links and values the walk of a unit had to skip
+func (w *unitWalk) unitWarnings(unit *models.UnitResponse) []models.ResolutionWarning {
+	warnings := w.found
+	if (w.root.Type == "unit" || w.root.Type == "model") && (unit.Profiles == nil || unit.Profiles.Unit == nil) {
+		warnings = append(warnings, models.ResolutionWarning{
+			Code:        models.RuleMissingUnitProfile,
+			Message:     fmt.Sprintf("no Unit profile found in %q, its child entries, groups or infoLinks", w.root.Name),
+			CatalogueID: w.catalogueID,
+			EntryID:     w.root.ID,
+			File:        w.file(),
+			Line:        w.root.Pos.Line,
+		})
+		warnings = append(warnings, w.profileLinks...)
+	}
+	return warnings
+}
+
+// catalogueWarnings reports the root entryLinks and catalogueLinks of a catalogue that don't resolve
+func (t *Transformer) catalogueWarnings(catalogue *models.Catalogue) []models.ResolutionWarning {
+	var warnings []models.ResolutionWarning
+
+	for _, catLink := range catalogue.CatalogueLinks {
+		_, isLibrary := t.resolver.parser.GetLibrary(catLink.TargetID)
+		_, isCatalogue := t.resolver.parser.GetCatalogue(catLink.TargetID)
+		if !isLibrary && !isCatalogue {
+			file, _ := t.resolver.parser.GetCatalogueFile(catalogue.ID)
+			warnings = append(warnings, models.ResolutionWarning{
+				Code:        models.RuleUnresolvedCatalogueLink,
+				Message:     fmt.Sprintf("catalogueLink %q targets catalogue %s, which is not loaded", catLink.Name, catLink.TargetID),
+				CatalogueID: catalogue.ID,
+				LinkID:      catLink.ID,
+				TargetID:    catLink.TargetID,
+				File:        file,
+			})
+		}
+	}
+
+	for i := range catalogue.EntryLinks {
+		entryLink := &catalogue.EntryLinks[i]
+		if entryLink.Type != "selectionEntry" {
+			continue
+		}
+		if _, err := t.resolver.ResolveEntryLink(entryLink, catalogue.ID); err != nil {
+			warnings = append(warnings, t.resolver.UnresolvedLinkWarning(entryLink, catalogue.ID, "", err))
+		}
+	}
+
+	return warnings
+}
@@ -0,0 +1,212 @@
+// Package server builds the router of the REST API: every route, with its middleware, and the
+// OpenAPI document describing them. The server command runs it, and pkg/client serves it in-process
+// for tests.
+package server
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+
+	"grimoire-api/internal/export"
+	"grimoire-api/internal/graphql"
+	"grimoire-api/internal/handlers"
+	"grimoire-api/internal/homebrew"
+	"grimoire-api/internal/openapi"
+	"grimoire-api/internal/service"
+	"grimoire-api/internal/snapshot"
+)
+
+// Default request timeouts
+const (
+	DefaultRequestTimeout     = 10 * time.Second
+	DefaultSlowRequestTimeout = time.Minute
+)
+
+// Config holds what the routes serve from. NewRouter fills in the zero timeouts and limits with
+// their defaults.
+type Config struct {
+	Snapshots *snapshot.Store
+	History   *service.HistoryService
+	Homebrew  *homebrew.Store
+	Exports   *export.Files // Written exports; kept in a new temporary directory when nil
+
+	// Bearer tokens of homebrew uploads and deletes, and of the /api/v1/admin routes; empty turns them off
+	HomebrewToken string
+	AdminToken    string
+
+	RequestTimeout     time.Duration // For single lookups
+	SlowRequestTimeout time.Duration // For routes that list, search or diff whole datasets
+	GraphQLLimits      graphql.Limits
+
+	Quiet bool // Don't log requests
+}
+
+// NewRouter builds the router serving config. It fails when a route is missing from the OpenAPI
+// routes table.
+func NewRouter(config Config) (*gin.Engine, error) {
+	snapshots := config.Snapshots
+	if config.RequestTimeout == 0 {
+		config.RequestTimeout = DefaultRequestTimeout
+	}
+	if config.SlowRequestTimeout == 0 {
+		config.SlowRequestTimeout = DefaultSlowRequestTimeout
+	}
+	if config.GraphQLLimits == (graphql.Limits{}) {
+		config.GraphQLLimits = graphql.DefaultLimits
+	}
+
+	// Initialize handlers
+	unitHandler := handlers.NewUnitHandler(snapshots)
+	catalogueHandler := handlers.NewCatalogueHandler(snapshots)
+	factionHandler := handlers.NewFactionHandler(snapshots)
+	searchHandler := handlers.NewSearchHandler(snapshots)
+	gameSystemHandler := handlers.NewGameSystemHandler(snapshots)
+	ruleHandler := handlers.NewRuleHandler(snapshots)
+	diffHandler := handlers.NewDiffHandler(snapshots)
+	exports := config.Exports
+	if exports == nil {
+		exports = export.NewFiles("", 0)
+	}
+	exportHandler := handlers.NewExportHandler(snapshots, exports)
+	historyHandler := handlers.NewHistoryHandler(config.History)
+	adminHandler := handlers.NewAdminHandler(snapshots)
+	homebrewHandler := handlers.NewHomebrewHandler(config.Homebrew, snapshots)
+	graphQLSchema, err := graphql.NewSchema(config.GraphQLLimits)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build the GraphQL schema: %w", err)
+	}
+	graphQLHandler := handlers.NewGraphQLHandler(snapshots, graphQLSchema)
+
+	router := gin.New()
+	if !config.Quiet {
+		router.Use(gin.Logger())
+	}
+	router.Use(gin.Recovery())
+
+	// CORS middleware
+	corsConfig := cors.DefaultConfig()
+	corsConfig.AllowAllOrigins = true
+	corsConfig.AllowMethods = []string{"GET", "POST", "DELETE", "OPTIONS"}
+	corsConfig.AddAllowHeaders("Authorization", handlers.RevisionHeader)
+	corsConfig.AddExposeHeaders(handlers.RevisionHeader)
+	router.Use(cors.New(corsConfig))
+
+	// Health check
+	router.GET("/health", func(c *gin.Context) {
+		current := snapshots.Current()
+		status := "healthy"
+		if len(current.LoadReport.Errors) > 0 {
+			status = "degraded"
+		}
+		c.JSON(200, gin.H{
+			"status":   status,
+			"revision": current.Revision,
+			"load":     current.LoadReport,
+		})
+	})
+
+	// Readiness probe: not ready while the current snapshot is warming up
+	router.GET("/ready", func(c *gin.Context) {
+		readiness := snapshots.Current().Readiness()
+		status := 200
+		if readiness.Status != "ready" {
+			status = 503
+		}
+		c.JSON(status, readiness)
+	})
+
+	// API routes
+	v1 := router.Group("/api/v1", handlers.PinSnapshot(snapshots))
+	timeout := handlers.Timeout(config.RequestTimeout)
+	slow := handlers.Timeout(config.SlowRequestTimeout)
+	{
+		// Game system
+		v1.GET("/game-system", timeout, gameSystemHandler.GetGameSystem)
+
+		// Catalogues
+		v1.GET("/catalogues", timeout, catalogueHandler.ListCatalogues)
+		v1.GET("/catalogues/graph", slow, catalogueHandler.GetCatalogueGraph)
+		v1.GET("/catalogues/:id", timeout, catalogueHandler.GetCatalogue)
+		v1.GET("/catalogues/:id/units", slow, catalogueHandler.GetCatalogueUnits)
+
+		// Homebrew catalogues, uploaded with the token in HOMEBREW_TOKEN
+		requireToken := handlers.RequireToken(config.HomebrewToken)
+		v1.POST("/catalogues", requireToken, homebrewHandler.UploadCatalogue)
+		v1.DELETE("/catalogues/:id", requireToken, homebrewHandler.DeleteCatalogue)
+
+		// Shared rules
+		v1.GET("/rules", slow, ruleHandler.ListRules)
+		v1.GET("/rules/:id", timeout, ruleHandler.GetRule)
+
+		// Units
+		v1.GET("/units", slow, unitHandler.ListUnits)
+		v1.GET("/units/:id", timeout, unitHandler.GetUnit)
+		v1.GET("/units/:id/explain", timeout, unitHandler.ExplainUnit)
+		v1.GET("/units/:id/weapons", timeout, unitHandler.GetUnitWeapons)
+		v1.GET("/units/:id/history", timeout, historyHandler.GetUnitHistory)
+
+		// Factions
+		v1.GET("/factions", slow, factionHandler.ListFactions)
+		v1.GET("/factions/:name", slow, factionHandler.GetFaction)
+		v1.GET("/factions/:name/units", slow, factionHandler.GetFactionUnits)
+
+		// Search
+		v1.GET("/search", slow, searchHandler.Search)
+
+		// Data revision diffs
+		v1.GET("/diff", slow, diffHandler.GetDiff)
+
+		// Dataset exports
+		v1.GET("/export/:format", slow, exportHandler.GetExport)
+
+		// GraphQL
+		v1.GET("/graphql", slow, graphQLHandler.Query)
+		v1.POST("/graphql", slow, graphQLHandler.Query)
+
+		// Admin, with the token in ADMIN_TOKEN
+		admin := v1.Group("/admin", handlers.RequireToken(config.AdminToken))
+		admin.GET("/data-quality", adminHandler.GetDataQuality)
+		admin.GET("/overlays", adminHandler.GetOverlays)
+		admin.GET("/snapshots", adminHandler.ListSnapshots)
+		admin.GET("/cache", adminHandler.GetCacheStats)
+		admin.POST("/reload", adminHandler.Reload)
+	}
+
+	// OpenAPI document of the routes above, and a page to browse it
+	document, err := openapi.Generate(router.Routes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate the OpenAPI document: %w", err)
+	}
+	docsHandler, err := handlers.NewDocsHandler(document)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode the OpenAPI document: %w", err)
+	}
+	router.GET(openapi.SpecPath, docsHandler.GetSpec)
+	router.GET(openapi.DocsPath, docsHandler.GetDocs)
+
+	// Root endpoint
+	router.GET("/", func(c *gin.Context) {
+		c.JSON(200, gin.H{
+			"name":    "Warhammer 40K 10th Edition API",
+			"version": "1.0.0",
+			"endpoints": gin.H{
+				"game-system": "/api/v1/game-system",
+				"catalogues":  "/api/v1/catalogues",
+				"units":       "/api/v1/units",
+				"factions":    "/api/v1/factions",
+				"rules":       "/api/v1/rules",
+				"search":      "/api/v1/search",
+				"diff":        "/api/v1/diff",
+				"export":      "/api/v1/export/{csv,ndjson,sqlite}",
+				"graphql":     "/api/v1/graphql",
+				"openapi":     "/api/v1/openapi.json",
+				"docs":        "/api/v1/docs",
+			},
+		})
+	})
+
+	return router, nil
+}
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"grimoire-api/internal/models"
+	"grimoire-api/internal/parser"
+)
+
+// GetCatalogueGraph returns the catalogue import graph. When focus names a catalogue
+// (by ID or name), the graph is limited to that catalogue, everything it imports and
+// everything that depends on it, and to the links between them and their unresolved links.
+// It stops with the context's error once the context is done.
+func (s *CatalogueService) GetCatalogueGraph(ctx context.Context, focus string) (*models.CatalogueGraphResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	graph := s.resolver.ResolveCatalogueGraph()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var include map[string]bool
+	if focus != "" {
+		node := findGraphNode(graph, focus)
+		if node == nil {
+			return nil, fmt.Errorf("catalogue not found: %s", focus)
+		}
+		include = map[string]bool{node.Catalogue.ID: true}
+		for _, id := range node.TransitiveImports {
+			include[id] = true
+		}
+		for _, id := range node.Dependents {
+			include[id] = true
+		}
+	}
+
+	inCycle := make(map[string]bool)
+	response := &models.CatalogueGraphResponse{
+		Nodes:  make([]models.CatalogueGraphNode, 0, len(graph.Nodes)),
+		Edges:  make([]models.CatalogueGraphEdge, 0, len(graph.Edges)),
+		Cycles: make([][]string, 0, len(graph.Cycles)),
+	}
+
+	for _, cycle := range graph.Cycles {
+		for _, id := range cycle {
+			inCycle[id] = true
+		}
+		if include == nil || include[cycle[0]] {
+			response.Cycles = append(response.Cycles, cycle)
+		}
+	}
+
+	for _, node := range graph.Nodes {
+		if include != nil && !include[node.Catalogue.ID] {
+			continue
+		}
+		response.Nodes = append(response.Nodes, models.CatalogueGraphNode{
+			ID:                node.Catalogue.ID,
+			Name:              node.Catalogue.Name,
+			Revision:          node.Catalogue.Revision,
+			Library:           node.Catalogue.Library,
+			Imports:           nonNil(node.Imports),
+			ImportedBy:        nonNil(node.ImportedBy),
+			TransitiveImports: nonNil(node.TransitiveImports),
+			Dependents:        nonNil(node.Dependents),
+			InCycle:           inCycle[node.Catalogue.ID],
+		})
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	for _, edge := range graph.Edges {
+		// A link to a catalogue outside the focus would be drawn as a node without a label
+		if include != nil && (!include[edge.From] || (edge.Resolved && !include[edge.To])) {
+			continue
+		}
+		response.Edges = append(response.Edges, models.CatalogueGraphEdge{
+			From:              edge.From,
+			To:                edge.To,
+			Name:              edge.Name,
+			ImportRootEntries: edge.ImportRootEntries,
+			Resolved:          edge.Resolved,
+		})
+	}
+
+	return response, nil
+}
+
+// findGraphNode finds a graph node by catalogue ID or case-insensitive name
+func findGraphNode(graph *parser.CatalogueGraph, query string) *parser.CatalogueNode {
+	if node, exists := graph.Node(query); exists {
+		return node
+	}
+	for _, node := range graph.Nodes {
+		if strings.EqualFold(node.Catalogue.Name, query) {
+			return node
+		}
+	}
+	return nil
+}
+
+// RenderCatalogueGraphDOT renders the graph in Graphviz DOT format.
+// Links that don't import root entries are dashed; unresolved targets are drawn in red.
+func RenderCatalogueGraphDOT(graph *models.CatalogueGraphResponse) string {
+	var b strings.Builder
+	b.WriteString("digraph catalogues {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box];\n")
+
+	for _, node := range graph.Nodes {
+		attrs := []string{"label=" + dotQuote(node.Name)}
+		if node.Library {
+			attrs = append(attrs, "style=rounded")
+		}
+		if node.InCycle {
+			attrs = append(attrs, "color=red")
+		}
+		fmt.Fprintf(&b, "  %s [%s];\n", dotQuote(node.ID), strings.Join(attrs, ", "))
+	}
+
+	for _, edge := range graph.Edges {
+		var attrs []string
+		if !edge.ImportRootEntries {
+			attrs = append(attrs, "style=dashed")
+		}
+		if !edge.Resolved {
+			attrs = append(attrs, "color=red", "label=\"missing\"")
+		}
+		fmt.Fprintf(&b, "  %s -> %s", dotQuote(edge.From), dotQuote(edge.To))
+		if len(attrs) > 0 {
+			fmt.Fprintf(&b, " [%s]", strings.Join(attrs, ", "))
+		}
+		b.WriteString(";\n")
+	}
+
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// RenderCatalogueGraphMermaid renders the graph as a Mermaid flowchart.
+// Links that don't import root entries are dotted; each missing target is declared once.
+func RenderCatalogueGraphMermaid(graph *models.CatalogueGraphResponse) string {
+	ids := make(map[string]string, len(graph.Nodes))
+	mermaidID := func(id string) string {
+		if mid, exists := ids[id]; exists {
+			return mid
+		}
+		mid := fmt.Sprintf("n%d", len(ids))
+		ids[id] = mid
+		return mid
+	}
+
+	var b strings.Builder
+	b.WriteString("graph LR\n")
+
+	for _, node := range graph.Nodes {
+		label := mermaidQuote(node.Name)
+		if node.Library {
+			fmt.Fprintf(&b, "  %s([%s])\n", mermaidID(node.ID), label)
+		} else {
+			fmt.Fprintf(&b, "  %s[%s]\n", mermaidID(node.ID), label)
+		}
+	}
+
+	missing := make(map[string]bool)
+	for _, edge := range graph.Edges {
+		arrow := "-->"
+		if !edge.ImportRootEntries {
+			arrow = "-.->"
+		}
+		target := mermaidID(edge.To)
+		if !edge.Resolved && !missing[edge.To] {
+			missing[edge.To] = true
+			fmt.Fprintf(&b, "  %s[%s]\n", target, mermaidQuote("missing: "+edge.To))
+		}
+		fmt.Fprintf(&b, "  %s %s %s\n", mermaidID(edge.From), arrow, target)
+	}
+
+	return b.String()
+}
+
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+func mermaidQuote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, "#quot;") + `"`
+}
+
+// nonNil returns an empty slice instead of nil so JSON renders [] rather than null
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
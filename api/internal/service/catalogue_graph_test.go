@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"grimoire-api/internal/cache"
+	"grimoire-api/internal/models"
+	"grimoire-api/internal/parser"
+)
+
+func newFixtureCatalogueService(t *testing.T) *CatalogueService {
+	p := parser.NewParser(getFixtureDataDir(t))
+	if err := p.LoadGameSystem(); err != nil {
+		t.Fatalf("Failed to load game system: %v", err)
+	}
+	if err := p.LoadAllCatalogues(); err != nil {
+		t.Fatalf("Failed to load catalogues: %v", err)
+	}
+
+	resolver := parser.NewLinkResolver(p)
+	transformer := parser.NewTransformer(resolver)
+	return NewCatalogueService(p, resolver, transformer, cache.NewCache())
+}
+
+func TestGetCatalogueGraphFocus(t *testing.T) {
+	service := newFixtureCatalogueService(t)
+
+	graph, err := service.GetCatalogueGraph(context.Background(), "Library - Fixture Astartes")
+	if err != nil {
+		t.Fatalf("Failed to get graph: %v", err)
+	}
+
+	// The library and both catalogues that depend on it, but not the unrelated Daemons
+	if len(graph.Nodes) != 3 {
+		t.Errorf("Expected 3 nodes, got %d", len(graph.Nodes))
+	}
+	for _, node := range graph.Nodes {
+		if node.ID == "cat-fixture-daemons" {
+			t.Error("Unrelated catalogue included in focused graph")
+		}
+	}
+
+	if _, err := service.GetCatalogueGraph(context.Background(), "nonexistent"); err == nil {
+		t.Error("Expected error for unknown focus catalogue")
+	}
+}
+
+func TestGetCatalogueGraphCancelled(t *testing.T) {
+	service := newFixtureCatalogueService(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := service.GetCatalogueGraph(ctx, ""); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestGetCatalogueGraphFocusEdges(t *testing.T) {
+	p := parser.NewParser(getFixtureDataDir(t))
+	if err := p.LoadGameSystem(); err != nil {
+		t.Fatalf("Failed to load game system: %v", err)
+	}
+	if err := p.LoadAllCatalogues(); err != nil {
+		t.Fatalf("Failed to load catalogues: %v", err)
+	}
+	// A dependent of the library that also imports the unrelated Daemons
+	p.AddHomebrewCatalogue(&models.Catalogue{ID: "cat-mixed", Name: "Mixed", CatalogueLinks: []models.CatalogueLink{
+		{ID: "cl-mixed-marines", TargetID: "cat-fixture-marines", ImportRootEntries: true},
+		{ID: "cl-mixed-daemons", TargetID: "cat-fixture-daemons", ImportRootEntries: true},
+	}}, "mixed.cat")
+	resolver := parser.NewLinkResolver(p)
+	service := NewCatalogueService(p, resolver, parser.NewTransformer(resolver), cache.NewCache())
+
+	graph, err := service.GetCatalogueGraph(context.Background(), "lib-fixture-astartes")
+	if err != nil {
+		t.Fatalf("Failed to get graph: %v", err)
+	}
+
+	nodes := make(map[string]bool)
+	for _, node := range graph.Nodes {
+		nodes[node.ID] = true
+	}
+	if !nodes["cat-mixed"] || nodes["cat-fixture-daemons"] {
+		t.Errorf("Unexpected nodes: %v", nodes)
+	}
+	for _, edge := range graph.Edges {
+		if !nodes[edge.From] || !nodes[edge.To] {
+			t.Errorf("Edge %s -> %s leaves the focused graph", edge.From, edge.To)
+		}
+	}
+}
+
+func TestRenderCatalogueGraph(t *testing.T) {
+	service := newFixtureCatalogueService(t)
+
+	graph, err := service.GetCatalogueGraph(context.Background(), "")
+	if err != nil {
+		t.Fatalf("Failed to get graph: %v", err)
+	}
+
+	dot := RenderCatalogueGraphDOT(graph)
+	if !strings.HasPrefix(dot, "digraph catalogues {") ||
+		!strings.Contains(dot, `"cat-fixture-marines" -> "lib-fixture-astartes";`) {
+		t.Errorf("Unexpected DOT output:\n%s", dot)
+	}
+
+	mermaid := RenderCatalogueGraphMermaid(graph)
+	if !strings.HasPrefix(mermaid, "graph LR\n") || !strings.Contains(mermaid, `(["Library - Fixture Astartes"])`) {
+		t.Errorf("Unexpected Mermaid output:\n%s", mermaid)
+	}
+}
+
+func TestRenderCatalogueGraphMermaidMissingOnce(t *testing.T) {
+	graph := &models.CatalogueGraphResponse{
+		Nodes: []models.CatalogueGraphNode{{ID: "cat-a", Name: "A"}, {ID: "cat-b", Name: "B"}},
+		Edges: []models.CatalogueGraphEdge{
+			{From: "cat-a", To: "cat-gone", ImportRootEntries: true},
+			{From: "cat-b", To: "cat-gone"},
+		},
+	}
+
+	mermaid := RenderCatalogueGraphMermaid(graph)
+	if count := strings.Count(mermaid, `["missing: cat-gone"]`); count != 1 {
+		t.Errorf("Expected the missing catalogue to be declared once, got %d times:\n%s", count, mermaid)
+	}
+	if !strings.Contains(mermaid, "n0 --> n2") || !strings.Contains(mermaid, "n1 -.-> n2") {
+		t.Errorf("Expected both links to the missing catalogue:\n%s", mermaid)
+	}
+}
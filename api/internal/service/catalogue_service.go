@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"fmt"
 
 	"grimoire-api/internal/cache"
@@ -10,29 +11,31 @@ import (
 
 // CatalogueService handles catalogue-related business logic
 type CatalogueService struct {
-	parser     *parser.Parser
-	resolver   *parser.LinkResolver
+	parser      *parser.Parser
+	resolver    *parser.LinkResolver
 	transformer *parser.Transformer
-	cache      *cache.Cache
+	cache       *cache.Cache
 }
 
 // NewCatalogueService creates a new catalogue service
 func NewCatalogueService(p *parser.Parser, r *parser.LinkResolver, t *parser.Transformer, c *cache.Cache) *CatalogueService {
 	return &CatalogueService{
-		parser:     p,
-		resolver:   r,
+		parser:      p,
+		resolver:    r,
 		transformer: t,
-		cache:      c,
+		cache:       c,
 	}
 }
 
 // GetCatalogue retrieves a catalogue by ID
-func (s *CatalogueService) GetCatalogue(id string) (*models.CatalogueResponse, error) {
-	// Check cache first
-	if catalogue, exists := s.cache.GetCatalogue(id); exists {
-		return catalogue, nil
-	}
+func (s *CatalogueService) GetCatalogue(ctx context.Context, id string) (*models.CatalogueResponse, error) {
+	return s.cache.Catalogue(ctx, id, func(ctx context.Context) (*models.CatalogueResponse, error) {
+		return s.transformCatalogue(ctx, id)
+	})
+}
 
+// transformCatalogue finds and transforms a catalogue, without the cache
+func (s *CatalogueService) transformCatalogue(ctx context.Context, id string) (*models.CatalogueResponse, error) {
 	// Get catalogue from parser
 	catalogue, exists := s.parser.GetCatalogue(id)
 	if !exists {
@@ -40,16 +43,11 @@ func (s *CatalogueService) GetCatalogue(id string) (*models.CatalogueResponse, e
 	}
 
 	// Transform to response
-	response := s.transformer.TransformCatalogue(catalogue)
-
-	// Cache the result
-	s.cache.SetCatalogue(id, response)
-
-	return response, nil
+	return s.transformer.TransformCatalogue(ctx, catalogue)
 }
 
 // ListCatalogues lists all catalogues
-func (s *CatalogueService) ListCatalogues() ([]models.CatalogueInfo, error) {
+func (s *CatalogueService) ListCatalogues(ctx context.Context) ([]models.CatalogueInfo, error) {
 	catalogues := s.parser.GetAllCatalogues()
 	result := make([]models.CatalogueInfo, 0, len(catalogues))
 
@@ -58,7 +56,8 @@ func (s *CatalogueService) ListCatalogues() ([]models.CatalogueInfo, error) {
 			ID:       cat.ID,
 			Name:     cat.Name,
 			Revision: cat.Revision,
-			Library:  cat.Library == "true",
+			Library:  cat.Library,
+			Homebrew: s.parser.IsHomebrew(cat.ID),
 		})
 	}
 
@@ -66,17 +65,29 @@ func (s *CatalogueService) ListCatalogues() ([]models.CatalogueInfo, error) {
 }
 
 // GetCatalogueUnits retrieves all units in a catalogue
-func (s *CatalogueService) GetCatalogueUnits(id string) ([]models.UnitSummary, error) {
+func (s *CatalogueService) GetCatalogueUnits(ctx context.Context, id string) ([]models.UnitSummary, error) {
+	units, _, err := s.GetCatalogueUnitsWithWarnings(ctx, id)
+	return units, err
+}
+
+// GetCatalogueUnitsWithWarnings is GetCatalogueUnits, also returning a warning for each
+// entryLink that was skipped because it didn't resolve
+func (s *CatalogueService) GetCatalogueUnitsWithWarnings(ctx context.Context, id string) ([]models.UnitSummary, []models.ResolutionWarning, error) {
 	catalogue, exists := s.parser.GetCatalogue(id)
 	if !exists {
-		return nil, fmt.Errorf("catalogue not found: %s", id)
+		return nil, nil, fmt.Errorf("catalogue not found: %s", id)
 	}
 
+	var warnings []models.ResolutionWarning
 	units := make([]models.UnitSummary, 0, len(catalogue.EntryLinks))
 	for _, entryLink := range catalogue.EntryLinks {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
 		if entryLink.Type == "selectionEntry" {
 			entry, err := s.resolver.ResolveEntryLink(&entryLink, id)
 			if err != nil {
+				warnings = append(warnings, s.resolver.UnresolvedLinkWarning(&entryLink, id, "", err))
 				continue
 			}
 
@@ -86,12 +97,11 @@ func (s *CatalogueService) GetCatalogueUnits(id string) ([]models.UnitSummary, e
 				TargetID: entryLink.TargetID,
 				Type:     entry.Type,
 				Costs:    s.transformer.TransformCosts(entry.Costs),
+				Overlays: entry.Overlays,
 			}
 			units = append(units, summary)
 		}
 	}
 
-	return units, nil
+	return units, warnings, nil
 }
-
-
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"testing"
 
 	"grimoire-api/internal/cache"
@@ -9,7 +10,7 @@ import (
 
 func TestGetCatalogue(t *testing.T) {
 	dataDir := getTestDataDir(t)
-	
+
 	p := parser.NewParser(dataDir)
 	if err := p.LoadGameSystem(); err != nil {
 		t.Fatalf("Failed to load game system: %v", err)
@@ -36,7 +37,7 @@ func TestGetCatalogue(t *testing.T) {
 		break
 	}
 
-	catalogue, err := service.GetCatalogue(testCatalogueID)
+	catalogue, err := service.GetCatalogue(context.Background(), testCatalogueID)
 	if err != nil {
 		t.Fatalf("Failed to get catalogue: %v", err)
 	}
@@ -54,7 +55,7 @@ func TestGetCatalogue(t *testing.T) {
 	}
 
 	// Test non-existent catalogue
-	_, err = service.GetCatalogue("nonexistent-id")
+	_, err = service.GetCatalogue(context.Background(), "nonexistent-id")
 	if err == nil {
 		t.Error("Expected error for non-existent catalogue")
 	}
@@ -62,7 +63,7 @@ func TestGetCatalogue(t *testing.T) {
 
 func TestListCatalogues(t *testing.T) {
 	dataDir := getTestDataDir(t)
-	
+
 	p := parser.NewParser(dataDir)
 	if err := p.LoadGameSystem(); err != nil {
 		t.Fatalf("Failed to load game system: %v", err)
@@ -77,7 +78,7 @@ func TestListCatalogues(t *testing.T) {
 
 	service := NewCatalogueService(p, resolver, transformer, cache)
 
-	catalogues, err := service.ListCatalogues()
+	catalogues, err := service.ListCatalogues(context.Background())
 	if err != nil {
 		t.Fatalf("Failed to list catalogues: %v", err)
 	}
@@ -102,7 +103,7 @@ func TestListCatalogues(t *testing.T) {
 
 func TestGetCatalogueUnits(t *testing.T) {
 	dataDir := getTestDataDir(t)
-	
+
 	p := parser.NewParser(dataDir)
 	if err := p.LoadGameSystem(); err != nil {
 		t.Fatalf("Failed to load game system: %v", err)
@@ -131,7 +132,7 @@ func TestGetCatalogueUnits(t *testing.T) {
 		t.Skip("No catalogue with units found")
 	}
 
-	units, err := service.GetCatalogueUnits(testCatalogueID)
+	units, err := service.GetCatalogueUnits(context.Background(), testCatalogueID)
 	if err != nil {
 		t.Fatalf("Failed to get catalogue units: %v", err)
 	}
@@ -150,5 +151,3 @@ func TestGetCatalogueUnits(t *testing.T) {
 		}
 	}
 }
-
-
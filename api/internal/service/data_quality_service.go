@@ -0,0 +1,54 @@
+package service
+
+import (
+	"strings"
+	"sync"
+
+	"grimoire-api/internal/lint"
+	"grimoire-api/internal/models"
+	"grimoire-api/internal/parser"
+)
+
+// DataQualityService reports data-quality issues in the loaded dataset
+type DataQualityService struct {
+	parser *parser.Parser
+
+	once   sync.Once
+	report *models.DataQualityReport
+}
+
+// NewDataQualityService creates a new data-quality service
+func NewDataQualityService(p *parser.Parser) *DataQualityService {
+	return &DataQualityService{parser: p}
+}
+
+// DataQualityFilter narrows the issues in a report; empty fields match everything
+type DataQualityFilter struct {
+	Rule      string
+	Severity  string
+	Catalogue string // Catalogue ID or name
+}
+
+// GetReport returns the issues matching filter. The data doesn't change while the server
+// runs, so the dataset is checked once; the summary always covers the whole dataset.
+func (s *DataQualityService) GetReport(filter DataQualityFilter) *models.DataQualityReport {
+	s.once.Do(func() {
+		s.report = lint.Run(s.parser)
+	})
+
+	issues := make([]models.DataQualityIssue, 0)
+	for _, issue := range s.report.Issues {
+		if filter.Rule != "" && issue.Rule != filter.Rule {
+			continue
+		}
+		if filter.Severity != "" && issue.Severity != filter.Severity {
+			continue
+		}
+		if filter.Catalogue != "" && issue.CatalogueID != filter.Catalogue && !strings.EqualFold(issue.CatalogueName, filter.Catalogue) {
+			continue
+		}
+		issues = append(issues, issue)
+	}
+
+	return &models.DataQualityReport{Summary: s.report.Summary, Issues: issues}
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"grimoire-api/internal/cache"
+	"grimoire-api/internal/diff"
+	"grimoire-api/internal/gitdata"
+	"grimoire-api/internal/models"
+	"grimoire-api/internal/parser"
+)
+
+// CurrentRevision names the dataset the server has loaded
+const CurrentRevision = "current"
+
+// DefaultDiffLoads is how many diffs may load revisions from git at once
+const DefaultDiffLoads = 2
+
+// DiffCache is shared by the diff services of every snapshot. It keeps the diffs between two
+// commits, which never change, in the bounded response cache, and limits how many diffs load
+// revisions at once, since each load extracts and parses a whole dataset.
+type DiffCache struct {
+	cache *cache.Cache
+	loads chan struct{}
+}
+
+// NewDiffCache creates a diff cache storing diffs in c and running up to loads diffs at once
+// (DefaultDiffLoads if loads is 0)
+func NewDiffCache(c *cache.Cache, loads int) *DiffCache {
+	if loads <= 0 {
+		loads = DefaultDiffLoads
+	}
+	// Diffs between commits don't depend on the snapshot, so they are kept under no revision
+	return &DiffCache{cache: c.ForRevision(""), loads: make(chan struct{}, loads)}
+}
+
+// DiffService compares the loaded dataset with revisions of the data git repository
+type DiffService struct {
+	parser  *parser.Parser
+	dataDir string
+	diffs   *DiffCache
+}
+
+// NewDiffService creates a new diff service for the data repository at dataDir
+func NewDiffService(p *parser.Parser, dataDir string, diffs *DiffCache) *DiffService {
+	return &DiffService{
+		parser:  p,
+		dataDir: dataDir,
+		diffs:   diffs,
+	}
+}
+
+// Diff compares two git revisions of the data repository. Either side may be
+// CurrentRevision to use the dataset the server has loaded.
+func (s *DiffService) Diff(ctx context.Context, from, to string) (*models.DataDiff, error) {
+	if from == "" || to == "" {
+		return nil, fmt.Errorf("both from and to revisions are required")
+	}
+
+	fromSource, err := s.resolve(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+	toSource, err := s.resolve(ctx, to)
+	if err != nil {
+		return nil, err
+	}
+
+	if fromSource.Commit == "" || toSource.Commit == "" {
+		return s.compare(ctx, fromSource, toSource)
+	}
+
+	value, err := s.diffs.cache.Load(ctx, cache.KindDiff, fromSource.Commit+".."+toSource.Commit, "", func(ctx context.Context) (interface{}, int, error) {
+		result, err := s.compare(ctx, fromSource, toSource)
+		if err != nil {
+			return nil, 0, err
+		}
+		return result, 1 + len(result.Added) + len(result.Removed) + len(result.Renamed) + len(result.Changed), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The cached diff is shared; label its sides with the revisions as this caller gave them
+	result := *value.(*models.DataDiff)
+	result.From.Label = from
+	result.To.Label = to
+	return &result, nil
+}
+
+// compare loads both sides of a diff, waiting for a free load, and compares them
+func (s *DiffService) compare(ctx context.Context, fromSource, toSource models.DiffSource) (*models.DataDiff, error) {
+	select {
+	case s.diffs.loads <- struct{}{}:
+		defer func() { <-s.diffs.loads }()
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	fromParser, err := s.load(ctx, fromSource)
+	if err != nil {
+		return nil, err
+	}
+	toParser, err := s.load(ctx, toSource)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	result, err := diff.Compare(ctx, fromParser, toParser)
+	if err != nil {
+		return nil, err
+	}
+	fromSource.GameSystemRevision = result.From.GameSystemRevision
+	toSource.GameSystemRevision = result.To.GameSystemRevision
+	result.From = fromSource
+	result.To = toSource
+	return result, nil
+}
+
+// resolve identifies a revision, resolving git revisions to their commit hash
+func (s *DiffService) resolve(ctx context.Context, rev string) (models.DiffSource, error) {
+	source := models.DiffSource{Label: rev}
+	if rev == CurrentRevision {
+		return source, nil
+	}
+
+	commit, err := gitdata.ResolveCommit(ctx, s.dataDir, rev)
+	if err != nil {
+		return source, err
+	}
+	source.Commit = commit
+	return source, nil
+}
+
+// load returns the parser for a source, reusing the loaded dataset for CurrentRevision
+func (s *DiffService) load(ctx context.Context, source models.DiffSource) (*parser.Parser, error) {
+	if source.Commit == "" {
+		return s.parser, nil
+	}
+	return diff.LoadCommit(ctx, s.dataDir, source.Commit)
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"grimoire-api/internal/cache"
+	"grimoire-api/internal/datatest"
+	"grimoire-api/internal/parser"
+)
+
+func TestDiffCacheIsShared(t *testing.T) {
+	repo := datatest.NewRepo(t)
+	repo.Edit("Chaos - Fixture Daemons.cat", `value="420"`, `value="400"`)
+	repo.Commit("balance update")
+
+	p, err := parser.LoadDataDir(repo.Dir)
+	if err != nil {
+		t.Fatalf("Failed to load data: %v", err)
+	}
+	diffs := NewDiffCache(cache.NewCache(), 1)
+	first := NewDiffService(p, repo.Dir, diffs)
+	second := NewDiffService(p, repo.Dir, diffs) // The diff service of another snapshot
+
+	result, err := first.Diff(context.Background(), "HEAD~1", "HEAD")
+	if err != nil {
+		t.Fatalf("Failed to diff: %v", err)
+	}
+	if result.Summary.Changed != 1 || result.From.Label != "HEAD~1" {
+		t.Errorf("Unexpected diff: %+v", result)
+	}
+
+	cached, err := second.Diff(context.Background(), result.From.Commit, "HEAD")
+	if err != nil {
+		t.Fatalf("Failed to diff: %v", err)
+	}
+	if cached.Summary != result.Summary || cached.From.Label != result.From.Commit || result.From.Label != "HEAD~1" {
+		t.Errorf("Cached diff should keep its summary and take the caller's labels: %+v", cached)
+	}
+	stats := diffs.cache.Stats()
+	if len(stats.Kinds) != 1 || stats.Kinds[0].Kind != string(cache.KindDiff) || stats.Kinds[0].Entries != 1 || stats.Kinds[0].Hits != 1 {
+		t.Errorf("Expected one cached diff hit once, got %+v", stats.Kinds)
+	}
+
+	// While every load is taken, a diff waits for one until its context ends
+	diffs.loads <- struct{}{}
+	defer func() { <-diffs.loads }()
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := first.Diff(ctx, "HEAD~1", CurrentRevision); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the diff to time out waiting, got %v", err)
+	}
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"grimoire-api/internal/models"
+	"grimoire-api/internal/parser"
+)
+
+// FactionService handles faction-related business logic
+type FactionService struct {
+	parser   *parser.Parser
+	resolver *parser.LinkResolver
+}
+
+// NewFactionService creates a new faction service
+func NewFactionService(p *parser.Parser, r *parser.LinkResolver) *FactionService {
+	return &FactionService{
+		parser:   p,
+		resolver: r,
+	}
+}
+
+// ListFactions lists all factions sorted by name
+func (s *FactionService) ListFactions(ctx context.Context) ([]models.FactionResponse, error) {
+	factions := s.resolver.ResolveFactions()
+	result := make([]models.FactionResponse, 0, len(factions))
+	for _, faction := range factions {
+		result = append(result, toFactionResponse(faction))
+	}
+	return result, nil
+}
+
+// GetFaction retrieves a faction by ID, name, keyword or catalogue
+func (s *FactionService) GetFaction(ctx context.Context, query string) (*models.FactionResponse, error) {
+	for _, faction := range s.resolver.FindFactions(query) {
+		if faction.Matches(query) {
+			response := toFactionResponse(faction)
+			return &response, nil
+		}
+	}
+	return nil, fmt.Errorf("faction not found: %s", query)
+}
+
+// FindFactions returns factions matching query, including every faction of a super-faction
+func (s *FactionService) FindFactions(ctx context.Context, query string) ([]models.FactionResponse, error) {
+	factions := s.resolver.FindFactions(query)
+	result := make([]models.FactionResponse, 0, len(factions))
+	for _, faction := range factions {
+		result = append(result, toFactionResponse(faction))
+	}
+	return result, nil
+}
+
+// toFactionResponse transforms a parser faction to its API representation
+func toFactionResponse(faction *parser.Faction) models.FactionResponse {
+	response := models.FactionResponse{
+		ID:           faction.ID,
+		Name:         faction.Name,
+		SuperFaction: faction.SuperFaction,
+		ParentID:     faction.ParentID,
+		Catalogue:    toCatalogueInfo(faction.Catalogue),
+		SubFactions:  make([]models.CatalogueInfo, 0, len(faction.SubFactions)),
+		Libraries:    make([]models.CatalogueInfo, 0, len(faction.Libraries)),
+		UnitCount:    faction.UnitCount,
+	}
+
+	if faction.Keyword != nil {
+		response.Keyword = &models.CategoryInfo{
+			ID:   faction.Keyword.TargetID,
+			Name: faction.Keyword.Name,
+		}
+	}
+
+	for _, cat := range faction.SubFactions {
+		response.SubFactions = append(response.SubFactions, toCatalogueInfo(cat))
+	}
+	for _, lib := range faction.Libraries {
+		response.Libraries = append(response.Libraries, toCatalogueInfo(lib))
+	}
+
+	return response
+}
+
+// toCatalogueInfo summarizes a catalogue
+func toCatalogueInfo(catalogue *models.Catalogue) models.CatalogueInfo {
+	return models.CatalogueInfo{
+		ID:       catalogue.ID,
+		Name:     catalogue.Name,
+		Revision: catalogue.Revision,
+		Library:  catalogue.Library,
+	}
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"grimoire-api/internal/models"
+	"grimoire-api/internal/parser"
+)
+
+// GameSystemService handles game system lookups
+type GameSystemService struct {
+	parser *parser.Parser
+}
+
+// NewGameSystemService creates a new game system service
+func NewGameSystemService(p *parser.Parser) *GameSystemService {
+	return &GameSystemService{parser: p}
+}
+
+// GetGameSystem returns the game system's profile types, visible categories and cost types
+func (s *GameSystemService) GetGameSystem(ctx context.Context) (*models.GameSystemResponse, error) {
+	gameSystem := s.parser.GetGameSystem()
+	if gameSystem == nil {
+		return nil, fmt.Errorf("game system not loaded")
+	}
+
+	response := &models.GameSystemResponse{
+		ID:                  gameSystem.ID,
+		Name:                gameSystem.Name,
+		Revision:            gameSystem.Revision,
+		BattleScribeVersion: gameSystem.BattleScribeVersion,
+		ProfileTypes:        make([]models.ProfileTypeInfo, 0),
+		Categories:          make([]models.CategoryInfo, 0),
+		CostTypes:           make([]models.CostTypeInfo, 0),
+	}
+
+	// Transform profile types
+	for _, pt := range gameSystem.ProfileTypes {
+		characteristics := make([]string, 0, len(pt.CharacteristicTypes))
+		for _, ct := range pt.CharacteristicTypes {
+			characteristics = append(characteristics, ct.Name)
+		}
+		response.ProfileTypes = append(response.ProfileTypes, models.ProfileTypeInfo{
+			ID:              pt.ID,
+			Name:            pt.Name,
+			Characteristics: characteristics,
+		})
+	}
+
+	// Transform categories
+	for _, cat := range gameSystem.CategoryEntries {
+		if !cat.Hidden {
+			response.Categories = append(response.Categories, models.CategoryInfo{
+				ID:   cat.ID,
+				Name: cat.Name,
+			})
+		}
+	}
+
+	// Transform cost types
+	for _, ct := range gameSystem.CostTypes {
+		response.CostTypes = append(response.CostTypes, models.CostTypeInfo{
+			ID:               ct.ID,
+			Name:             ct.Name,
+			DefaultCostLimit: ct.DefaultCostLimit,
+			Hidden:           ct.Hidden,
+		})
+	}
+
+	return response, nil
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"grimoire-api/internal/history"
+	"grimoire-api/internal/models"
+)
+
+// ErrHistoryNotReady is returned while the points history index has not been built
+var ErrHistoryNotReady = errors.New("points history index is not built yet")
+
+// HistoryService serves unit points history from the on-disk history index
+type HistoryService struct {
+	dataDir   string
+	indexPath string
+
+	mu       sync.RWMutex
+	index    *history.Index
+	updateMu sync.Mutex // Serializes index updates
+}
+
+// NewHistoryService creates a history service for the data repository at dataDir,
+// keeping its index at indexPath
+func NewHistoryService(dataDir, indexPath string) *HistoryService {
+	return &HistoryService{
+		dataDir:   dataDir,
+		indexPath: indexPath,
+	}
+}
+
+// Load reads the index from disk
+func (s *HistoryService) Load() error {
+	idx, err := history.Load(s.indexPath)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	if idx.Head != "" {
+		s.index = idx
+	}
+	s.mu.Unlock()
+	return nil
+}
+
+// Refresh indexes data commits made since the index was last updated, saving the index after
+// every batch of commits. It returns how many commits were indexed.
+func (s *HistoryService) Refresh() (int, error) {
+	s.updateMu.Lock()
+	defer s.updateMu.Unlock()
+
+	idx, err := history.Load(s.indexPath)
+	if err != nil {
+		return 0, err
+	}
+
+	indexed, err := history.Update(s.dataDir, idx, s.indexPath)
+	if err != nil {
+		return indexed, err
+	}
+
+	s.mu.Lock()
+	s.index = idx
+	s.mu.Unlock()
+	return indexed, nil
+}
+
+// GetUnitHistory returns a unit's points history by entryLink ID or selectionEntry ID
+func (s *HistoryService) GetUnitHistory(id string) (*models.UnitHistory, error) {
+	s.mu.RLock()
+	idx := s.index
+	s.mu.RUnlock()
+
+	if idx == nil {
+		return nil, ErrHistoryNotReady
+	}
+
+	unit, found := idx.Lookup(id)
+	if !found {
+		return nil, fmt.Errorf("no points history for unit: %s", id)
+	}
+	return unit, nil
+}
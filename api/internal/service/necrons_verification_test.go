@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -13,7 +14,7 @@ import (
 // TestAllNecronUnits verifies that all Necron units match their XML data
 func TestAllNecronUnits(t *testing.T) {
 	dataDir := getTestDataDir(t)
-	
+
 	p := parser.NewParser(dataDir)
 	if err := p.LoadGameSystem(); err != nil {
 		t.Fatalf("Failed to load game system: %v", err)
@@ -49,8 +50,8 @@ func TestAllNecronUnits(t *testing.T) {
 	// Filter out UI/metadata entries
 	skipNames := map[string]bool{
 		"Show/Hide Options": true,
-		"Order of Battle":  true,
-		"Detachment":       true,
+		"Order of Battle":   true,
+		"Detachment":        true,
 	}
 
 	// Get all unit entryLinks
@@ -74,7 +75,7 @@ func TestAllNecronUnits(t *testing.T) {
 		unitName := entryLink.Name
 
 		// Get unit via API
-		unit, err := service.GetUnit(unitID)
+		unit, err := service.GetUnit(context.Background(), unitID)
 		if err != nil {
 			errorCount++
 			errors = append(errors, fmt.Sprintf("%s (ID: %s): Failed to get unit: %v", unitName, unitID, err))
@@ -178,10 +179,10 @@ func TestAllNecronUnits(t *testing.T) {
 			xmlRangedCount := 0
 			xmlMeleeCount := 0
 			countWeaponsInEntry(mergedEntry, &xmlRangedCount, &xmlMeleeCount, resolver, necronsCatalogueID)
-			
+
 			apiRangedCount := len(unit.Weapons.Ranged)
 			apiMeleeCount := len(unit.Weapons.Melee)
-			
+
 			// Only report if there's a significant mismatch (allowing for some flexibility)
 			if xmlRangedCount > 0 && apiRangedCount == 0 {
 				hasErrors = true
@@ -241,4 +242,3 @@ Errors: %d
 		t.Logf("First 20 errors:\n%s", formatErrors(errors[:min(20, len(errors))]))
 	}
 }
-
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -14,7 +15,7 @@ import (
 // TestAllOrkUnits verifies that all Ork units match their XML data
 func TestAllOrkUnits(t *testing.T) {
 	dataDir := getTestDataDir(t)
-	
+
 	p := parser.NewParser(dataDir)
 	if err := p.LoadGameSystem(); err != nil {
 		t.Fatalf("Failed to load game system: %v", err)
@@ -50,8 +51,8 @@ func TestAllOrkUnits(t *testing.T) {
 	// Filter out UI/metadata entries
 	skipNames := map[string]bool{
 		"Show/Hide Options": true,
-		"Order of Battle":  true,
-		"Detachment":       true,
+		"Order of Battle":   true,
+		"Detachment":        true,
 	}
 
 	// Get all unit entryLinks
@@ -75,7 +76,7 @@ func TestAllOrkUnits(t *testing.T) {
 		unitName := entryLink.Name
 
 		// Get unit via API
-		unit, err := service.GetUnit(unitID)
+		unit, err := service.GetUnit(context.Background(), unitID)
 		if err != nil {
 			errorCount++
 			errors = append(errors, fmt.Sprintf("%s (ID: %s): Failed to get unit: %v", unitName, unitID, err))
@@ -179,10 +180,10 @@ func TestAllOrkUnits(t *testing.T) {
 			xmlRangedCount := 0
 			xmlMeleeCount := 0
 			countWeaponsInEntry(mergedEntry, &xmlRangedCount, &xmlMeleeCount, resolver, orksCatalogueID)
-			
+
 			apiRangedCount := len(unit.Weapons.Ranged)
 			apiMeleeCount := len(unit.Weapons.Melee)
-			
+
 			// Only report if there's a significant mismatch (allowing for some flexibility)
 			if xmlRangedCount > 0 && apiRangedCount == 0 {
 				hasErrors = true
@@ -396,4 +397,3 @@ func countWeaponsInGroup(group *models.SelectionEntryGroup, rangedCount *int, me
 		countWeaponsInGroup(&group.SelectionEntryGroups[i], rangedCount, meleeCount, resolver, catalogueID)
 	}
 }
-
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+
+	"grimoire-api/internal/models"
+)
+
+// Repositories are what the API reads its data from. The services in this package implement
+// them over the parsed XML; a database written by `grimoire import` implements them too.
+type Repositories struct {
+	Units      UnitRepository
+	Catalogues CatalogueRepository
+	Factions   FactionRepository
+	Rules      RuleRepository
+	GameSystem GameSystemRepository
+}
+
+// UnitRepository reads transformed units
+type UnitRepository interface {
+	GetUnit(ctx context.Context, id string) (*models.UnitResponse, error)
+	GetUnitWithWarnings(ctx context.Context, id string) (*models.UnitResponse, error)
+	GetUnitWeapons(ctx context.Context, id string) (*models.WeaponSet, error)
+	ListUnitsWithWarnings(ctx context.Context, query UnitQuery) ([]models.UnitSummary, int, []models.ResolutionWarning, error)
+	SearchUnitsWithWarnings(ctx context.Context, query string, limit int) ([]models.SearchResult, []models.ResolutionWarning, error)
+}
+
+// CatalogueRepository reads catalogues and the units at their root
+type CatalogueRepository interface {
+	ListCatalogues(ctx context.Context) ([]models.CatalogueInfo, error)
+	GetCatalogue(ctx context.Context, id string) (*models.CatalogueResponse, error)
+	GetCatalogueUnitsWithWarnings(ctx context.Context, id string) ([]models.UnitSummary, []models.ResolutionWarning, error)
+}
+
+// FactionRepository reads the factions derived from the catalogues
+type FactionRepository interface {
+	ListFactions(ctx context.Context) ([]models.FactionResponse, error)
+	GetFaction(ctx context.Context, query string) (*models.FactionResponse, error)
+	FindFactions(ctx context.Context, query string) ([]models.FactionResponse, error)
+}
+
+// RuleRepository reads the shared rules of the game system, catalogues and libraries
+type RuleRepository interface {
+	ListRules(ctx context.Context) ([]models.RuleResponse, error)
+	GetRule(ctx context.Context, id string) (*models.RuleResponse, error)
+}
+
+// GameSystemRepository reads the game system
+type GameSystemRepository interface {
+	GetGameSystem(ctx context.Context) (*models.GameSystemResponse, error)
+}
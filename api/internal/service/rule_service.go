@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"grimoire-api/internal/models"
+	"grimoire-api/internal/parser"
+)
+
+// RuleService handles shared rule lookups
+type RuleService struct {
+	parser *parser.Parser
+}
+
+// NewRuleService creates a new rule service
+func NewRuleService(p *parser.Parser) *RuleService {
+	return &RuleService{parser: p}
+}
+
+// ListRules lists the shared rules of the game system, catalogues and libraries, sorted by name.
+// A rule defined in more than one file is listed once, from the game system if it is there.
+func (s *RuleService) ListRules(ctx context.Context) ([]models.RuleResponse, error) {
+	var rules []models.RuleResponse
+	seen := make(map[string]bool)
+	add := func(sharedRules []models.Rule, catalogue *models.CatalogueInfo) {
+		for _, rule := range sharedRules {
+			if rule.Hidden || seen[rule.ID] {
+				continue
+			}
+			seen[rule.ID] = true
+			rules = append(rules, models.RuleResponse{
+				ID:          rule.ID,
+				Name:        rule.Name,
+				Description: rule.Description,
+				Catalogue:   catalogue,
+			})
+		}
+	}
+
+	if gameSystem := s.parser.GetGameSystem(); gameSystem != nil {
+		add(gameSystem.SharedRules, nil)
+	}
+	for _, catalogues := range []map[string]*models.Catalogue{s.parser.GetAllLibraries(), s.parser.GetAllCatalogues()} {
+		for _, catalogue := range sortedCatalogues(catalogues) {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			info := toCatalogueInfo(catalogue)
+			add(catalogue.SharedRules, &info)
+		}
+	}
+
+	sort.SliceStable(rules, func(i, j int) bool {
+		if rules[i].Name != rules[j].Name {
+			return rules[i].Name < rules[j].Name
+		}
+		return rules[i].ID < rules[j].ID
+	})
+	return rules, nil
+}
+
+// GetRule retrieves a shared rule by ID
+func (s *RuleService) GetRule(ctx context.Context, id string) (*models.RuleResponse, error) {
+	rules, err := s.ListRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range rules {
+		if rules[i].ID == id {
+			return &rules[i], nil
+		}
+	}
+	return nil, fmt.Errorf("rule not found: %s", id)
+}
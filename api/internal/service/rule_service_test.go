@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"grimoire-api/internal/parser"
+)
+
+func TestRuleService(t *testing.T) {
+	p := parser.NewParser(getFixtureDataDir(t))
+	if err := p.LoadGameSystem(); err != nil {
+		t.Fatalf("Failed to load game system: %v", err)
+	}
+	if err := p.LoadAllCatalogues(); err != nil {
+		t.Fatalf("Failed to load catalogues: %v", err)
+	}
+	service := NewRuleService(p)
+
+	rules, err := service.ListRules(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to list rules: %v", err)
+	}
+	if len(rules) != 1 || rules[0].ID != "rule-fixture-deep-strike" {
+		t.Fatalf("Expected the game system's Deep Strike rule, got %+v", rules)
+	}
+	// Rules of the game system don't name a catalogue
+	if rules[0].Catalogue != nil {
+		t.Errorf("Expected no catalogue, got %+v", rules[0].Catalogue)
+	}
+
+	rule, err := service.GetRule(context.Background(), "rule-fixture-deep-strike")
+	if err != nil {
+		t.Fatalf("Failed to get rule: %v", err)
+	}
+	if rule.Name != "Deep Strike" || rule.Description == "" {
+		t.Errorf("Unexpected rule %+v", rule)
+	}
+
+	if _, err := service.GetRule(context.Background(), "missing"); err == nil {
+		t.Error("Expected an error for a missing rule")
+	}
+}
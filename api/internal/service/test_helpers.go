@@ -19,3 +19,11 @@ func getTestDataDir(t *testing.T) string {
 	return dataDir
 }
 
+// getFixtureDataDir returns the path of the small checked-in fixture dataset
+func getFixtureDataDir(t *testing.T) string {
+	dataDir := "../../testdata/wh40k-fixture"
+	if _, err := os.Stat(dataDir); os.IsNotExist(err) {
+		t.Fatalf("Fixture directory not found: %s", dataDir)
+	}
+	return dataDir
+}
@@ -0,0 +1,274 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"grimoire-api/internal/models"
+)
+
+// Sort fields accepted by ListUnits
+const (
+	SortByName      = "name"
+	SortByPoints    = "points"
+	SortByToughness = "toughness"
+	SortByWounds    = "wounds"
+	SortByOC        = "oc"
+	SortByCatalogue = "catalogue"
+)
+
+// LegendsFilter controls whether Legends units are listed
+type LegendsFilter string
+
+const (
+	LegendsInclude LegendsFilter = "include"
+	LegendsExclude LegendsFilter = "exclude"
+	LegendsOnly    LegendsFilter = "only"
+)
+
+// UnitQuery holds the filters, sort order and pagination for ListUnits.
+// Values within a multi-value filter are ORed; different filters are ANDed.
+type UnitQuery struct {
+	Factions   []string // Faction IDs, names, keywords, catalogues or super-factions
+	Categories []string // Category names (substring match)
+	Catalogues []string // Catalogue IDs or names (exact, case-insensitive)
+	Search     string   // Unit name substring
+	MinPoints  int      // Minimum base pts cost, 0 for no lower bound
+	MaxPoints  int      // Maximum base pts cost, 0 for no upper bound
+	Legends    LegendsFilter
+	Sort       string // One of the SortBy constants, defaults to name
+	Descending bool
+	Limit      int // 0 returns all matching units
+	Offset     int
+}
+
+// cacheKey identifies the units a query lists, before pagination. Filter values are
+// order-independent, so they are sorted; %q keeps values containing separators distinct.
+func (q UnitQuery) cacheKey() string {
+	sorted := func(values []string) []string {
+		values = append([]string(nil), values...)
+		sort.Strings(values)
+		return values
+	}
+	return fmt.Sprintf("factions=%q&categories=%q&catalogues=%q&search=%q&min=%d&max=%d&legends=%s&sort=%s&desc=%t",
+		sorted(q.Factions), sorted(q.Categories), sorted(q.Catalogues), q.Search,
+		q.MinPoints, q.MaxPoints, q.Legends, q.Sort, q.Descending)
+}
+
+// Normalized returns the query with the default sort field, or an error if the sort field is unknown
+func (q UnitQuery) Normalized() (UnitQuery, error) {
+	if q.Sort == "" {
+		q.Sort = SortByName
+	}
+	if !isValidSort(q.Sort) {
+		return q, fmt.Errorf("invalid sort field: %s", q.Sort)
+	}
+	return q, nil
+}
+
+// ParseLegendsFilter validates a legends query value, defaulting to include
+func ParseLegendsFilter(value string) (LegendsFilter, error) {
+	switch LegendsFilter(strings.ToLower(value)) {
+	case "", LegendsInclude:
+		return LegendsInclude, nil
+	case LegendsExclude:
+		return LegendsExclude, nil
+	case LegendsOnly:
+		return LegendsOnly, nil
+	}
+	return "", fmt.Errorf("invalid legends filter: %s (expected include, exclude or only)", value)
+}
+
+// matches reports whether a unit with the given name passes the filter
+func (f LegendsFilter) matches(name string) bool {
+	switch f {
+	case LegendsExclude:
+		return !isLegends(name)
+	case LegendsOnly:
+		return isLegends(name)
+	}
+	return true
+}
+
+// LegendsMarker is the BSData marker in the names of Legends units
+const LegendsMarker = "[Legends]"
+
+// isLegends reports whether a unit name carries the Legends marker
+func isLegends(name string) bool {
+	return strings.Contains(name, LegendsMarker)
+}
+
+// isValidSort reports whether field is a supported sort field
+func isValidSort(field string) bool {
+	switch field {
+	case SortByName, SortByPoints, SortByToughness, SortByWounds, SortByOC, SortByCatalogue:
+		return true
+	}
+	return false
+}
+
+// unitListing pairs a unit summary with the values it can be sorted by
+type unitListing struct {
+	summary models.UnitSummary
+	profile *models.UnitProfile
+	points  int
+}
+
+// sortUnitListings sorts listings by field, breaking ties by name, catalogue and ID
+// so that pagination is stable between requests
+func sortUnitListings(listings []unitListing, field string, descending bool) {
+	sort.SliceStable(listings, func(i, j int) bool {
+		a, b := &listings[i], &listings[j]
+
+		if cmp := compareListings(a, b, field); cmp != 0 {
+			if descending {
+				return cmp > 0
+			}
+			return cmp < 0
+		}
+
+		if cmp := strings.Compare(strings.ToLower(a.summary.Name), strings.ToLower(b.summary.Name)); cmp != 0 {
+			return cmp < 0
+		}
+		if cmp := strings.Compare(catalogueName(a), catalogueName(b)); cmp != 0 {
+			return cmp < 0
+		}
+		return a.summary.ID < b.summary.ID
+	})
+}
+
+// compareListings compares two listings on a single sort field
+func compareListings(a, b *unitListing, field string) int {
+	switch field {
+	case SortByPoints:
+		return compareInts(a.points, b.points)
+	case SortByToughness:
+		return compareInts(profileStat(a, func(p *models.UnitProfile) int { return p.Toughness }),
+			profileStat(b, func(p *models.UnitProfile) int { return p.Toughness }))
+	case SortByWounds:
+		return compareInts(profileStat(a, func(p *models.UnitProfile) int { return p.Wounds }),
+			profileStat(b, func(p *models.UnitProfile) int { return p.Wounds }))
+	case SortByOC:
+		return compareInts(profileStat(a, func(p *models.UnitProfile) int { return p.ObjectiveControl }),
+			profileStat(b, func(p *models.UnitProfile) int { return p.ObjectiveControl }))
+	case SortByCatalogue:
+		return strings.Compare(catalogueName(a), catalogueName(b))
+	}
+	return strings.Compare(strings.ToLower(a.summary.Name), strings.ToLower(b.summary.Name))
+}
+
+// profileStat reads a stat from a listing's unit profile, 0 if it has none
+func profileStat(listing *unitListing, stat func(*models.UnitProfile) int) int {
+	if listing.profile == nil {
+		return 0
+	}
+	return stat(listing.profile)
+}
+
+// catalogueName returns the name of the catalogue a listing came from
+func catalogueName(listing *unitListing) string {
+	if listing.summary.Catalogue == nil {
+		return ""
+	}
+	return listing.summary.Catalogue.Name
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// matchesAnyCategory reports whether any categoryLink name contains one of names.
+// An empty names list matches everything.
+func matchesAnyCategory(categoryLinks []models.CategoryLink, names []string) bool {
+	if len(names) == 0 {
+		return true
+	}
+	for _, catLink := range categoryLinks {
+		for _, name := range names {
+			if strings.Contains(catLink.Name, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// factionFilter matches units against the factions selected by a query
+type factionFilter struct {
+	active       bool
+	keywordIDs   map[string]bool // Faction keyword category IDs
+	catalogueIDs map[string]bool // Catalogues of factions that have no keyword
+}
+
+// resolveFactionFilter resolves faction query values to keyword and catalogue IDs
+func (s *UnitService) resolveFactionFilter(values []string) factionFilter {
+	filter := factionFilter{
+		active:       len(values) > 0,
+		keywordIDs:   make(map[string]bool),
+		catalogueIDs: make(map[string]bool),
+	}
+	for _, value := range values {
+		for _, faction := range s.resolver.FindFactions(value) {
+			if faction.Keyword != nil {
+				filter.keywordIDs[faction.Keyword.TargetID] = true
+				continue
+			}
+			for _, cat := range faction.Catalogues() {
+				filter.catalogueIDs[cat.ID] = true
+			}
+		}
+	}
+	return filter
+}
+
+// matches reports whether a unit from catalogueID with the given categories passes the filter
+func (f factionFilter) matches(categoryLinks []models.CategoryLink, catalogueID string) bool {
+	if !f.active {
+		return true
+	}
+	if f.catalogueIDs[catalogueID] {
+		return true
+	}
+	for _, catLink := range categoryLinks {
+		if f.keywordIDs[catLink.TargetID] {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesCatalogue reports whether a catalogue's ID or name is in filters.
+// An empty filters list matches everything.
+func matchesCatalogue(catalogue *models.Catalogue, filters []string) bool {
+	if len(filters) == 0 {
+		return true
+	}
+	for _, filter := range filters {
+		if catalogue.ID == filter || strings.EqualFold(catalogue.Name, filter) {
+			return true
+		}
+	}
+	return false
+}
+
+// sortedCatalogues returns catalogues ordered by name, then ID
+func sortedCatalogues(catalogues map[string]*models.Catalogue) []*models.Catalogue {
+	result := make([]*models.Catalogue, 0, len(catalogues))
+	for _, cat := range catalogues {
+		result = append(result, cat)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Name != result[j].Name {
+			return result[i].Name < result[j].Name
+		}
+		return result[i].ID < result[j].ID
+	})
+	return result
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"grimoire-api/internal/cache"
+	"grimoire-api/internal/parser"
+)
+
+func newFixtureUnitService(t *testing.T) *UnitService {
+	p := parser.NewParser(getFixtureDataDir(t))
+	if err := p.LoadGameSystem(); err != nil {
+		t.Fatalf("Failed to load game system: %v", err)
+	}
+	if err := p.LoadAllCatalogues(); err != nil {
+		t.Fatalf("Failed to load catalogues: %v", err)
+	}
+
+	resolver := parser.NewLinkResolver(p)
+	transformer := parser.NewTransformer(resolver)
+	return NewUnitService(p, resolver, transformer, cache.NewCache())
+}
+
+func unitNames(t *testing.T, service *UnitService, query UnitQuery) []string {
+	units, _, err := service.ListUnits(context.Background(), query)
+	if err != nil {
+		t.Fatalf("ListUnits failed: %v", err)
+	}
+	names := make([]string, 0, len(units))
+	for _, unit := range units {
+		names = append(names, unit.Name)
+	}
+	return names
+}
+
+func assertNames(t *testing.T, got []string, want ...string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Expected %v, got %v", want, got)
+		}
+	}
+}
+
+func TestListUnitsSorting(t *testing.T) {
+	service := newFixtureUnitService(t)
+
+	assertNames(t, unitNames(t, service, UnitQuery{}),
+		"Fixture Bloodletters", "Fixture Bloodthirster", "Fixture Captain", "Fixture Intercessors",
+		"Fixture Land Raider", "Fixture Sanguinary Guard", "Fixture Terminators [Legends]")
+
+	// Equal points fall back to name ordering
+	assertNames(t, unitNames(t, service, UnitQuery{Sort: SortByPoints}),
+		"Fixture Captain", "Fixture Intercessors", "Fixture Bloodletters", "Fixture Sanguinary Guard",
+		"Fixture Terminators [Legends]", "Fixture Land Raider", "Fixture Bloodthirster")
+
+	assertNames(t, unitNames(t, service, UnitQuery{Sort: SortByWounds, Descending: true, Limit: 3}),
+		"Fixture Bloodthirster", "Fixture Land Raider", "Fixture Captain")
+
+	assertNames(t, unitNames(t, service, UnitQuery{Sort: SortByCatalogue, Limit: 2}),
+		"Fixture Bloodletters", "Fixture Bloodthirster")
+
+	if _, _, err := service.ListUnits(context.Background(), UnitQuery{Sort: "colour"}); err == nil {
+		t.Error("Expected error for unknown sort field")
+	}
+}
+
+func TestListUnitsPaginationIsStable(t *testing.T) {
+	service := newFixtureUnitService(t)
+
+	all := unitNames(t, service, UnitQuery{Sort: SortByToughness})
+	var paged []string
+	for offset := 0; offset < len(all); offset += 2 {
+		paged = append(paged, unitNames(t, service, UnitQuery{Sort: SortByToughness, Limit: 2, Offset: offset})...)
+	}
+	assertNames(t, paged, all...)
+}
+
+func TestListUnitsMultiValueFilters(t *testing.T) {
+	service := newFixtureUnitService(t)
+
+	assertNames(t, unitNames(t, service, UnitQuery{Categories: []string{"Vehicle", "Monster"}}),
+		"Fixture Bloodthirster", "Fixture Land Raider")
+
+	assertNames(t, unitNames(t, service, UnitQuery{
+		Factions:   []string{"Legiones Daemonica", "Adeptus Astartes"},
+		Categories: []string{"Character"},
+	}), "Fixture Bloodthirster", "Fixture Captain")
+
+	assertNames(t, unitNames(t, service, UnitQuery{Catalogues: []string{"chaos - fixture daemons"}}),
+		"Fixture Bloodletters", "Fixture Bloodthirster")
+
+	assertNames(t, unitNames(t, service, UnitQuery{MinPoints: 100, MaxPoints: 240}),
+		"Fixture Bloodletters", "Fixture Land Raider", "Fixture Sanguinary Guard", "Fixture Terminators [Legends]")
+
+	assertNames(t, unitNames(t, service, UnitQuery{Legends: LegendsOnly}), "Fixture Terminators [Legends]")
+
+	excluded := unitNames(t, service, UnitQuery{Legends: LegendsExclude})
+	if len(excluded) != 6 {
+		t.Errorf("Expected 6 non-Legends units, got %v", excluded)
+	}
+}
+
+func TestParseLegendsFilter(t *testing.T) {
+	if f, err := ParseLegendsFilter(""); err != nil || f != LegendsInclude {
+		t.Errorf("Expected include by default, got %q (%v)", f, err)
+	}
+	if f, err := ParseLegendsFilter("EXCLUDE"); err != nil || f != LegendsExclude {
+		t.Errorf("Expected exclude, got %q (%v)", f, err)
+	}
+	if _, err := ParseLegendsFilter("sometimes"); err == nil {
+		t.Error("Expected error for invalid legends filter")
+	}
+}
+
+func TestListUnitsFactionFilter(t *testing.T) {
+	service := newFixtureUnitService(t)
+
+	// Super-faction names select every faction in the grand alliance
+	assertNames(t, unitNames(t, service, UnitQuery{Factions: []string{"Chaos"}}),
+		"Fixture Bloodletters", "Fixture Bloodthirster")
+
+	// Faction keywords span sub-faction catalogues
+	assertNames(t, unitNames(t, service, UnitQuery{Factions: []string{"fac-fixture-astartes"}, Legends: LegendsExclude}),
+		"Fixture Captain", "Fixture Intercessors", "Fixture Land Raider", "Fixture Sanguinary Guard")
+
+	// Partial names no longer match by substring
+	if names := unitNames(t, service, UnitQuery{Factions: []string{"Astartes"}}); len(names) != 0 {
+		t.Errorf("Expected no units for partial faction name, got %v", names)
+	}
+}
+
+func TestSearchUnitsLimitIsStable(t *testing.T) {
+	service := newFixtureUnitService(t)
+
+	// Matches from every catalogue are ordered by name before the limit is applied
+	for i := 0; i < 5; i++ {
+		results, err := service.searchUnits(context.Background(), "fixture", 3)
+		if err != nil {
+			t.Fatalf("searchUnits failed: %v", err)
+		}
+		names := make([]string, 0, len(results.results))
+		for _, result := range results.results {
+			names = append(names, result.Name)
+		}
+		assertNames(t, names, "Fixture Bloodletters", "Fixture Bloodthirster", "Fixture Captain")
+	}
+}
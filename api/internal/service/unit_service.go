@@ -1,8 +1,11 @@
 package service
 
 import (
+	"context"
 	"fmt"
+	"sort"
 	"strings"
+	"sync/atomic"
 
 	"grimoire-api/internal/cache"
 	"grimoire-api/internal/models"
@@ -11,28 +14,56 @@ import (
 
 // UnitService handles unit-related business logic
 type UnitService struct {
-	parser     *parser.Parser
-	resolver   *parser.LinkResolver
+	parser      *parser.Parser
+	resolver    *parser.LinkResolver
 	transformer *parser.Transformer
-	cache      *cache.Cache
+	cache       *cache.Cache
+	warm        atomic.Pointer[warmUnits] // Set once WarmUp finishes
 }
 
 // NewUnitService creates a new unit service
 func NewUnitService(p *parser.Parser, r *parser.LinkResolver, t *parser.Transformer, c *cache.Cache) *UnitService {
 	return &UnitService{
-		parser:     p,
-		resolver:   r,
+		parser:      p,
+		resolver:    r,
 		transformer: t,
-		cache:      c,
+		cache:       c,
 	}
 }
 
 // GetUnit retrieves a unit by ID
 // The ID can be either an entryLink ID (from a catalogue) or a selectionEntry ID (from a library)
-func (s *UnitService) GetUnit(id string) (*models.UnitResponse, error) {
-	// Check cache first
-	if unit, exists := s.cache.GetUnit(id); exists {
-		return unit, nil
+// Concurrent requests for a unit that isn't cached yet transform it only once.
+func (s *UnitService) GetUnit(ctx context.Context, id string) (*models.UnitResponse, error) {
+	if s.warm.Load() != nil {
+		if _, catID, found := s.parser.FindEntryLinkByID(id); found {
+			if unit, ok := s.warmUnit(catID, id); ok {
+				return unit, nil
+			}
+		}
+	}
+	return s.cache.Unit(ctx, id, func(ctx context.Context) (*models.UnitResponse, error) {
+		return s.transformUnit(ctx, id, false)
+	})
+}
+
+// GetUnitWithWarnings is GetUnit, with the links and values that had to be skipped in the unit's
+// warnings. Units with warnings are cached apart from the others.
+func (s *UnitService) GetUnitWithWarnings(ctx context.Context, id string) (*models.UnitResponse, error) {
+	value, err := s.cache.Load(ctx, cache.KindUnit, id, "warnings", func(ctx context.Context) (interface{}, int, error) {
+		unit, err := s.transformUnit(ctx, id, true)
+		return unit, 1, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.(*models.UnitResponse), nil
+}
+
+// transformUnit finds and transforms a unit, with its warnings if asked for, without the cache
+func (s *UnitService) transformUnit(ctx context.Context, id string, warnings bool) (*models.UnitResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
 
 	var entry *models.SelectionEntry
@@ -64,7 +95,12 @@ func (s *UnitService) GetUnit(id string) (*models.UnitResponse, error) {
 	}
 
 	// Transform to response
-	unit := s.transformer.TransformUnit(entry, catalogueID)
+	var unit *models.UnitResponse
+	if warnings {
+		unit = s.transformer.TransformUnitWithWarnings(entry, catalogueID)
+	} else {
+		unit = s.transformer.TransformUnit(entry, catalogueID)
+	}
 
 	// If we found via entryLink, preserve the original entryLink ID
 	// (the transformer uses the selectionEntry ID by default)
@@ -72,137 +108,269 @@ func (s *UnitService) GetUnit(id string) (*models.UnitResponse, error) {
 		unit.ID = id
 	}
 
-	// Cache the result
-	s.cache.SetUnit(id, unit)
-
 	return unit, nil
 }
 
-// ListUnits lists all units with optional filters
-func (s *UnitService) ListUnits(faction, category, search string, limit, offset int) ([]models.UnitSummary, int, error) {
-	var allUnits []models.UnitSummary
+// ExplainUnit reports where each profile, weapon, cost, category and modifier of a unit came from
+// The ID is looked up the same way as in GetUnit. Explanations are not cached.
+func (s *UnitService) ExplainUnit(ctx context.Context, id string) (*models.UnitExplanation, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if entryLink, catID, linkFound := s.parser.FindEntryLinkByID(id); linkFound {
+		if explanation, err := s.transformer.ExplainEntryLink(entryLink, catID); err == nil {
+			return explanation, nil
+		}
+	}
+
+	entry, catalogueID, found := s.parser.FindSelectionEntryByID(id)
+	if !found {
+		return nil, fmt.Errorf("unit not found: %s (tried as entryLink and selectionEntry)", id)
+	}
+
+	return s.transformer.ExplainEntry(entry, catalogueID), nil
+}
+
+// ListUnits lists units matching the query, sorted and paginated
+func (s *UnitService) ListUnits(ctx context.Context, query UnitQuery) ([]models.UnitSummary, int, error) {
+	units, total, _, err := s.ListUnitsWithWarnings(ctx, query)
+	return units, total, err
+}
+
+// ListUnitsWithWarnings is ListUnits, also returning a warning for each entryLink that was
+// skipped because it didn't resolve
+func (s *UnitService) ListUnitsWithWarnings(ctx context.Context, query UnitQuery) ([]models.UnitSummary, int, []models.ResolutionWarning, error) {
+	query, err := query.Normalized()
+	if err != nil {
+		return nil, 0, nil, err
+	}
+
+	// Every page of a query shares one cached list
+	value, err := s.cache.Load(ctx, cache.KindUnitList, "", query.cacheKey(), func(ctx context.Context) (interface{}, int, error) {
+		list, err := s.listUnits(ctx, query)
+		if err != nil {
+			return nil, 0, err
+		}
+		return list, len(list.summaries) + 1, nil
+	})
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	list := value.(*unitList)
+
+	total := len(list.summaries)
+
+	// Apply pagination
+	start := query.Offset
+	if start > total {
+		start = total
+	}
+	end := total
+	if query.Limit > 0 && start+query.Limit < total {
+		end = start + query.Limit
+	}
+
+	units := make([]models.UnitSummary, 0, end-start)
+	units = append(units, list.summaries[start:end]...)
+
+	return units, total, list.warnings, nil
+}
+
+// unitList is every unit matching a query, sorted, before pagination
+type unitList struct {
+	summaries []models.UnitSummary
+	warnings  []models.ResolutionWarning
+}
+
+// listUnits finds, transforms and sorts the units matching a query. It stops with the context's
+// error once the context is done.
+func (s *UnitService) listUnits(ctx context.Context, query UnitQuery) (*unitList, error) {
+	factions := s.resolveFactionFilter(query.Factions)
+
+	var listings []unitListing
+	var warnings []models.ResolutionWarning
+
+	// Collect units from all catalogues, in a stable catalogue order
+	for _, catalogue := range sortedCatalogues(s.parser.GetAllCatalogues()) {
+		if !matchesCatalogue(catalogue, query.Catalogues) {
+			continue
+		}
 
-	// Collect units from all catalogues
-	for _, catalogue := range s.parser.GetAllCatalogues() {
 		for _, entryLink := range catalogue.EntryLinks {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
 			if entryLink.Type == "selectionEntry" {
 				// Try to resolve the entry
 				resolvedEntry, err := s.resolver.ResolveEntryLink(&entryLink, catalogue.ID)
 				if err != nil {
+					// Only the link's own categories are known, so report it if those could match
+					if factions.matches(entryLink.CategoryLinks, catalogue.ID) {
+						warnings = append(warnings, s.resolver.UnresolvedLinkWarning(&entryLink, catalogue.ID, "", err))
+					}
 					continue
 				}
+
 				// Merge entryLink overrides with resolved entry (preserves modifiers)
 				entry := s.resolver.MergeEntryLinkWithSelectionEntry(&entryLink, resolvedEntry)
 
 				// Apply filters
-				if faction != "" {
-					hasFaction := false
-					for _, catLink := range entry.CategoryLinks {
-						if strings.Contains(catLink.Name, faction) {
-							hasFaction = true
-							break
-						}
-					}
-					if !hasFaction {
-						continue
-					}
+				if !factions.matches(entry.CategoryLinks, catalogue.ID) {
+					continue
 				}
 
-				if category != "" {
-					hasCategory := false
-					for _, catLink := range entry.CategoryLinks {
-						if strings.Contains(catLink.Name, category) {
-							hasCategory = true
-							break
-						}
-					}
-					if !hasCategory {
-						continue
-					}
+				if !matchesAnyCategory(entry.CategoryLinks, query.Categories) {
+					continue
 				}
 
-				if search != "" {
-					if !strings.Contains(strings.ToLower(entry.Name), strings.ToLower(search)) {
+				if query.Search != "" {
+					if !strings.Contains(strings.ToLower(entry.Name), strings.ToLower(query.Search)) {
 						continue
 					}
 				}
 
-				// Transform the full unit to get tiered costs
-				fullUnit := s.transformer.TransformUnit(entry, catalogue.ID)
-				
-				summary := models.UnitSummary{
-					ID:          entryLink.ID,
-					Name:        entry.Name,
-					TargetID:    entryLink.TargetID,
-					Type:        entry.Type,
-					Costs:       s.transformer.TransformCosts(entry.Costs),
-					TieredCosts: fullUnit.TieredCosts,
+				if !query.Legends.matches(entry.Name) {
+					continue
+				}
+
+				costs := s.transformer.TransformCosts(entry.Costs)
+				points := costs["pts"]
+				if query.MinPoints > 0 && points < query.MinPoints {
+					continue
 				}
-				allUnits = append(allUnits, summary)
+				if query.MaxPoints > 0 && points > query.MaxPoints {
+					continue
+				}
+
+				// The full unit has the tiered costs and stats for sorting
+				fullUnit := s.rootUnit(&entryLink, entry, catalogue.ID)
+
+				catalogueInfo := toCatalogueInfo(catalogue)
+				listings = append(listings, unitListing{
+					summary: models.UnitSummary{
+						ID:          entryLink.ID,
+						Name:        entry.Name,
+						TargetID:    entryLink.TargetID,
+						Type:        entry.Type,
+						Costs:       costs,
+						TieredCosts: fullUnit.TieredCosts,
+						Catalogue:   &catalogueInfo,
+						Overlays:    fullUnit.Overlays,
+					},
+					profile: fullUnit.Profiles.Unit,
+					points:  points,
+				})
 			}
 		}
 	}
 
-	total := len(allUnits)
+	sortUnitListings(listings, query.Sort, query.Descending)
 
-	// Apply pagination
-	start := offset
-	if start > total {
-		start = total
-	}
-	end := start + limit
-	if end > total {
-		end = total
+	summaries := make([]models.UnitSummary, 0, len(listings))
+	for _, listing := range listings {
+		summaries = append(summaries, listing.summary)
 	}
+	return &unitList{summaries: summaries, warnings: warnings}, nil
+}
 
-	if start < total {
-		return allUnits[start:end], total, nil
+// SearchUnits searches for units by name
+func (s *UnitService) SearchUnits(ctx context.Context, query string, limit int) ([]models.SearchResult, error) {
+	results, _, err := s.SearchUnitsWithWarnings(ctx, query, limit)
+	return results, err
+}
+
+// SearchUnitsWithWarnings is SearchUnits, also returning a warning for each entryLink that was
+// skipped because it didn't resolve, when the link's own name matches the query
+func (s *UnitService) SearchUnitsWithWarnings(ctx context.Context, query string, limit int) ([]models.SearchResult, []models.ResolutionWarning, error) {
+	query = strings.ToLower(query)
+	value, err := s.cache.Load(ctx, cache.KindSearch, "", fmt.Sprintf("q=%s&limit=%d", query, limit), func(ctx context.Context) (interface{}, int, error) {
+		search, err := s.searchUnits(ctx, query, limit)
+		if err != nil {
+			return nil, 0, err
+		}
+		return search, len(search.results) + 1, nil
+	})
+	if err != nil {
+		return nil, nil, err
 	}
+	search := value.(*unitSearch)
+	return search.results, search.warnings, nil
+}
 
-	return []models.UnitSummary{}, total, nil
+// unitSearch is the result of a search
+type unitSearch struct {
+	results  []models.SearchResult
+	warnings []models.ResolutionWarning
 }
 
-// SearchUnits searches for units by name
-func (s *UnitService) SearchUnits(query string, limit int) ([]models.SearchResult, error) {
-	query = strings.ToLower(query)
-	var results []models.SearchResult
+// searchUnits searches the units for a lowercased query, until the context is done. Matches are
+// ordered by name, catalogue name and ID before the limit is applied, so the same limit always
+// returns the same units.
+func (s *UnitService) searchUnits(ctx context.Context, query string, limit int) (*unitSearch, error) {
+	type match struct {
+		result    models.SearchResult
+		catalogue string
+	}
+	var matches []match
+	search := &unitSearch{}
 
 	// Search through all catalogues
-	for _, catalogue := range s.parser.GetAllCatalogues() {
+	for _, catalogue := range sortedCatalogues(s.parser.GetAllCatalogues()) {
 		for _, entryLink := range catalogue.EntryLinks {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
 			if entryLink.Type == "selectionEntry" {
-				resolvedEntry, err := s.resolver.ResolveEntryLink(&entryLink, catalogue.ID)
-				if err != nil {
-					continue
+				name := ""
+				if unit, ok := s.warmUnit(catalogue.ID, entryLink.ID); ok {
+					name = unit.Name
+				} else {
+					resolvedEntry, err := s.resolver.ResolveEntryLink(&entryLink, catalogue.ID)
+					if err != nil {
+						// Only the link's own name is known, so report it if that matches
+						if strings.Contains(strings.ToLower(entryLink.Name), query) {
+							search.warnings = append(search.warnings, s.resolver.UnresolvedLinkWarning(&entryLink, catalogue.ID, "", err))
+						}
+						continue
+					}
+					// Merge entryLink overrides with resolved entry (preserves modifiers)
+					name = s.resolver.MergeEntryLinkWithSelectionEntry(&entryLink, resolvedEntry).Name
 				}
-				// Merge entryLink overrides with resolved entry (preserves modifiers)
-				entry := s.resolver.MergeEntryLinkWithSelectionEntry(&entryLink, resolvedEntry)
 
-				if strings.Contains(strings.ToLower(entry.Name), query) {
-					results = append(results, models.SearchResult{
-						Type: "unit",
-						ID:   entryLink.ID,
-						Name: entry.Name,
+				if strings.Contains(strings.ToLower(name), query) {
+					matches = append(matches, match{
+						result:    models.SearchResult{Type: "unit", ID: entryLink.ID, Name: name},
+						catalogue: catalogue.Name,
 					})
-
-					if len(results) >= limit {
-						return results, nil
-					}
 				}
 			}
 		}
 	}
 
-	return results, nil
+	sort.SliceStable(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if nameA, nameB := strings.ToLower(a.result.Name), strings.ToLower(b.result.Name); nameA != nameB {
+			return nameA < nameB
+		}
+		if a.catalogue != b.catalogue {
+			return a.catalogue < b.catalogue
+		}
+		return a.result.ID < b.result.ID
+	})
+	for _, m := range matches[:min(limit, len(matches))] {
+		search.results = append(search.results, m.result)
+	}
+
+	return search, nil
 }
 
 // GetUnitWeapons retrieves weapons for a unit
-func (s *UnitService) GetUnitWeapons(id string) (*models.WeaponSet, error) {
-	unit, err := s.GetUnit(id)
+func (s *UnitService) GetUnitWeapons(ctx context.Context, id string) (*models.WeaponSet, error) {
+	unit, err := s.GetUnit(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
 	return unit.Weapons, nil
 }
-
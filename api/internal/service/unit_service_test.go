@@ -1,6 +1,8 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"testing"
 
 	"grimoire-api/internal/cache"
@@ -9,7 +11,7 @@ import (
 
 func TestGetUnit(t *testing.T) {
 	dataDir := getTestDataDir(t)
-	
+
 	p := parser.NewParser(dataDir)
 	if err := p.LoadGameSystem(); err != nil {
 		t.Fatalf("Failed to load game system: %v", err)
@@ -26,7 +28,7 @@ func TestGetUnit(t *testing.T) {
 
 	// Test with entryLink ID (most common case)
 	entryLinkID := "a502-4dbe-d0c6-69fd" // Warlock
-	unit, err := service.GetUnit(entryLinkID)
+	unit, err := service.GetUnit(context.Background(), entryLinkID)
 	if err != nil {
 		t.Fatalf("Failed to get unit: %v", err)
 	}
@@ -45,7 +47,7 @@ func TestGetUnit(t *testing.T) {
 
 	// Test with selectionEntry ID
 	selectionEntryID := "828d-840a-9a67-9074" // Asurmen
-	unit2, err := service.GetUnit(selectionEntryID)
+	unit2, err := service.GetUnit(context.Background(), selectionEntryID)
 	if err != nil {
 		t.Fatalf("Failed to get unit by selectionEntry ID: %v", err)
 	}
@@ -59,7 +61,7 @@ func TestGetUnit(t *testing.T) {
 	}
 
 	// Test non-existent ID
-	_, err = service.GetUnit("nonexistent-id")
+	_, err = service.GetUnit(context.Background(), "nonexistent-id")
 	if err == nil {
 		t.Error("Expected error for non-existent unit")
 	}
@@ -67,7 +69,7 @@ func TestGetUnit(t *testing.T) {
 
 func TestListUnits(t *testing.T) {
 	dataDir := getTestDataDir(t)
-	
+
 	p := parser.NewParser(dataDir)
 	if err := p.LoadGameSystem(); err != nil {
 		t.Fatalf("Failed to load game system: %v", err)
@@ -83,7 +85,7 @@ func TestListUnits(t *testing.T) {
 	service := NewUnitService(p, resolver, transformer, cache)
 
 	// Test listing all units
-	units, total, err := service.ListUnits("", "", "", 100, 0)
+	units, total, err := service.ListUnits(context.Background(), UnitQuery{Limit: 100})
 	if err != nil {
 		t.Fatalf("Failed to list units: %v", err)
 	}
@@ -97,7 +99,7 @@ func TestListUnits(t *testing.T) {
 	}
 
 	// Test pagination
-	units2, total2, err := service.ListUnits("", "", "", 10, 0)
+	units2, total2, err := service.ListUnits(context.Background(), UnitQuery{Limit: 10})
 	if err != nil {
 		t.Fatalf("Failed to list units with pagination: %v", err)
 	}
@@ -111,7 +113,7 @@ func TestListUnits(t *testing.T) {
 	}
 
 	// Test search filter
-	units3, _, err := service.ListUnits("", "", "marine", 100, 0)
+	units3, _, err := service.ListUnits(context.Background(), UnitQuery{Search: "marine", Limit: 100})
 	if err != nil {
 		t.Fatalf("Failed to search units: %v", err)
 	}
@@ -122,7 +124,7 @@ func TestListUnits(t *testing.T) {
 	}
 
 	// Test faction filter
-	units4, _, err := service.ListUnits("Imperium", "", "", 100, 0)
+	units4, _, err := service.ListUnits(context.Background(), UnitQuery{Factions: []string{"Imperium"}, Limit: 100})
 	if err != nil {
 		t.Fatalf("Failed to filter by faction: %v", err)
 	}
@@ -135,7 +137,7 @@ func TestListUnits(t *testing.T) {
 
 func TestSearchUnits(t *testing.T) {
 	dataDir := getTestDataDir(t)
-	
+
 	p := parser.NewParser(dataDir)
 	if err := p.LoadGameSystem(); err != nil {
 		t.Fatalf("Failed to load game system: %v", err)
@@ -151,7 +153,7 @@ func TestSearchUnits(t *testing.T) {
 	service := NewUnitService(p, resolver, transformer, cache)
 
 	// Test search
-	results, err := service.SearchUnits("asurmen", 10)
+	results, err := service.SearchUnits(context.Background(), "asurmen", 10)
 	if err != nil {
 		t.Fatalf("Failed to search units: %v", err)
 	}
@@ -181,7 +183,7 @@ func TestSearchUnits(t *testing.T) {
 	}
 
 	// Test limit
-	results2, err := service.SearchUnits("a", 5)
+	results2, err := service.SearchUnits(context.Background(), "a", 5)
 	if err != nil {
 		t.Fatalf("Failed to search with limit: %v", err)
 	}
@@ -193,7 +195,7 @@ func TestSearchUnits(t *testing.T) {
 
 func TestGetUnitWeapons(t *testing.T) {
 	dataDir := getTestDataDir(t)
-	
+
 	p := parser.NewParser(dataDir)
 	if err := p.LoadGameSystem(); err != nil {
 		t.Fatalf("Failed to load game system: %v", err)
@@ -210,7 +212,7 @@ func TestGetUnitWeapons(t *testing.T) {
 
 	// Get a unit with weapons (Asurmen)
 	unitID := "828d-840a-9a67-9074"
-	weapons, err := service.GetUnitWeapons(unitID)
+	weapons, err := service.GetUnitWeapons(context.Background(), unitID)
 	if err != nil {
 		t.Fatalf("Failed to get unit weapons: %v", err)
 	}
@@ -225,11 +227,33 @@ func TestGetUnitWeapons(t *testing.T) {
 	}
 }
 
+func TestCancelledContext(t *testing.T) {
+	service := newFixtureUnitService(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := service.GetUnit(ctx, "el-fixture-captain"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected GetUnit to be cancelled, got %v", err)
+	}
+	if _, _, err := service.ListUnits(ctx, UnitQuery{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected ListUnits to be cancelled, got %v", err)
+	}
+	if _, err := service.SearchUnits(ctx, "captain", 10); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected SearchUnits to be cancelled, got %v", err)
+	}
+
+	// The cancelled calls left nothing behind in the cache
+	unit, err := service.GetUnit(context.Background(), "el-fixture-captain")
+	if err != nil || unit == nil {
+		t.Fatalf("Expected the unit once not cancelled, got %v", err)
+	}
+}
 
 func containsIgnoreCase(s, substr string) bool {
-	return len(s) >= len(substr) && 
+	return len(s) >= len(substr) &&
 		(len(s) == len(substr) && s == substr ||
-		containsMiddleIgnoreCase(s, substr))
+			containsMiddleIgnoreCase(s, substr))
 }
 
 func containsMiddleIgnoreCase(s, substr string) bool {
@@ -254,4 +278,3 @@ func toLower(s string) string {
 	}
 	return string(result)
 }
-
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"sort"
+	"sync"
+
+	"grimoire-api/internal/models"
+	"grimoire-api/internal/parser"
+)
+
+// warmKey identifies a unit entryLink at the root of a catalogue
+type warmKey struct {
+	catalogueID string
+	linkID      string
+}
+
+// warmUnits are the units transformed by WarmUp. The map is never written after it is published.
+type warmUnits map[warmKey]*models.UnitResponse
+
+// WarmUp transforms every unit at the root of a catalogue with the given number of workers, and
+// from then on serves them to GetUnit, ListUnits and SearchUnits without transforming them again.
+// progress, if not nil, is called after each unit from one goroutine. It returns the number of units.
+func (s *UnitService) WarmUp(workers int, progress func(done, total int)) int {
+	roots := s.resolver.ResolveRootUnits()
+	if workers < 1 {
+		workers = 1
+	}
+
+	type result struct {
+		key  warmKey
+		unit *models.UnitResponse
+	}
+	jobs := make(chan parser.RootUnit)
+	results := make(chan result)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for root := range jobs {
+				unit := s.transformer.TransformUnit(root.Entry, root.Catalogue.ID)
+				unit.ID = root.EntryLink.ID // As GetUnit returns it
+				results <- result{key: warmKey{root.Catalogue.ID, root.EntryLink.ID}, unit: unit}
+			}
+		}()
+	}
+	go func() {
+		for _, root := range roots {
+			jobs <- root
+		}
+		close(jobs)
+		wg.Wait()
+		close(results)
+	}()
+
+	units := make(warmUnits, len(roots))
+	for r := range results {
+		units[r.key] = r.unit
+		if progress != nil {
+			progress(len(units), len(roots))
+		}
+	}
+
+	s.warm.Store(&units)
+	return len(units)
+}
+
+// warmUnit returns the warmed unit of a root entryLink, if WarmUp has finished
+func (s *UnitService) warmUnit(catalogueID, linkID string) (*models.UnitResponse, bool) {
+	units := s.warm.Load()
+	if units == nil {
+		return nil, false
+	}
+	unit, ok := (*units)[warmKey{catalogueID, linkID}]
+	return unit, ok
+}
+
+// WarmUnit is a transformed unit at the root of a catalogue, as WarmUp makes it
+type WarmUnit struct {
+	CatalogueID string
+	LinkID      string
+	Unit        *models.UnitResponse
+}
+
+// WarmUnits returns the units WarmUp transformed, ordered by catalogue and entryLink ID, or nil
+// before it has finished
+func (s *UnitService) WarmUnits() []WarmUnit {
+	units := s.warm.Load()
+	if units == nil {
+		return nil
+	}
+	result := make([]WarmUnit, 0, len(*units))
+	for key, unit := range *units {
+		result = append(result, WarmUnit{CatalogueID: key.catalogueID, LinkID: key.linkID, Unit: unit})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].CatalogueID != result[j].CatalogueID {
+			return result[i].CatalogueID < result[j].CatalogueID
+		}
+		return result[i].LinkID < result[j].LinkID
+	})
+	return result
+}
+
+// SetWarmUnits serves units transformed elsewhere, such as those of a compiled snapshot, as if
+// WarmUp had transformed them
+func (s *UnitService) SetWarmUnits(units []WarmUnit) {
+	warm := make(warmUnits, len(units))
+	for _, unit := range units {
+		warm[warmKey{unit.CatalogueID, unit.LinkID}] = unit.Unit
+	}
+	s.warm.Store(&warm)
+}
+
+// rootUnit returns the full unit of a root entryLink merged with its entry, warmed or transformed now
+func (s *UnitService) rootUnit(entryLink *models.EntryLink, entry *models.SelectionEntry, catalogueID string) *models.UnitResponse {
+	if unit, ok := s.warmUnit(catalogueID, entryLink.ID); ok {
+		return unit
+	}
+	return s.transformer.TransformUnit(entry, catalogueID)
+}
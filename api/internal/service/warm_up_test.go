@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestWarmUpServesTheSameUnits(t *testing.T) {
+	cold := newFixtureUnitService(t)
+	warm := newFixtureUnitService(t)
+
+	calls := 0
+	units := warm.WarmUp(4, func(done, total int) {
+		calls++
+		if done != calls || total < done {
+			t.Errorf("Unexpected progress %d of %d", done, total)
+		}
+	})
+	if units == 0 || calls != units {
+		t.Fatalf("Expected progress for each of %d units, got %d calls", units, calls)
+	}
+
+	// Detail, list and search give the same results warmed as transformed on demand
+	want, err := cold.GetUnit(context.Background(), "el-fixture-captain")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := warm.GetUnit(context.Background(), "el-fixture-captain")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("Warmed unit differs:\nwant %+v\ngot  %+v", want, got)
+	}
+	if again, _ := warm.GetUnit(context.Background(), "el-fixture-captain"); again != got {
+		t.Error("GetUnit should return the warmed unit")
+	}
+
+	query := UnitQuery{Sort: SortByPoints, Descending: true}
+	wantList, wantTotal, err := cold.ListUnits(context.Background(), query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotList, gotTotal, err := warm.ListUnits(context.Background(), query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wantTotal != gotTotal || !reflect.DeepEqual(wantList, gotList) {
+		t.Errorf("Warmed list differs:\nwant %+v\ngot  %+v", wantList, gotList)
+	}
+
+	wantResults, wantWarnings, _ := cold.SearchUnitsWithWarnings(context.Background(), "captain", 50)
+	gotResults, gotWarnings, _ := warm.SearchUnitsWithWarnings(context.Background(), "captain", 50)
+	if len(wantResults) == 0 || len(wantResults) != len(gotResults) || len(wantWarnings) != len(gotWarnings) {
+		t.Errorf("Warmed search differs: want %d results and %d warnings, got %d and %d",
+			len(wantResults), len(wantWarnings), len(gotResults), len(gotWarnings))
+	}
+}
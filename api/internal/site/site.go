@@ -0,0 +1,362 @@
+// Package site writes a static mirror of the API: the JSON body of every read route, as files a
+// static host or CDN can serve, with an index manifest of content hashes and precomputed search
+// shards. Bodies are served by the API's own router, so they can't drift from the live API.
+package site
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+
+	"grimoire-api/internal/handlers"
+	"grimoire-api/internal/models"
+	"grimoire-api/internal/server"
+	"grimoire-api/internal/snapshot"
+)
+
+// IndexFile is the manifest at the root of a generated site
+const IndexFile = "index.json"
+
+// DefaultPageSize and MaxPageSize bound the number of units on each page of the unit list, as
+// the limit of GET /api/v1/units does
+const (
+	DefaultPageSize = 100
+	MaxPageSize     = 1000
+)
+
+// File is a route written to the site
+type File struct {
+	Route string `json:"route"` // The API request the file answers
+	Path  string `json:"path"`  // Relative to the site root
+	Hash  string `json:"hash"`  // Of the content, to append to the path as ?v= for cache busting
+	Size  int    `json:"size"`
+}
+
+// Shard is a precomputed search shard: the units with a word in their name starting with Prefix
+type Shard struct {
+	Prefix string `json:"prefix"`
+	Path   string `json:"path"`
+	Hash   string `json:"hash"`
+	Units  int    `json:"units"`
+}
+
+// Manifest is the index of a generated site
+type Manifest struct {
+	Revision    string    `json:"revision"` // Revision of the snapshot the site was generated from
+	GeneratedAt time.Time `json:"generatedAt"`
+	PageSize    int       `json:"pageSize"`
+	UnitPages   int       `json:"unitPages"` // Pages of the unit list, at api/v1/units/pages/<n>.json from 1
+	PrefixSize  int       `json:"prefixSize"`
+	Files       []File    `json:"files"`
+	Search      []Shard   `json:"search"`
+}
+
+// prefixSize is the number of characters of the word prefixes search shards are keyed by
+const prefixSize = 2
+
+// generator writes the files of a site
+type generator struct {
+	ctx      context.Context
+	router   *gin.Engine
+	revision string
+	dir      string
+	manifest *Manifest
+}
+
+// Generate writes a site of the current snapshot of snapshots to dir, replacing the site there
+// once the new one is complete. pageSize is the number of units on each page of the unit list.
+func Generate(ctx context.Context, snapshots *snapshot.Store, dir string, pageSize int) (*Manifest, error) {
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+	if pageSize > MaxPageSize {
+		return nil, fmt.Errorf("page size must be at most %d, the limit of the unit list", MaxPageSize)
+	}
+	if err := checkReplaceable(dir); err != nil {
+		return nil, err
+	}
+	parent := filepath.Dir(filepath.Clean(dir))
+	if err := os.MkdirAll(parent, 0o755); err != nil {
+		return nil, err
+	}
+	tmp, err := os.MkdirTemp(parent, filepath.Base(dir)+".tmp-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	// The API's own router, of which only the read routes are requested
+	router, err := server.NewRouter(server.Config{Snapshots: snapshots, Quiet: true})
+	if err != nil {
+		return nil, err
+	}
+	snap := snapshots.Current()
+	g := &generator{
+		ctx:      ctx,
+		router:   router,
+		revision: snap.Revision,
+		dir:      tmp,
+		manifest: &Manifest{
+			Revision:    snap.Revision,
+			GeneratedAt: time.Now().UTC().Truncate(time.Second),
+			PageSize:    pageSize,
+			PrefixSize:  prefixSize,
+		},
+	}
+	if err := g.generate(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(g.manifest.Files, func(i, j int) bool { return g.manifest.Files[i].Path < g.manifest.Files[j].Path })
+	index, err := json.MarshalIndent(g.manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(tmp, IndexFile), index, 0o644); err != nil {
+		return nil, err
+	}
+
+	if err := os.RemoveAll(dir); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmp, dir); err != nil {
+		return nil, err
+	}
+	return g.manifest, nil
+}
+
+// checkReplaceable refuses to replace anything but a missing or empty directory or a site
+func checkReplaceable(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+	if _, err := os.Stat(filepath.Join(dir, IndexFile)); err != nil {
+		return fmt.Errorf("refusing to replace %s: it is not a generated site", dir)
+	}
+	return nil
+}
+
+// generate writes every route, walking the lists for the IDs of the routes below them
+func (g *generator) generate() error {
+	if _, err := g.write("/api/v1/game-system", ""); err != nil {
+		return err
+	}
+
+	var catalogues, factions, rules listBody
+	if err := g.writeList("/api/v1/catalogues", &catalogues); err != nil {
+		return err
+	}
+	for _, catalogue := range catalogues.Data {
+		route := "/api/v1/catalogues/" + url.PathEscape(catalogue.ID)
+		if err := g.writeRoutes(route, route+"/units"); err != nil {
+			return err
+		}
+	}
+
+	if err := g.writeList("/api/v1/factions", &factions); err != nil {
+		return err
+	}
+	for _, faction := range factions.Data {
+		route := "/api/v1/factions/" + url.PathEscape(faction.ID)
+		if err := g.writeRoutes(route, route+"/units"); err != nil {
+			return err
+		}
+	}
+
+	if err := g.writeList("/api/v1/rules", &rules); err != nil {
+		return err
+	}
+	for _, rule := range rules.Data {
+		if err := g.writeRoutes("/api/v1/rules/" + url.PathEscape(rule.ID)); err != nil {
+			return err
+		}
+	}
+
+	// The unit list, page by page, and every unit on it
+	var units []listItem
+	for page, offset := 1, 0; ; page, offset = page+1, offset+g.manifest.PageSize {
+		route := fmt.Sprintf("/api/v1/units?limit=%d&offset=%d", g.manifest.PageSize, offset)
+		body, err := g.write(route, fmt.Sprintf("api/v1/units/pages/%d.json", page))
+		if err != nil {
+			return err
+		}
+		var list pageBody
+		if err := json.Unmarshal(body, &list); err != nil {
+			return fmt.Errorf("failed to read %s: %w", route, err)
+		}
+		units = append(units, list.Data...)
+		g.manifest.UnitPages = page
+		if !list.HasMore {
+			break
+		}
+	}
+	// A unit linked from several catalogues is one route
+	var unique []listItem
+	seen := make(map[string]bool)
+	for _, unit := range units {
+		if seen[unit.ID] {
+			continue
+		}
+		seen[unit.ID] = true
+		unique = append(unique, unit)
+		route := "/api/v1/units/" + url.PathEscape(unit.ID)
+		if err := g.writeRoutes(route, route+"/weapons"); err != nil {
+			return err
+		}
+	}
+
+	return g.writeSearch(unique)
+}
+
+// listItem is what a site needs of an item of a list body
+type listItem struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// listBody is a list response
+type listBody struct {
+	Data []listItem `json:"data"`
+}
+
+// pageBody is a paginated list response
+type pageBody struct {
+	Data    []listItem `json:"data"`
+	HasMore bool       `json:"hasMore"`
+}
+
+// writeList writes a list route and decodes it into list
+func (g *generator) writeList(route string, list *listBody) error {
+	body, err := g.write(route, "")
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, list); err != nil {
+		return fmt.Errorf("failed to read %s: %w", route, err)
+	}
+	return nil
+}
+
+func (g *generator) writeRoutes(routes ...string) error {
+	for _, route := range routes {
+		if _, err := g.write(route, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// write serves a route from the snapshot the site is generated from and writes its body to file,
+// by default the route's path with .json appended
+func (g *generator) write(route, file string) ([]byte, error) {
+	if err := g.ctx.Err(); err != nil {
+		return nil, err
+	}
+	req := httptest.NewRequest(http.MethodGet, route, nil).WithContext(g.ctx)
+	req.Header.Set(handlers.RevisionHeader, g.revision)
+	w := httptest.NewRecorder()
+	g.router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		return nil, fmt.Errorf("%s answered %d: %s", route, w.Code, strings.TrimSpace(w.Body.String()))
+	}
+
+	if file == "" {
+		file = strings.TrimPrefix(route, "/") + ".json"
+	}
+	body := w.Body.Bytes()
+	hash, err := g.writeFile(file, body)
+	if err != nil {
+		return nil, err
+	}
+	g.manifest.Files = append(g.manifest.Files, File{Route: route, Path: file, Hash: hash, Size: len(body)})
+	return body, nil
+}
+
+// writeFile writes data to a file of the site and returns the hash of its content
+func (g *generator) writeFile(file string, data []byte) (string, error) {
+	full := filepath.Join(g.dir, filepath.FromSlash(file))
+	if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(full, data, 0o644); err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8]), nil
+}
+
+// shardBody is the content of a search shard
+type shardBody struct {
+	Prefix  string                `json:"prefix"`
+	Results []models.SearchResult `json:"results"`
+}
+
+// writeSearch writes the search shards: each unit is listed in the shard of the prefix of every
+// word of its name. A client searching loads the shard of its query's prefix and keeps the results
+// whose name contains the query.
+func (g *generator) writeSearch(units []listItem) error {
+	shards := make(map[string][]models.SearchResult)
+	for _, unit := range units {
+		listed := make(map[string]bool)
+		for _, word := range strings.FieldsFunc(strings.ToLower(unit.Name), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		}) {
+			prefix := Prefix(word)
+			if listed[prefix] {
+				continue
+			}
+			listed[prefix] = true
+			shards[prefix] = append(shards[prefix], models.SearchResult{Type: "unit", ID: unit.ID, Name: unit.Name})
+		}
+	}
+
+	prefixes := make([]string, 0, len(shards))
+	for prefix := range shards {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Strings(prefixes)
+	for _, prefix := range prefixes {
+		body, err := json.Marshal(shardBody{Prefix: prefix, Results: shards[prefix]})
+		if err != nil {
+			return err
+		}
+		file := path.Join("search", url.PathEscape(prefix)+".json")
+		hash, err := g.writeFile(file, body)
+		if err != nil {
+			return err
+		}
+		g.manifest.Search = append(g.manifest.Search, Shard{Prefix: prefix, Path: file, Hash: hash, Units: len(shards[prefix])})
+	}
+	return nil
+}
+
+// Prefix is the search shard of a lowercased word or query: its first characters
+func Prefix(word string) string {
+	runes := []rune(word)
+	if len(runes) > prefixSize {
+		runes = runes[:prefixSize]
+	}
+	return string(runes)
+}
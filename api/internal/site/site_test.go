@@ -0,0 +1,113 @@
+package site
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"grimoire-api/internal/snapshot"
+)
+
+const fixtureDir = "../../testdata/wh40k-fixture"
+
+func newStore(t *testing.T) *snapshot.Store {
+	gin.SetMode(gin.TestMode)
+	snapshots := snapshot.NewStore(snapshot.Config{DataDir: fixtureDir, Quiet: true})
+	_, _, err := snapshots.Reload()
+	require.NoError(t, err)
+	return snapshots
+}
+
+func TestGenerate(t *testing.T) {
+	snapshots := newStore(t)
+	dir := filepath.Join(t.TempDir(), "site")
+
+	manifest, err := Generate(context.Background(), snapshots, dir, 3)
+	require.NoError(t, err)
+	assert.Equal(t, snapshots.Current().Revision, manifest.Revision)
+	assert.Equal(t, 3, manifest.UnitPages)
+
+	var index Manifest
+	data, err := os.ReadFile(filepath.Join(dir, IndexFile))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &index))
+	assert.Equal(t, manifest.Revision, index.Revision)
+
+	// Every route is written with the hash of its content
+	paths := make(map[string]bool)
+	for _, file := range index.Files {
+		body, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(file.Path)))
+		require.NoError(t, err, file.Path)
+		sum := sha256.Sum256(body)
+		assert.Equal(t, hex.EncodeToString(sum[:8]), file.Hash, file.Path)
+		assert.Equal(t, len(body), file.Size, file.Path)
+		paths[file.Path] = true
+	}
+	for _, path := range []string{
+		"api/v1/game-system.json",
+		"api/v1/catalogues.json",
+		"api/v1/catalogues/cat-fixture-marines.json",
+		"api/v1/catalogues/cat-fixture-marines/units.json",
+		"api/v1/units/pages/1.json",
+		"api/v1/units/pages/3.json",
+		"api/v1/units/el-fixture-captain.json",
+		"api/v1/units/el-fixture-captain/weapons.json",
+		"api/v1/factions.json",
+		"api/v1/factions/fac-fixture-astartes/units.json",
+		"api/v1/rules/rule-fixture-deep-strike.json",
+	} {
+		assert.True(t, paths[path], path)
+	}
+
+	// Bodies are the API's, envelope included
+	var unit struct {
+		Data struct {
+			ID    string         `json:"id"`
+			Costs map[string]int `json:"costs"`
+		} `json:"data"`
+	}
+	data, err = os.ReadFile(filepath.Join(dir, "api/v1/units/el-fixture-captain.json"))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &unit))
+	assert.Equal(t, "el-fixture-captain", unit.Data.ID)
+	assert.Equal(t, 80, unit.Data.Costs["pts"])
+
+	// A unit is in the search shard of every word of its name
+	var shard shardBody
+	data, err = os.ReadFile(filepath.Join(dir, "search", Prefix("bloodthirster")+".json"))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &shard))
+	var ids []string
+	for _, result := range shard.Results {
+		ids = append(ids, result.ID)
+	}
+	assert.ElementsMatch(t, []string{"el-fixture-bloodletters", "el-fixture-bloodthirster"}, ids)
+
+	// Generating again replaces the site
+	_, err = Generate(context.Background(), snapshots, dir, DefaultPageSize)
+	require.NoError(t, err)
+	_, err = os.Stat(filepath.Join(dir, "api/v1/units/pages/2.json"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestGenerateRefusesOtherDirectories(t *testing.T) {
+	snapshots := newStore(t)
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("keep me"), 0o644))
+
+	_, err := Generate(context.Background(), snapshots, dir, DefaultPageSize)
+	assert.Error(t, err)
+	_, err = os.Stat(filepath.Join(dir, "notes.txt"))
+	assert.NoError(t, err)
+
+	_, err = Generate(context.Background(), snapshots, filepath.Join(t.TempDir(), "site"), MaxPageSize+1)
+	assert.Error(t, err)
+}
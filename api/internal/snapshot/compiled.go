@@ -0,0 +1,178 @@
+package snapshot
+
+import (
+	"bufio"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"grimoire-api/internal/models"
+	"grimoire-api/internal/parser"
+	"grimoire-api/internal/service"
+)
+
+// CompiledVersion is the version of the compiled snapshot format. Bump it whenever the models or
+// the layout below change, so older files are ignored instead of decoded wrongly.
+const CompiledVersion = 4
+
+const compiledMagic = "grimoire-snapshot"
+
+// compiledHeader starts a compiled file, so a stale or foreign file is rejected before the
+// dataset is decoded
+type compiledHeader struct {
+	Magic    string
+	Version  int
+	Revision string // Revision of the data and overlays it was compiled from
+}
+
+// compiledBody is the parsed dataset with everything that is slow to derive from it
+type compiledBody struct {
+	Dataset  *parser.Dataset
+	Overlays *models.OverlayReport
+	Units    []compiledUnit
+}
+
+// compiledUnit is a transformed root unit. It is kept as JSON because gob can't tell empty slices
+// from nil ones, and the API responses must not change.
+type compiledUnit struct {
+	CatalogueID string
+	LinkID      string
+	JSON        []byte
+}
+
+// errStaleCompiled is returned when a compiled file doesn't match the data
+var errStaleCompiled = errors.New("compiled snapshot is out of date")
+
+// compiledConfig is the part of config a compiled file covers. Homebrew uploads change at runtime
+// and are loaded on top of it; they live in their own namespace, so they can't change official units.
+func compiledConfig(config Config) Config {
+	config.Homebrew = nil
+	config.CompiledFile = ""
+	return config
+}
+
+// Compile loads the data and overlays named by config and writes them, with every root unit already
+// transformed, to a compiled file at path. Homebrew catalogues are not compiled.
+func Compile(config Config, path string) (*Snapshot, error) {
+	config = compiledConfig(config)
+	revision, err := Revision(config)
+	if err != nil {
+		return nil, err
+	}
+	p, overlayReport, err := parse(config)
+	if err != nil {
+		return nil, err
+	}
+	snap := newSnapshot(config, revision, p, overlayReport)
+
+	body := compiledBody{
+		Dataset:  p.Dataset(),
+		Overlays: overlayReport,
+	}
+	snap.Units.WarmUp(runtime.GOMAXPROCS(0), nil)
+	for _, unit := range snap.Units.WarmUnits() {
+		encoded, err := json.Marshal(unit.Unit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode unit %s: %w", unit.LinkID, err)
+		}
+		body.Units = append(body.Units, compiledUnit{CatalogueID: unit.CatalogueID, LinkID: unit.LinkID, JSON: encoded})
+	}
+
+	// Write to a temporary file first, so a running server never reads half a file
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create compiled snapshot: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	w := bufio.NewWriter(tmp)
+	encoder := gob.NewEncoder(w)
+	header := compiledHeader{Magic: compiledMagic, Version: CompiledVersion, Revision: revision}
+	if err := encoder.Encode(header); err != nil {
+		tmp.Close()
+		return nil, fmt.Errorf("failed to write compiled snapshot: %w", err)
+	}
+	if err := encoder.Encode(body); err != nil {
+		tmp.Close()
+		return nil, fmt.Errorf("failed to write compiled snapshot: %w", err)
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return nil, fmt.Errorf("failed to write compiled snapshot: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write compiled snapshot: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return nil, fmt.Errorf("failed to write compiled snapshot: %w", err)
+	}
+
+	snap.Compiled = true
+	return snap, nil
+}
+
+// loadCompiled loads a snapshot from config.CompiledFile. It fails with errStaleCompiled when the
+// file was compiled from other data or by another version, and the caller should parse instead.
+func loadCompiled(config Config, revision string) (*Snapshot, error) {
+	dataRevision, err := Revision(compiledConfig(config))
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(config.CompiledFile)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	decoder := gob.NewDecoder(bufio.NewReader(file))
+	var header compiledHeader
+	if err := decoder.Decode(&header); err != nil || header.Magic != compiledMagic {
+		return nil, fmt.Errorf("%s is not a compiled snapshot", config.CompiledFile)
+	}
+	if header.Version != CompiledVersion || header.Revision != dataRevision {
+		return nil, errStaleCompiled
+	}
+
+	var body compiledBody
+	if err := decoder.Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to read compiled snapshot: %w", err)
+	}
+	if body.Dataset == nil || body.Overlays == nil {
+		return nil, fmt.Errorf("compiled snapshot %s is incomplete", config.CompiledFile)
+	}
+	// gob drops empty slices, which the reports serve as []
+	if report := body.Dataset.LoadReport; report != nil && report.Errors == nil {
+		report.Errors = make([]models.LoadError, 0)
+	}
+	if body.Overlays.Overlays == nil {
+		body.Overlays.Overlays = make([]models.OverlaySummary, 0)
+	}
+	if body.Overlays.Conflicts == nil {
+		body.Overlays.Conflicts = make([]models.OverlayConflict, 0)
+	}
+
+	if err := checkLoadReport(config, body.Dataset.LoadReport); err != nil {
+		return nil, err
+	}
+
+	p := parser.NewParserFromDataset(config.DataDir, body.Dataset)
+	p.SetQuiet(config.Quiet)
+	snap := newSnapshot(config, revision, p, body.Overlays)
+	// The compiled units are served the way WarmUp serves units, to unit, list and search requests
+	units := make([]service.WarmUnit, 0, len(body.Units))
+	for _, compiled := range body.Units {
+		var unit models.UnitResponse
+		if err := json.Unmarshal(compiled.JSON, &unit); err != nil {
+			return nil, fmt.Errorf("failed to read compiled unit %s: %w", compiled.LinkID, err)
+		}
+		units = append(units, service.WarmUnit{CatalogueID: compiled.CatalogueID, LinkID: compiled.LinkID, Unit: &unit})
+	}
+	snap.Units.SetWarmUnits(units)
+	snap.Compiled = true
+	return snap, nil
+}
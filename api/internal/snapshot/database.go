@@ -0,0 +1,55 @@
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"grimoire-api/internal/sqlite"
+)
+
+// Import loads the data and overlays named by config and writes them to a SQLite database at path,
+// which the server can read instead of the XML (Config.Database). Homebrew catalogues are not imported.
+func Import(ctx context.Context, config Config, path string) (*sqlite.Info, error) {
+	config.Homebrew = nil
+	config.CompiledFile = ""
+	config.Database = ""
+	config.WarmUp = false
+
+	snap, err := Load(config)
+	if err != nil {
+		return nil, err
+	}
+	// Transform every unit once up front; the import reads each of them several times
+	snap.WarmUp()
+
+	return sqlite.Import(ctx, path, sqlite.Source{
+		Revision:     snap.Revision,
+		Parser:       snap.Parser,
+		Repositories: snap.Repositories,
+		LoadReport:   snap.LoadReport,
+	})
+}
+
+// loadDatabase opens config.Database as a snapshot. Only the repositories are served from it;
+// everything that needs the parsed XML is nil.
+func loadDatabase(config Config, revision string) (*Snapshot, error) {
+	db, err := sqlite.Open(config.Database)
+	if err != nil {
+		return nil, err
+	}
+	info := db.Info()
+	if info.Revision != revision {
+		db.Close()
+		return nil, fmt.Errorf("database %s changed while it was being opened", config.Database)
+	}
+
+	return &Snapshot{
+		Revision:     revision,
+		LoadedAt:     time.Now().UTC(),
+		Repositories: db.Repositories(),
+		LoadReport:   info.LoadReport,
+		database:     db,
+		quiet:        config.Quiet,
+	}, nil
+}
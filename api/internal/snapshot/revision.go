@@ -0,0 +1,83 @@
+package snapshot
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"grimoire-api/internal/sqlite"
+)
+
+// Revision hashes every file a snapshot of config would be loaded from: the game system and
+// catalogues, the overlays and the homebrew uploads. Equal revisions mean equal data. A snapshot
+// of a database has the revision of the snapshot it was imported from.
+func Revision(config Config) (string, error) {
+	if config.Database != "" {
+		return sqlite.ReadRevision(config.Database)
+	}
+
+	type file struct{ name, path string } // name is relative to its directory, which may move
+	var files []file
+	add := func(label, dir string, exts ...string) error {
+		if dir == "" {
+			return nil
+		}
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				return nil
+			}
+			ext := strings.ToLower(filepath.Ext(path))
+			for _, want := range exts {
+				if ext == want {
+					rel, err := filepath.Rel(dir, path)
+					if err != nil {
+						return err
+					}
+					files = append(files, file{name: label + "/" + filepath.ToSlash(rel), path: path})
+					break
+				}
+			}
+			return nil
+		})
+		if os.IsNotExist(err) && dir != config.DataDir {
+			return nil
+		}
+		return err
+	}
+
+	if err := add("data", config.DataDir, ".gst", ".cat"); err != nil {
+		return "", err
+	}
+	if err := add("overlays", config.OverlayDir, ".json"); err != nil {
+		return "", err
+	}
+	if config.Homebrew != nil {
+		if err := add("homebrew", config.Homebrew.Dir(), ".cat", ".catz"); err != nil {
+			return "", err
+		}
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].name < files[j].name })
+
+	hash := sha256.New()
+	for _, f := range files {
+		content, err := os.Open(f.path)
+		if err != nil {
+			return "", err
+		}
+		io.WriteString(hash, f.name+"\x00")
+		_, err = io.Copy(hash, content)
+		content.Close()
+		if err != nil {
+			return "", err
+		}
+	}
+	return hex.EncodeToString(hash.Sum(nil))[:12], nil
+}
@@ -0,0 +1,393 @@
+// Package snapshot loads the data into immutable snapshots. A snapshot holds a parsed dataset with
+// everything derived from it, and is never changed once published: reloading builds a new one.
+// Requests can pin a snapshot by revision to get consistent results while a reload happens.
+package snapshot
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"grimoire-api/internal/cache"
+	"grimoire-api/internal/homebrew"
+	"grimoire-api/internal/models"
+	"grimoire-api/internal/overlay"
+	"grimoire-api/internal/parser"
+	"grimoire-api/internal/service"
+	"grimoire-api/internal/sqlite"
+)
+
+// DefaultRetained is how many snapshots are kept for pinned requests, including the current one,
+// unless Config.Retained says otherwise. Each holds a whole parsed dataset, so only the previous
+// snapshot is kept for requests that were pinned to it across a reload.
+const DefaultRetained = 2
+
+// Config says where the data of a snapshot comes from
+type Config struct {
+	DataDir    string
+	OverlayDir string             // Optional local overlays
+	Homebrew   *homebrew.Store    // Optional uploaded catalogues
+	Quiet      bool               // Don't log each file loaded
+	Strict     bool               // Fail the load if any catalogue file fails, instead of serving the rest
+	Cache      *cache.Cache       // Shared by the snapshots, which cache under their own revision; optional
+	Diffs      *service.DiffCache // Diffs between commits, shared by the snapshots; optional
+	WarmUp     bool               // Transform every unit: in the background for the first snapshot, before publishing a reload
+	Retained   int                // Snapshots kept for pinned requests, including the current one; DefaultRetained when zero
+
+	// CompiledFile is an optional snapshot written by Compile. It is loaded instead of parsing
+	// the XML while it matches the data and overlays, and ignored once they change.
+	CompiledFile string
+
+	// Database is an optional SQLite database written by Import. When set, snapshots are read from
+	// it instead of the XML; only Repositories are served, and a reload picks up a new import.
+	Database string
+}
+
+// Snapshot is one load of the data. Nothing in it changes after it is published.
+type Snapshot struct {
+	Revision string // Content hash of every file the snapshot was loaded from
+	LoadedAt time.Time
+	Compiled bool // Loaded from or written to a compiled file rather than only parsed
+
+	Parser      *parser.Parser
+	Resolver    *parser.LinkResolver
+	Transformer *parser.Transformer
+	Cache       *cache.Cache // The shared cache, scoped to this snapshot's revision
+
+	// Repositories serve the units, catalogues, factions, rules and game system: the services
+	// below, or the tables of a database. The other fields are nil for a snapshot of a database.
+	Repositories service.Repositories
+
+	Units       *service.UnitService
+	Catalogues  *service.CatalogueService
+	Factions    *service.FactionService
+	Diffs       *service.DiffService
+	DataQuality *service.DataQualityService
+	Overlays    *models.OverlayReport
+	LoadReport  *models.LoadReport // Catalogue files that failed to load, in lenient mode
+
+	database *sqlite.DB // Open while the snapshot is retained, for a snapshot of a database
+
+	quiet                 bool
+	warming               atomic.Bool // Between loading with Config.WarmUp and WarmUp finishing
+	warmedUnits, allUnits atomic.Int64
+}
+
+// Load reads the data, overlays and homebrew catalogues named by config into a new snapshot
+func Load(config Config) (*Snapshot, error) {
+	revision, err := Revision(config)
+	if err != nil {
+		return nil, err
+	}
+	return load(config, revision)
+}
+
+func load(config Config, revision string) (*Snapshot, error) {
+	if config.Database != "" {
+		return loadDatabase(config, revision)
+	}
+	if config.CompiledFile != "" {
+		snap, err := loadCompiled(config, revision)
+		if err == nil {
+			return snap, nil
+		}
+		if !config.Quiet {
+			log.Printf("Not using compiled snapshot %s: %v", config.CompiledFile, err)
+		}
+	}
+
+	p, overlayReport, err := parse(config)
+	if err != nil {
+		return nil, err
+	}
+	return newSnapshot(config, revision, p, overlayReport), nil
+}
+
+// parse reads the game system and catalogues and applies the overlays
+func parse(config Config) (*parser.Parser, *models.OverlayReport, error) {
+	p := parser.NewParser(config.DataDir)
+	p.SetQuiet(config.Quiet)
+	if err := p.LoadGameSystem(); err != nil {
+		return nil, nil, err
+	}
+	if err := p.LoadAllCatalogues(); err != nil {
+		var loadErrs *parser.LoadErrors
+		if !errors.As(err, &loadErrs) {
+			return nil, nil, err
+		}
+		if err := checkLoadReport(config, loadErrs.Report); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// Apply local overlays (errata patches) on top of the data before anything reads it
+	var overlays []*overlay.Overlay
+	if config.OverlayDir != "" {
+		var err error
+		if overlays, err = overlay.LoadDir(config.OverlayDir); err != nil {
+			return nil, nil, err
+		}
+	}
+	overlayReport := overlay.Apply(p, overlays)
+	if !config.Quiet {
+		for _, summary := range overlayReport.Overlays {
+			log.Printf("Applied overlay %s: %d values changed by %d patches", summary.Name, summary.Applied, summary.Patches)
+		}
+	}
+	for _, conflict := range overlayReport.Conflicts {
+		log.Printf("Overlay conflict in %s (%s %s): %s", conflict.File, conflict.TargetID, conflict.Field, conflict.Reason)
+	}
+	return p, overlayReport, nil
+}
+
+// checkLoadReport fails a strict load with catalogue errors, and logs the errors of a lenient one
+func checkLoadReport(config Config, report *models.LoadReport) error {
+	if report == nil || len(report.Errors) == 0 {
+		return nil
+	}
+	if config.Strict {
+		return &parser.LoadErrors{Report: report}
+	}
+	for _, loadErr := range report.Errors {
+		log.Printf("Skipped catalogue %s: %s", loadErr.File, loadErr.Error)
+	}
+	return nil
+}
+
+// newSnapshot loads the homebrew catalogues into p and builds the services on top of it
+func newSnapshot(config Config, revision string, p *parser.Parser, overlayReport *models.OverlayReport) *Snapshot {
+	if config.Homebrew != nil {
+		for _, err := range config.Homebrew.LoadAll(p) {
+			log.Printf("Skipped homebrew catalogue %v", err)
+		}
+	}
+
+	resolver := parser.NewLinkResolver(p)
+	transformer := parser.NewTransformer(resolver)
+	c := config.Cache
+	if c == nil {
+		c = cache.NewCache()
+	}
+	diffs := config.Diffs
+	if diffs == nil {
+		diffs = service.NewDiffCache(c, 0)
+	}
+	c = c.ForRevision(revision)
+
+	snap := &Snapshot{
+		Revision:    revision,
+		LoadedAt:    time.Now().UTC(),
+		Parser:      p,
+		Resolver:    resolver,
+		Transformer: transformer,
+		Cache:       c,
+		Units:       service.NewUnitService(p, resolver, transformer, c),
+		Catalogues:  service.NewCatalogueService(p, resolver, transformer, c),
+		Factions:    service.NewFactionService(p, resolver),
+		Diffs:       service.NewDiffService(p, config.DataDir, diffs),
+		DataQuality: service.NewDataQualityService(p),
+		Overlays:    overlayReport,
+		LoadReport:  p.LoadReport(),
+		quiet:       config.Quiet,
+	}
+	snap.Repositories = service.Repositories{
+		Units:      snap.Units,
+		Catalogues: snap.Catalogues,
+		Factions:   snap.Factions,
+		Rules:      service.NewRuleService(p),
+		GameSystem: service.NewGameSystemService(p),
+	}
+	snap.warming.Store(config.WarmUp)
+	return snap
+}
+
+// WarmUp transforms every unit of the snapshot in parallel, logging progress, so that unit,
+// list and search requests no longer transform them. Requests are served meanwhile. A snapshot of
+// a database has nothing to transform.
+func (s *Snapshot) WarmUp() {
+	if s.Units == nil {
+		return
+	}
+	s.warming.Store(true)
+	start := time.Now()
+	logged := 0
+	units := s.Units.WarmUp(runtime.GOMAXPROCS(0), func(done, total int) {
+		s.warmedUnits.Store(int64(done))
+		s.allUnits.Store(int64(total))
+		// Log every tenth of the way
+		if tenth := done * 10 / total; tenth > logged && !s.quiet {
+			logged = tenth
+			log.Printf("Warming up snapshot %s: %d of %d units", s.Revision, done, total)
+		}
+	})
+	s.warming.Store(false)
+	if !s.quiet {
+		log.Printf("Warmed up snapshot %s: %d units in %s", s.Revision, units, time.Since(start).Round(time.Millisecond))
+	}
+}
+
+// Readiness reports whether the snapshot is still warming up
+func (s *Snapshot) Readiness() models.Readiness {
+	readiness := models.Readiness{
+		Status:   "ready",
+		Revision: s.Revision,
+		Warmed:   int(s.warmedUnits.Load()),
+		Units:    int(s.allUnits.Load()),
+	}
+	if s.warming.Load() {
+		readiness.Status = "warming"
+	}
+	return readiness
+}
+
+// Info describes the snapshot
+func (s *Snapshot) Info() models.SnapshotInfo {
+	info := models.SnapshotInfo{
+		Revision: s.Revision,
+		LoadedAt: s.LoadedAt,
+		Compiled: s.Compiled,
+	}
+	if s.database != nil {
+		dbInfo := s.database.Info()
+		info.Database = true
+		info.Catalogues = dbInfo.Catalogues
+		info.Libraries = dbInfo.Libraries
+		return info
+	}
+	info.Catalogues = len(s.Parser.GetAllCatalogues())
+	info.Libraries = len(s.Parser.GetAllLibraries())
+	return info
+}
+
+// close releases the database of a snapshot that is no longer retained
+func (s *Snapshot) close() {
+	if s.database != nil {
+		s.database.Close()
+	}
+}
+
+// Store publishes snapshots and keeps the most recent ones for pinned requests
+type Store struct {
+	config Config
+
+	reloadMu  sync.Mutex // One reload at a time
+	mu        sync.RWMutex
+	snapshots []*Snapshot // Newest first; the first is current
+}
+
+// NewStore creates a store loading snapshots from config. Call Reload to load the first one.
+func NewStore(config Config) *Store {
+	if config.Cache == nil {
+		config.Cache = cache.NewCache()
+	}
+	if config.Diffs == nil {
+		config.Diffs = service.NewDiffCache(config.Cache, 0)
+	}
+	if config.Retained <= 0 {
+		config.Retained = DefaultRetained
+	}
+	return &Store{config: config}
+}
+
+// Cache returns the cache shared by the store's snapshots
+func (s *Store) Cache() *cache.Cache {
+	return s.config.Cache
+}
+
+// Reload loads a new snapshot and makes it current. When no file has changed since the current
+// snapshot was loaded, the current snapshot is kept and changed is false. When the files are back
+// to those of another retained snapshot, that snapshot becomes current again.
+func (s *Store) Reload() (snap *Snapshot, changed bool, err error) {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	revision, err := Revision(s.config)
+	if err != nil {
+		return nil, false, err
+	}
+	if current := s.Current(); current != nil && current.Revision == revision {
+		return current, false, nil
+	}
+
+	// Going back to the files of a retained snapshot makes that snapshot current again, so no two
+	// retained snapshots ever share a revision
+	snap, retained := s.Get(revision)
+	first := s.Current() == nil
+	if !retained {
+		snap, err = load(s.config, revision)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to load snapshot: %w", err)
+		}
+		// A reload warms the new snapshot up before publishing it, so the current snapshot stays
+		// ready meanwhile. Only the first snapshot is published while it warms up.
+		if s.config.WarmUp && !first {
+			snap.WarmUp()
+		}
+	}
+
+	s.mu.Lock()
+	snapshots := []*Snapshot{snap}
+	for _, other := range s.snapshots {
+		if other != snap {
+			snapshots = append(snapshots, other)
+		}
+	}
+	s.snapshots = snapshots
+	var dropped []*Snapshot
+	if retained := s.config.Retained; len(s.snapshots) > retained {
+		dropped = s.snapshots[retained:]
+		s.snapshots = s.snapshots[:retained]
+	}
+	s.mu.Unlock()
+
+	if s.config.WarmUp && first {
+		go snap.WarmUp()
+	}
+
+	// Nothing can pin a dropped snapshot any more, so its cached values are only taking space
+	for _, old := range dropped {
+		s.config.Cache.ClearRevision(old.Revision)
+		old.close()
+	}
+
+	return snap, true, nil
+}
+
+// Current returns the current snapshot, or nil before the first Reload
+func (s *Store) Current() *Snapshot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if len(s.snapshots) == 0 {
+		return nil
+	}
+	return s.snapshots[0]
+}
+
+// Get returns a retained snapshot by revision
+func (s *Store) Get(revision string) (*Snapshot, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, snap := range s.snapshots {
+		if snap.Revision == revision {
+			return snap, true
+		}
+	}
+	return nil, false
+}
+
+// List describes the retained snapshots, newest first
+func (s *Store) List() []models.SnapshotInfo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	infos := make([]models.SnapshotInfo, 0, len(s.snapshots))
+	for i, snap := range s.snapshots {
+		info := snap.Info()
+		info.Current = i == 0
+		infos = append(infos, info)
+	}
+	return infos
+}
@@ -0,0 +1,354 @@
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"grimoire-api/internal/datatest"
+	"grimoire-api/internal/parser"
+	"grimoire-api/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const fixtureDir = "../../testdata/wh40k-fixture"
+
+func TestRevision(t *testing.T) {
+	overlayDir := t.TempDir()
+	config := Config{DataDir: fixtureDir, OverlayDir: overlayDir}
+
+	first, err := Revision(config)
+	require.NoError(t, err)
+	again, err := Revision(config)
+	require.NoError(t, err)
+	assert.Equal(t, first, again)
+	assert.Len(t, first, 12)
+
+	require.NoError(t, os.WriteFile(filepath.Join(overlayDir, "errata.json"), []byte(`{"patches": []}`), 0o644))
+	changed, err := Revision(config)
+	require.NoError(t, err)
+	assert.NotEqual(t, first, changed)
+
+	_, err = Revision(Config{DataDir: filepath.Join(t.TempDir(), "missing")})
+	assert.Error(t, err)
+}
+
+func TestStoreReload(t *testing.T) {
+	overlayDir := t.TempDir()
+	store := NewStore(Config{DataDir: fixtureDir, OverlayDir: overlayDir, Quiet: true})
+	assert.Nil(t, store.Current())
+
+	first, changed, err := store.Reload()
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Same(t, first, store.Current())
+
+	// Nothing changed, so the current snapshot is kept
+	same, changed, err := store.Reload()
+	require.NoError(t, err)
+	assert.False(t, changed)
+	assert.Same(t, first, same)
+
+	// Each change publishes a new snapshot and older ones stay available up to DefaultRetained
+	var latest *Snapshot
+	for i := 0; i < DefaultRetained; i++ {
+		patch := `{"patches": [{"id": "el-fixture-captain", "costs": {"pts": ` + string(rune('0'+i)) + `}}]}`
+		require.NoError(t, os.WriteFile(filepath.Join(overlayDir, "errata.json"), []byte(patch), 0o644))
+		latest, changed, err = store.Reload()
+		require.NoError(t, err)
+		assert.True(t, changed)
+	}
+
+	assert.Same(t, latest, store.Current())
+	assert.NotSame(t, first.Parser, latest.Parser)
+	_, found := store.Get(first.Revision)
+	assert.False(t, found, "the first snapshot is past the retention limit")
+
+	infos := store.List()
+	require.Len(t, infos, DefaultRetained)
+	assert.True(t, infos[0].Current)
+	assert.Equal(t, latest.Revision, infos[0].Revision)
+	pinned, found := store.Get(infos[1].Revision)
+	require.True(t, found)
+	assert.NotSame(t, latest, pinned)
+}
+
+func TestStoreReloadBackToRetained(t *testing.T) {
+	overlayDir := t.TempDir()
+	errata := filepath.Join(overlayDir, "errata.json")
+	store := NewStore(Config{DataDir: fixtureDir, OverlayDir: overlayDir, Quiet: true, Retained: 2})
+
+	first, _, err := store.Reload()
+	require.NoError(t, err)
+	_, err = first.Units.GetUnit(context.Background(), "el-fixture-captain")
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(errata, []byte(`{"patches": [{"id": "el-fixture-captain", "costs": {"pts": 90}}]}`), 0o644))
+	second, changed, err := store.Reload()
+	require.NoError(t, err)
+	assert.True(t, changed)
+
+	// Going back to the first files makes the first snapshot current again rather than a copy of it
+	require.NoError(t, os.Remove(errata))
+	again, changed, err := store.Reload()
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Same(t, first, again)
+	assert.Same(t, first, store.Current())
+
+	infos := store.List()
+	require.Len(t, infos, 2)
+	assert.Equal(t, first.Revision, infos[0].Revision)
+	assert.Equal(t, second.Revision, infos[1].Revision)
+
+	// Dropping the second snapshot leaves the current one's cached values alone
+	require.NoError(t, os.WriteFile(errata, []byte(`{"patches": []}`), 0o644))
+	_, _, err = store.Reload()
+	require.NoError(t, err)
+	_, found := store.Get(second.Revision)
+	assert.False(t, found)
+	pinned, found := store.Get(first.Revision)
+	require.True(t, found)
+	_, cached := pinned.Cache.GetUnit("el-fixture-captain")
+	assert.True(t, cached)
+}
+
+func TestCompiledSnapshot(t *testing.T) {
+	overlayDir := t.TempDir()
+	patch := `{"patches": [{"id": "el-fixture-captain", "costs": {"pts": 95}}]}`
+	require.NoError(t, os.WriteFile(filepath.Join(overlayDir, "errata.json"), []byte(patch), 0o644))
+	compiledFile := filepath.Join(t.TempDir(), "snapshot.bin")
+	config := Config{DataDir: fixtureDir, OverlayDir: overlayDir, Quiet: true, CompiledFile: compiledFile}
+
+	compiled, err := Compile(config, compiledFile)
+	require.NoError(t, err)
+	parsed, err := Load(Config{DataDir: fixtureDir, OverlayDir: overlayDir, Quiet: true})
+	require.NoError(t, err)
+
+	loaded, err := Load(config)
+	require.NoError(t, err)
+	assert.True(t, loaded.Compiled)
+	assert.Equal(t, compiled.Revision, loaded.Revision)
+	assert.Equal(t, parsed.Revision, loaded.Revision)
+	assert.True(t, loaded.Parser.Overlaid())
+	assert.Len(t, loaded.Parser.GetAllCatalogues(), len(parsed.Parser.GetAllCatalogues()))
+	assert.Len(t, loaded.Parser.GetAllLibraries(), len(parsed.Parser.GetAllLibraries()))
+	assert.Equal(t, parsed.Overlays, loaded.Overlays)
+
+	// Units come out of the compiled file already transformed, are served without transforming
+	// or caching them again, and match freshly parsed ones
+	require.Len(t, loaded.Units.WarmUnits(), len(parsed.Resolver.ResolveRootUnits()))
+	cached, err := loaded.Units.GetUnit(context.Background(), "el-fixture-captain")
+	require.NoError(t, err)
+	assert.Equal(t, 95, cached.Costs["pts"])
+	_, inCache := loaded.Cache.GetUnit("el-fixture-captain")
+	assert.False(t, inCache)
+	want, err := parsed.Units.GetUnit(context.Background(), "el-fixture-captain")
+	require.NoError(t, err)
+	wantJSON, err := json.Marshal(want)
+	require.NoError(t, err)
+	cachedJSON, err := json.Marshal(cached)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(wantJSON), string(cachedJSON))
+
+	// Everything else still works from the restored data
+	units, total, err := loaded.Units.ListUnits(context.Background(), service.UnitQuery{})
+	require.NoError(t, err)
+	wantUnits, wantTotal, err := parsed.Units.ListUnits(context.Background(), service.UnitQuery{})
+	require.NoError(t, err)
+	assert.Equal(t, wantTotal, total)
+	assert.Equal(t, wantUnits, units)
+
+	// Once the overlays change the compiled file is stale, and the data is parsed instead
+	patch = `{"patches": [{"id": "el-fixture-captain", "costs": {"pts": 100}}]}`
+	require.NoError(t, os.WriteFile(filepath.Join(overlayDir, "errata.json"), []byte(patch), 0o644))
+	reparsed, err := Load(config)
+	require.NoError(t, err)
+	assert.False(t, reparsed.Compiled)
+	unit, err := reparsed.Units.GetUnit(context.Background(), "el-fixture-captain")
+	require.NoError(t, err)
+	assert.Equal(t, 100, unit.Costs["pts"])
+
+	// As is a file that isn't a compiled snapshot at all
+	require.NoError(t, os.WriteFile(compiledFile, []byte("not a snapshot"), 0o644))
+	fallback, err := Load(config)
+	require.NoError(t, err)
+	assert.False(t, fallback.Compiled)
+}
+
+func TestLoadModes(t *testing.T) {
+	dataDir := datatest.CopyFixture(t)
+
+	clean, err := Load(Config{DataDir: dataDir, Quiet: true, Strict: true})
+	require.NoError(t, err)
+	assert.Equal(t, 4, clean.LoadReport.Loaded)
+	assert.Empty(t, clean.LoadReport.Errors)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dataDir, "Broken.cat"), []byte("<catalogue"), 0o644))
+
+	// Strict refuses the data
+	_, err = Load(Config{DataDir: dataDir, Quiet: true, Strict: true})
+	var loadErrs *parser.LoadErrors
+	require.ErrorAs(t, err, &loadErrs)
+	assert.Equal(t, "Broken.cat", loadErrs.Report.Errors[0].File)
+
+	// Lenient serves everything that parsed and reports the rest
+	lenient, err := Load(Config{DataDir: dataDir, Quiet: true})
+	require.NoError(t, err)
+	assert.Equal(t, 5, lenient.LoadReport.Files)
+	assert.Equal(t, 4, lenient.LoadReport.Loaded)
+	require.Len(t, lenient.LoadReport.Errors, 1)
+	assert.Len(t, lenient.Parser.GetAllCatalogues(), len(clean.Parser.GetAllCatalogues()))
+
+	// A compiled snapshot keeps the report, and strict mode won't load one compiled from bad data
+	compiledFile := filepath.Join(t.TempDir(), "snapshot.bin")
+	_, err = Compile(Config{DataDir: dataDir, Quiet: true}, compiledFile)
+	require.NoError(t, err)
+	compiled, err := Load(Config{DataDir: dataDir, Quiet: true, CompiledFile: compiledFile})
+	require.NoError(t, err)
+	assert.True(t, compiled.Compiled)
+	assert.Equal(t, lenient.LoadReport.Errors, compiled.LoadReport.Errors)
+	_, err = Load(Config{DataDir: dataDir, Quiet: true, Strict: true, CompiledFile: compiledFile})
+	assert.ErrorAs(t, err, &loadErrs)
+}
+
+func TestWarmUpReadiness(t *testing.T) {
+	overlayDir := t.TempDir()
+	store := NewStore(Config{DataDir: fixtureDir, OverlayDir: overlayDir, Quiet: true, WarmUp: true})
+	snap, _, err := store.Reload()
+	require.NoError(t, err)
+
+	// Warm-up runs in the background after the snapshot is published
+	assert.Eventually(t, func() bool {
+		return snap.Readiness().Status == "ready"
+	}, 5*time.Second, 10*time.Millisecond)
+	readiness := snap.Readiness()
+	assert.Equal(t, snap.Revision, readiness.Revision)
+	assert.Equal(t, len(snap.Resolver.ResolveRootUnits()), readiness.Units)
+	assert.Equal(t, readiness.Units, readiness.Warmed)
+
+	// A reload is warmed up before it is published, so the current snapshot is always ready
+	require.NoError(t, os.WriteFile(filepath.Join(overlayDir, "errata.json"), []byte(`{"patches": []}`), 0o644))
+	reloaded, changed, err := store.Reload()
+	require.NoError(t, err)
+	require.True(t, changed)
+	assert.Same(t, reloaded, store.Current())
+	assert.Equal(t, "ready", store.Current().Readiness().Status)
+	assert.Equal(t, readiness.Units, reloaded.Readiness().Warmed)
+
+	// Without warm-up a snapshot is ready as soon as it is loaded
+	cold, err := Load(Config{DataDir: fixtureDir, Quiet: true})
+	require.NoError(t, err)
+	assert.Equal(t, "ready", cold.Readiness().Status)
+	assert.Zero(t, cold.Readiness().Units)
+}
+
+func TestDatabaseSnapshot(t *testing.T) {
+	ctx := context.Background()
+	database := filepath.Join(t.TempDir(), "grimoire.db")
+	info, err := Import(ctx, Config{DataDir: fixtureDir, Quiet: true}, database)
+	require.NoError(t, err)
+	parsed, err := Load(Config{DataDir: fixtureDir, Quiet: true})
+	require.NoError(t, err)
+	assert.Equal(t, parsed.Revision, info.Revision)
+
+	config := Config{DataDir: fixtureDir, Quiet: true, Database: database}
+	revision, err := Revision(config)
+	require.NoError(t, err)
+	assert.Equal(t, parsed.Revision, revision)
+
+	loaded, err := Load(config)
+	require.NoError(t, err)
+	defer loaded.close()
+	assert.Nil(t, loaded.Parser)
+	assert.True(t, loaded.Info().Database)
+	assert.Equal(t, parsed.Info().Catalogues, loaded.Info().Catalogues)
+	assert.Equal(t, parsed.Info().Libraries, loaded.Info().Libraries)
+	loaded.WarmUp()
+	assert.Equal(t, "ready", loaded.Readiness().Status)
+
+	// Every repository answers from the database as the services do from the XML
+	sameJSON := func(name string, read func(service.Repositories) (interface{}, error)) {
+		t.Helper()
+		want, wantErr := read(parsed.Repositories)
+		got, err := read(loaded.Repositories)
+		if wantErr != nil {
+			require.Error(t, err, name)
+			assert.Equal(t, wantErr.Error(), err.Error(), name)
+			return
+		}
+		require.NoError(t, err, name)
+		wantJSON, err := json.Marshal(want)
+		require.NoError(t, err)
+		gotJSON, err := json.Marshal(got)
+		require.NoError(t, err)
+		assert.JSONEq(t, string(wantJSON), string(gotJSON), name)
+	}
+
+	for _, id := range []string{"el-fixture-captain", "se-fixture-captain", "el-fixture-land-raider", "missing"} {
+		sameJSON("unit "+id, func(r service.Repositories) (interface{}, error) { return r.Units.GetUnit(ctx, id) })
+		sameJSON("weapons "+id, func(r service.Repositories) (interface{}, error) { return r.Units.GetUnitWeapons(ctx, id) })
+	}
+
+	queries := map[string]service.UnitQuery{
+		"all":             {},
+		"points desc":     {Sort: service.SortByPoints, Descending: true},
+		"toughness":       {Sort: service.SortByToughness},
+		"catalogue":       {Sort: service.SortByCatalogue, Limit: 3, Offset: 2},
+		"by catalogue":    {Catalogues: []string{"Imperium - Fixture Marines"}},
+		"by faction":      {Factions: []string{"Adeptus Astartes"}},
+		"by superfaction": {Factions: []string{"chaos"}},
+		"unknown faction": {Factions: []string{"Necrons"}},
+		"by category":     {Categories: []string{"Character"}},
+		"search":          {Search: "blood"},
+		"legends only":    {Legends: service.LegendsOnly},
+		"without legends": {Legends: service.LegendsExclude},
+		"points range":    {MinPoints: 100, MaxPoints: 240},
+		"invalid sort":    {Sort: "colour"},
+	}
+	for name, query := range queries {
+		query := query
+		sameJSON("units "+name, func(r service.Repositories) (interface{}, error) {
+			units, total, warnings, err := r.Units.ListUnitsWithWarnings(ctx, query)
+			return []interface{}{units, total, warnings}, err
+		})
+	}
+	sameJSON("search", func(r service.Repositories) (interface{}, error) {
+		// Matches are ordered the same way, so a limit cuts both at the same unit
+		results, warnings, err := r.Units.SearchUnitsWithWarnings(ctx, "Fixture", 3)
+		return []interface{}{results, warnings}, err
+	})
+
+	sameJSON("catalogues", func(r service.Repositories) (interface{}, error) {
+		catalogues, err := r.Catalogues.ListCatalogues(ctx)
+		// The services list catalogues in map order
+		sort.Slice(catalogues, func(i, j int) bool { return catalogues[i].Name < catalogues[j].Name })
+		return catalogues, err
+	})
+	for _, id := range []string{"cat-fixture-marines", "missing"} {
+		sameJSON("catalogue "+id, func(r service.Repositories) (interface{}, error) { return r.Catalogues.GetCatalogue(ctx, id) })
+		sameJSON("catalogue units "+id, func(r service.Repositories) (interface{}, error) {
+			units, warnings, err := r.Catalogues.GetCatalogueUnitsWithWarnings(ctx, id)
+			return []interface{}{units, warnings}, err
+		})
+	}
+
+	sameJSON("factions", func(r service.Repositories) (interface{}, error) { return r.Factions.ListFactions(ctx) })
+	for _, query := range []string{"Adeptus Astartes", "imperium", "cat-fixture-daemons", "Necrons"} {
+		sameJSON("faction "+query, func(r service.Repositories) (interface{}, error) { return r.Factions.GetFaction(ctx, query) })
+		sameJSON("find factions "+query, func(r service.Repositories) (interface{}, error) { return r.Factions.FindFactions(ctx, query) })
+	}
+
+	sameJSON("rules", func(r service.Repositories) (interface{}, error) { return r.Rules.ListRules(ctx) })
+	sameJSON("rule", func(r service.Repositories) (interface{}, error) {
+		return r.Rules.GetRule(ctx, "rule-fixture-deep-strike")
+	})
+	sameJSON("missing rule", func(r service.Repositories) (interface{}, error) { return r.Rules.GetRule(ctx, "missing") })
+	sameJSON("game system", func(r service.Repositories) (interface{}, error) { return r.GameSystem.GetGameSystem(ctx) })
+}
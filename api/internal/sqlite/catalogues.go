@@ -0,0 +1,68 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"grimoire-api/internal/models"
+)
+
+// catalogueRepository reads the catalogues table
+type catalogueRepository struct {
+	db *sql.DB
+}
+
+// ListCatalogues lists all catalogues
+func (r *catalogueRepository) ListCatalogues(ctx context.Context) ([]models.CatalogueInfo, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT id, name, revision, library, homebrew FROM catalogues ORDER BY position`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	catalogues := make([]models.CatalogueInfo, 0)
+	for rows.Next() {
+		var info models.CatalogueInfo
+		if err := rows.Scan(&info.ID, &info.Name, &info.Revision, &info.Library, &info.Homebrew); err != nil {
+			return nil, err
+		}
+		catalogues = append(catalogues, info)
+	}
+	return catalogues, rows.Err()
+}
+
+// GetCatalogue retrieves a catalogue by ID
+func (r *catalogueRepository) GetCatalogue(ctx context.Context, id string) (*models.CatalogueResponse, error) {
+	var catalogue models.CatalogueResponse
+	notFound := fmt.Errorf("catalogue not found: %s", id)
+	if err := queryDocument(ctx, r.db, &catalogue, notFound, `SELECT data FROM catalogues WHERE id = ?`, id); err != nil {
+		return nil, err
+	}
+	return &catalogue, nil
+}
+
+// GetCatalogueUnitsWithWarnings retrieves the units at the root of a catalogue, with a warning for
+// each entryLink that didn't resolve
+func (r *catalogueRepository) GetCatalogueUnitsWithWarnings(ctx context.Context, id string) ([]models.UnitSummary, []models.ResolutionWarning, error) {
+	var units, warnings string
+	err := r.db.QueryRowContext(ctx, `SELECT units, warnings FROM catalogues WHERE id = ?`, id).Scan(&units, &warnings)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil, fmt.Errorf("catalogue not found: %s", id)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var summaries []models.UnitSummary
+	if err := json.Unmarshal([]byte(units), &summaries); err != nil {
+		return nil, nil, err
+	}
+	var unresolved []models.ResolutionWarning
+	if err := json.Unmarshal([]byte(warnings), &unresolved); err != nil {
+		return nil, nil, err
+	}
+	return summaries, unresolved, nil
+}
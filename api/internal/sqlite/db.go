@@ -0,0 +1,175 @@
+// Package sqlite stores the API's data in a SQLite database and serves it from there. Import
+// writes the database from a parsed snapshot; Open reads it back as repositories, so a server can
+// start without parsing the XML and keeps little of the data in memory. The driver is pure Go.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+
+	"grimoire-api/internal/models"
+	"grimoire-api/internal/service"
+)
+
+// Meta keys written by Import
+const (
+	metaSchemaVersion = "schema_version"
+	metaRevision      = "revision"
+	metaImportedAt    = "imported_at"
+	metaCatalogues    = "catalogues"
+	metaLibraries     = "libraries"
+	metaUnits         = "units"
+	metaLoadReport    = "load_report"
+)
+
+// Info describes an imported database
+type Info struct {
+	Revision   string // Revision of the snapshot it was imported from
+	ImportedAt time.Time
+	Catalogues int
+	Libraries  int
+	Units      int                // Root units of the catalogues
+	LoadReport *models.LoadReport // Catalogue files that failed to load when it was imported
+}
+
+// DB is an imported database opened read-only
+type DB struct {
+	db   *sql.DB
+	info Info
+}
+
+// Open opens a database written by Import
+func Open(path string) (*DB, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("sqlite", dsn(path, "mode=ro", "_pragma=busy_timeout(5000)"))
+	if err != nil {
+		return nil, err
+	}
+
+	meta, err := readMeta(db)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to read database %s: %w", path, err)
+	}
+	if meta[metaSchemaVersion] != strconv.Itoa(SchemaVersion) {
+		db.Close()
+		return nil, fmt.Errorf("database %s has schema version %s, not %d; import it again", path, meta[metaSchemaVersion], SchemaVersion)
+	}
+
+	info := Info{Revision: meta[metaRevision], LoadReport: &models.LoadReport{}}
+	info.ImportedAt, _ = time.Parse(time.RFC3339, meta[metaImportedAt])
+	info.Catalogues, _ = strconv.Atoi(meta[metaCatalogues])
+	info.Libraries, _ = strconv.Atoi(meta[metaLibraries])
+	info.Units, _ = strconv.Atoi(meta[metaUnits])
+	if report := meta[metaLoadReport]; report != "" {
+		if err := json.Unmarshal([]byte(report), info.LoadReport); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to read database %s: %w", path, err)
+		}
+	}
+
+	return &DB{db: db, info: info}, nil
+}
+
+// ReadRevision returns the revision of the snapshot a database was imported from
+func ReadRevision(path string) (string, error) {
+	db, err := Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer db.Close()
+	return db.Info().Revision, nil
+}
+
+// Info describes the database
+func (d *DB) Info() Info {
+	return d.info
+}
+
+// Close closes the database once the queries running on it finish
+func (d *DB) Close() error {
+	return d.db.Close()
+}
+
+// Repositories returns the repositories reading the database
+func (d *DB) Repositories() service.Repositories {
+	return service.Repositories{
+		Units:      &unitRepository{db: d.db},
+		Catalogues: &catalogueRepository{db: d.db},
+		Factions:   &factionRepository{db: d.db},
+		Rules:      &ruleRepository{db: d.db},
+		GameSystem: &gameSystemRepository{db: d.db},
+	}
+}
+
+// dsn builds the data source name of the database at path with query parameters
+func dsn(path string, params ...string) string {
+	return (&url.URL{Scheme: "file", OmitHost: true, Path: path, RawQuery: strings.Join(params, "&")}).String()
+}
+
+func readMeta(db *sql.DB) (map[string]string, error) {
+	rows, err := db.Query(`SELECT key, value FROM meta`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	meta := make(map[string]string)
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, err
+		}
+		meta[key] = value
+	}
+	return meta, rows.Err()
+}
+
+// queryDocument decodes the JSON document in the first column of the first row of a query into
+// v, returning notFound when there is no row
+func queryDocument(ctx context.Context, db *sql.DB, v interface{}, notFound error, query string, args ...interface{}) error {
+	var data string
+	err := db.QueryRowContext(ctx, query, args...).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return notFound
+	}
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(data), v)
+}
+
+// queryDocuments decodes the JSON document in the first column of each row of a query
+func queryDocuments[T any](ctx context.Context, db *sql.DB, query string, args ...interface{}) ([]T, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var documents []T
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		var document T
+		if err := json.Unmarshal([]byte(data), &document); err != nil {
+			return nil, err
+		}
+		documents = append(documents, document)
+	}
+	return documents, rows.Err()
+}
@@ -0,0 +1,84 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"grimoire-api/internal/models"
+)
+
+// factionRepository reads the factions table
+type factionRepository struct {
+	db *sql.DB
+}
+
+// ListFactions lists all factions sorted by name
+func (r *factionRepository) ListFactions(ctx context.Context) ([]models.FactionResponse, error) {
+	factions, err := queryDocuments[models.FactionResponse](ctx, r.db, `SELECT data FROM factions ORDER BY position`)
+	if factions == nil && err == nil {
+		factions = []models.FactionResponse{}
+	}
+	return factions, err
+}
+
+// GetFaction retrieves a faction by ID, name, keyword or catalogue
+func (r *factionRepository) GetFaction(ctx context.Context, query string) (*models.FactionResponse, error) {
+	factions, err := r.ListFactions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range factions {
+		if factionMatches(&factions[i], query) {
+			return &factions[i], nil
+		}
+	}
+	return nil, fmt.Errorf("faction not found: %s", query)
+}
+
+// FindFactions returns factions matching query, including every faction of a super-faction
+func (r *factionRepository) FindFactions(ctx context.Context, query string) ([]models.FactionResponse, error) {
+	factions, err := r.ListFactions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return findFactions(factions, query), nil
+}
+
+// findFactions returns the factions query identifies directly or, when there are none, the
+// factions of the super-faction it names. It matches like the parser's LinkResolver.FindFactions.
+func findFactions(factions []models.FactionResponse, query string) []models.FactionResponse {
+	matched := make([]models.FactionResponse, 0)
+	for i := range factions {
+		if factionMatches(&factions[i], query) {
+			matched = append(matched, factions[i])
+		}
+	}
+	if len(matched) > 0 {
+		return matched
+	}
+
+	for _, faction := range factions {
+		if faction.SuperFaction != "" && strings.EqualFold(faction.SuperFaction, query) {
+			matched = append(matched, faction)
+		}
+	}
+	return matched
+}
+
+// factionMatches reports whether query is the faction's ID, name or keyword, or one of its catalogues
+func factionMatches(faction *models.FactionResponse, query string) bool {
+	if faction.ID == query || strings.EqualFold(faction.Name, query) {
+		return true
+	}
+	if faction.Keyword != nil && strings.EqualFold(faction.Keyword.Name, query) {
+		return true
+	}
+	for _, cat := range append([]models.CatalogueInfo{faction.Catalogue}, faction.SubFactions...) {
+		if cat.ID == query || strings.EqualFold(cat.Name, query) {
+			return true
+		}
+	}
+	return false
+}
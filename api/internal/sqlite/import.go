@@ -0,0 +1,362 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"grimoire-api/internal/models"
+	"grimoire-api/internal/parser"
+	"grimoire-api/internal/service"
+)
+
+// Source is what Import copies: the repositories of a snapshot parsed from the XML, and the
+// parser for the categories of links that didn't resolve, which no repository returns
+type Source struct {
+	Revision     string
+	Parser       *parser.Parser
+	Repositories service.Repositories
+	LoadReport   *models.LoadReport
+}
+
+// Import writes the data of src to a new database at path, replacing any database there once
+// it is complete, and returns its description
+func Import(ctx context.Context, path string, src Source) (*Info, error) {
+	// Write to a temporary file first, so a running server never reads half a database
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create database: %w", err)
+	}
+	tmp.Close()
+	defer os.Remove(tmp.Name())
+
+	db, err := sql.Open("sqlite", dsn(tmp.Name(), "_pragma=journal_mode(OFF)", "_pragma=synchronous(OFF)"))
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	if _, err := db.ExecContext(ctx, schema); err != nil {
+		return nil, fmt.Errorf("failed to create tables: %w", err)
+	}
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	w := &importer{ctx: ctx, tx: tx, src: src}
+	info := &Info{
+		Revision:   src.Revision,
+		ImportedAt: time.Now().UTC().Truncate(time.Second),
+		Catalogues: len(src.Parser.GetAllCatalogues()),
+		Libraries:  len(src.Parser.GetAllLibraries()),
+		LoadReport: src.LoadReport,
+	}
+	steps := []func() error{w.gameSystem, w.catalogues, w.factions, w.units, w.rules}
+	for _, step := range steps {
+		if err := step(); err != nil {
+			return nil, fmt.Errorf("failed to import: %w", err)
+		}
+	}
+	info.Units = w.unitCount
+	if err := w.meta(info); err != nil {
+		return nil, fmt.Errorf("failed to import: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to write database: %w", err)
+	}
+	if err := db.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write database: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return nil, fmt.Errorf("failed to write database: %w", err)
+	}
+	return info, nil
+}
+
+// importer writes the tables of one import
+type importer struct {
+	ctx context.Context
+	tx  *sql.Tx
+	src Source
+
+	factionList []models.FactionResponse
+	unitCount   int
+}
+
+func (w *importer) exec(query string, args ...interface{}) error {
+	_, err := w.tx.ExecContext(w.ctx, query, args...)
+	return err
+}
+
+func (w *importer) gameSystem() error {
+	gameSystem, err := w.src.Repositories.GameSystem.GetGameSystem(w.ctx)
+	if err != nil {
+		return err
+	}
+	return w.exec(`INSERT INTO game_system (id, name, revision, data) VALUES (?, ?, ?, ?)`,
+		gameSystem.ID, gameSystem.Name, gameSystem.Revision, document(gameSystem))
+}
+
+func (w *importer) catalogues() error {
+	catalogues, err := w.src.Repositories.Catalogues.ListCatalogues(w.ctx)
+	if err != nil {
+		return err
+	}
+	sort.Slice(catalogues, func(i, j int) bool {
+		if catalogues[i].Name != catalogues[j].Name {
+			return catalogues[i].Name < catalogues[j].Name
+		}
+		return catalogues[i].ID < catalogues[j].ID
+	})
+
+	for position, info := range catalogues {
+		catalogue, err := w.src.Repositories.Catalogues.GetCatalogue(w.ctx, info.ID)
+		if err != nil {
+			return err
+		}
+		units, warnings, err := w.src.Repositories.Catalogues.GetCatalogueUnitsWithWarnings(w.ctx, info.ID)
+		if err != nil {
+			return err
+		}
+		err = w.exec(`INSERT INTO catalogues (id, position, name, revision, library, homebrew, data, units, warnings) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			info.ID, position, info.Name, info.Revision, info.Library, info.Homebrew, document(catalogue), document(units), document(warnings))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *importer) factions() error {
+	factions, err := w.src.Repositories.Factions.ListFactions(w.ctx)
+	if err != nil {
+		return err
+	}
+	w.factionList = factions
+
+	for position, faction := range factions {
+		var keywordID interface{}
+		if faction.Keyword != nil {
+			keywordID = faction.Keyword.ID
+		}
+		err := w.exec(`INSERT INTO factions (id, position, name, super_faction, keyword_id, catalogue_id, data) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			faction.ID, position, faction.Name, faction.SuperFaction, keywordID, faction.Catalogue.ID, document(faction))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// units writes every root unit as /api/v1/units lists it, with its full response, categories,
+// weapons and factions, and the root links that didn't resolve
+func (w *importer) units() error {
+	units := w.src.Repositories.Units
+	summaries, _, warnings, err := units.ListUnitsWithWarnings(w.ctx, service.UnitQuery{})
+	if err != nil {
+		return err
+	}
+
+	responses := make(map[string]bool)
+	addResponse := func(id string, unit *models.UnitResponse) error {
+		if responses[id] {
+			return nil
+		}
+		responses[id] = true
+		return w.exec(`INSERT INTO unit_responses (id, data) VALUES (?, ?)`, id, document(unit))
+	}
+
+	for _, summary := range summaries {
+		catalogueID := summary.Catalogue.ID
+		unit, err := units.GetUnitWithWarnings(w.ctx, summary.ID)
+		if err != nil {
+			return err
+		}
+		if err := addResponse(summary.ID, unit); err != nil {
+			return err
+		}
+		// The unit is also looked up by the ID of the selectionEntry it links to
+		if summary.TargetID != "" && !responses[summary.TargetID] {
+			if entry, err := units.GetUnitWithWarnings(w.ctx, summary.TargetID); err == nil {
+				if err := addResponse(summary.TargetID, entry); err != nil {
+					return err
+				}
+			}
+		}
+
+		var stats models.UnitProfile
+		if unit.Profiles != nil && unit.Profiles.Unit != nil {
+			stats = *unit.Profiles.Unit
+		}
+		err = w.exec(`INSERT INTO units (catalogue_id, id, target_id, name, sort_name, type, points, toughness, wounds, objective_control, summary)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			catalogueID, summary.ID, summary.TargetID, summary.Name, strings.ToLower(summary.Name), summary.Type,
+			summary.Costs["pts"], stats.Toughness, stats.Wounds, stats.ObjectiveControl, document(summary))
+		if err != nil {
+			return err
+		}
+
+		categoryIDs := make([]string, 0, len(unit.Categories))
+		for _, category := range unit.Categories {
+			categoryIDs = append(categoryIDs, category.ID)
+			err := w.exec(`INSERT INTO unit_categories (catalogue_id, unit_id, category_id, name, is_primary) VALUES (?, ?, ?, ?, ?)`,
+				catalogueID, summary.ID, category.ID, category.Name, category.Primary)
+			if err != nil {
+				return err
+			}
+		}
+		if err := w.weapons(catalogueID, summary.ID, unit.Weapons); err != nil {
+			return err
+		}
+		for _, factionID := range w.factionsOf(catalogueID, categoryIDs) {
+			if err := w.exec(`INSERT INTO unit_factions (catalogue_id, unit_id, faction_id) VALUES (?, ?, ?)`, catalogueID, summary.ID, factionID); err != nil {
+				return err
+			}
+		}
+	}
+	w.unitCount = len(summaries)
+
+	for position, warning := range warnings {
+		var name string
+		var categoryIDs []string
+		if entryLink := w.rootLink(warning.CatalogueID, warning.LinkID); entryLink != nil {
+			name = entryLink.Name
+			for _, catLink := range entryLink.CategoryLinks {
+				categoryIDs = append(categoryIDs, catLink.TargetID)
+			}
+		}
+		err := w.exec(`INSERT INTO unresolved_links (catalogue_id, link_id, position, name, warning) VALUES (?, ?, ?, ?, ?)`,
+			warning.CatalogueID, warning.LinkID, position, name, document(warning))
+		if err != nil {
+			return err
+		}
+		// Only the link's own categories are known
+		for _, factionID := range w.factionsOf(warning.CatalogueID, categoryIDs) {
+			err := w.exec(`INSERT INTO unresolved_link_factions (catalogue_id, link_id, faction_id) VALUES (?, ?, ?)`, warning.CatalogueID, warning.LinkID, factionID)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (w *importer) weapons(catalogueID, unitID string, weapons *models.WeaponSet) error {
+	if weapons == nil {
+		return nil
+	}
+	const insert = `INSERT INTO unit_weapons (catalogue_id, unit_id, type, name, range, attacks, skill, strength, armor_penetration, damage, keywords)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	for _, weapon := range weapons.Ranged {
+		err := w.exec(insert, catalogueID, unitID, "ranged", weapon.Name, weapon.Range, weapon.Attacks, weapon.BallisticSkill,
+			weapon.Strength, weapon.ArmorPenetration, weapon.Damage, strings.Join(weapon.Keywords, ", "))
+		if err != nil {
+			return err
+		}
+	}
+	for _, weapon := range weapons.Melee {
+		err := w.exec(insert, catalogueID, unitID, "melee", weapon.Name, weapon.Range, weapon.Attacks, weapon.WeaponSkill,
+			weapon.Strength, weapon.ArmorPenetration, weapon.Damage, strings.Join(weapon.Keywords, ", "))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// factionsOf returns the factions a unit of a catalogue with the given categories belongs to, as
+// the unit service's faction filter decides: by faction keyword, or by catalogue for factions
+// without one
+func (w *importer) factionsOf(catalogueID string, categoryIDs []string) []string {
+	var ids []string
+	for _, faction := range w.factionList {
+		if faction.Keyword != nil {
+			for _, id := range categoryIDs {
+				if id == faction.Keyword.ID {
+					ids = append(ids, faction.ID)
+					break
+				}
+			}
+			continue
+		}
+		for _, cat := range append([]models.CatalogueInfo{faction.Catalogue}, faction.SubFactions...) {
+			if cat.ID == catalogueID {
+				ids = append(ids, faction.ID)
+				break
+			}
+		}
+	}
+	return ids
+}
+
+// rootLink returns a root entryLink of a catalogue, or nil when there is none with linkID
+func (w *importer) rootLink(catalogueID, linkID string) *models.EntryLink {
+	catalogue, exists := w.src.Parser.GetCatalogue(catalogueID)
+	if !exists {
+		return nil
+	}
+	for i := range catalogue.EntryLinks {
+		if catalogue.EntryLinks[i].ID == linkID {
+			return &catalogue.EntryLinks[i]
+		}
+	}
+	return nil
+}
+
+func (w *importer) rules() error {
+	rules, err := w.src.Repositories.Rules.ListRules(w.ctx)
+	if err != nil {
+		return err
+	}
+	for position, rule := range rules {
+		var catalogueID interface{}
+		if rule.Catalogue != nil {
+			catalogueID = rule.Catalogue.ID
+		}
+		err := w.exec(`INSERT INTO rules (id, position, name, description, catalogue_id, data) VALUES (?, ?, ?, ?, ?, ?)`,
+			rule.ID, position, rule.Name, rule.Description, catalogueID, document(rule))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *importer) meta(info *Info) error {
+	meta := map[string]string{
+		metaSchemaVersion: strconv.Itoa(SchemaVersion),
+		metaRevision:      info.Revision,
+		metaImportedAt:    info.ImportedAt.Format(time.RFC3339),
+		metaCatalogues:    strconv.Itoa(info.Catalogues),
+		metaLibraries:     strconv.Itoa(info.Libraries),
+		metaUnits:         strconv.Itoa(info.Units),
+	}
+	if info.LoadReport != nil {
+		meta[metaLoadReport] = document(info.LoadReport)
+	}
+	for key, value := range meta {
+		if err := w.exec(`INSERT INTO meta (key, value) VALUES (?, ?)`, key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// document encodes a value stored as JSON. The models always encode.
+func document(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(fmt.Sprintf("failed to encode %T: %v", v, err))
+	}
+	return string(data)
+}
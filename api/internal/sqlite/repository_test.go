@@ -0,0 +1,148 @@
+package sqlite_test
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"grimoire-api/internal/datatest"
+	"grimoire-api/internal/service"
+	"grimoire-api/internal/snapshot"
+	"grimoire-api/internal/sqlite"
+)
+
+// openFixture imports the fixture into a new database and returns the repositories of the parsed
+// fixture and of the database
+func openFixture(t *testing.T) (memory, database service.Repositories) {
+	t.Helper()
+	config := snapshot.Config{DataDir: datatest.FixtureDir(), Quiet: true}
+	path := filepath.Join(t.TempDir(), "grimoire.db")
+	_, err := snapshot.Import(context.Background(), config, path)
+	require.NoError(t, err)
+
+	db, err := sqlite.Open(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	parsed, err := snapshot.Load(config)
+	require.NoError(t, err)
+	return parsed.Repositories, db.Repositories()
+}
+
+// assertSame checks that read answers the same from both repositories, comparing results as JSON
+func assertSame(t *testing.T, memory, database service.Repositories, read func(service.Repositories) (interface{}, error)) {
+	t.Helper()
+	want, wantErr := read(memory)
+	got, err := read(database)
+	if wantErr != nil {
+		require.Error(t, err)
+		assert.Equal(t, wantErr.Error(), err.Error())
+		return
+	}
+	require.NoError(t, err)
+
+	wantJSON, err := json.Marshal(want)
+	require.NoError(t, err)
+	gotJSON, err := json.Marshal(got)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(wantJSON), string(gotJSON))
+}
+
+func TestListUnitsMatchesService(t *testing.T) {
+	memory, database := openFixture(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name  string
+		query service.UnitQuery
+	}{
+		{"all", service.UnitQuery{}},
+
+		// Filters
+		{"catalogue by ID", service.UnitQuery{Catalogues: []string{"cat-fixture-daemons"}}},
+		{"catalogue by name", service.UnitQuery{Catalogues: []string{"imperium - fixture marines"}}},
+		{"several catalogues", service.UnitQuery{Catalogues: []string{"cat-fixture-daemons", "Imperium - Fixture Blood Angels"}}},
+		{"faction", service.UnitQuery{Factions: []string{"Adeptus Astartes"}}},
+		{"super faction", service.UnitQuery{Factions: []string{"chaos"}}},
+		{"category", service.UnitQuery{Categories: []string{"Character"}}},
+		{"search", service.UnitQuery{Search: "BLOOD"}},
+		{"legends only", service.UnitQuery{Legends: service.LegendsOnly}},
+		{"without legends", service.UnitQuery{Legends: service.LegendsExclude}},
+		{"points range", service.UnitQuery{MinPoints: 100, MaxPoints: 240}},
+		{"filters combined", service.UnitQuery{Factions: []string{"Imperium"}, MaxPoints: 200, Legends: service.LegendsExclude}},
+
+		// Unknown faction values match nothing, and report no warnings
+		{"unknown faction", service.UnitQuery{Factions: []string{"Necrons"}}},
+		{"unknown and known faction", service.UnitQuery{Factions: []string{"Necrons", "Legiones Daemonica"}}},
+
+		// Sorts, with Captain and Intercessors tied on points
+		{"name descending", service.UnitQuery{Sort: service.SortByName, Descending: true}},
+		{"points", service.UnitQuery{Sort: service.SortByPoints}},
+		{"points descending", service.UnitQuery{Sort: service.SortByPoints, Descending: true}},
+		{"toughness", service.UnitQuery{Sort: service.SortByToughness}},
+		{"wounds descending", service.UnitQuery{Sort: service.SortByWounds, Descending: true}},
+		{"objective control", service.UnitQuery{Sort: service.SortByOC}},
+		{"catalogue", service.UnitQuery{Sort: service.SortByCatalogue}},
+		{"invalid sort", service.UnitQuery{Sort: "colour"}},
+
+		// Pagination
+		{"first page", service.UnitQuery{Sort: service.SortByPoints, Limit: 2}},
+		{"second page", service.UnitQuery{Sort: service.SortByPoints, Limit: 2, Offset: 2}},
+		{"offset only", service.UnitQuery{Offset: 5}},
+		{"past the end", service.UnitQuery{Limit: 3, Offset: 10}},
+
+		// Warnings are limited to the catalogues and factions listed
+		{"catalogue without unresolved links", service.UnitQuery{Catalogues: []string{"Chaos - Fixture Daemons"}}},
+		{"catalogue with unresolved links", service.UnitQuery{Catalogues: []string{"cat-fixture-marines"}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assertSame(t, memory, database, func(r service.Repositories) (interface{}, error) {
+				units, total, warnings, err := r.Units.ListUnitsWithWarnings(ctx, test.query)
+				return []interface{}{units, total, warnings}, err
+			})
+		})
+	}
+}
+
+func TestSearchUnitsMatchesService(t *testing.T) {
+	memory, database := openFixture(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name  string
+		query string
+		limit int
+	}{
+		{"every unit", "fixture", 20},
+		{"limited", "fixture", 3},
+		{"case", "BLOOD", 20},
+		{"unresolved link", "missing", 20},
+		{"no unresolved link", "captain", 20},
+		{"no match", "necron", 20},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assertSame(t, memory, database, func(r service.Repositories) (interface{}, error) {
+				results, warnings, err := r.Units.SearchUnitsWithWarnings(ctx, test.query, test.limit)
+				return []interface{}{results, warnings}, err
+			})
+		})
+	}
+
+	// Only the unresolved links whose name contains the query are reported
+	_, warnings, err := database.Units.SearchUnitsWithWarnings(ctx, "missing", 20)
+	require.NoError(t, err)
+	require.Len(t, warnings, 1)
+	assert.Equal(t, "el-fixture-broken", warnings[0].LinkID)
+
+	_, warnings, err = database.Units.SearchUnitsWithWarnings(ctx, "captain", 20)
+	require.NoError(t, err)
+	assert.Empty(t, warnings)
+}
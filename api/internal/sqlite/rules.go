@@ -0,0 +1,46 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"grimoire-api/internal/models"
+)
+
+// ruleRepository reads the rules table
+type ruleRepository struct {
+	db *sql.DB
+}
+
+// ListRules lists the shared rules sorted by name
+func (r *ruleRepository) ListRules(ctx context.Context) ([]models.RuleResponse, error) {
+	rules, err := queryDocuments[models.RuleResponse](ctx, r.db, `SELECT data FROM rules ORDER BY position`)
+	if rules == nil && err == nil {
+		rules = []models.RuleResponse{}
+	}
+	return rules, err
+}
+
+// GetRule retrieves a shared rule by ID
+func (r *ruleRepository) GetRule(ctx context.Context, id string) (*models.RuleResponse, error) {
+	var rule models.RuleResponse
+	if err := queryDocument(ctx, r.db, &rule, fmt.Errorf("rule not found: %s", id), `SELECT data FROM rules WHERE id = ?`, id); err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// gameSystemRepository reads the game_system table
+type gameSystemRepository struct {
+	db *sql.DB
+}
+
+// GetGameSystem returns the game system
+func (r *gameSystemRepository) GetGameSystem(ctx context.Context) (*models.GameSystemResponse, error) {
+	var gameSystem models.GameSystemResponse
+	if err := queryDocument(ctx, r.db, &gameSystem, fmt.Errorf("game system not loaded"), `SELECT data FROM game_system`); err != nil {
+		return nil, err
+	}
+	return &gameSystem, nil
+}
@@ -0,0 +1,122 @@
+package sqlite
+
+// SchemaVersion is the version of the tables below. Bump it whenever they change, so the server
+// refuses a database it would read wrongly instead of serving from it.
+const SchemaVersion = 2
+
+// schema is written by Import. The API reads the JSON documents; the other columns are there to
+// filter and sort units and for ad-hoc queries.
+const schema = `
+CREATE TABLE meta (
+	key   TEXT PRIMARY KEY,
+	value TEXT NOT NULL
+);
+
+CREATE TABLE game_system (
+	id       TEXT PRIMARY KEY,
+	name     TEXT NOT NULL,
+	revision TEXT NOT NULL,
+	data     TEXT NOT NULL -- GameSystemResponse
+);
+
+CREATE TABLE catalogues (
+	id       TEXT PRIMARY KEY,
+	position INTEGER NOT NULL,
+	name     TEXT NOT NULL,
+	revision TEXT NOT NULL,
+	library  INTEGER NOT NULL,
+	homebrew INTEGER NOT NULL,
+	data     TEXT NOT NULL, -- CatalogueResponse
+	units    TEXT NOT NULL, -- UnitSummary of each root unit, in catalogue order
+	warnings TEXT NOT NULL  -- ResolutionWarning of each root entryLink that didn't resolve
+);
+
+-- Root units of the catalogues, as listed by /api/v1/units
+CREATE TABLE units (
+	catalogue_id      TEXT NOT NULL REFERENCES catalogues (id),
+	id                TEXT NOT NULL, -- entryLink ID
+	target_id         TEXT NOT NULL,
+	name              TEXT NOT NULL,
+	sort_name         TEXT NOT NULL, -- Lowercased name
+	type              TEXT NOT NULL,
+	points            INTEGER NOT NULL,
+	toughness         INTEGER NOT NULL,
+	wounds            INTEGER NOT NULL,
+	objective_control INTEGER NOT NULL,
+	summary           TEXT NOT NULL, -- UnitSummary
+	PRIMARY KEY (catalogue_id, id)
+);
+CREATE INDEX units_id ON units (id);
+CREATE INDEX units_sort_name ON units (sort_name);
+
+-- Every unit GET /api/v1/units/:id answers, by entryLink and selectionEntry ID
+CREATE TABLE unit_responses (
+	id   TEXT PRIMARY KEY,
+	data TEXT NOT NULL -- UnitResponse
+);
+
+CREATE TABLE unit_categories (
+	catalogue_id TEXT NOT NULL,
+	unit_id      TEXT NOT NULL,
+	category_id  TEXT NOT NULL,
+	name         TEXT NOT NULL,
+	is_primary   INTEGER NOT NULL
+);
+CREATE INDEX unit_categories_unit ON unit_categories (catalogue_id, unit_id);
+
+CREATE TABLE unit_weapons (
+	catalogue_id      TEXT NOT NULL,
+	unit_id           TEXT NOT NULL,
+	type              TEXT NOT NULL, -- ranged or melee
+	name              TEXT NOT NULL,
+	range             TEXT NOT NULL,
+	attacks           TEXT NOT NULL,
+	skill             TEXT NOT NULL, -- Ballistic or weapon skill
+	strength          TEXT NOT NULL,
+	armor_penetration TEXT NOT NULL,
+	damage            TEXT NOT NULL,
+	keywords          TEXT NOT NULL  -- Comma-separated
+);
+CREATE INDEX unit_weapons_unit ON unit_weapons (catalogue_id, unit_id);
+
+CREATE TABLE factions (
+	id            TEXT NOT NULL,
+	position      INTEGER NOT NULL,
+	name          TEXT NOT NULL,
+	super_faction TEXT NOT NULL,
+	keyword_id    TEXT,
+	catalogue_id  TEXT NOT NULL,
+	data          TEXT NOT NULL -- FactionResponse
+);
+
+CREATE TABLE unit_factions (
+	catalogue_id TEXT NOT NULL,
+	unit_id      TEXT NOT NULL,
+	faction_id   TEXT NOT NULL
+);
+CREATE INDEX unit_factions_unit ON unit_factions (catalogue_id, unit_id);
+
+-- Root entryLinks that didn't resolve, reported as warnings by unit lists and searches
+CREATE TABLE unresolved_links (
+	catalogue_id TEXT NOT NULL,
+	link_id      TEXT NOT NULL,
+	position     INTEGER NOT NULL,
+	name         TEXT NOT NULL, -- The entryLink's name, matched by searches
+	warning      TEXT NOT NULL  -- ResolutionWarning
+);
+
+CREATE TABLE unresolved_link_factions (
+	catalogue_id TEXT NOT NULL,
+	link_id      TEXT NOT NULL,
+	faction_id   TEXT NOT NULL
+);
+
+CREATE TABLE rules (
+	id           TEXT PRIMARY KEY,
+	position     INTEGER NOT NULL,
+	name         TEXT NOT NULL,
+	description  TEXT NOT NULL,
+	catalogue_id TEXT, -- NULL for rules of the game system
+	data         TEXT NOT NULL -- RuleResponse
+);
+`
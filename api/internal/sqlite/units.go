@@ -0,0 +1,230 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"grimoire-api/internal/models"
+	"grimoire-api/internal/service"
+)
+
+// unitRepository reads units from the units and unit_responses tables
+type unitRepository struct {
+	db *sql.DB
+}
+
+// GetUnit retrieves a unit by entryLink or selectionEntry ID
+func (r *unitRepository) GetUnit(ctx context.Context, id string) (*models.UnitResponse, error) {
+	var unit models.UnitResponse
+	notFound := fmt.Errorf("unit not found: %s (tried as entryLink and selectionEntry)", id)
+	if err := queryDocument(ctx, r.db, &unit, notFound, `SELECT data FROM unit_responses WHERE id = ?`, id); err != nil {
+		return nil, err
+	}
+	return &unit, nil
+}
+
+// GetUnitWithWarnings retrieves a unit with its resolution warnings, which are stored with it
+func (r *unitRepository) GetUnitWithWarnings(ctx context.Context, id string) (*models.UnitResponse, error) {
+	return r.GetUnit(ctx, id)
+}
+
+// GetUnitWeapons retrieves the weapons of a unit
+func (r *unitRepository) GetUnitWeapons(ctx context.Context, id string) (*models.WeaponSet, error) {
+	unit, err := r.GetUnit(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return unit.Weapons, nil
+}
+
+// unitSortColumns are the columns each sort field orders units by
+var unitSortColumns = map[string]string{
+	service.SortByName:      "u.sort_name",
+	service.SortByPoints:    "u.points",
+	service.SortByToughness: "u.toughness",
+	service.SortByWounds:    "u.wounds",
+	service.SortByOC:        "u.objective_control",
+	service.SortByCatalogue: "c.name",
+}
+
+// ListUnitsWithWarnings lists units matching the query, sorted and paginated the same way as the
+// unit service, with a warning for each unresolved entryLink in the catalogues and factions listed
+func (r *unitRepository) ListUnitsWithWarnings(ctx context.Context, query service.UnitQuery) ([]models.UnitSummary, int, []models.ResolutionWarning, error) {
+	query, err := query.Normalized()
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	factionIDs, err := r.factionIDs(ctx, query.Factions)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+
+	var where conditions
+	where.catalogues("u.catalogue_id", query.Catalogues)
+	where.factions("unit_factions", "f.catalogue_id = u.catalogue_id AND f.unit_id = u.id", query.Factions, factionIDs)
+	if len(query.Categories) > 0 {
+		matches := make([]string, len(query.Categories))
+		for i, name := range query.Categories {
+			matches[i] = "instr(uc.name, ?) > 0"
+			where.args = append(where.args, name)
+		}
+		where.add(`EXISTS (SELECT 1 FROM unit_categories uc WHERE uc.catalogue_id = u.catalogue_id AND uc.unit_id = u.id AND (` + strings.Join(matches, " OR ") + `))`)
+	}
+	if query.Search != "" {
+		where.add("instr(u.sort_name, ?) > 0", strings.ToLower(query.Search))
+	}
+	switch query.Legends {
+	case service.LegendsExclude:
+		where.add("instr(u.name, ?) = 0", service.LegendsMarker)
+	case service.LegendsOnly:
+		where.add("instr(u.name, ?) > 0", service.LegendsMarker)
+	}
+	if query.MinPoints > 0 {
+		where.add("u.points >= ?", query.MinPoints)
+	}
+	if query.MaxPoints > 0 {
+		where.add("u.points <= ?", query.MaxPoints)
+	}
+
+	from := ` FROM units u JOIN catalogues c ON c.id = u.catalogue_id` + where.sql()
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*)`+from, where.args...).Scan(&total); err != nil {
+		return nil, 0, nil, err
+	}
+
+	// Ties are broken by name, catalogue and ID, as the unit service does
+	order := unitSortColumns[query.Sort]
+	if query.Descending {
+		order += " DESC"
+	}
+	limit := query.Limit
+	if limit <= 0 {
+		limit = -1
+	}
+	args := append(append([]interface{}{}, where.args...), limit, query.Offset)
+	units, err := queryDocuments[models.UnitSummary](ctx, r.db,
+		`SELECT u.summary`+from+` ORDER BY `+order+`, u.sort_name, c.name, u.id LIMIT ? OFFSET ?`, args...)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	if units == nil {
+		units = []models.UnitSummary{}
+	}
+
+	// Links that didn't resolve are reported for the catalogues and factions listed
+	var linkWhere conditions
+	linkWhere.catalogues("l.catalogue_id", query.Catalogues)
+	linkWhere.factions("unresolved_link_factions", "f.catalogue_id = l.catalogue_id AND f.link_id = l.link_id", query.Factions, factionIDs)
+	warnings, err := queryDocuments[models.ResolutionWarning](ctx, r.db,
+		`SELECT l.warning FROM unresolved_links l JOIN catalogues c ON c.id = l.catalogue_id`+linkWhere.sql()+` ORDER BY l.position`, linkWhere.args...)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+
+	return units, total, warnings, nil
+}
+
+// SearchUnitsWithWarnings searches for units whose name contains query, with a warning for each
+// unresolved entryLink whose name contains it. Matches are ordered by name, so the same limit always returns the same units.
+func (r *unitRepository) SearchUnitsWithWarnings(ctx context.Context, query string, limit int) ([]models.SearchResult, []models.ResolutionWarning, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT u.id, u.name FROM units u JOIN catalogues c ON c.id = u.catalogue_id
+		WHERE instr(u.sort_name, ?) > 0 ORDER BY u.sort_name, c.name, u.id LIMIT ?`, strings.ToLower(query), limit)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	var results []models.SearchResult
+	for rows.Next() {
+		result := models.SearchResult{Type: "unit"}
+		if err := rows.Scan(&result.ID, &result.Name); err != nil {
+			return nil, nil, err
+		}
+		results = append(results, result)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	// Like the unit service, only the links whose name contains the query are reported
+	warnings, err := queryDocuments[models.ResolutionWarning](ctx, r.db,
+		`SELECT warning FROM unresolved_links WHERE instr(lower(name), ?) > 0 ORDER BY position`, strings.ToLower(query))
+	if err != nil {
+		return nil, nil, err
+	}
+	return results, warnings, nil
+}
+
+// factionIDs resolves faction query values to the IDs of the factions they name
+func (r *unitRepository) factionIDs(ctx context.Context, values []string) ([]string, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	factions, err := (&factionRepository{db: r.db}).ListFactions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, value := range values {
+		for _, faction := range findFactions(factions, value) {
+			ids = append(ids, faction.ID)
+		}
+	}
+	return ids, nil
+}
+
+// conditions builds the WHERE clause of a query
+type conditions struct {
+	clauses []string
+	args    []interface{}
+}
+
+func (c *conditions) add(clause string, args ...interface{}) {
+	c.clauses = append(c.clauses, clause)
+	c.args = append(c.args, args...)
+}
+
+// catalogues matches rows whose catalogue has one of the IDs or names in filters, like the unit
+// service's catalogue filter. The query must join catalogues as c.
+func (c *conditions) catalogues(column string, filters []string) {
+	if len(filters) == 0 {
+		return
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(filters)), ", ")
+	var args []interface{}
+	for _, filter := range filters {
+		args = append(args, filter)
+	}
+	for _, filter := range filters {
+		args = append(args, strings.ToLower(filter))
+	}
+	c.add(column+" IN ("+placeholders+") OR lower(c.name) IN ("+placeholders+")", args...)
+}
+
+// factions matches rows belonging to one of the factions with ids, through the table f pairing
+// them with factions, joined to the row by join. Faction values that named no faction match nothing.
+func (c *conditions) factions(table, join string, values, ids []string) {
+	if len(values) == 0 {
+		return
+	}
+	if len(ids) == 0 {
+		c.add("0")
+		return
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", ")
+	var args []interface{}
+	for _, id := range ids {
+		args = append(args, id)
+	}
+	c.add(`EXISTS (SELECT 1 FROM `+table+` f WHERE `+join+` AND f.faction_id IN (`+placeholders+`))`, args...)
+}
+
+func (c *conditions) sql() string {
+	if len(c.clauses) == 0 {
+		return ""
+	}
+	return " WHERE (" + strings.Join(c.clauses, ") AND (") + ")"
+}
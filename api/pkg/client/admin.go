@@ -0,0 +1,69 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"grimoire-api/pkg/response"
+)
+
+// The /admin routes need the admin token; see WithToken.
+
+// DataQualityQuery filters the data-quality report; empty fields don't filter
+type DataQualityQuery struct {
+	Rule      string
+	Severity  string // error or warning
+	Catalogue string
+}
+
+// GetDataQuality returns the data-quality report of the loaded data
+func (c *Client) GetDataQuality(ctx context.Context, query DataQualityQuery) (*DataQualityReport, error) {
+	values := url.Values{}
+	for name, value := range map[string]string{"rule": query.Rule, "severity": query.Severity, "catalogue": query.Catalogue} {
+		if value != "" {
+			values.Set(name, value)
+		}
+	}
+	var report DataQualityReport
+	if err := c.get(ctx, escape("admin", "data-quality"), values, &report, nil); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// GetOverlays returns the overlays applied to the loaded data
+func (c *Client) GetOverlays(ctx context.Context) (*OverlayReport, error) {
+	var report OverlayReport
+	if err := c.get(ctx, escape("admin", "overlays"), nil, &report, nil); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// ListSnapshots returns the retained snapshots, which requests can be pinned to with Pinned
+func (c *Client) ListSnapshots(ctx context.Context) ([]SnapshotInfo, error) {
+	var snapshots []SnapshotInfo
+	if err := c.get(ctx, escape("admin", "snapshots"), nil, &snapshots, nil); err != nil {
+		return nil, err
+	}
+	return snapshots, nil
+}
+
+// GetCacheStats returns the size and hit rates of the response cache
+func (c *Client) GetCacheStats(ctx context.Context) (*CacheStats, error) {
+	var stats CacheStats
+	if err := c.get(ctx, escape("admin", "cache"), nil, &stats, nil); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// Reload reloads the data and returns the current snapshot
+func (c *Client) Reload(ctx context.Context) (*SnapshotInfo, error) {
+	var info SnapshotInfo
+	if _, err := c.decode(ctx, request{method: http.MethodPost, path: escape("admin", "reload")}, &response.SuccessResponse{Data: &info}); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
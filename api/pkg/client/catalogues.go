@@ -0,0 +1,116 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+
+	"grimoire-api/pkg/response"
+)
+
+// ListCatalogues returns the catalogues and libraries
+func (c *Client) ListCatalogues(ctx context.Context) ([]CatalogueInfo, error) {
+	var catalogues []CatalogueInfo
+	if err := c.get(ctx, escape("catalogues"), nil, &catalogues, nil); err != nil {
+		return nil, err
+	}
+	return catalogues, nil
+}
+
+// GetCatalogue returns a catalogue or library with its units, with its resolution warnings if debug
+func (c *Client) GetCatalogue(ctx context.Context, id string, debug bool) (*Catalogue, error) {
+	var catalogue Catalogue
+	if err := c.get(ctx, escape("catalogues", id), debugQuery(debug), &catalogue, nil); err != nil {
+		return nil, err
+	}
+	return &catalogue, nil
+}
+
+// GetCatalogueUnits returns the units at the root of a catalogue. The warnings are only returned
+// if debug.
+func (c *Client) GetCatalogueUnits(ctx context.Context, id string, debug bool) ([]UnitSummary, []ResolutionWarning, error) {
+	var units []UnitSummary
+	var warnings []ResolutionWarning
+	if err := c.get(ctx, escape("catalogues", id, "units"), debugQuery(debug), &units, &warnings); err != nil {
+		return nil, nil, err
+	}
+	return units, warnings, nil
+}
+
+// GetCatalogueGraph returns the catalogueLink import graph, limited to the imports and dependents
+// of focus unless it is empty
+func (c *Client) GetCatalogueGraph(ctx context.Context, focus string) (*CatalogueGraph, error) {
+	var graph CatalogueGraph
+	if err := c.get(ctx, escape("catalogues", "graph"), graphQuery(focus, ""), &graph, nil); err != nil {
+		return nil, err
+	}
+	return &graph, nil
+}
+
+// GetCatalogueGraphDiagram returns the import graph as a Graphviz ("dot") or Mermaid ("mermaid")
+// diagram
+func (c *Client) GetCatalogueGraphDiagram(ctx context.Context, focus, format string) (string, error) {
+	resp, err := c.send(ctx, request{method: http.MethodGet, path: escape("catalogues", "graph"), query: graphQuery(focus, format)})
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	diagram, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	return string(diagram), nil
+}
+
+func graphQuery(focus, format string) url.Values {
+	query := url.Values{}
+	if focus != "" {
+		query.Set("focus", focus)
+	}
+	if format != "" {
+		query.Set("format", format)
+	}
+	return query
+}
+
+// UploadCatalogue uploads a homebrew .cat file, read from catalogue, and returns the catalogue it
+// was loaded as. It needs the homebrew token; see WithToken.
+func (c *Client) UploadCatalogue(ctx context.Context, fileName string, catalogue io.Reader) (*CatalogueInfo, error) {
+	var form bytes.Buffer
+	writer := multipart.NewWriter(&form)
+	part, err := writer.CreateFormFile("file", fileName)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(part, catalogue); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", fileName, err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	var info CatalogueInfo
+	_, err = c.decode(ctx, request{
+		method:      http.MethodPost,
+		path:        escape("catalogues"),
+		body:        form.Bytes(),
+		contentType: writer.FormDataContentType(),
+	}, &response.SuccessResponse{Data: &info})
+	if err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// DeleteCatalogue deletes a homebrew catalogue. It needs the homebrew token; see WithToken.
+func (c *Client) DeleteCatalogue(ctx context.Context, id string) error {
+	resp, err := c.send(ctx, request{method: http.MethodDelete, path: escape("catalogues", id)})
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
@@ -0,0 +1,277 @@
+// Package client is a typed Go client of the REST API. Responses decode into the API's own models,
+// through the envelopes of pkg/response, so services calling the API don't keep copies of either.
+//
+// Every method takes a context. GET and DELETE requests are retried with exponential backoff when
+// the connection fails or the server answers 429, 502, 503 or 504. AllUnits iterates over every
+// page of a unit list, pinned to the revision of its first page. Package clienttest serves the API
+// in-process for tests.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"grimoire-api/pkg/response"
+)
+
+// RevisionHeader names the snapshot a response was answered from, and pins a request to a snapshot
+const RevisionHeader = "X-Data-Revision"
+
+const apiPath = "/api/v1"
+
+// Defaults of the retry options
+const (
+	DefaultRetries    = 3
+	DefaultBackoff    = 200 * time.Millisecond
+	DefaultMaxBackoff = 5 * time.Second
+)
+
+// Client calls the API at one base URL. It is safe for concurrent use.
+type Client struct {
+	baseURL    *url.URL
+	http       *http.Client
+	token      string
+	revision   string
+	retries    int
+	backoff    time.Duration
+	maxBackoff time.Duration
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithHTTPClient sends requests with h instead of http.DefaultClient
+func WithHTTPClient(h *http.Client) Option {
+	return func(c *Client) { c.http = h }
+}
+
+// WithToken sends token as a bearer token, for the homebrew and reload routes
+func WithToken(token string) Option {
+	return func(c *Client) { c.token = token }
+}
+
+// WithRetries retries a failed GET or DELETE up to retries times, waiting a random time up to
+// backoff before the first retry and doubling it for each one after, up to maxBackoff. A
+// Retry-After header from the server is waited for instead. 0 retries turns retrying off.
+func WithRetries(retries int, backoff, maxBackoff time.Duration) Option {
+	return func(c *Client) {
+		c.retries = retries
+		c.backoff = backoff
+		c.maxBackoff = maxBackoff
+	}
+}
+
+// New creates a client of the API served at baseURL, such as http://localhost:8080
+func New(baseURL string, options ...Option) (*Client, error) {
+	parsed, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URL: %w", err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return nil, fmt.Errorf("invalid base URL %q: the scheme must be http or https", baseURL)
+	}
+	c := &Client{
+		baseURL:    parsed,
+		http:       http.DefaultClient,
+		retries:    DefaultRetries,
+		backoff:    DefaultBackoff,
+		maxBackoff: DefaultMaxBackoff,
+	}
+	for _, option := range options {
+		option(c)
+	}
+	return c, nil
+}
+
+// BaseURL is the URL the client was created with
+func (c *Client) BaseURL() string {
+	return c.baseURL.String()
+}
+
+// Pinned returns a copy of the client whose requests read the snapshot at revision. Requests fail
+// with 410 Gone once the server no longer retains it.
+func (c *Client) Pinned(revision string) *Client {
+	pinned := *c
+	pinned.revision = revision
+	return &pinned
+}
+
+// Error is an error response of the API
+type Error struct {
+	StatusCode int
+	Status     string // Status text sent by the server, such as "Not Found"
+	Message    string
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("grimoire API: %d %s", e.StatusCode, e.Status)
+	}
+	return fmt.Sprintf("grimoire API: %d %s: %s", e.StatusCode, e.Status, e.Message)
+}
+
+// IsNotFound reports whether err is a 404 response
+func IsNotFound(err error) bool {
+	var apiErr *Error
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// request is one call of the API
+type request struct {
+	method      string
+	path        string // From the root of the server, with path parameters escaped
+	query       url.Values
+	body        []byte
+	contentType string
+	accept      []int // Error statuses whose body is returned rather than failing
+}
+
+// send sends r, retrying as configured. The caller closes the body of the response.
+func (c *Client) send(ctx context.Context, r request) (*http.Response, error) {
+	target := *c.baseURL
+	target.Path += r.path
+	target.RawQuery = r.query.Encode()
+
+	for attempt := 0; ; attempt++ {
+		var body io.Reader
+		if r.body != nil {
+			body = bytes.NewReader(r.body)
+		}
+		req, err := http.NewRequestWithContext(ctx, r.method, target.String(), body)
+		if err != nil {
+			return nil, err
+		}
+		if r.contentType != "" {
+			req.Header.Set("Content-Type", r.contentType)
+		}
+		if c.token != "" {
+			req.Header.Set("Authorization", "Bearer "+c.token)
+		}
+		if c.revision != "" {
+			req.Header.Set(RevisionHeader, c.revision)
+		}
+
+		resp, err := c.http.Do(req)
+		if attempt >= c.retries || !retryable(r.method, resp, err) || ctx.Err() != nil {
+			if err != nil {
+				return nil, err
+			}
+			if resp.StatusCode >= 400 && !slices.Contains(r.accept, resp.StatusCode) {
+				defer resp.Body.Close()
+				return nil, readError(resp)
+			}
+			return resp, nil
+		}
+
+		wait := c.wait(attempt, resp)
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// retryable reports whether a request can be sent again after resp or err. Only GET and DELETE
+// are retried, as sending them twice does no harm.
+func retryable(method string, resp *http.Response, err error) bool {
+	if method != http.MethodGet && method != http.MethodDelete {
+		return false
+	}
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// wait is the time to wait before retrying after attempt: the server's Retry-After, or a random
+// time up to the backoff doubled for each attempt
+func (c *Client) wait(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			return min(time.Duration(seconds)*time.Second, c.maxBackoff)
+		}
+	}
+	backoff := min(c.backoff<<attempt, c.maxBackoff)
+	if backoff <= 0 {
+		return 0
+	}
+	return rand.N(backoff) + 1
+}
+
+// readError reads the ErrorResponse of a failed request
+func readError(resp *http.Response) error {
+	apiErr := &Error{StatusCode: resp.StatusCode, Status: http.StatusText(resp.StatusCode)}
+	var body response.ErrorResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err == nil {
+		if body.Error != "" {
+			apiErr.Status = body.Error
+		}
+		apiErr.Message = body.Message
+	}
+	return apiErr
+}
+
+// decode sends r and decodes its JSON body into v
+func (c *Client) decode(ctx context.Context, r request, v interface{}) (*http.Response, error) {
+	resp, err := c.send(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return nil, fmt.Errorf("failed to decode the response of %s %s: %w", r.method, r.path, err)
+	}
+	return resp, nil
+}
+
+// get decodes the data of a response.SuccessResponse into data, and its warnings into warnings
+// when not nil
+func (c *Client) get(ctx context.Context, path string, query url.Values, data interface{}, warnings *[]ResolutionWarning) error {
+	body := response.SuccessResponse{Data: data}
+	if warnings != nil {
+		body.Warnings = warnings
+	}
+	_, err := c.decode(ctx, request{method: http.MethodGet, path: path, query: query}, &body)
+	return err
+}
+
+// debugQuery is the query of a request for resolution warnings, or none
+func debugQuery(debug bool) url.Values {
+	if !debug {
+		return nil
+	}
+	return url.Values{"debug": {"true"}}
+}
+
+// escape builds an API path from segments, escaping each
+func escape(segments ...string) string {
+	var path strings.Builder
+	path.WriteString(apiPath)
+	for _, segment := range segments {
+		path.WriteString("/")
+		path.WriteString(url.PathEscape(segment))
+	}
+	return path.String()
+}
@@ -0,0 +1,283 @@
+package client_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"grimoire-api/pkg/client"
+	"grimoire-api/pkg/client/clienttest"
+)
+
+const fixtureDir = "../../testdata/wh40k-fixture"
+
+func TestEndpoints(t *testing.T) {
+	c := clienttest.NewServer(t, fixtureDir)
+	ctx := context.Background()
+
+	gameSystem, err := c.GetGameSystem(ctx)
+	require.NoError(t, err)
+	assert.NotEmpty(t, gameSystem.Name)
+
+	catalogues, err := c.ListCatalogues(ctx)
+	require.NoError(t, err)
+	assert.NotEmpty(t, catalogues)
+
+	catalogue, err := c.GetCatalogue(ctx, "cat-fixture-marines", true)
+	require.NoError(t, err)
+	assert.NotEmpty(t, catalogue.Units)
+	assert.NotEmpty(t, catalogue.Warnings)
+
+	units, warnings, err := c.GetCatalogueUnits(ctx, "cat-fixture-marines", true)
+	require.NoError(t, err)
+	assert.NotEmpty(t, units)
+	assert.NotEmpty(t, warnings)
+
+	graph, err := c.GetCatalogueGraph(ctx, "")
+	require.NoError(t, err)
+	assert.NotEmpty(t, graph.Nodes)
+	diagram, err := c.GetCatalogueGraphDiagram(ctx, "lib-fixture-astartes", "dot")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(diagram, "digraph"), diagram)
+
+	rules, err := c.ListRules(ctx)
+	require.NoError(t, err)
+	assert.NotEmpty(t, rules)
+	rule, err := c.GetRule(ctx, "rule-fixture-deep-strike")
+	require.NoError(t, err)
+	assert.Equal(t, "rule-fixture-deep-strike", rule.ID)
+
+	unit, err := c.GetUnit(ctx, "el-fixture-captain", false)
+	require.NoError(t, err)
+	assert.Equal(t, "el-fixture-captain", unit.ID)
+	assert.NotNil(t, unit.Profiles)
+	weapons, err := c.GetUnitWeapons(ctx, "el-fixture-captain")
+	require.NoError(t, err)
+	assert.Equal(t, unit.Weapons, weapons)
+	explanation, err := c.ExplainUnit(ctx, "el-fixture-captain")
+	require.NoError(t, err)
+	assert.NotNil(t, explanation)
+	_, err = c.GetUnitHistory(ctx, "el-fixture-captain")
+	var apiErr *client.Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusServiceUnavailable, apiErr.StatusCode)
+
+	factions, err := c.ListFactions(ctx)
+	require.NoError(t, err)
+	assert.NotEmpty(t, factions)
+	faction, err := c.GetFaction(ctx, "fac-fixture-astartes")
+	require.NoError(t, err)
+	assert.Equal(t, "fac-fixture-astartes", faction.ID)
+	factionUnits, _, err := c.GetFactionUnits(ctx, "fac-fixture-astartes", false)
+	require.NoError(t, err)
+	assert.NotEmpty(t, factionUnits)
+
+	results, err := c.Search(ctx, "fixture", 2)
+	require.NoError(t, err)
+	assert.Len(t, results.Results, 2)
+
+	_, err = c.Diff(ctx, "no-such-revision", "")
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+
+	export, err := c.Export(ctx, "ndjson")
+	require.NoError(t, err)
+	data, err := io.ReadAll(export)
+	require.NoError(t, err)
+	require.NoError(t, export.Close())
+	_, err = zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	assert.NoError(t, err)
+
+	report, err := c.GetDataQuality(ctx, client.DataQualityQuery{Severity: "error"})
+	require.NoError(t, err)
+	assert.NotNil(t, report)
+	_, err = c.GetOverlays(ctx)
+	require.NoError(t, err)
+	_, err = c.GetCacheStats(ctx)
+	require.NoError(t, err)
+	snapshots, err := c.ListSnapshots(ctx)
+	require.NoError(t, err)
+	require.NotEmpty(t, snapshots)
+	reloaded, err := c.Reload(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, snapshots[0].Revision, reloaded.Revision)
+
+	err = c.DeleteCatalogue(ctx, "homebrew:missing")
+	assert.True(t, client.IsNotFound(err), err)
+
+	document, err := c.OpenAPI(ctx)
+	require.NoError(t, err)
+	assert.Contains(t, string(document), `"openapi"`)
+
+	health, err := c.Health(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, reloaded.Revision, health.Revision)
+	readiness, err := c.Ready(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "ready", readiness.Status)
+}
+
+func TestErrors(t *testing.T) {
+	c := clienttest.NewServer(t, fixtureDir)
+
+	_, err := c.GetUnit(context.Background(), "missing", false)
+	assert.True(t, client.IsNotFound(err))
+	var apiErr *client.Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "Not Found", apiErr.Status)
+	assert.Contains(t, apiErr.Message, "missing")
+
+	_, err = c.ListUnits(context.Background(), client.UnitQuery{Sort: "sideways"})
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+
+	// A snapshot that is no longer retained
+	_, err = c.Pinned("gone").GetUnit(context.Background(), "el-fixture-captain", false)
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusGone, apiErr.StatusCode)
+}
+
+func TestUnitPages(t *testing.T) {
+	c := clienttest.NewServer(t, fixtureDir)
+	ctx := context.Background()
+
+	all, err := c.ListUnits(ctx, client.UnitQuery{Limit: 1000})
+	require.NoError(t, err)
+	require.Greater(t, all.Total, 2)
+	assert.NotEmpty(t, all.Revision)
+
+	first, err := c.ListUnits(ctx, client.UnitQuery{Limit: 2, Sort: client.SortByPoints, Descending: true})
+	require.NoError(t, err)
+	assert.Len(t, first.Units, 2)
+	assert.True(t, first.HasMore)
+	assert.GreaterOrEqual(t, first.Units[0].Costs["pts"], first.Units[1].Costs["pts"])
+
+	var ids []string
+	for unit, err := range c.AllUnits(ctx, client.UnitQuery{Limit: 2}) {
+		require.NoError(t, err)
+		ids = append(ids, unit.ID)
+	}
+	var want []string
+	for _, unit := range all.Units {
+		want = append(want, unit.ID)
+	}
+	assert.Equal(t, want, ids)
+
+	// Stopping early stops fetching pages
+	count := 0
+	for range c.AllUnits(ctx, client.UnitQuery{Limit: 2}) {
+		count++
+		if count == 3 {
+			break
+		}
+	}
+	assert.Equal(t, 3, count)
+
+	for _, err := range c.AllUnits(ctx, client.UnitQuery{Legends: "sometimes"}) {
+		assert.Error(t, err)
+	}
+}
+
+func TestGraphQL(t *testing.T) {
+	c := clienttest.NewServer(t, fixtureDir)
+
+	result, err := c.GraphQL(context.Background(), client.GraphQLRequest{
+		Query:     "query Unit($id: ID!) { unit(id: $id) { name } }",
+		Variables: map[string]interface{}{"id": "el-fixture-captain"},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, result.Errors)
+	var data struct{ Unit struct{ Name string } }
+	require.NoError(t, json.Unmarshal(result.Data, &data))
+	assert.NotEmpty(t, data.Unit.Name)
+
+	result, err = c.GraphQL(context.Background(), client.GraphQLRequest{Query: "{ nope }"})
+	var apiErr *client.Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+	require.NotNil(t, result)
+	assert.NotEmpty(t, result.Errors)
+}
+
+// flaky answers failures with status until it has been called fail times, then the fixture's game system
+func flaky(t *testing.T, status, fail int) (*httptest.Server, *atomic.Int32) {
+	var calls atomic.Int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if int(calls.Add(1)) <= fail {
+			w.WriteHeader(status)
+			w.Write([]byte(`{"error": "Service Unavailable", "message": "try again"}`))
+			return
+		}
+		w.Write([]byte(`{"data": {"id": "gs", "name": "Game"}}`))
+	}))
+	t.Cleanup(s.Close)
+	return s, &calls
+}
+
+func TestRetries(t *testing.T) {
+	fast := client.WithRetries(3, time.Millisecond, 10*time.Millisecond)
+
+	s, calls := flaky(t, http.StatusServiceUnavailable, 2)
+	c, err := client.New(s.URL, fast)
+	require.NoError(t, err)
+	gameSystem, err := c.GetGameSystem(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "Game", gameSystem.Name)
+	assert.Equal(t, int32(3), calls.Load())
+
+	// Retries run out
+	s, calls = flaky(t, http.StatusBadGateway, 10)
+	c, err = client.New(s.URL, fast)
+	require.NoError(t, err)
+	_, err = c.GetGameSystem(context.Background())
+	var apiErr *client.Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusBadGateway, apiErr.StatusCode)
+	assert.Equal(t, "try again", apiErr.Message)
+	assert.Equal(t, int32(4), calls.Load())
+
+	// Other errors, and POST requests, are not retried
+	s, calls = flaky(t, http.StatusNotFound, 10)
+	c, err = client.New(s.URL, fast)
+	require.NoError(t, err)
+	_, err = c.GetGameSystem(context.Background())
+	assert.True(t, client.IsNotFound(err))
+	assert.Equal(t, int32(1), calls.Load())
+
+	s, calls = flaky(t, http.StatusServiceUnavailable, 10)
+	c, err = client.New(s.URL, fast)
+	require.NoError(t, err)
+	_, err = c.Reload(context.Background())
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), calls.Load())
+
+	// A cancelled context stops waiting between retries
+	s, _ = flaky(t, http.StatusServiceUnavailable, 10)
+	c, err = client.New(s.URL, client.WithRetries(3, time.Hour, time.Hour))
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = c.GetGameSystem(ctx)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), err)
+}
+
+func TestNew(t *testing.T) {
+	_, err := client.New("localhost:8080")
+	assert.Error(t, err)
+
+	c, err := client.New("http://localhost:8080/")
+	require.NoError(t, err)
+	assert.Equal(t, "http://localhost:8080", c.BaseURL())
+}
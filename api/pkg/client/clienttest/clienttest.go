@@ -0,0 +1,60 @@
+// Package clienttest serves the API in-process for tests of code that calls it through
+// pkg/client, over data of the test's choosing.
+package clienttest
+
+import (
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"grimoire-api/internal/graphql"
+	"grimoire-api/internal/homebrew"
+	"grimoire-api/internal/server"
+	"grimoire-api/internal/service"
+	"grimoire-api/internal/snapshot"
+	"grimoire-api/pkg/client"
+)
+
+// Token is the homebrew and admin token of the test server, which the client sends
+const Token = "clienttest-token"
+
+// NewServer loads the BattleScribe data in dataDir and serves it on an httptest server, closed
+// when the test ends. It returns a client of the server, with options applied after its own.
+// Uploaded homebrew is kept in a temporary directory, and the points history is never indexed.
+func NewServer(t testing.TB, dataDir string, options ...client.Option) *client.Client {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	dir := t.TempDir()
+	homebrewStore := homebrew.NewStore(filepath.Join(dir, "homebrew"))
+	snapshots := snapshot.NewStore(snapshot.Config{DataDir: dataDir, Homebrew: homebrewStore, Quiet: true})
+	if _, _, err := snapshots.Reload(); err != nil {
+		t.Fatalf("clienttest: failed to load %s: %v", dataDir, err)
+	}
+
+	router, err := server.NewRouter(server.Config{
+		Snapshots:          snapshots,
+		History:            service.NewHistoryService(dataDir, filepath.Join(dir, "history.json.gz")),
+		Homebrew:           homebrewStore,
+		HomebrewToken:      Token,
+		AdminToken:         Token,
+		RequestTimeout:     10 * time.Second,
+		SlowRequestTimeout: time.Minute,
+		GraphQLLimits:      graphql.DefaultLimits,
+		Quiet:              true,
+	})
+	if err != nil {
+		t.Fatalf("clienttest: %v", err)
+	}
+	s := httptest.NewServer(router)
+	t.Cleanup(s.Close)
+
+	c, err := client.New(s.URL, append([]client.Option{client.WithToken(Token), client.WithHTTPClient(s.Client())}, options...)...)
+	if err != nil {
+		t.Fatalf("clienttest: %v", err)
+	}
+	return c
+}
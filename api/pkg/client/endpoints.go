@@ -0,0 +1,163 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// GetGameSystem returns the game system
+func (c *Client) GetGameSystem(ctx context.Context) (*GameSystem, error) {
+	var gameSystem GameSystem
+	if err := c.get(ctx, escape("game-system"), nil, &gameSystem, nil); err != nil {
+		return nil, err
+	}
+	return &gameSystem, nil
+}
+
+// ListRules returns the shared rules of the game system, catalogues and libraries
+func (c *Client) ListRules(ctx context.Context) ([]Rule, error) {
+	var rules []Rule
+	if err := c.get(ctx, escape("rules"), nil, &rules, nil); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// GetRule returns a shared rule by ID
+func (c *Client) GetRule(ctx context.Context, id string) (*Rule, error) {
+	var rule Rule
+	if err := c.get(ctx, escape("rules", id), nil, &rule, nil); err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// ListFactions returns the factions
+func (c *Client) ListFactions(ctx context.Context) ([]Faction, error) {
+	var factions []Faction
+	if err := c.get(ctx, escape("factions"), nil, &factions, nil); err != nil {
+		return nil, err
+	}
+	return factions, nil
+}
+
+// GetFaction returns a faction by ID, name, keyword or catalogue
+func (c *Client) GetFaction(ctx context.Context, name string) (*Faction, error) {
+	var faction Faction
+	if err := c.get(ctx, escape("factions", name), nil, &faction, nil); err != nil {
+		return nil, err
+	}
+	return &faction, nil
+}
+
+// GetFactionUnits returns the units of a faction or super-faction. The warnings are only returned
+// if debug.
+func (c *Client) GetFactionUnits(ctx context.Context, name string, debug bool) ([]UnitSummary, []ResolutionWarning, error) {
+	var units []UnitSummary
+	var warnings []ResolutionWarning
+	if err := c.get(ctx, escape("factions", name, "units"), debugQuery(debug), &units, &warnings); err != nil {
+		return nil, nil, err
+	}
+	return units, warnings, nil
+}
+
+// Search finds units by name, returning up to limit results (1 to 200; 0 for the server's
+// default of 50)
+func (c *Client) Search(ctx context.Context, query string, limit int) (*SearchResponse, error) {
+	values := url.Values{"q": {query}}
+	if limit != 0 {
+		values.Set("limit", strconv.Itoa(limit))
+	}
+	var results SearchResponse
+	if err := c.get(ctx, escape("search"), values, &results, nil); err != nil {
+		return nil, err
+	}
+	return &results, nil
+}
+
+// Diff compares two git revisions of the data; an empty to compares with the loaded data
+func (c *Client) Diff(ctx context.Context, from, to string) (*DataDiff, error) {
+	values := url.Values{"from": {from}}
+	if to != "" {
+		values.Set("to", to)
+	}
+	var diff DataDiff
+	if err := c.get(ctx, escape("diff"), values, &diff, nil); err != nil {
+		return nil, err
+	}
+	return &diff, nil
+}
+
+// Export streams the dataset as "csv" or "ndjson" (zips of tables) or "sqlite". The caller closes
+// the reader. A failure while streaming leaves the export truncated, and fails the read.
+func (c *Client) Export(ctx context.Context, format string) (io.ReadCloser, error) {
+	resp, err := c.send(ctx, request{method: http.MethodGet, path: escape("export", format)})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// GraphQL runs a GraphQL query. A query refused before it ran returns the response, with its
+// errors, and an *Error with status 400. Errors of fields that failed while the query ran are only
+// in the response.
+func (c *Client) GraphQL(ctx context.Context, query GraphQLRequest) (*GraphQLResponse, error) {
+	body, err := json.Marshal(query)
+	if err != nil {
+		return nil, err
+	}
+	var result GraphQLResponse
+	resp, err := c.decode(ctx, request{
+		method:      http.MethodPost,
+		path:        escape("graphql"),
+		body:        body,
+		contentType: "application/json",
+		accept:      []int{http.StatusBadRequest},
+	}, &result)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusBadRequest {
+		messages := make([]string, len(result.Errors))
+		for i, e := range result.Errors {
+			messages[i] = e.Message
+		}
+		return &result, &Error{StatusCode: resp.StatusCode, Status: http.StatusText(resp.StatusCode), Message: strings.Join(messages, "; ")}
+	}
+	return &result, nil
+}
+
+// OpenAPI returns the OpenAPI document of the API
+func (c *Client) OpenAPI(ctx context.Context) (json.RawMessage, error) {
+	var document json.RawMessage
+	if _, err := c.decode(ctx, request{method: http.MethodGet, path: apiPath + "/openapi.json"}, &document); err != nil {
+		return nil, err
+	}
+	return document, nil
+}
+
+// Health returns the health of the server and the load report of its current snapshot
+func (c *Client) Health(ctx context.Context) (*Health, error) {
+	var health Health
+	if _, err := c.decode(ctx, request{method: http.MethodGet, path: "/health"}, &health); err != nil {
+		return nil, err
+	}
+	return &health, nil
+}
+
+// Ready reports whether the current snapshot has warmed up. A warming server answers 503, which
+// is returned as its Readiness rather than an error, without retrying.
+func (c *Client) Ready(ctx context.Context) (*Readiness, error) {
+	var readiness Readiness
+	noRetries := *c
+	noRetries.retries = 0
+	if _, err := noRetries.decode(ctx, request{method: http.MethodGet, path: "/ready", accept: []int{http.StatusServiceUnavailable}}, &readiness); err != nil {
+		return nil, err
+	}
+	return &readiness, nil
+}
@@ -0,0 +1,80 @@
+package client
+
+import (
+	"encoding/json"
+
+	"grimoire-api/internal/models"
+)
+
+// The API's response models, under names code outside this module can refer to
+type (
+	GameSystem = models.GameSystemResponse
+
+	Catalogue      = models.CatalogueResponse
+	CatalogueInfo  = models.CatalogueInfo
+	CatalogueGraph = models.CatalogueGraphResponse
+
+	Unit            = models.UnitResponse
+	UnitSummary     = models.UnitSummary
+	UnitProfiles    = models.UnitProfiles
+	UnitProfile     = models.UnitProfile
+	AbilityProfile  = models.AbilityProfile
+	WeaponSet       = models.WeaponSet
+	RangedWeapon    = models.RangedWeapon
+	MeleeWeapon     = models.MeleeWeapon
+	CategoryInfo    = models.CategoryInfo
+	RuleInfo        = models.RuleInfo
+	FactionInfo     = models.FactionInfo
+	TieredCosts     = models.TieredCosts
+	CostTier        = models.CostTier
+	UnitExplanation = models.UnitExplanation
+	UnitHistory     = models.UnitHistory
+
+	Faction = models.FactionResponse
+	Rule    = models.RuleResponse
+
+	SearchResponse = models.SearchResponse
+	SearchResult   = models.SearchResult
+
+	DataDiff = models.DataDiff
+
+	DataQualityReport = models.DataQualityReport
+	OverlayReport     = models.OverlayReport
+	SnapshotInfo      = models.SnapshotInfo
+	CacheStats        = models.CacheStats
+	Readiness         = models.Readiness
+	LoadReport        = models.LoadReport
+
+	ResolutionWarning = models.ResolutionWarning
+)
+
+// Health is the answer of /health
+type Health struct {
+	Status   string     `json:"status"` // healthy, or degraded when catalogue files failed to load
+	Revision string     `json:"revision"`
+	Load     LoadReport `json:"load"`
+}
+
+// GraphQLRequest is a query for /api/v1/graphql
+type GraphQLRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+// GraphQLResponse is the result of a GraphQL query. Data is left encoded so it can be decoded into
+// types matching the query.
+type GraphQLResponse struct {
+	Data   json.RawMessage `json:"data,omitempty"`
+	Errors []GraphQLError  `json:"errors,omitempty"`
+}
+
+// GraphQLError is an error of a query, or of the field at Path
+type GraphQLError struct {
+	Message   string `json:"message"`
+	Locations []struct {
+		Line   int `json:"line"`
+		Column int `json:"column"`
+	} `json:"locations,omitempty"`
+	Path []interface{} `json:"path,omitempty"`
+}
@@ -0,0 +1,167 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"grimoire-api/pkg/response"
+)
+
+// Legends filters of UnitQuery
+const (
+	LegendsInclude = "include"
+	LegendsExclude = "exclude"
+	LegendsOnly    = "only"
+)
+
+// Sort keys of UnitQuery
+const (
+	SortByName      = "name"
+	SortByPoints    = "points"
+	SortByToughness = "toughness"
+	SortByWounds    = "wounds"
+	SortByOC        = "oc"
+	SortByCatalogue = "catalogue"
+)
+
+// UnitQuery holds the filters, sort order and page of ListUnits. Values of a multi-value filter
+// are ORed; different filters are ANDed. Zero values are left to the server's defaults.
+type UnitQuery struct {
+	Factions   []string // Faction IDs, names, keywords, catalogues or super-factions
+	Categories []string // Category names (substring match)
+	Catalogues []string // Catalogue IDs or names
+	Search     string   // Unit name substring
+	MinPoints  int
+	MaxPoints  int
+	Legends    string // One of the Legends constants
+	Sort       string // One of the SortBy constants
+	Descending bool
+	Limit      int // Page size, 1 to 1000; the server's default is 100
+	Offset     int
+	Debug      bool // Return resolution warnings
+}
+
+func (q UnitQuery) values() url.Values {
+	values := url.Values{}
+	for name, filter := range map[string][]string{"faction": q.Factions, "category": q.Categories, "catalogue": q.Catalogues} {
+		for _, value := range filter {
+			values.Add(name, value)
+		}
+	}
+	set := func(name, value string) {
+		if value != "" {
+			values.Set(name, value)
+		}
+	}
+	number := func(name string, value int) {
+		if value != 0 {
+			values.Set(name, strconv.Itoa(value))
+		}
+	}
+	set("search", q.Search)
+	number("minPoints", q.MinPoints)
+	number("maxPoints", q.MaxPoints)
+	set("legends", q.Legends)
+	set("sort", q.Sort)
+	if q.Descending {
+		values.Set("order", "desc")
+	}
+	number("limit", q.Limit)
+	number("offset", q.Offset)
+	if q.Debug {
+		values.Set("debug", "true")
+	}
+	return values
+}
+
+// UnitPage is a page of units
+type UnitPage struct {
+	Units    []UnitSummary
+	Total    int
+	Limit    int
+	Offset   int
+	HasMore  bool
+	Warnings []ResolutionWarning
+	Revision string // Snapshot the page was read from; pin later pages to it with Pinned
+}
+
+// ListUnits returns a page of the units matching query
+func (c *Client) ListUnits(ctx context.Context, query UnitQuery) (*UnitPage, error) {
+	page := &UnitPage{}
+	body := response.PaginatedResponse{Data: &page.Units, Warnings: &page.Warnings}
+	resp, err := c.decode(ctx, request{method: http.MethodGet, path: escape("units"), query: query.values()}, &body)
+	if err != nil {
+		return nil, err
+	}
+	page.Total, page.Limit, page.Offset, page.HasMore = body.Total, body.Limit, body.Offset, body.HasMore
+	page.Revision = resp.Header.Get(RevisionHeader)
+	return page, nil
+}
+
+// AllUnits iterates over every unit matching query, a page at a time, starting at its offset.
+// Pages after the first are pinned to the first page's revision, so reloads of the data don't
+// shift units between pages. Iteration stops at the first error, which is yielded.
+func (c *Client) AllUnits(ctx context.Context, query UnitQuery) iter.Seq2[UnitSummary, error] {
+	return func(yield func(UnitSummary, error) bool) {
+		pages := c
+		for {
+			page, err := pages.ListUnits(ctx, query)
+			if err != nil {
+				yield(UnitSummary{}, err)
+				return
+			}
+			for _, unit := range page.Units {
+				if !yield(unit, nil) {
+					return
+				}
+			}
+			if !page.HasMore || len(page.Units) == 0 {
+				return
+			}
+			if pages == c && page.Revision != "" {
+				pages = c.Pinned(page.Revision)
+			}
+			query.Offset += len(page.Units)
+		}
+	}
+}
+
+// GetUnit returns a unit by entryLink or selectionEntry ID, with its resolution warnings if debug
+func (c *Client) GetUnit(ctx context.Context, id string, debug bool) (*Unit, error) {
+	var unit Unit
+	if err := c.get(ctx, escape("units", id), debugQuery(debug), &unit, nil); err != nil {
+		return nil, err
+	}
+	return &unit, nil
+}
+
+// GetUnitWeapons returns the weapons of a unit
+func (c *Client) GetUnitWeapons(ctx context.Context, id string) (*WeaponSet, error) {
+	var weapons WeaponSet
+	if err := c.get(ctx, escape("units", id, "weapons"), nil, &weapons, nil); err != nil {
+		return nil, err
+	}
+	return &weapons, nil
+}
+
+// ExplainUnit explains how each value of a unit was resolved
+func (c *Client) ExplainUnit(ctx context.Context, id string) (*UnitExplanation, error) {
+	var explanation UnitExplanation
+	if err := c.get(ctx, escape("units", id, "explain"), nil, &explanation, nil); err != nil {
+		return nil, err
+	}
+	return &explanation, nil
+}
+
+// GetUnitHistory returns the points history of a unit. It fails with 503 until the server has
+// indexed the history.
+func (c *Client) GetUnitHistory(ctx context.Context, id string) (*UnitHistory, error) {
+	var history UnitHistory
+	if err := c.get(ctx, escape("units", id, "history"), nil, &history, nil); err != nil {
+		return nil, err
+	}
+	return &history, nil
+}
@@ -0,0 +1,2401 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        (unknown)
+// source: grimoire/v1/grimoire.proto
+
+// Typed access to the units and catalogues the REST API serves. The messages mirror the JSON
+// response models in internal/models, field for field.
+
+package grimoirev1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Legends selects whether Legends units are listed
+type Legends int32
+
+const (
+	Legends_LEGENDS_UNSPECIFIED Legends = 0 // Same as LEGENDS_INCLUDE
+	Legends_LEGENDS_INCLUDE     Legends = 1
+	Legends_LEGENDS_EXCLUDE     Legends = 2
+	Legends_LEGENDS_ONLY        Legends = 3
+)
+
+// Enum value maps for Legends.
+var (
+	Legends_name = map[int32]string{
+		0: "LEGENDS_UNSPECIFIED",
+		1: "LEGENDS_INCLUDE",
+		2: "LEGENDS_EXCLUDE",
+		3: "LEGENDS_ONLY",
+	}
+	Legends_value = map[string]int32{
+		"LEGENDS_UNSPECIFIED": 0,
+		"LEGENDS_INCLUDE":     1,
+		"LEGENDS_EXCLUDE":     2,
+		"LEGENDS_ONLY":        3,
+	}
+)
+
+func (x Legends) Enum() *Legends {
+	p := new(Legends)
+	*p = x
+	return p
+}
+
+func (x Legends) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Legends) Descriptor() protoreflect.EnumDescriptor {
+	return file_grimoire_v1_grimoire_proto_enumTypes[0].Descriptor()
+}
+
+func (Legends) Type() protoreflect.EnumType {
+	return &file_grimoire_v1_grimoire_proto_enumTypes[0]
+}
+
+func (x Legends) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Legends.Descriptor instead.
+func (Legends) EnumDescriptor() ([]byte, []int) {
+	return file_grimoire_v1_grimoire_proto_rawDescGZIP(), []int{0}
+}
+
+// UnitSort is the field units are listed by
+type UnitSort int32
+
+const (
+	UnitSort_UNIT_SORT_UNSPECIFIED UnitSort = 0 // Same as UNIT_SORT_NAME
+	UnitSort_UNIT_SORT_NAME        UnitSort = 1
+	UnitSort_UNIT_SORT_POINTS      UnitSort = 2
+	UnitSort_UNIT_SORT_TOUGHNESS   UnitSort = 3
+	UnitSort_UNIT_SORT_WOUNDS      UnitSort = 4
+	UnitSort_UNIT_SORT_OC          UnitSort = 5
+	UnitSort_UNIT_SORT_CATALOGUE   UnitSort = 6
+)
+
+// Enum value maps for UnitSort.
+var (
+	UnitSort_name = map[int32]string{
+		0: "UNIT_SORT_UNSPECIFIED",
+		1: "UNIT_SORT_NAME",
+		2: "UNIT_SORT_POINTS",
+		3: "UNIT_SORT_TOUGHNESS",
+		4: "UNIT_SORT_WOUNDS",
+		5: "UNIT_SORT_OC",
+		6: "UNIT_SORT_CATALOGUE",
+	}
+	UnitSort_value = map[string]int32{
+		"UNIT_SORT_UNSPECIFIED": 0,
+		"UNIT_SORT_NAME":        1,
+		"UNIT_SORT_POINTS":      2,
+		"UNIT_SORT_TOUGHNESS":   3,
+		"UNIT_SORT_WOUNDS":      4,
+		"UNIT_SORT_OC":          5,
+		"UNIT_SORT_CATALOGUE":   6,
+	}
+)
+
+func (x UnitSort) Enum() *UnitSort {
+	p := new(UnitSort)
+	*p = x
+	return p
+}
+
+func (x UnitSort) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (UnitSort) Descriptor() protoreflect.EnumDescriptor {
+	return file_grimoire_v1_grimoire_proto_enumTypes[1].Descriptor()
+}
+
+func (UnitSort) Type() protoreflect.EnumType {
+	return &file_grimoire_v1_grimoire_proto_enumTypes[1]
+}
+
+func (x UnitSort) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use UnitSort.Descriptor instead.
+func (UnitSort) EnumDescriptor() ([]byte, []int) {
+	return file_grimoire_v1_grimoire_proto_rawDescGZIP(), []int{1}
+}
+
+type GetUnitRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Keep the resolution warnings, like ?debug=true
+	Debug         bool `protobuf:"varint,2,opt,name=debug,proto3" json:"debug,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUnitRequest) Reset() {
+	*x = GetUnitRequest{}
+	mi := &file_grimoire_v1_grimoire_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUnitRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUnitRequest) ProtoMessage() {}
+
+func (x *GetUnitRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_grimoire_v1_grimoire_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUnitRequest.ProtoReflect.Descriptor instead.
+func (*GetUnitRequest) Descriptor() ([]byte, []int) {
+	return file_grimoire_v1_grimoire_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *GetUnitRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *GetUnitRequest) GetDebug() bool {
+	if x != nil {
+		return x.Debug
+	}
+	return false
+}
+
+// ListUnitsRequest takes the filters of GET /api/v1/units. Values of a repeated filter are
+// ORed; different filters are ANDed.
+type ListUnitsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Factions      []string               `protobuf:"bytes,1,rep,name=factions,proto3" json:"factions,omitempty"`                     // Faction IDs, names, keywords, catalogues or super-factions
+	Categories    []string               `protobuf:"bytes,2,rep,name=categories,proto3" json:"categories,omitempty"`                 // Category names (substring match)
+	Catalogues    []string               `protobuf:"bytes,3,rep,name=catalogues,proto3" json:"catalogues,omitempty"`                 // Catalogue IDs or names
+	Search        string                 `protobuf:"bytes,4,opt,name=search,proto3" json:"search,omitempty"`                         // Unit name substring
+	MinPoints     int32                  `protobuf:"varint,5,opt,name=min_points,json=minPoints,proto3" json:"min_points,omitempty"` // 0 for no lower bound
+	MaxPoints     int32                  `protobuf:"varint,6,opt,name=max_points,json=maxPoints,proto3" json:"max_points,omitempty"` // 0 for no upper bound
+	Legends       Legends                `protobuf:"varint,7,opt,name=legends,proto3,enum=grimoire.v1.Legends" json:"legends,omitempty"`
+	Sort          UnitSort               `protobuf:"varint,8,opt,name=sort,proto3,enum=grimoire.v1.UnitSort" json:"sort,omitempty"`
+	Descending    bool                   `protobuf:"varint,9,opt,name=descending,proto3" json:"descending,omitempty"`
+	Limit         int32                  `protobuf:"varint,10,opt,name=limit,proto3" json:"limit,omitempty"` // 0 streams every matching unit
+	Offset        int32                  `protobuf:"varint,11,opt,name=offset,proto3" json:"offset,omitempty"`
+	Debug         bool                   `protobuf:"varint,12,opt,name=debug,proto3" json:"debug,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListUnitsRequest) Reset() {
+	*x = ListUnitsRequest{}
+	mi := &file_grimoire_v1_grimoire_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListUnitsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListUnitsRequest) ProtoMessage() {}
+
+func (x *ListUnitsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_grimoire_v1_grimoire_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListUnitsRequest.ProtoReflect.Descriptor instead.
+func (*ListUnitsRequest) Descriptor() ([]byte, []int) {
+	return file_grimoire_v1_grimoire_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ListUnitsRequest) GetFactions() []string {
+	if x != nil {
+		return x.Factions
+	}
+	return nil
+}
+
+func (x *ListUnitsRequest) GetCategories() []string {
+	if x != nil {
+		return x.Categories
+	}
+	return nil
+}
+
+func (x *ListUnitsRequest) GetCatalogues() []string {
+	if x != nil {
+		return x.Catalogues
+	}
+	return nil
+}
+
+func (x *ListUnitsRequest) GetSearch() string {
+	if x != nil {
+		return x.Search
+	}
+	return ""
+}
+
+func (x *ListUnitsRequest) GetMinPoints() int32 {
+	if x != nil {
+		return x.MinPoints
+	}
+	return 0
+}
+
+func (x *ListUnitsRequest) GetMaxPoints() int32 {
+	if x != nil {
+		return x.MaxPoints
+	}
+	return 0
+}
+
+func (x *ListUnitsRequest) GetLegends() Legends {
+	if x != nil {
+		return x.Legends
+	}
+	return Legends_LEGENDS_UNSPECIFIED
+}
+
+func (x *ListUnitsRequest) GetSort() UnitSort {
+	if x != nil {
+		return x.Sort
+	}
+	return UnitSort_UNIT_SORT_UNSPECIFIED
+}
+
+func (x *ListUnitsRequest) GetDescending() bool {
+	if x != nil {
+		return x.Descending
+	}
+	return false
+}
+
+func (x *ListUnitsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListUnitsRequest) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *ListUnitsRequest) GetDebug() bool {
+	if x != nil {
+		return x.Debug
+	}
+	return false
+}
+
+type SearchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Query         string                 `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	Limit         int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"` // 1 to 200; 0 for 50
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchRequest) Reset() {
+	*x = SearchRequest{}
+	mi := &file_grimoire_v1_grimoire_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchRequest) ProtoMessage() {}
+
+func (x *SearchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_grimoire_v1_grimoire_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchRequest.ProtoReflect.Descriptor instead.
+func (*SearchRequest) Descriptor() ([]byte, []int) {
+	return file_grimoire_v1_grimoire_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *SearchRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *SearchRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type SearchResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Query         string                 `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	Results       []*SearchResult        `protobuf:"bytes,2,rep,name=results,proto3" json:"results,omitempty"`
+	Total         int32                  `protobuf:"varint,3,opt,name=total,proto3" json:"total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchResponse) Reset() {
+	*x = SearchResponse{}
+	mi := &file_grimoire_v1_grimoire_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchResponse) ProtoMessage() {}
+
+func (x *SearchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_grimoire_v1_grimoire_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchResponse.ProtoReflect.Descriptor instead.
+func (*SearchResponse) Descriptor() ([]byte, []int) {
+	return file_grimoire_v1_grimoire_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *SearchResponse) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *SearchResponse) GetResults() []*SearchResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+func (x *SearchResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+// SearchResult is a unit, weapon or ability matching a search
+type SearchResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"` // unit, weapon or ability
+	Id            string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Summary       string                 `protobuf:"bytes,4,opt,name=summary,proto3" json:"summary,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchResult) Reset() {
+	*x = SearchResult{}
+	mi := &file_grimoire_v1_grimoire_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchResult) ProtoMessage() {}
+
+func (x *SearchResult) ProtoReflect() protoreflect.Message {
+	mi := &file_grimoire_v1_grimoire_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchResult.ProtoReflect.Descriptor instead.
+func (*SearchResult) Descriptor() ([]byte, []int) {
+	return file_grimoire_v1_grimoire_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *SearchResult) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *SearchResult) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *SearchResult) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *SearchResult) GetSummary() string {
+	if x != nil {
+		return x.Summary
+	}
+	return ""
+}
+
+type GetCatalogueRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Debug         bool                   `protobuf:"varint,2,opt,name=debug,proto3" json:"debug,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCatalogueRequest) Reset() {
+	*x = GetCatalogueRequest{}
+	mi := &file_grimoire_v1_grimoire_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCatalogueRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCatalogueRequest) ProtoMessage() {}
+
+func (x *GetCatalogueRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_grimoire_v1_grimoire_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCatalogueRequest.ProtoReflect.Descriptor instead.
+func (*GetCatalogueRequest) Descriptor() ([]byte, []int) {
+	return file_grimoire_v1_grimoire_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetCatalogueRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *GetCatalogueRequest) GetDebug() bool {
+	if x != nil {
+		return x.Debug
+	}
+	return false
+}
+
+// Unit mirrors models.UnitResponse
+type Unit struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Type          string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
+	Publication   *PublicationInfo       `protobuf:"bytes,4,opt,name=publication,proto3" json:"publication,omitempty"`
+	Profiles      *UnitProfiles          `protobuf:"bytes,5,opt,name=profiles,proto3" json:"profiles,omitempty"`
+	Weapons       *WeaponSet             `protobuf:"bytes,6,opt,name=weapons,proto3" json:"weapons,omitempty"`
+	Categories    []*CategoryInfo        `protobuf:"bytes,7,rep,name=categories,proto3" json:"categories,omitempty"`
+	Rules         []*RuleInfo            `protobuf:"bytes,8,rep,name=rules,proto3" json:"rules,omitempty"`
+	Costs         map[string]int32       `protobuf:"bytes,9,rep,name=costs,proto3" json:"costs,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	TieredCosts   *TieredCosts           `protobuf:"bytes,10,opt,name=tiered_costs,json=tieredCosts,proto3" json:"tiered_costs,omitempty"`
+	Constraints   *UnitConstraints       `protobuf:"bytes,11,opt,name=constraints,proto3" json:"constraints,omitempty"`
+	Faction       *FactionInfo           `protobuf:"bytes,12,opt,name=faction,proto3" json:"faction,omitempty"`
+	Catalogue     *CatalogueInfo         `protobuf:"bytes,13,opt,name=catalogue,proto3" json:"catalogue,omitempty"`
+	Warnings      []*ResolutionWarning   `protobuf:"bytes,14,rep,name=warnings,proto3" json:"warnings,omitempty"` // Only set for debug requests
+	Overlays      []*OverlayMark         `protobuf:"bytes,15,rep,name=overlays,proto3" json:"overlays,omitempty"` // Values changed by local overlays
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Unit) Reset() {
+	*x = Unit{}
+	mi := &file_grimoire_v1_grimoire_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Unit) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Unit) ProtoMessage() {}
+
+func (x *Unit) ProtoReflect() protoreflect.Message {
+	mi := &file_grimoire_v1_grimoire_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Unit.ProtoReflect.Descriptor instead.
+func (*Unit) Descriptor() ([]byte, []int) {
+	return file_grimoire_v1_grimoire_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Unit) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Unit) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Unit) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Unit) GetPublication() *PublicationInfo {
+	if x != nil {
+		return x.Publication
+	}
+	return nil
+}
+
+func (x *Unit) GetProfiles() *UnitProfiles {
+	if x != nil {
+		return x.Profiles
+	}
+	return nil
+}
+
+func (x *Unit) GetWeapons() *WeaponSet {
+	if x != nil {
+		return x.Weapons
+	}
+	return nil
+}
+
+func (x *Unit) GetCategories() []*CategoryInfo {
+	if x != nil {
+		return x.Categories
+	}
+	return nil
+}
+
+func (x *Unit) GetRules() []*RuleInfo {
+	if x != nil {
+		return x.Rules
+	}
+	return nil
+}
+
+func (x *Unit) GetCosts() map[string]int32 {
+	if x != nil {
+		return x.Costs
+	}
+	return nil
+}
+
+func (x *Unit) GetTieredCosts() *TieredCosts {
+	if x != nil {
+		return x.TieredCosts
+	}
+	return nil
+}
+
+func (x *Unit) GetConstraints() *UnitConstraints {
+	if x != nil {
+		return x.Constraints
+	}
+	return nil
+}
+
+func (x *Unit) GetFaction() *FactionInfo {
+	if x != nil {
+		return x.Faction
+	}
+	return nil
+}
+
+func (x *Unit) GetCatalogue() *CatalogueInfo {
+	if x != nil {
+		return x.Catalogue
+	}
+	return nil
+}
+
+func (x *Unit) GetWarnings() []*ResolutionWarning {
+	if x != nil {
+		return x.Warnings
+	}
+	return nil
+}
+
+func (x *Unit) GetOverlays() []*OverlayMark {
+	if x != nil {
+		return x.Overlays
+	}
+	return nil
+}
+
+type UnitProfiles struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Unit          *UnitProfile           `protobuf:"bytes,1,opt,name=unit,proto3" json:"unit,omitempty"`
+	Abilities     []*AbilityProfile      `protobuf:"bytes,2,rep,name=abilities,proto3" json:"abilities,omitempty"`
+	Transport     *TransportProfile      `protobuf:"bytes,3,opt,name=transport,proto3" json:"transport,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnitProfiles) Reset() {
+	*x = UnitProfiles{}
+	mi := &file_grimoire_v1_grimoire_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnitProfiles) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnitProfiles) ProtoMessage() {}
+
+func (x *UnitProfiles) ProtoReflect() protoreflect.Message {
+	mi := &file_grimoire_v1_grimoire_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnitProfiles.ProtoReflect.Descriptor instead.
+func (*UnitProfiles) Descriptor() ([]byte, []int) {
+	return file_grimoire_v1_grimoire_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *UnitProfiles) GetUnit() *UnitProfile {
+	if x != nil {
+		return x.Unit
+	}
+	return nil
+}
+
+func (x *UnitProfiles) GetAbilities() []*AbilityProfile {
+	if x != nil {
+		return x.Abilities
+	}
+	return nil
+}
+
+func (x *UnitProfiles) GetTransport() *TransportProfile {
+	if x != nil {
+		return x.Transport
+	}
+	return nil
+}
+
+type UnitProfile struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Movement         string                 `protobuf:"bytes,1,opt,name=movement,proto3" json:"movement,omitempty"`
+	Toughness        int32                  `protobuf:"varint,2,opt,name=toughness,proto3" json:"toughness,omitempty"`
+	Save             string                 `protobuf:"bytes,3,opt,name=save,proto3" json:"save,omitempty"`
+	Wounds           int32                  `protobuf:"varint,4,opt,name=wounds,proto3" json:"wounds,omitempty"`
+	Leadership       string                 `protobuf:"bytes,5,opt,name=leadership,proto3" json:"leadership,omitempty"`
+	ObjectiveControl int32                  `protobuf:"varint,6,opt,name=objective_control,json=objectiveControl,proto3" json:"objective_control,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *UnitProfile) Reset() {
+	*x = UnitProfile{}
+	mi := &file_grimoire_v1_grimoire_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnitProfile) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnitProfile) ProtoMessage() {}
+
+func (x *UnitProfile) ProtoReflect() protoreflect.Message {
+	mi := &file_grimoire_v1_grimoire_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnitProfile.ProtoReflect.Descriptor instead.
+func (*UnitProfile) Descriptor() ([]byte, []int) {
+	return file_grimoire_v1_grimoire_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *UnitProfile) GetMovement() string {
+	if x != nil {
+		return x.Movement
+	}
+	return ""
+}
+
+func (x *UnitProfile) GetToughness() int32 {
+	if x != nil {
+		return x.Toughness
+	}
+	return 0
+}
+
+func (x *UnitProfile) GetSave() string {
+	if x != nil {
+		return x.Save
+	}
+	return ""
+}
+
+func (x *UnitProfile) GetWounds() int32 {
+	if x != nil {
+		return x.Wounds
+	}
+	return 0
+}
+
+func (x *UnitProfile) GetLeadership() string {
+	if x != nil {
+		return x.Leadership
+	}
+	return ""
+}
+
+func (x *UnitProfile) GetObjectiveControl() int32 {
+	if x != nil {
+		return x.ObjectiveControl
+	}
+	return 0
+}
+
+type AbilityProfile struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Description   string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AbilityProfile) Reset() {
+	*x = AbilityProfile{}
+	mi := &file_grimoire_v1_grimoire_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AbilityProfile) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AbilityProfile) ProtoMessage() {}
+
+func (x *AbilityProfile) ProtoReflect() protoreflect.Message {
+	mi := &file_grimoire_v1_grimoire_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AbilityProfile.ProtoReflect.Descriptor instead.
+func (*AbilityProfile) Descriptor() ([]byte, []int) {
+	return file_grimoire_v1_grimoire_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *AbilityProfile) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *AbilityProfile) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+type TransportProfile struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Capacity      string                 `protobuf:"bytes,1,opt,name=capacity,proto3" json:"capacity,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TransportProfile) Reset() {
+	*x = TransportProfile{}
+	mi := &file_grimoire_v1_grimoire_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TransportProfile) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransportProfile) ProtoMessage() {}
+
+func (x *TransportProfile) ProtoReflect() protoreflect.Message {
+	mi := &file_grimoire_v1_grimoire_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransportProfile.ProtoReflect.Descriptor instead.
+func (*TransportProfile) Descriptor() ([]byte, []int) {
+	return file_grimoire_v1_grimoire_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *TransportProfile) GetCapacity() string {
+	if x != nil {
+		return x.Capacity
+	}
+	return ""
+}
+
+type WeaponSet struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ranged        []*RangedWeapon        `protobuf:"bytes,1,rep,name=ranged,proto3" json:"ranged,omitempty"`
+	Melee         []*MeleeWeapon         `protobuf:"bytes,2,rep,name=melee,proto3" json:"melee,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WeaponSet) Reset() {
+	*x = WeaponSet{}
+	mi := &file_grimoire_v1_grimoire_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WeaponSet) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WeaponSet) ProtoMessage() {}
+
+func (x *WeaponSet) ProtoReflect() protoreflect.Message {
+	mi := &file_grimoire_v1_grimoire_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WeaponSet.ProtoReflect.Descriptor instead.
+func (*WeaponSet) Descriptor() ([]byte, []int) {
+	return file_grimoire_v1_grimoire_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *WeaponSet) GetRanged() []*RangedWeapon {
+	if x != nil {
+		return x.Ranged
+	}
+	return nil
+}
+
+func (x *WeaponSet) GetMelee() []*MeleeWeapon {
+	if x != nil {
+		return x.Melee
+	}
+	return nil
+}
+
+type RangedWeapon struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Name             string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Range            string                 `protobuf:"bytes,2,opt,name=range,proto3" json:"range,omitempty"`
+	Attacks          string                 `protobuf:"bytes,3,opt,name=attacks,proto3" json:"attacks,omitempty"`
+	BallisticSkill   string                 `protobuf:"bytes,4,opt,name=ballistic_skill,json=ballisticSkill,proto3" json:"ballistic_skill,omitempty"`
+	Strength         string                 `protobuf:"bytes,5,opt,name=strength,proto3" json:"strength,omitempty"`
+	ArmorPenetration string                 `protobuf:"bytes,6,opt,name=armor_penetration,json=armorPenetration,proto3" json:"armor_penetration,omitempty"`
+	Damage           string                 `protobuf:"bytes,7,opt,name=damage,proto3" json:"damage,omitempty"`
+	Keywords         []string               `protobuf:"bytes,8,rep,name=keywords,proto3" json:"keywords,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *RangedWeapon) Reset() {
+	*x = RangedWeapon{}
+	mi := &file_grimoire_v1_grimoire_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RangedWeapon) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RangedWeapon) ProtoMessage() {}
+
+func (x *RangedWeapon) ProtoReflect() protoreflect.Message {
+	mi := &file_grimoire_v1_grimoire_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RangedWeapon.ProtoReflect.Descriptor instead.
+func (*RangedWeapon) Descriptor() ([]byte, []int) {
+	return file_grimoire_v1_grimoire_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *RangedWeapon) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *RangedWeapon) GetRange() string {
+	if x != nil {
+		return x.Range
+	}
+	return ""
+}
+
+func (x *RangedWeapon) GetAttacks() string {
+	if x != nil {
+		return x.Attacks
+	}
+	return ""
+}
+
+func (x *RangedWeapon) GetBallisticSkill() string {
+	if x != nil {
+		return x.BallisticSkill
+	}
+	return ""
+}
+
+func (x *RangedWeapon) GetStrength() string {
+	if x != nil {
+		return x.Strength
+	}
+	return ""
+}
+
+func (x *RangedWeapon) GetArmorPenetration() string {
+	if x != nil {
+		return x.ArmorPenetration
+	}
+	return ""
+}
+
+func (x *RangedWeapon) GetDamage() string {
+	if x != nil {
+		return x.Damage
+	}
+	return ""
+}
+
+func (x *RangedWeapon) GetKeywords() []string {
+	if x != nil {
+		return x.Keywords
+	}
+	return nil
+}
+
+type MeleeWeapon struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Name             string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Range            string                 `protobuf:"bytes,2,opt,name=range,proto3" json:"range,omitempty"`
+	Attacks          string                 `protobuf:"bytes,3,opt,name=attacks,proto3" json:"attacks,omitempty"`
+	WeaponSkill      string                 `protobuf:"bytes,4,opt,name=weapon_skill,json=weaponSkill,proto3" json:"weapon_skill,omitempty"`
+	Strength         string                 `protobuf:"bytes,5,opt,name=strength,proto3" json:"strength,omitempty"`
+	ArmorPenetration string                 `protobuf:"bytes,6,opt,name=armor_penetration,json=armorPenetration,proto3" json:"armor_penetration,omitempty"`
+	Damage           string                 `protobuf:"bytes,7,opt,name=damage,proto3" json:"damage,omitempty"`
+	Keywords         []string               `protobuf:"bytes,8,rep,name=keywords,proto3" json:"keywords,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *MeleeWeapon) Reset() {
+	*x = MeleeWeapon{}
+	mi := &file_grimoire_v1_grimoire_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MeleeWeapon) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MeleeWeapon) ProtoMessage() {}
+
+func (x *MeleeWeapon) ProtoReflect() protoreflect.Message {
+	mi := &file_grimoire_v1_grimoire_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MeleeWeapon.ProtoReflect.Descriptor instead.
+func (*MeleeWeapon) Descriptor() ([]byte, []int) {
+	return file_grimoire_v1_grimoire_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *MeleeWeapon) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *MeleeWeapon) GetRange() string {
+	if x != nil {
+		return x.Range
+	}
+	return ""
+}
+
+func (x *MeleeWeapon) GetAttacks() string {
+	if x != nil {
+		return x.Attacks
+	}
+	return ""
+}
+
+func (x *MeleeWeapon) GetWeaponSkill() string {
+	if x != nil {
+		return x.WeaponSkill
+	}
+	return ""
+}
+
+func (x *MeleeWeapon) GetStrength() string {
+	if x != nil {
+		return x.Strength
+	}
+	return ""
+}
+
+func (x *MeleeWeapon) GetArmorPenetration() string {
+	if x != nil {
+		return x.ArmorPenetration
+	}
+	return ""
+}
+
+func (x *MeleeWeapon) GetDamage() string {
+	if x != nil {
+		return x.Damage
+	}
+	return ""
+}
+
+func (x *MeleeWeapon) GetKeywords() []string {
+	if x != nil {
+		return x.Keywords
+	}
+	return nil
+}
+
+type CategoryInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Primary       bool                   `protobuf:"varint,3,opt,name=primary,proto3" json:"primary,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CategoryInfo) Reset() {
+	*x = CategoryInfo{}
+	mi := &file_grimoire_v1_grimoire_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CategoryInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CategoryInfo) ProtoMessage() {}
+
+func (x *CategoryInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_grimoire_v1_grimoire_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CategoryInfo.ProtoReflect.Descriptor instead.
+func (*CategoryInfo) Descriptor() ([]byte, []int) {
+	return file_grimoire_v1_grimoire_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *CategoryInfo) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *CategoryInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CategoryInfo) GetPrimary() bool {
+	if x != nil {
+		return x.Primary
+	}
+	return false
+}
+
+type RuleInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RuleInfo) Reset() {
+	*x = RuleInfo{}
+	mi := &file_grimoire_v1_grimoire_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RuleInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RuleInfo) ProtoMessage() {}
+
+func (x *RuleInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_grimoire_v1_grimoire_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RuleInfo.ProtoReflect.Descriptor instead.
+func (*RuleInfo) Descriptor() ([]byte, []int) {
+	return file_grimoire_v1_grimoire_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *RuleInfo) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *RuleInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+// TieredCosts are costs that vary with the number of models
+type TieredCosts struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BaseCost      int32                  `protobuf:"varint,1,opt,name=base_cost,json=baseCost,proto3" json:"base_cost,omitempty"`
+	Tiers         []*CostTier            `protobuf:"bytes,2,rep,name=tiers,proto3" json:"tiers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TieredCosts) Reset() {
+	*x = TieredCosts{}
+	mi := &file_grimoire_v1_grimoire_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TieredCosts) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TieredCosts) ProtoMessage() {}
+
+func (x *TieredCosts) ProtoReflect() protoreflect.Message {
+	mi := &file_grimoire_v1_grimoire_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TieredCosts.ProtoReflect.Descriptor instead.
+func (*TieredCosts) Descriptor() ([]byte, []int) {
+	return file_grimoire_v1_grimoire_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *TieredCosts) GetBaseCost() int32 {
+	if x != nil {
+		return x.BaseCost
+	}
+	return 0
+}
+
+func (x *TieredCosts) GetTiers() []*CostTier {
+	if x != nil {
+		return x.Tiers
+	}
+	return nil
+}
+
+type CostTier struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MinModels     int32                  `protobuf:"varint,1,opt,name=min_models,json=minModels,proto3" json:"min_models,omitempty"`
+	Cost          int32                  `protobuf:"varint,2,opt,name=cost,proto3" json:"cost,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CostTier) Reset() {
+	*x = CostTier{}
+	mi := &file_grimoire_v1_grimoire_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CostTier) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CostTier) ProtoMessage() {}
+
+func (x *CostTier) ProtoReflect() protoreflect.Message {
+	mi := &file_grimoire_v1_grimoire_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CostTier.ProtoReflect.Descriptor instead.
+func (*CostTier) Descriptor() ([]byte, []int) {
+	return file_grimoire_v1_grimoire_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *CostTier) GetMinModels() int32 {
+	if x != nil {
+		return x.MinModels
+	}
+	return 0
+}
+
+func (x *CostTier) GetCost() int32 {
+	if x != nil {
+		return x.Cost
+	}
+	return 0
+}
+
+type UnitConstraints struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MaxPerRoster  int32                  `protobuf:"varint,1,opt,name=max_per_roster,json=maxPerRoster,proto3" json:"max_per_roster,omitempty"`
+	MinPerRoster  int32                  `protobuf:"varint,2,opt,name=min_per_roster,json=minPerRoster,proto3" json:"min_per_roster,omitempty"`
+	MaxPerForce   int32                  `protobuf:"varint,3,opt,name=max_per_force,json=maxPerForce,proto3" json:"max_per_force,omitempty"`
+	MinPerForce   int32                  `protobuf:"varint,4,opt,name=min_per_force,json=minPerForce,proto3" json:"min_per_force,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnitConstraints) Reset() {
+	*x = UnitConstraints{}
+	mi := &file_grimoire_v1_grimoire_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnitConstraints) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnitConstraints) ProtoMessage() {}
+
+func (x *UnitConstraints) ProtoReflect() protoreflect.Message {
+	mi := &file_grimoire_v1_grimoire_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnitConstraints.ProtoReflect.Descriptor instead.
+func (*UnitConstraints) Descriptor() ([]byte, []int) {
+	return file_grimoire_v1_grimoire_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *UnitConstraints) GetMaxPerRoster() int32 {
+	if x != nil {
+		return x.MaxPerRoster
+	}
+	return 0
+}
+
+func (x *UnitConstraints) GetMinPerRoster() int32 {
+	if x != nil {
+		return x.MinPerRoster
+	}
+	return 0
+}
+
+func (x *UnitConstraints) GetMaxPerForce() int32 {
+	if x != nil {
+		return x.MaxPerForce
+	}
+	return 0
+}
+
+func (x *UnitConstraints) GetMinPerForce() int32 {
+	if x != nil {
+		return x.MinPerForce
+	}
+	return 0
+}
+
+type PublicationInfo struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Id              string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name            string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	ShortName       string                 `protobuf:"bytes,3,opt,name=short_name,json=shortName,proto3" json:"short_name,omitempty"`
+	PublicationDate string                 `protobuf:"bytes,4,opt,name=publication_date,json=publicationDate,proto3" json:"publication_date,omitempty"`
+	Page            string                 `protobuf:"bytes,5,opt,name=page,proto3" json:"page,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *PublicationInfo) Reset() {
+	*x = PublicationInfo{}
+	mi := &file_grimoire_v1_grimoire_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PublicationInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PublicationInfo) ProtoMessage() {}
+
+func (x *PublicationInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_grimoire_v1_grimoire_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PublicationInfo.ProtoReflect.Descriptor instead.
+func (*PublicationInfo) Descriptor() ([]byte, []int) {
+	return file_grimoire_v1_grimoire_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *PublicationInfo) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *PublicationInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *PublicationInfo) GetShortName() string {
+	if x != nil {
+		return x.ShortName
+	}
+	return ""
+}
+
+func (x *PublicationInfo) GetPublicationDate() string {
+	if x != nil {
+		return x.PublicationDate
+	}
+	return ""
+}
+
+func (x *PublicationInfo) GetPage() string {
+	if x != nil {
+		return x.Page
+	}
+	return ""
+}
+
+type FactionInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FactionInfo) Reset() {
+	*x = FactionInfo{}
+	mi := &file_grimoire_v1_grimoire_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FactionInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FactionInfo) ProtoMessage() {}
+
+func (x *FactionInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_grimoire_v1_grimoire_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FactionInfo.ProtoReflect.Descriptor instead.
+func (*FactionInfo) Descriptor() ([]byte, []int) {
+	return file_grimoire_v1_grimoire_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *FactionInfo) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *FactionInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type CatalogueInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Revision      string                 `protobuf:"bytes,3,opt,name=revision,proto3" json:"revision,omitempty"`
+	Library       bool                   `protobuf:"varint,4,opt,name=library,proto3" json:"library,omitempty"`
+	Homebrew      bool                   `protobuf:"varint,5,opt,name=homebrew,proto3" json:"homebrew,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CatalogueInfo) Reset() {
+	*x = CatalogueInfo{}
+	mi := &file_grimoire_v1_grimoire_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CatalogueInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CatalogueInfo) ProtoMessage() {}
+
+func (x *CatalogueInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_grimoire_v1_grimoire_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CatalogueInfo.ProtoReflect.Descriptor instead.
+func (*CatalogueInfo) Descriptor() ([]byte, []int) {
+	return file_grimoire_v1_grimoire_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *CatalogueInfo) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *CatalogueInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CatalogueInfo) GetRevision() string {
+	if x != nil {
+		return x.Revision
+	}
+	return ""
+}
+
+func (x *CatalogueInfo) GetLibrary() bool {
+	if x != nil {
+		return x.Library
+	}
+	return false
+}
+
+func (x *CatalogueInfo) GetHomebrew() bool {
+	if x != nil {
+		return x.Homebrew
+	}
+	return false
+}
+
+// ResolutionWarning explains why a link or value was left out of a response
+type ResolutionWarning struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	CatalogueId   string                 `protobuf:"bytes,3,opt,name=catalogue_id,json=catalogueId,proto3" json:"catalogue_id,omitempty"`
+	EntryId       string                 `protobuf:"bytes,4,opt,name=entry_id,json=entryId,proto3" json:"entry_id,omitempty"`
+	LinkId        string                 `protobuf:"bytes,5,opt,name=link_id,json=linkId,proto3" json:"link_id,omitempty"`
+	TargetId      string                 `protobuf:"bytes,6,opt,name=target_id,json=targetId,proto3" json:"target_id,omitempty"`
+	File          string                 `protobuf:"bytes,7,opt,name=file,proto3" json:"file,omitempty"`
+	Line          int32                  `protobuf:"varint,8,opt,name=line,proto3" json:"line,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResolutionWarning) Reset() {
+	*x = ResolutionWarning{}
+	mi := &file_grimoire_v1_grimoire_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResolutionWarning) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResolutionWarning) ProtoMessage() {}
+
+func (x *ResolutionWarning) ProtoReflect() protoreflect.Message {
+	mi := &file_grimoire_v1_grimoire_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResolutionWarning.ProtoReflect.Descriptor instead.
+func (*ResolutionWarning) Descriptor() ([]byte, []int) {
+	return file_grimoire_v1_grimoire_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *ResolutionWarning) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *ResolutionWarning) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *ResolutionWarning) GetCatalogueId() string {
+	if x != nil {
+		return x.CatalogueId
+	}
+	return ""
+}
+
+func (x *ResolutionWarning) GetEntryId() string {
+	if x != nil {
+		return x.EntryId
+	}
+	return ""
+}
+
+func (x *ResolutionWarning) GetLinkId() string {
+	if x != nil {
+		return x.LinkId
+	}
+	return ""
+}
+
+func (x *ResolutionWarning) GetTargetId() string {
+	if x != nil {
+		return x.TargetId
+	}
+	return ""
+}
+
+func (x *ResolutionWarning) GetFile() string {
+	if x != nil {
+		return x.File
+	}
+	return ""
+}
+
+func (x *ResolutionWarning) GetLine() int32 {
+	if x != nil {
+		return x.Line
+	}
+	return 0
+}
+
+// OverlayMark records a value an overlay changed
+type OverlayMark struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Overlay       string                 `protobuf:"bytes,1,opt,name=overlay,proto3" json:"overlay,omitempty"`
+	TargetId      string                 `protobuf:"bytes,2,opt,name=target_id,json=targetId,proto3" json:"target_id,omitempty"`
+	Field         string                 `protobuf:"bytes,3,opt,name=field,proto3" json:"field,omitempty"`
+	Original      string                 `protobuf:"bytes,4,opt,name=original,proto3" json:"original,omitempty"`
+	Value         string                 `protobuf:"bytes,5,opt,name=value,proto3" json:"value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OverlayMark) Reset() {
+	*x = OverlayMark{}
+	mi := &file_grimoire_v1_grimoire_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OverlayMark) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OverlayMark) ProtoMessage() {}
+
+func (x *OverlayMark) ProtoReflect() protoreflect.Message {
+	mi := &file_grimoire_v1_grimoire_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OverlayMark.ProtoReflect.Descriptor instead.
+func (*OverlayMark) Descriptor() ([]byte, []int) {
+	return file_grimoire_v1_grimoire_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *OverlayMark) GetOverlay() string {
+	if x != nil {
+		return x.Overlay
+	}
+	return ""
+}
+
+func (x *OverlayMark) GetTargetId() string {
+	if x != nil {
+		return x.TargetId
+	}
+	return ""
+}
+
+func (x *OverlayMark) GetField() string {
+	if x != nil {
+		return x.Field
+	}
+	return ""
+}
+
+func (x *OverlayMark) GetOriginal() string {
+	if x != nil {
+		return x.Original
+	}
+	return ""
+}
+
+func (x *OverlayMark) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+// UnitSummary mirrors models.UnitSummary
+type UnitSummary struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	TargetId      string                 `protobuf:"bytes,3,opt,name=target_id,json=targetId,proto3" json:"target_id,omitempty"`
+	Costs         map[string]int32       `protobuf:"bytes,4,rep,name=costs,proto3" json:"costs,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	TieredCosts   *TieredCosts           `protobuf:"bytes,5,opt,name=tiered_costs,json=tieredCosts,proto3" json:"tiered_costs,omitempty"`
+	Type          string                 `protobuf:"bytes,6,opt,name=type,proto3" json:"type,omitempty"`
+	Catalogue     *CatalogueInfo         `protobuf:"bytes,7,opt,name=catalogue,proto3" json:"catalogue,omitempty"`
+	Overlays      []*OverlayMark         `protobuf:"bytes,8,rep,name=overlays,proto3" json:"overlays,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnitSummary) Reset() {
+	*x = UnitSummary{}
+	mi := &file_grimoire_v1_grimoire_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnitSummary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnitSummary) ProtoMessage() {}
+
+func (x *UnitSummary) ProtoReflect() protoreflect.Message {
+	mi := &file_grimoire_v1_grimoire_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnitSummary.ProtoReflect.Descriptor instead.
+func (*UnitSummary) Descriptor() ([]byte, []int) {
+	return file_grimoire_v1_grimoire_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *UnitSummary) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *UnitSummary) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *UnitSummary) GetTargetId() string {
+	if x != nil {
+		return x.TargetId
+	}
+	return ""
+}
+
+func (x *UnitSummary) GetCosts() map[string]int32 {
+	if x != nil {
+		return x.Costs
+	}
+	return nil
+}
+
+func (x *UnitSummary) GetTieredCosts() *TieredCosts {
+	if x != nil {
+		return x.TieredCosts
+	}
+	return nil
+}
+
+func (x *UnitSummary) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *UnitSummary) GetCatalogue() *CatalogueInfo {
+	if x != nil {
+		return x.Catalogue
+	}
+	return nil
+}
+
+func (x *UnitSummary) GetOverlays() []*OverlayMark {
+	if x != nil {
+		return x.Overlays
+	}
+	return nil
+}
+
+// Catalogue mirrors models.CatalogueResponse
+type Catalogue struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Id               string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name             string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Revision         string                 `protobuf:"bytes,3,opt,name=revision,proto3" json:"revision,omitempty"`
+	Library          bool                   `protobuf:"varint,4,opt,name=library,proto3" json:"library,omitempty"`
+	GameSystemId     string                 `protobuf:"bytes,5,opt,name=game_system_id,json=gameSystemId,proto3" json:"game_system_id,omitempty"`
+	LinkedCatalogues []*CatalogueInfo       `protobuf:"bytes,6,rep,name=linked_catalogues,json=linkedCatalogues,proto3" json:"linked_catalogues,omitempty"`
+	Units            []*UnitSummary         `protobuf:"bytes,7,rep,name=units,proto3" json:"units,omitempty"`
+	Publications     []*PublicationInfo     `protobuf:"bytes,8,rep,name=publications,proto3" json:"publications,omitempty"`
+	Warnings         []*ResolutionWarning   `protobuf:"bytes,9,rep,name=warnings,proto3" json:"warnings,omitempty"` // Only set for debug requests
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *Catalogue) Reset() {
+	*x = Catalogue{}
+	mi := &file_grimoire_v1_grimoire_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Catalogue) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Catalogue) ProtoMessage() {}
+
+func (x *Catalogue) ProtoReflect() protoreflect.Message {
+	mi := &file_grimoire_v1_grimoire_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Catalogue.ProtoReflect.Descriptor instead.
+func (*Catalogue) Descriptor() ([]byte, []int) {
+	return file_grimoire_v1_grimoire_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *Catalogue) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Catalogue) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Catalogue) GetRevision() string {
+	if x != nil {
+		return x.Revision
+	}
+	return ""
+}
+
+func (x *Catalogue) GetLibrary() bool {
+	if x != nil {
+		return x.Library
+	}
+	return false
+}
+
+func (x *Catalogue) GetGameSystemId() string {
+	if x != nil {
+		return x.GameSystemId
+	}
+	return ""
+}
+
+func (x *Catalogue) GetLinkedCatalogues() []*CatalogueInfo {
+	if x != nil {
+		return x.LinkedCatalogues
+	}
+	return nil
+}
+
+func (x *Catalogue) GetUnits() []*UnitSummary {
+	if x != nil {
+		return x.Units
+	}
+	return nil
+}
+
+func (x *Catalogue) GetPublications() []*PublicationInfo {
+	if x != nil {
+		return x.Publications
+	}
+	return nil
+}
+
+func (x *Catalogue) GetWarnings() []*ResolutionWarning {
+	if x != nil {
+		return x.Warnings
+	}
+	return nil
+}
+
+var File_grimoire_v1_grimoire_proto protoreflect.FileDescriptor
+
+const file_grimoire_v1_grimoire_proto_rawDesc = "" +
+	"\n" +
+	"\x1agrimoire/v1/grimoire.proto\x12\vgrimoire.v1\"6\n" +
+	"\x0eGetUnitRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
+	"\x05debug\x18\x02 \x01(\bR\x05debug\"\x83\x03\n" +
+	"\x10ListUnitsRequest\x12\x1a\n" +
+	"\bfactions\x18\x01 \x03(\tR\bfactions\x12\x1e\n" +
+	"\n" +
+	"categories\x18\x02 \x03(\tR\n" +
+	"categories\x12\x1e\n" +
+	"\n" +
+	"catalogues\x18\x03 \x03(\tR\n" +
+	"catalogues\x12\x16\n" +
+	"\x06search\x18\x04 \x01(\tR\x06search\x12\x1d\n" +
+	"\n" +
+	"min_points\x18\x05 \x01(\x05R\tminPoints\x12\x1d\n" +
+	"\n" +
+	"max_points\x18\x06 \x01(\x05R\tmaxPoints\x12.\n" +
+	"\alegends\x18\a \x01(\x0e2\x14.grimoire.v1.LegendsR\alegends\x12)\n" +
+	"\x04sort\x18\b \x01(\x0e2\x15.grimoire.v1.UnitSortR\x04sort\x12\x1e\n" +
+	"\n" +
+	"descending\x18\t \x01(\bR\n" +
+	"descending\x12\x14\n" +
+	"\x05limit\x18\n" +
+	" \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06offset\x18\v \x01(\x05R\x06offset\x12\x14\n" +
+	"\x05debug\x18\f \x01(\bR\x05debug\";\n" +
+	"\rSearchRequest\x12\x14\n" +
+	"\x05query\x18\x01 \x01(\tR\x05query\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\"q\n" +
+	"\x0eSearchResponse\x12\x14\n" +
+	"\x05query\x18\x01 \x01(\tR\x05query\x123\n" +
+	"\aresults\x18\x02 \x03(\v2\x19.grimoire.v1.SearchResultR\aresults\x12\x14\n" +
+	"\x05total\x18\x03 \x01(\x05R\x05total\"`\n" +
+	"\fSearchResult\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12\x18\n" +
+	"\asummary\x18\x04 \x01(\tR\asummary\";\n" +
+	"\x13GetCatalogueRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
+	"\x05debug\x18\x02 \x01(\bR\x05debug\"\x9a\x06\n" +
+	"\x04Unit\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x12\n" +
+	"\x04type\x18\x03 \x01(\tR\x04type\x12>\n" +
+	"\vpublication\x18\x04 \x01(\v2\x1c.grimoire.v1.PublicationInfoR\vpublication\x125\n" +
+	"\bprofiles\x18\x05 \x01(\v2\x19.grimoire.v1.UnitProfilesR\bprofiles\x120\n" +
+	"\aweapons\x18\x06 \x01(\v2\x16.grimoire.v1.WeaponSetR\aweapons\x129\n" +
+	"\n" +
+	"categories\x18\a \x03(\v2\x19.grimoire.v1.CategoryInfoR\n" +
+	"categories\x12+\n" +
+	"\x05rules\x18\b \x03(\v2\x15.grimoire.v1.RuleInfoR\x05rules\x122\n" +
+	"\x05costs\x18\t \x03(\v2\x1c.grimoire.v1.Unit.CostsEntryR\x05costs\x12;\n" +
+	"\ftiered_costs\x18\n" +
+	" \x01(\v2\x18.grimoire.v1.TieredCostsR\vtieredCosts\x12>\n" +
+	"\vconstraints\x18\v \x01(\v2\x1c.grimoire.v1.UnitConstraintsR\vconstraints\x122\n" +
+	"\afaction\x18\f \x01(\v2\x18.grimoire.v1.FactionInfoR\afaction\x128\n" +
+	"\tcatalogue\x18\r \x01(\v2\x1a.grimoire.v1.CatalogueInfoR\tcatalogue\x12:\n" +
+	"\bwarnings\x18\x0e \x03(\v2\x1e.grimoire.v1.ResolutionWarningR\bwarnings\x124\n" +
+	"\boverlays\x18\x0f \x03(\v2\x18.grimoire.v1.OverlayMarkR\boverlays\x1a8\n" +
+	"\n" +
+	"CostsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x05R\x05value:\x028\x01\"\xb4\x01\n" +
+	"\fUnitProfiles\x12,\n" +
+	"\x04unit\x18\x01 \x01(\v2\x18.grimoire.v1.UnitProfileR\x04unit\x129\n" +
+	"\tabilities\x18\x02 \x03(\v2\x1b.grimoire.v1.AbilityProfileR\tabilities\x12;\n" +
+	"\ttransport\x18\x03 \x01(\v2\x1d.grimoire.v1.TransportProfileR\ttransport\"\xc0\x01\n" +
+	"\vUnitProfile\x12\x1a\n" +
+	"\bmovement\x18\x01 \x01(\tR\bmovement\x12\x1c\n" +
+	"\ttoughness\x18\x02 \x01(\x05R\ttoughness\x12\x12\n" +
+	"\x04save\x18\x03 \x01(\tR\x04save\x12\x16\n" +
+	"\x06wounds\x18\x04 \x01(\x05R\x06wounds\x12\x1e\n" +
+	"\n" +
+	"leadership\x18\x05 \x01(\tR\n" +
+	"leadership\x12+\n" +
+	"\x11objective_control\x18\x06 \x01(\x05R\x10objectiveControl\"F\n" +
+	"\x0eAbilityProfile\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\".\n" +
+	"\x10TransportProfile\x12\x1a\n" +
+	"\bcapacity\x18\x01 \x01(\tR\bcapacity\"n\n" +
+	"\tWeaponSet\x121\n" +
+	"\x06ranged\x18\x01 \x03(\v2\x19.grimoire.v1.RangedWeaponR\x06ranged\x12.\n" +
+	"\x05melee\x18\x02 \x03(\v2\x18.grimoire.v1.MeleeWeaponR\x05melee\"\xf8\x01\n" +
+	"\fRangedWeapon\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05range\x18\x02 \x01(\tR\x05range\x12\x18\n" +
+	"\aattacks\x18\x03 \x01(\tR\aattacks\x12'\n" +
+	"\x0fballistic_skill\x18\x04 \x01(\tR\x0eballisticSkill\x12\x1a\n" +
+	"\bstrength\x18\x05 \x01(\tR\bstrength\x12+\n" +
+	"\x11armor_penetration\x18\x06 \x01(\tR\x10armorPenetration\x12\x16\n" +
+	"\x06damage\x18\a \x01(\tR\x06damage\x12\x1a\n" +
+	"\bkeywords\x18\b \x03(\tR\bkeywords\"\xf1\x01\n" +
+	"\vMeleeWeapon\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05range\x18\x02 \x01(\tR\x05range\x12\x18\n" +
+	"\aattacks\x18\x03 \x01(\tR\aattacks\x12!\n" +
+	"\fweapon_skill\x18\x04 \x01(\tR\vweaponSkill\x12\x1a\n" +
+	"\bstrength\x18\x05 \x01(\tR\bstrength\x12+\n" +
+	"\x11armor_penetration\x18\x06 \x01(\tR\x10armorPenetration\x12\x16\n" +
+	"\x06damage\x18\a \x01(\tR\x06damage\x12\x1a\n" +
+	"\bkeywords\x18\b \x03(\tR\bkeywords\"L\n" +
+	"\fCategoryInfo\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x18\n" +
+	"\aprimary\x18\x03 \x01(\bR\aprimary\".\n" +
+	"\bRuleInfo\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\"W\n" +
+	"\vTieredCosts\x12\x1b\n" +
+	"\tbase_cost\x18\x01 \x01(\x05R\bbaseCost\x12+\n" +
+	"\x05tiers\x18\x02 \x03(\v2\x15.grimoire.v1.CostTierR\x05tiers\"=\n" +
+	"\bCostTier\x12\x1d\n" +
+	"\n" +
+	"min_models\x18\x01 \x01(\x05R\tminModels\x12\x12\n" +
+	"\x04cost\x18\x02 \x01(\x05R\x04cost\"\xa5\x01\n" +
+	"\x0fUnitConstraints\x12$\n" +
+	"\x0emax_per_roster\x18\x01 \x01(\x05R\fmaxPerRoster\x12$\n" +
+	"\x0emin_per_roster\x18\x02 \x01(\x05R\fminPerRoster\x12\"\n" +
+	"\rmax_per_force\x18\x03 \x01(\x05R\vmaxPerForce\x12\"\n" +
+	"\rmin_per_force\x18\x04 \x01(\x05R\vminPerForce\"\x93\x01\n" +
+	"\x0fPublicationInfo\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1d\n" +
+	"\n" +
+	"short_name\x18\x03 \x01(\tR\tshortName\x12)\n" +
+	"\x10publication_date\x18\x04 \x01(\tR\x0fpublicationDate\x12\x12\n" +
+	"\x04page\x18\x05 \x01(\tR\x04page\"1\n" +
+	"\vFactionInfo\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\"\x85\x01\n" +
+	"\rCatalogueInfo\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1a\n" +
+	"\brevision\x18\x03 \x01(\tR\brevision\x12\x18\n" +
+	"\alibrary\x18\x04 \x01(\bR\alibrary\x12\x1a\n" +
+	"\bhomebrew\x18\x05 \x01(\bR\bhomebrew\"\xdd\x01\n" +
+	"\x11ResolutionWarning\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12!\n" +
+	"\fcatalogue_id\x18\x03 \x01(\tR\vcatalogueId\x12\x19\n" +
+	"\bentry_id\x18\x04 \x01(\tR\aentryId\x12\x17\n" +
+	"\alink_id\x18\x05 \x01(\tR\x06linkId\x12\x1b\n" +
+	"\ttarget_id\x18\x06 \x01(\tR\btargetId\x12\x12\n" +
+	"\x04file\x18\a \x01(\tR\x04file\x12\x12\n" +
+	"\x04line\x18\b \x01(\x05R\x04line\"\x8c\x01\n" +
+	"\vOverlayMark\x12\x18\n" +
+	"\aoverlay\x18\x01 \x01(\tR\aoverlay\x12\x1b\n" +
+	"\ttarget_id\x18\x02 \x01(\tR\btargetId\x12\x14\n" +
+	"\x05field\x18\x03 \x01(\tR\x05field\x12\x1a\n" +
+	"\boriginal\x18\x04 \x01(\tR\boriginal\x12\x14\n" +
+	"\x05value\x18\x05 \x01(\tR\x05value\"\x84\x03\n" +
+	"\vUnitSummary\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1b\n" +
+	"\ttarget_id\x18\x03 \x01(\tR\btargetId\x129\n" +
+	"\x05costs\x18\x04 \x03(\v2#.grimoire.v1.UnitSummary.CostsEntryR\x05costs\x12;\n" +
+	"\ftiered_costs\x18\x05 \x01(\v2\x18.grimoire.v1.TieredCostsR\vtieredCosts\x12\x12\n" +
+	"\x04type\x18\x06 \x01(\tR\x04type\x128\n" +
+	"\tcatalogue\x18\a \x01(\v2\x1a.grimoire.v1.CatalogueInfoR\tcatalogue\x124\n" +
+	"\boverlays\x18\b \x03(\v2\x18.grimoire.v1.OverlayMarkR\boverlays\x1a8\n" +
+	"\n" +
+	"CostsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x05R\x05value:\x028\x01\"\x82\x03\n" +
+	"\tCatalogue\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1a\n" +
+	"\brevision\x18\x03 \x01(\tR\brevision\x12\x18\n" +
+	"\alibrary\x18\x04 \x01(\bR\alibrary\x12$\n" +
+	"\x0egame_system_id\x18\x05 \x01(\tR\fgameSystemId\x12G\n" +
+	"\x11linked_catalogues\x18\x06 \x03(\v2\x1a.grimoire.v1.CatalogueInfoR\x10linkedCatalogues\x12.\n" +
+	"\x05units\x18\a \x03(\v2\x18.grimoire.v1.UnitSummaryR\x05units\x12@\n" +
+	"\fpublications\x18\b \x03(\v2\x1c.grimoire.v1.PublicationInfoR\fpublications\x12:\n" +
+	"\bwarnings\x18\t \x03(\v2\x1e.grimoire.v1.ResolutionWarningR\bwarnings*^\n" +
+	"\aLegends\x12\x17\n" +
+	"\x13LEGENDS_UNSPECIFIED\x10\x00\x12\x13\n" +
+	"\x0fLEGENDS_INCLUDE\x10\x01\x12\x13\n" +
+	"\x0fLEGENDS_EXCLUDE\x10\x02\x12\x10\n" +
+	"\fLEGENDS_ONLY\x10\x03*\xa9\x01\n" +
+	"\bUnitSort\x12\x19\n" +
+	"\x15UNIT_SORT_UNSPECIFIED\x10\x00\x12\x12\n" +
+	"\x0eUNIT_SORT_NAME\x10\x01\x12\x14\n" +
+	"\x10UNIT_SORT_POINTS\x10\x02\x12\x17\n" +
+	"\x13UNIT_SORT_TOUGHNESS\x10\x03\x12\x14\n" +
+	"\x10UNIT_SORT_WOUNDS\x10\x04\x12\x10\n" +
+	"\fUNIT_SORT_OC\x10\x05\x12\x17\n" +
+	"\x13UNIT_SORT_CATALOGUE\x10\x062\x9a\x02\n" +
+	"\x0fGrimoireService\x129\n" +
+	"\aGetUnit\x12\x1b.grimoire.v1.GetUnitRequest\x1a\x11.grimoire.v1.Unit\x12?\n" +
+	"\tListUnits\x12\x1d.grimoire.v1.ListUnitsRequest\x1a\x11.grimoire.v1.Unit0\x01\x12A\n" +
+	"\x06Search\x12\x1a.grimoire.v1.SearchRequest\x1a\x1b.grimoire.v1.SearchResponse\x12H\n" +
+	"\fGetCatalogue\x12 .grimoire.v1.GetCatalogueRequest\x1a\x16.grimoire.v1.CatalogueB,Z*grimoire-api/pkg/pb/grimoire/v1;grimoirev1b\x06proto3"
+
+var (
+	file_grimoire_v1_grimoire_proto_rawDescOnce sync.Once
+	file_grimoire_v1_grimoire_proto_rawDescData []byte
+)
+
+func file_grimoire_v1_grimoire_proto_rawDescGZIP() []byte {
+	file_grimoire_v1_grimoire_proto_rawDescOnce.Do(func() {
+		file_grimoire_v1_grimoire_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_grimoire_v1_grimoire_proto_rawDesc), len(file_grimoire_v1_grimoire_proto_rawDesc)))
+	})
+	return file_grimoire_v1_grimoire_proto_rawDescData
+}
+
+var file_grimoire_v1_grimoire_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_grimoire_v1_grimoire_proto_msgTypes = make([]protoimpl.MessageInfo, 28)
+var file_grimoire_v1_grimoire_proto_goTypes = []any{
+	(Legends)(0),                // 0: grimoire.v1.Legends
+	(UnitSort)(0),               // 1: grimoire.v1.UnitSort
+	(*GetUnitRequest)(nil),      // 2: grimoire.v1.GetUnitRequest
+	(*ListUnitsRequest)(nil),    // 3: grimoire.v1.ListUnitsRequest
+	(*SearchRequest)(nil),       // 4: grimoire.v1.SearchRequest
+	(*SearchResponse)(nil),      // 5: grimoire.v1.SearchResponse
+	(*SearchResult)(nil),        // 6: grimoire.v1.SearchResult
+	(*GetCatalogueRequest)(nil), // 7: grimoire.v1.GetCatalogueRequest
+	(*Unit)(nil),                // 8: grimoire.v1.Unit
+	(*UnitProfiles)(nil),        // 9: grimoire.v1.UnitProfiles
+	(*UnitProfile)(nil),         // 10: grimoire.v1.UnitProfile
+	(*AbilityProfile)(nil),      // 11: grimoire.v1.AbilityProfile
+	(*TransportProfile)(nil),    // 12: grimoire.v1.TransportProfile
+	(*WeaponSet)(nil),           // 13: grimoire.v1.WeaponSet
+	(*RangedWeapon)(nil),        // 14: grimoire.v1.RangedWeapon
+	(*MeleeWeapon)(nil),         // 15: grimoire.v1.MeleeWeapon
+	(*CategoryInfo)(nil),        // 16: grimoire.v1.CategoryInfo
+	(*RuleInfo)(nil),            // 17: grimoire.v1.RuleInfo
+	(*TieredCosts)(nil),         // 18: grimoire.v1.TieredCosts
+	(*CostTier)(nil),            // 19: grimoire.v1.CostTier
+	(*UnitConstraints)(nil),     // 20: grimoire.v1.UnitConstraints
+	(*PublicationInfo)(nil),     // 21: grimoire.v1.PublicationInfo
+	(*FactionInfo)(nil),         // 22: grimoire.v1.FactionInfo
+	(*CatalogueInfo)(nil),       // 23: grimoire.v1.CatalogueInfo
+	(*ResolutionWarning)(nil),   // 24: grimoire.v1.ResolutionWarning
+	(*OverlayMark)(nil),         // 25: grimoire.v1.OverlayMark
+	(*UnitSummary)(nil),         // 26: grimoire.v1.UnitSummary
+	(*Catalogue)(nil),           // 27: grimoire.v1.Catalogue
+	nil,                         // 28: grimoire.v1.Unit.CostsEntry
+	nil,                         // 29: grimoire.v1.UnitSummary.CostsEntry
+}
+var file_grimoire_v1_grimoire_proto_depIdxs = []int32{
+	0,  // 0: grimoire.v1.ListUnitsRequest.legends:type_name -> grimoire.v1.Legends
+	1,  // 1: grimoire.v1.ListUnitsRequest.sort:type_name -> grimoire.v1.UnitSort
+	6,  // 2: grimoire.v1.SearchResponse.results:type_name -> grimoire.v1.SearchResult
+	21, // 3: grimoire.v1.Unit.publication:type_name -> grimoire.v1.PublicationInfo
+	9,  // 4: grimoire.v1.Unit.profiles:type_name -> grimoire.v1.UnitProfiles
+	13, // 5: grimoire.v1.Unit.weapons:type_name -> grimoire.v1.WeaponSet
+	16, // 6: grimoire.v1.Unit.categories:type_name -> grimoire.v1.CategoryInfo
+	17, // 7: grimoire.v1.Unit.rules:type_name -> grimoire.v1.RuleInfo
+	28, // 8: grimoire.v1.Unit.costs:type_name -> grimoire.v1.Unit.CostsEntry
+	18, // 9: grimoire.v1.Unit.tiered_costs:type_name -> grimoire.v1.TieredCosts
+	20, // 10: grimoire.v1.Unit.constraints:type_name -> grimoire.v1.UnitConstraints
+	22, // 11: grimoire.v1.Unit.faction:type_name -> grimoire.v1.FactionInfo
+	23, // 12: grimoire.v1.Unit.catalogue:type_name -> grimoire.v1.CatalogueInfo
+	24, // 13: grimoire.v1.Unit.warnings:type_name -> grimoire.v1.ResolutionWarning
+	25, // 14: grimoire.v1.Unit.overlays:type_name -> grimoire.v1.OverlayMark
+	10, // 15: grimoire.v1.UnitProfiles.unit:type_name -> grimoire.v1.UnitProfile
+	11, // 16: grimoire.v1.UnitProfiles.abilities:type_name -> grimoire.v1.AbilityProfile
+	12, // 17: grimoire.v1.UnitProfiles.transport:type_name -> grimoire.v1.TransportProfile
+	14, // 18: grimoire.v1.WeaponSet.ranged:type_name -> grimoire.v1.RangedWeapon
+	15, // 19: grimoire.v1.WeaponSet.melee:type_name -> grimoire.v1.MeleeWeapon
+	19, // 20: grimoire.v1.TieredCosts.tiers:type_name -> grimoire.v1.CostTier
+	29, // 21: grimoire.v1.UnitSummary.costs:type_name -> grimoire.v1.UnitSummary.CostsEntry
+	18, // 22: grimoire.v1.UnitSummary.tiered_costs:type_name -> grimoire.v1.TieredCosts
+	23, // 23: grimoire.v1.UnitSummary.catalogue:type_name -> grimoire.v1.CatalogueInfo
+	25, // 24: grimoire.v1.UnitSummary.overlays:type_name -> grimoire.v1.OverlayMark
+	23, // 25: grimoire.v1.Catalogue.linked_catalogues:type_name -> grimoire.v1.CatalogueInfo
+	26, // 26: grimoire.v1.Catalogue.units:type_name -> grimoire.v1.UnitSummary
+	21, // 27: grimoire.v1.Catalogue.publications:type_name -> grimoire.v1.PublicationInfo
+	24, // 28: grimoire.v1.Catalogue.warnings:type_name -> grimoire.v1.ResolutionWarning
+	2,  // 29: grimoire.v1.GrimoireService.GetUnit:input_type -> grimoire.v1.GetUnitRequest
+	3,  // 30: grimoire.v1.GrimoireService.ListUnits:input_type -> grimoire.v1.ListUnitsRequest
+	4,  // 31: grimoire.v1.GrimoireService.Search:input_type -> grimoire.v1.SearchRequest
+	7,  // 32: grimoire.v1.GrimoireService.GetCatalogue:input_type -> grimoire.v1.GetCatalogueRequest
+	8,  // 33: grimoire.v1.GrimoireService.GetUnit:output_type -> grimoire.v1.Unit
+	8,  // 34: grimoire.v1.GrimoireService.ListUnits:output_type -> grimoire.v1.Unit
+	5,  // 35: grimoire.v1.GrimoireService.Search:output_type -> grimoire.v1.SearchResponse
+	27, // 36: grimoire.v1.GrimoireService.GetCatalogue:output_type -> grimoire.v1.Catalogue
+	33, // [33:37] is the sub-list for method output_type
+	29, // [29:33] is the sub-list for method input_type
+	29, // [29:29] is the sub-list for extension type_name
+	29, // [29:29] is the sub-list for extension extendee
+	0,  // [0:29] is the sub-list for field type_name
+}
+
+func init() { file_grimoire_v1_grimoire_proto_init() }
+func file_grimoire_v1_grimoire_proto_init() {
+	if File_grimoire_v1_grimoire_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_grimoire_v1_grimoire_proto_rawDesc), len(file_grimoire_v1_grimoire_proto_rawDesc)),
+			NumEnums:      2,
+			NumMessages:   28,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_grimoire_v1_grimoire_proto_goTypes,
+		DependencyIndexes: file_grimoire_v1_grimoire_proto_depIdxs,
+		EnumInfos:         file_grimoire_v1_grimoire_proto_enumTypes,
+		MessageInfos:      file_grimoire_v1_grimoire_proto_msgTypes,
+	}.Build()
+	File_grimoire_v1_grimoire_proto = out.File
+	file_grimoire_v1_grimoire_proto_goTypes = nil
+	file_grimoire_v1_grimoire_proto_depIdxs = nil
+}
@@ -0,0 +1,256 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: grimoire/v1/grimoire.proto
+
+// Typed access to the units and catalogues the REST API serves. The messages mirror the JSON
+// response models in internal/models, field for field.
+
+package grimoirev1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	GrimoireService_GetUnit_FullMethodName      = "/grimoire.v1.GrimoireService/GetUnit"
+	GrimoireService_ListUnits_FullMethodName    = "/grimoire.v1.GrimoireService/ListUnits"
+	GrimoireService_Search_FullMethodName       = "/grimoire.v1.GrimoireService/Search"
+	GrimoireService_GetCatalogue_FullMethodName = "/grimoire.v1.GrimoireService/GetCatalogue"
+)
+
+// GrimoireServiceClient is the client API for GrimoireService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// GrimoireService reads the current snapshot of the data, or the one named by the
+// x-data-revision request metadata. Responses carry the revision read in x-data-revision.
+type GrimoireServiceClient interface {
+	// GetUnit returns a unit by entryLink or selectionEntry ID
+	GetUnit(ctx context.Context, in *GetUnitRequest, opts ...grpc.CallOption) (*Unit, error)
+	// ListUnits streams every unit matching the filters, in full, for bulk exports
+	ListUnits(ctx context.Context, in *ListUnitsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Unit], error)
+	// Search finds units by name
+	Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error)
+	// GetCatalogue returns a catalogue or library with the units at its root
+	GetCatalogue(ctx context.Context, in *GetCatalogueRequest, opts ...grpc.CallOption) (*Catalogue, error)
+}
+
+type grimoireServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewGrimoireServiceClient(cc grpc.ClientConnInterface) GrimoireServiceClient {
+	return &grimoireServiceClient{cc}
+}
+
+func (c *grimoireServiceClient) GetUnit(ctx context.Context, in *GetUnitRequest, opts ...grpc.CallOption) (*Unit, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Unit)
+	err := c.cc.Invoke(ctx, GrimoireService_GetUnit_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *grimoireServiceClient) ListUnits(ctx context.Context, in *ListUnitsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Unit], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &GrimoireService_ServiceDesc.Streams[0], GrimoireService_ListUnits_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ListUnitsRequest, Unit]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type GrimoireService_ListUnitsClient = grpc.ServerStreamingClient[Unit]
+
+func (c *grimoireServiceClient) Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SearchResponse)
+	err := c.cc.Invoke(ctx, GrimoireService_Search_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *grimoireServiceClient) GetCatalogue(ctx context.Context, in *GetCatalogueRequest, opts ...grpc.CallOption) (*Catalogue, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Catalogue)
+	err := c.cc.Invoke(ctx, GrimoireService_GetCatalogue_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GrimoireServiceServer is the server API for GrimoireService service.
+// All implementations must embed UnimplementedGrimoireServiceServer
+// for forward compatibility.
+//
+// GrimoireService reads the current snapshot of the data, or the one named by the
+// x-data-revision request metadata. Responses carry the revision read in x-data-revision.
+type GrimoireServiceServer interface {
+	// GetUnit returns a unit by entryLink or selectionEntry ID
+	GetUnit(context.Context, *GetUnitRequest) (*Unit, error)
+	// ListUnits streams every unit matching the filters, in full, for bulk exports
+	ListUnits(*ListUnitsRequest, grpc.ServerStreamingServer[Unit]) error
+	// Search finds units by name
+	Search(context.Context, *SearchRequest) (*SearchResponse, error)
+	// GetCatalogue returns a catalogue or library with the units at its root
+	GetCatalogue(context.Context, *GetCatalogueRequest) (*Catalogue, error)
+	mustEmbedUnimplementedGrimoireServiceServer()
+}
+
+// UnimplementedGrimoireServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedGrimoireServiceServer struct{}
+
+func (UnimplementedGrimoireServiceServer) GetUnit(context.Context, *GetUnitRequest) (*Unit, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUnit not implemented")
+}
+func (UnimplementedGrimoireServiceServer) ListUnits(*ListUnitsRequest, grpc.ServerStreamingServer[Unit]) error {
+	return status.Errorf(codes.Unimplemented, "method ListUnits not implemented")
+}
+func (UnimplementedGrimoireServiceServer) Search(context.Context, *SearchRequest) (*SearchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Search not implemented")
+}
+func (UnimplementedGrimoireServiceServer) GetCatalogue(context.Context, *GetCatalogueRequest) (*Catalogue, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCatalogue not implemented")
+}
+func (UnimplementedGrimoireServiceServer) mustEmbedUnimplementedGrimoireServiceServer() {}
+func (UnimplementedGrimoireServiceServer) testEmbeddedByValue()                         {}
+
+// UnsafeGrimoireServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to GrimoireServiceServer will
+// result in compilation errors.
+type UnsafeGrimoireServiceServer interface {
+	mustEmbedUnimplementedGrimoireServiceServer()
+}
+
+func RegisterGrimoireServiceServer(s grpc.ServiceRegistrar, srv GrimoireServiceServer) {
+	// If the following call pancis, it indicates UnimplementedGrimoireServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&GrimoireService_ServiceDesc, srv)
+}
+
+func _GrimoireService_GetUnit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUnitRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GrimoireServiceServer).GetUnit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GrimoireService_GetUnit_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GrimoireServiceServer).GetUnit(ctx, req.(*GetUnitRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GrimoireService_ListUnits_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListUnitsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(GrimoireServiceServer).ListUnits(m, &grpc.GenericServerStream[ListUnitsRequest, Unit]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type GrimoireService_ListUnitsServer = grpc.ServerStreamingServer[Unit]
+
+func _GrimoireService_Search_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GrimoireServiceServer).Search(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GrimoireService_Search_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GrimoireServiceServer).Search(ctx, req.(*SearchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GrimoireService_GetCatalogue_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCatalogueRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GrimoireServiceServer).GetCatalogue(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GrimoireService_GetCatalogue_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GrimoireServiceServer).GetCatalogue(ctx, req.(*GetCatalogueRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// GrimoireService_ServiceDesc is the grpc.ServiceDesc for GrimoireService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var GrimoireService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "grimoire.v1.GrimoireService",
+	HandlerType: (*GrimoireServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetUnit",
+			Handler:    _GrimoireService_GetUnit_Handler,
+		},
+		{
+			MethodName: "Search",
+			Handler:    _GrimoireService_Search_Handler,
+		},
+		{
+			MethodName: "GetCatalogue",
+			Handler:    _GrimoireService_GetCatalogue_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ListUnits",
+			Handler:       _GrimoireService_ListUnits_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "grimoire/v1/grimoire.proto",
+}
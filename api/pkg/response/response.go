@@ -14,16 +14,18 @@ type ErrorResponse struct {
 
 // SuccessResponse represents a success response with data
 type SuccessResponse struct {
-	Data interface{} `json:"data"`
+	Data     interface{} `json:"data"`
+	Warnings interface{} `json:"warnings,omitempty"`
 }
 
 // PaginatedResponse represents a paginated response
 type PaginatedResponse struct {
-	Data       interface{} `json:"data"`
-	Total      int         `json:"total"`
-	Limit      int         `json:"limit"`
-	Offset     int         `json:"offset"`
-	HasMore    bool        `json:"hasMore"`
+	Data     interface{} `json:"data"`
+	Total    int         `json:"total"`
+	Limit    int         `json:"limit"`
+	Offset   int         `json:"offset"`
+	HasMore  bool        `json:"hasMore"`
+	Warnings interface{} `json:"warnings,omitempty"`
 }
 
 // Error sends an error response
@@ -39,6 +41,11 @@ func Success(c *gin.Context, data interface{}) {
 	c.JSON(http.StatusOK, SuccessResponse{Data: data})
 }
 
+// Created sends a 201 response with the created resource
+func Created(c *gin.Context, data interface{}) {
+	c.JSON(http.StatusCreated, SuccessResponse{Data: data})
+}
+
 // Paginated sends a paginated response
 func Paginated(c *gin.Context, data interface{}, total, limit, offset int) {
 	c.JSON(http.StatusOK, PaginatedResponse{
@@ -50,6 +57,30 @@ func Paginated(c *gin.Context, data interface{}, total, limit, offset int) {
 	})
 }
 
+// SuccessWithWarnings sends a success response, listing warnings when there are any
+func SuccessWithWarnings[W any](c *gin.Context, data interface{}, warnings []W) {
+	body := SuccessResponse{Data: data}
+	if len(warnings) > 0 {
+		body.Warnings = warnings
+	}
+	c.JSON(http.StatusOK, body)
+}
+
+// PaginatedWithWarnings sends a paginated response, listing warnings when there are any
+func PaginatedWithWarnings[W any](c *gin.Context, data interface{}, total, limit, offset int, warnings []W) {
+	body := PaginatedResponse{
+		Data:    data,
+		Total:   total,
+		Limit:   limit,
+		Offset:  offset,
+		HasMore: offset+limit < total,
+	}
+	if len(warnings) > 0 {
+		body.Warnings = warnings
+	}
+	c.JSON(http.StatusOK, body)
+}
+
 // NotFound sends a 404 response
 func NotFound(c *gin.Context, message string) {
 	Error(c, http.StatusNotFound, message)
@@ -65,4 +96,17 @@ func InternalServerError(c *gin.Context, message string) {
 	Error(c, http.StatusInternalServerError, message)
 }
 
+// NotImplemented sends a 501 response
+func NotImplemented(c *gin.Context, message string) {
+	Error(c, http.StatusNotImplemented, message)
+}
 
+// ServiceUnavailable sends a 503 response
+func ServiceUnavailable(c *gin.Context, message string) {
+	Error(c, http.StatusServiceUnavailable, message)
+}
+
+// GatewayTimeout sends a 504 response
+func GatewayTimeout(c *gin.Context, message string) {
+	Error(c, http.StatusGatewayTimeout, message)
+}
@@ -83,7 +83,7 @@ function UnitDetail() {
               <div>
                 <h3>Ranged Weapons</h3>
                 <div className="weapons-list">
-                  {unit.weapons.ranged.map((weapon: any, idx: number) => (
+                  {unit.weapons.ranged.map((weapon, idx) => (
                     <div key={idx} className="weapon">
                       <h4>{weapon.name}</h4>
                       <div className="weapon-stats">
@@ -102,7 +102,7 @@ function UnitDetail() {
               <div>
                 <h3>Melee Weapons</h3>
                 <div className="weapons-list">
-                  {unit.weapons.melee.map((weapon: any, idx: number) => (
+                  {unit.weapons.melee.map((weapon, idx) => (
                     <div key={idx} className="weapon">
                       <h4>{weapon.name}</h4>
                       <div className="weapon-stats">
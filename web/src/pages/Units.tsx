@@ -1,6 +1,6 @@
 import { useState, useEffect } from 'react'
 import { Link, useParams } from 'react-router-dom'
-import { apiClient, Unit } from '../utils/api'
+import { apiClient, Faction, Unit } from '../utils/api'
 import './Units.css'
 
 function Units() {
@@ -10,6 +10,14 @@ function Units() {
   const [search, setSearch] = useState('')
   const [faction, setFaction] = useState(factionParam || '')
   const [hasLoaded, setHasLoaded] = useState(false)
+  const [factions, setFactions] = useState<Faction[]>([])
+
+  useEffect(() => {
+    // Faction filters match a faction's exact name, so offer the names to pick from
+    apiClient.listFactions()
+      .then((response) => setFactions(response.data.data || []))
+      .catch(() => setFactions([]))
+  }, [])
 
   useEffect(() => {
     if (factionParam) {
@@ -70,13 +78,19 @@ function Units() {
           onChange={(e) => setSearch(e.target.value)}
           className="search-input"
         />
-        <input
-          type="text"
-          placeholder="Filter by faction..."
+        <select
           value={faction}
           onChange={(e) => setFaction(e.target.value)}
           className="faction-input"
-        />
+        >
+          <option value="">All factions</option>
+          {faction && !factions.some((f) => f.name === faction) && (
+            <option value={faction}>{faction}</option>
+          )}
+          {factions.map((f) => (
+            <option key={f.id} value={f.name}>{f.name}</option>
+          ))}
+        </select>
       </div>
       {hasLoaded && units.length === 0 ? (
         <p className="no-units">No units found.</p>
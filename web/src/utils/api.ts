@@ -19,6 +19,34 @@ export interface TieredCosts {
   tiers: CostTier[]
 }
 
+// Weapon profiles, as described by the RangedWeapon and MeleeWeapon schemas of /api/v1/openapi.json
+export interface RangedWeapon {
+  name: string
+  range: string
+  attacks: string
+  ballisticSkill: string
+  strength: string
+  armorPenetration: string
+  damage: string
+  keywords: string[] | null
+}
+
+export interface MeleeWeapon {
+  name: string
+  range: string
+  attacks: string
+  weaponSkill: string
+  strength: string
+  armorPenetration: string
+  damage: string
+  keywords: string[] | null
+}
+
+export interface WeaponSet {
+  ranged?: RangedWeapon[]
+  melee?: MeleeWeapon[]
+}
+
 export interface Unit {
   id: string
   name: string
@@ -37,10 +65,7 @@ export interface Unit {
       description: string
     }>
   }
-  weapons?: {
-    ranged: Array<any>
-    melee: Array<any>
-  }
+  weapons?: WeaponSet | null
   categories?: Array<{
     id: string
     name: string
@@ -58,8 +83,18 @@ export interface Catalogue {
 }
 
 export interface Faction {
+  id: string
   name: string
-  catalogues: string[]
+  superFaction?: string
+  parentId?: string
+  keyword?: {
+    id: string
+    name: string
+  }
+  catalogue: Catalogue
+  subFactions: Catalogue[]
+  libraries: Catalogue[]
+  unitCount: number
 }
 
 export const apiClient = {
@@ -84,7 +119,7 @@ export const apiClient = {
 
   // Factions
   listFactions: () => api.get('/factions'),
-  getFactionUnits: (name: string) => api.get(`/factions/${name}/units`),
+  getFactionUnits: (name: string) => api.get(`/factions/${encodeURIComponent(name)}/units`),
 
   // Search
   search: (query: string, limit?: number) =>